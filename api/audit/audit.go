@@ -0,0 +1,185 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/schema"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/audit"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+var decoder = schema.NewDecoder()
+
+// Request holds the filters of an audit query, empty filters match every event
+type Request struct {
+	Action    string `schema:"action"`
+	Principal string `schema:"principal"`
+	Cluster   string `schema:"cluster"`
+	Namespace string `schema:"namespace"`
+	Release   string `schema:"release"`
+	Outcome   string `schema:"outcome"`
+	// RFC 3339 timestamps
+	Since string `schema:"since"`
+	Until string `schema:"until"`
+	Limit int    `schema:"limit"`
+}
+
+// Event is an audit record of a mutating operation
+// swagger:model auditEvent
+type Event struct {
+	// example: 8c5f1c2a9e6b4d0f8a7e3b2c1d0e9f8a
+	ID string `json:"id"`
+	// example: 2021-03-24T12:24:18.450869Z
+	Time time.Time `json:"time"`
+	// one of install, upgrade, uninstall, rollback, repo_add, repo_remove or repo_update
+	// example: upgrade
+	Action string `json:"action"`
+	// example: jane
+	Principal string `json:"principal,omitempty"`
+	// example: 10.0.0.12
+	SourceIP string `json:"source_ip,omitempty"`
+	// example: staging
+	Cluster string `json:"cluster,omitempty"`
+	// example: default
+	Namespace string `json:"namespace,omitempty"`
+	// example: mysql-final
+	Release string `json:"release,omitempty"`
+	// Name of the repository for repository actions
+	// example: stable
+	Repository string `json:"repository,omitempty"`
+	// URL of the repository for repo_add
+	// example: https://charts.helm.sh/stable
+	URL string `json:"url,omitempty"`
+	// example: stable/mysql
+	Chart string `json:"chart,omitempty"`
+	// example: 1.6.9
+	ChartVersion string `json:"chart_version,omitempty"`
+	// example: sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a
+	ValuesHash string `json:"values_hash,omitempty"`
+	// example: false
+	DryRun bool `json:"dry_run"`
+	// one of success or failure
+	// example: success
+	Outcome audit.Outcome `json:"outcome"`
+	// example: 200
+	StatusCode int `json:"status_code"`
+	// example: release: not found
+	Error string `json:"error,omitempty"`
+	// example: 2
+	Revision int `json:"revision,omitempty"`
+	// example: 5321
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// Response is the body of /audit
+// swagger:model auditResponseBody
+type Response struct {
+	Events []Event `json:"events"`
+}
+
+type service interface {
+	Query(ctx context.Context, req Request) ([]Event, error)
+}
+
+// Handler handles an audit query
+// swagger:operation GET /audit audit queryAudit
+//
+//
+// ---
+// summary: Query the audit events of install, upgrade, uninstall, rollback and repository operations
+// produces:
+// - application/json
+// parameters:
+// - name: action
+//   in: query
+//   type: string
+// - name: principal
+//   in: query
+//   type: string
+// - name: cluster
+//   in: query
+//   type: string
+// - name: namespace
+//   in: query
+//   type: string
+// - name: release
+//   in: query
+//   type: string
+// - name: outcome
+//   in: query
+//   type: string
+// - name: since
+//   in: query
+//   type: string
+//   format: date-time
+// - name: until
+//   in: query
+//   type: string
+//   format: date-time
+// - name: limit
+//   in: query
+//   type: integer
+//   default: 100
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/auditResponseBody"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func Handler(s service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req Request
+		if err := decoder.Decode(&req, r.URL.Query()); err != nil {
+			respondAuditError(w, "error decoding request", apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+		if err := req.valid(); err != nil {
+			respondAuditError(w, "error validating request", apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+
+		events, err := s.Query(r.Context(), req)
+		if err != nil {
+			respondAuditError(w, "error querying audit events", err)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(Response{Events: events}); err != nil {
+			logger.Errorf("[Audit] error writing response: %v", err)
+		}
+	})
+}
+
+func respondAuditError(w http.ResponseWriter, logprefix string, err error) {
+	logger.Errorf("[Audit] %s: %v", logprefix, err)
+	apiErrors.Write(w, err)
+}
+
+func (req Request) valid() error {
+	if req.Outcome != "" && req.Outcome != string(audit.Success) && req.Outcome != string(audit.Failure) {
+		return fmt.Errorf("outcome must be %s or %s", audit.Success, audit.Failure)
+	}
+	if req.Limit < 0 {
+		return fmt.Errorf("limit cannot be negative")
+	}
+	for name, value := range map[string]string{"since": req.Since, "until": req.Until} {
+		if value == "" {
+			continue
+		}
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Errorf("%s must be an RFC 3339 timestamp", name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/gojekfarm/albatross/pkg/audit"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+type mockService struct {
+	mock.Mock
+}
+
+func (m *mockService) Query(ctx context.Context, req Request) ([]Event, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).([]Event), args.Error(1)
+}
+
+type AuditTestSuite struct {
+	suite.Suite
+	server      *httptest.Server
+	mockService *mockService
+}
+
+func (s *AuditTestSuite) SetupSuite() {
+	logger.Setup("default")
+}
+
+func (s *AuditTestSuite) SetupTest() {
+	s.mockService = new(mockService)
+	router := mux.NewRouter()
+	router.Handle("/audit", Handler(s.mockService)).Methods(http.MethodGet)
+	s.server = httptest.NewServer(router)
+}
+
+func (s *AuditTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *AuditTestSuite) get(query string) (int, string) {
+	resp, err := http.Get(s.server.URL + "/audit" + query)
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	require.NoError(s.T(), err)
+	return resp.StatusCode, strings.TrimSpace(string(body))
+}
+
+func (s *AuditTestSuite) TestShouldQueryEvents() {
+	req := Request{Cluster: "staging", Release: "mysql", Outcome: "failure", Since: "2021-03-24T12:00:00Z", Limit: 10}
+	event := Event{
+		ID:         "1",
+		Time:       time.Date(2021, 3, 24, 12, 30, 0, 0, time.UTC),
+		Action:     "upgrade",
+		Cluster:    "staging",
+		Release:    "mysql",
+		Outcome:    audit.Failure,
+		StatusCode: http.StatusInternalServerError,
+		DurationMs: 12,
+	}
+	s.mockService.On("Query", mock.Anything, req).Return([]Event{event}, nil)
+
+	status, body := s.get("?cluster=staging&release=mysql&outcome=failure&since=2021-03-24T12:00:00Z&limit=10")
+
+	assert.Equal(s.T(), http.StatusOK, status)
+	assert.JSONEq(s.T(), `{"events":[{"id":"1","time":"2021-03-24T12:30:00Z","action":"upgrade","cluster":"staging","release":"mysql",
+		"dry_run":false,"outcome":"failure","status_code":500,"duration_ms":12}]}`, body)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *AuditTestSuite) TestShouldRejectInvalidFilters() {
+	for query, reason := range map[string]string{
+		"?outcome=maybe":  "outcome must be success or failure",
+		"?since=tomorrow": "since must be an RFC 3339 timestamp",
+		"?limit=-1":       "limit cannot be negative",
+	} {
+		status, body := s.get(query)
+
+		assert.Equal(s.T(), http.StatusBadRequest, status, query)
+		assert.JSONEq(s.T(), `{"code":"invalid","message":"`+reason+`"}`, body, query)
+	}
+	s.mockService.AssertNotCalled(s.T(), "Query", mock.Anything, mock.Anything)
+}
+
+func (s *AuditTestSuite) TestShouldReturnInternalServerErrorWhenQueryFails() {
+	s.mockService.On("Query", mock.Anything, Request{}).Return([]Event{}, errors.New("permission denied"))
+
+	status, body := s.get("")
+
+	assert.Equal(s.T(), http.StatusInternalServerError, status)
+	assert.JSONEq(s.T(), `{"code":"internal","message":"permission denied"}`, body)
+}
+
+func TestAuditAPI(t *testing.T) {
+	suite.Run(t, new(AuditTestSuite))
+}
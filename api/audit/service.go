@@ -0,0 +1,52 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/gojekfarm/albatross/pkg/audit"
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+type querier interface {
+	Query(f audit.Filter) ([]audit.Event, error)
+}
+
+// Service answers audit queries from the audit log
+type Service struct {
+	log querier
+}
+
+// NewService returns a service querying log
+func NewService(log querier) Service {
+	return Service{log}
+}
+
+// Query returns the matching events, newest first
+func (s Service) Query(ctx context.Context, req Request) (_ []Event, err error) {
+	_, span := tracing.Start(ctx, "audit.Query")
+	defer func() { tracing.End(span, err) }()
+
+	filter := audit.Filter{
+		Action:    req.Action,
+		Principal: req.Principal,
+		Cluster:   req.Cluster,
+		Namespace: req.Namespace,
+		Release:   req.Release,
+		Outcome:   audit.Outcome(req.Outcome),
+		Limit:     req.Limit,
+	}
+	// the handler validated the timestamps
+	filter.Since, _ = time.Parse(time.RFC3339, req.Since)
+	filter.Until, _ = time.Parse(time.RFC3339, req.Until)
+
+	events, err := s.log.Query(filter)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]Event, 0, len(events))
+	for _, e := range events {
+		resp = append(resp, Event(e))
+	}
+	return resp, nil
+}
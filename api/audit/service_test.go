@@ -0,0 +1,26 @@
+package audit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/albatross/pkg/audit"
+)
+
+func TestServiceShouldFilterTheLog(t *testing.T) {
+	t0 := time.Date(2021, 3, 24, 12, 0, 0, 0, time.UTC)
+	memory := audit.NewMemorySink(10)
+	log := audit.NewLog(memory, memory)
+	log.Record(audit.Event{ID: "1", Time: t0, Action: "install", Release: "mysql", Outcome: audit.Success})
+	log.Record(audit.Event{ID: "2", Time: t0.Add(time.Hour), Action: "upgrade", Release: "mysql", Outcome: audit.Failure})
+	log.Record(audit.Event{ID: "3", Time: t0.Add(2 * time.Hour), Action: "upgrade", Release: "redis", Outcome: audit.Success})
+
+	events, err := NewService(log).Query(context.Background(), Request{Release: "mysql", Since: "2021-03-24T12:30:00Z"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []Event{{ID: "2", Time: t0.Add(time.Hour), Action: "upgrade", Release: "mysql", Outcome: audit.Failure}}, events)
+}
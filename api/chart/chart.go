@@ -0,0 +1,182 @@
+package chart
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/schema"
+	"helm.sh/helm/v3/pkg/chart"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+var decoder = schema.NewDecoder()
+
+// SearchRequest holds the filters of a chart search
+type SearchRequest struct {
+	Keyword string `schema:"keyword"`
+	// Semantic version constraint, the latest stable version is returned when empty
+	Version string `schema:"version"`
+	// Versions returns every matching version instead of the latest one
+	Versions bool `schema:"versions"`
+}
+
+// ChartVersion is a chart version of a repository
+// swagger:model chartVersion
+type ChartVersion struct {
+	// example: stable/mysql
+	Name string `json:"name"`
+	// example: 1.6.9
+	Version string `json:"version"`
+	// example: 5.7.30
+	AppVersion string `json:"app_version,omitempty"`
+	// example: Fast, reliable, scalable, and easy to use open-source relational database system.
+	Description string `json:"description,omitempty"`
+	// example: false
+	Deprecated bool `json:"deprecated,omitempty"`
+}
+
+// SearchResponse is the body of /charts/search
+// swagger:model searchChartsResponseBody
+type SearchResponse struct {
+	Charts []ChartVersion `json:"charts"`
+}
+
+// ShowRequest names the chart to show
+type ShowRequest struct {
+	// example: stable/mysql
+	Chart string `schema:"chart"`
+	// example: 1.6.9
+	Version string `schema:"version"`
+}
+
+// ShowResponse is the body of /charts/show
+// swagger:model showChartResponseBody
+type ShowResponse struct {
+	// Chart.yaml of the chart
+	Chart *chart.Metadata `json:"chart,omitempty"`
+	// Default values.yaml of the chart as written by its authors
+	Values string `json:"values,omitempty"`
+	Readme string `json:"readme,omitempty"`
+	// JSON schema of the values
+	Schema json.RawMessage `json:"schema,omitempty"`
+}
+
+type service interface {
+	Search(ctx context.Context, req SearchRequest) ([]ChartVersion, error)
+	Show(ctx context.Context, req ShowRequest) (ShowResponse, error)
+}
+
+// SearchHandler handles a chart search
+// swagger:operation GET /charts/search chart searchCharts
+//
+// Search the charts of the configured repositories, like helm search repo.
+// The repository indexes downloaded when a repository is added or updated are searched.
+// ---
+// produces:
+// - application/json
+// parameters:
+// - name: keyword
+//   in: query
+//   type: string
+// - name: version
+//   in: query
+//   type: string
+// - name: versions
+//   in: query
+//   type: boolean
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/searchChartsResponseBody"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func SearchHandler(s service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req SearchRequest
+		if err := decoder.Decode(&req, r.URL.Query()); err != nil {
+			respondSearchError(w, "error decoding request", apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+
+		charts, err := s.Search(r.Context(), req)
+		if err != nil {
+			respondSearchError(w, "error searching charts", err)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(SearchResponse{Charts: charts}); err != nil {
+			logger.Errorf("[ChartSearch] error writing response: %v", err)
+		}
+	})
+}
+
+// ShowHandler handles a chart show
+// swagger:operation GET /charts/show chart showChart
+//
+// Show the Chart.yaml, default values, README and values schema of a chart, like helm show all.
+// ---
+// produces:
+// - application/json
+// parameters:
+// - name: chart
+//   in: query
+//   required: true
+//   type: string
+// - name: version
+//   in: query
+//   type: string
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/showChartResponseBody"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func ShowHandler(s service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req ShowRequest
+		if err := decoder.Decode(&req, r.URL.Query()); err != nil {
+			respondShowError(w, "error decoding request", apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+		if req.Chart == "" {
+			respondShowError(w, "error validating request", apiErrors.New(apiErrors.Invalid, "chart cannot be empty string"))
+			return
+		}
+
+		resp, err := s.Show(r.Context(), req)
+		if err != nil {
+			respondShowError(w, "error showing chart", err)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(resp); err != nil {
+			logger.Errorf("[ChartShow] error writing response: %v", err)
+		}
+	})
+}
+
+func respondSearchError(w http.ResponseWriter, logprefix string, err error) {
+	logger.Errorf("[ChartSearch] %s: %v", logprefix, err)
+	apiErrors.Write(w, err)
+}
+
+func respondShowError(w http.ResponseWriter, logprefix string, err error) {
+	logger.Errorf("[ChartShow] %s: %v", logprefix, err)
+	apiErrors.Write(w, err)
+}
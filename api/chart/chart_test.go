@@ -0,0 +1,129 @@
+package chart
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"helm.sh/helm/v3/pkg/chart"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/helmcli/repository"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+type mockService struct {
+	mock.Mock
+}
+
+func (m *mockService) Search(ctx context.Context, req SearchRequest) ([]ChartVersion, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]ChartVersion), args.Error(1)
+}
+
+func (m *mockService) Show(ctx context.Context, req ShowRequest) (ShowResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(ShowResponse), args.Error(1)
+}
+
+type ChartTestSuite struct {
+	suite.Suite
+	server      *httptest.Server
+	mockService *mockService
+}
+
+func (s *ChartTestSuite) SetupSuite() {
+	logger.Setup("default")
+}
+
+func (s *ChartTestSuite) SetupTest() {
+	s.mockService = new(mockService)
+	router := mux.NewRouter()
+	router.Handle("/charts/search", SearchHandler(s.mockService)).Methods(http.MethodGet)
+	router.Handle("/charts/show", ShowHandler(s.mockService)).Methods(http.MethodGet)
+	s.server = httptest.NewServer(router)
+}
+
+func (s *ChartTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *ChartTestSuite) get(path string) (int, string) {
+	resp, err := http.Get(s.server.URL + path)
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func (s *ChartTestSuite) TestSearch() {
+	s.mockService.On("Search", mock.Anything, SearchRequest{Keyword: "mysql", Version: "~1.6", Versions: true}).
+		Return([]ChartVersion{{Name: "stable/mysql", Version: "1.6.9", AppVersion: "5.7.30"}}, nil)
+
+	code, body := s.get("/charts/search?keyword=mysql&version=~1.6&versions=true")
+
+	assert.Equal(s.T(), http.StatusOK, code)
+	assert.Equal(s.T(), `{"charts":[{"name":"stable/mysql","version":"1.6.9","app_version":"5.7.30"}]}`+"\n", body)
+}
+
+func (s *ChartTestSuite) TestSearchInvalidConstraint() {
+	s.mockService.On("Search", mock.Anything, SearchRequest{Version: "latest"}).
+		Return(nil, fmt.Errorf("%w \"latest\": improper constraint: latest", repository.ErrInvalidConstraint))
+
+	code, body := s.get("/charts/search?version=latest")
+
+	assert.Equal(s.T(), http.StatusBadRequest, code)
+	assert.Equal(s.T(), `{"code":"invalid","message":"invalid version constraint \"latest\": improper constraint: latest"}`+"\n", body)
+}
+
+func (s *ChartTestSuite) TestSearchRejectsUnknownParameters() {
+	code, _ := s.get("/charts/search?regexp=true")
+
+	assert.Equal(s.T(), http.StatusBadRequest, code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ChartTestSuite) TestShow() {
+	s.mockService.On("Show", mock.Anything, ShowRequest{Chart: "stable/mysql", Version: "1.6.9"}).Return(ShowResponse{
+		Chart:  &chart.Metadata{Name: "mysql", Version: "1.6.9", APIVersion: "v1"},
+		Values: "replicaCount: 1\n",
+		Readme: "# MySQL",
+	}, nil)
+
+	code, body := s.get("/charts/show?chart=stable/mysql&version=1.6.9")
+
+	assert.Equal(s.T(), http.StatusOK, code)
+	assert.Equal(s.T(), `{"chart":{"name":"mysql","version":"1.6.9","apiVersion":"v1"},"values":"replicaCount: 1\n","readme":"# MySQL"}`+"\n", body)
+}
+
+func (s *ChartTestSuite) TestShowRequiresChart() {
+	code, body := s.get("/charts/show?version=1.6.9")
+
+	assert.Equal(s.T(), http.StatusBadRequest, code)
+	assert.Equal(s.T(), `{"code":"invalid","message":"chart cannot be empty string"}`+"\n", body)
+}
+
+func (s *ChartTestSuite) TestShowUnknownChart() {
+	s.mockService.On("Show", mock.Anything, ShowRequest{Chart: "stable/unknown"}).
+		Return(ShowResponse{}, fmt.Errorf("%w: failed to download \"stable/unknown\"", helmcli.ErrChartNotFound))
+
+	code, body := s.get("/charts/show?chart=stable/unknown")
+
+	assert.Equal(s.T(), http.StatusNotFound, code)
+	assert.Equal(s.T(), `{"code":"chart_not_found","message":"chart not found: failed to download \"stable/unknown\""}`+"\n", body)
+}
+
+func TestChartAPI(t *testing.T) {
+	suite.Run(t, new(ChartTestSuite))
+}
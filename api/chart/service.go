@@ -0,0 +1,85 @@
+package chart
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/helmcli/repository"
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+// readmeFileNames are the readme files helm show recognizes, compared case insensitively
+var readmeFileNames = []string{"readme.md", "readme.txt", "readme"}
+
+type Service struct {
+	cli   helmcli.Client
+	repos repository.Client
+}
+
+// Search returns the chart versions of the cached repository indexes matching req
+func (s Service) Search(ctx context.Context, req SearchRequest) (_ []ChartVersion, err error) {
+	ctx, span := tracing.Start(ctx, "chart.Search")
+	defer func() { tracing.End(span, err) }()
+
+	searcher, err := s.repos.NewSearcher(flags.SearchFlags{
+		Keyword:  req.Keyword,
+		Version:  req.Version,
+		Versions: req.Versions,
+	})
+	if err != nil {
+		return nil, err
+	}
+	versions, err := searcher.Search(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	charts := make([]ChartVersion, 0, len(versions))
+	for _, v := range versions {
+		charts = append(charts, ChartVersion{
+			Name:        v.Name,
+			Version:     v.Version,
+			AppVersion:  v.AppVersion,
+			Description: v.Description,
+			Deprecated:  v.Deprecated,
+		})
+	}
+	return charts, nil
+}
+
+// Show returns the metadata, default values, readme and values schema of a chart
+func (s Service) Show(ctx context.Context, req ShowRequest) (_ ShowResponse, err error) {
+	ctx, span := tracing.Start(ctx, "chart.Show")
+	defer func() { tracing.End(span, err) }()
+
+	shower, err := s.cli.NewShower(flags.ShowFlags{Version: req.Version})
+	if err != nil {
+		return ShowResponse{}, fmt.Errorf("error while initializing the shower: %w", err)
+	}
+	ch, err := shower.Show(ctx, req.Chart)
+	if err != nil {
+		return ShowResponse{}, err
+	}
+
+	resp := ShowResponse{Chart: ch.Metadata, Schema: ch.Schema}
+	for _, f := range ch.Raw {
+		if f.Name == "values.yaml" {
+			resp.Values = string(f.Data)
+		}
+	}
+	for _, f := range ch.Files {
+		for _, name := range readmeFileNames {
+			if resp.Readme == "" && strings.EqualFold(f.Name, name) {
+				resp.Readme = string(f.Data)
+			}
+		}
+	}
+	return resp, nil
+}
+
+func NewService(cli helmcli.Client, repos repository.Client) Service {
+	return Service{cli, repos}
+}
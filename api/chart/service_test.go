@@ -0,0 +1,160 @@
+package chart
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/helmcli/repository"
+)
+
+// To satisfy the client interface, we have to define all methods(NewUpgrade, NewInstaller) on the mock struct
+// TODO: Find a way to isolate interface only for upgrade.
+type mockHelmClient struct{ mock.Mock }
+
+func (m *mockHelmClient) NewUpgrader(fl flags.UpgradeFlags) (helmcli.Upgrader, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Upgrader), args.Error(1)
+}
+
+func (m *mockHelmClient) NewInstaller(fl flags.InstallFlags) (helmcli.Installer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Installer), args.Error(1)
+}
+
+func (m *mockHelmClient) NewLister(fl flags.ListFlags) (helmcli.Lister, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Lister), args.Error(1)
+}
+
+func (m *mockHelmClient) NewStatusGiver(fl flags.StatusFlags) (helmcli.StatusGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.StatusGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewUninstaller(fl flags.UninstallFlags) (helmcli.Uninstaller, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Uninstaller), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRollbacker(fl flags.RollbackFlags) (helmcli.Rollbacker, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Rollbacker), args.Error(1)
+}
+
+func (m *mockHelmClient) NewHistoryGiver(fl flags.HistoryFlags) (helmcli.HistoryGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.HistoryGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewValuesGiver(fl flags.GetValuesFlags) (helmcli.ValuesGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.ValuesGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTemplater(fl flags.TemplateFlags) (helmcli.Templater, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Templater), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTester(fl flags.TestFlags) (helmcli.Tester, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Tester), args.Error(1)
+}
+
+func (m *mockHelmClient) NewShower(fl flags.ShowFlags) (helmcli.Shower, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Shower), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRecoverer(fl flags.RecoverFlags) (helmcli.Recoverer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Recoverer), args.Error(1)
+}
+
+type mockShower struct{ mock.Mock }
+
+func (m *mockShower) Show(ctx context.Context, chartName string) (*chart.Chart, error) {
+	args := m.Called(ctx, chartName)
+	return args.Get(0).(*chart.Chart), args.Error(1)
+}
+
+type mockRepositoryClient struct{ mock.Mock }
+
+func (m *mockRepositoryClient) NewAdder(fl flags.AddFlags) (repository.Adder, error) {
+	args := m.Called(fl)
+	return args.Get(0).(repository.Adder), args.Error(1)
+}
+
+func (m *mockRepositoryClient) NewLister() (repository.Lister, error) {
+	args := m.Called()
+	return args.Get(0).(repository.Lister), args.Error(1)
+}
+
+func (m *mockRepositoryClient) NewRemover(fl flags.RepoRemoveFlags) (repository.Remover, error) {
+	args := m.Called(fl)
+	return args.Get(0).(repository.Remover), args.Error(1)
+}
+
+func (m *mockRepositoryClient) NewUpdater(fl flags.RepoUpdateFlags) (repository.Updater, error) {
+	args := m.Called(fl)
+	return args.Get(0).(repository.Updater), args.Error(1)
+}
+
+func (m *mockRepositoryClient) NewSearcher(fl flags.SearchFlags) (repository.Searcher, error) {
+	args := m.Called(fl)
+	return args.Get(0).(repository.Searcher), args.Error(1)
+}
+
+type mockSearcher struct{ mock.Mock }
+
+func (m *mockSearcher) Search(ctx context.Context) ([]repository.ChartVersion, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]repository.ChartVersion), args.Error(1)
+}
+
+func TestServiceSearch(t *testing.T) {
+	repos := new(mockRepositoryClient)
+	searcher := new(mockSearcher)
+	s := NewService(new(mockHelmClient), repos)
+	repos.On("NewSearcher", flags.SearchFlags{Keyword: "mysql", Versions: true}).Return(searcher, nil)
+	searcher.On("Search", mock.Anything).Return([]repository.ChartVersion{
+		{Name: "stable/mysql", ChartVersion: &repo.ChartVersion{Metadata: &chart.Metadata{Name: "mysql", Version: "1.6.9", AppVersion: "5.7.30", Description: "MySQL"}}},
+	}, nil)
+
+	charts, err := s.Search(context.Background(), SearchRequest{Keyword: "mysql", Versions: true})
+
+	require.NoError(t, err)
+	assert.Equal(t, []ChartVersion{{Name: "stable/mysql", Version: "1.6.9", AppVersion: "5.7.30", Description: "MySQL"}}, charts)
+}
+
+func TestServiceShow(t *testing.T) {
+	cli := new(mockHelmClient)
+	shower := new(mockShower)
+	s := NewService(cli, new(mockRepositoryClient))
+	metadata := &chart.Metadata{Name: "mysql", Version: "1.6.9"}
+	cli.On("NewShower", flags.ShowFlags{Version: "1.6.9"}).Return(shower, nil)
+	shower.On("Show", mock.Anything, "stable/mysql").Return(&chart.Chart{
+		Metadata: metadata,
+		Raw:      []*chart.File{{Name: "Chart.yaml", Data: []byte("name: mysql")}, {Name: "values.yaml", Data: []byte("# replicas\nreplicaCount: 1\n")}},
+		Files:    []*chart.File{{Name: "README.md", Data: []byte("# MySQL")}},
+		Schema:   []byte(`{"type":"object"}`),
+	}, nil)
+
+	resp, err := s.Show(context.Background(), ShowRequest{Chart: "stable/mysql", Version: "1.6.9"})
+
+	require.NoError(t, err)
+	assert.Equal(t, ShowResponse{
+		Chart:  metadata,
+		Values: "# replicas\nreplicaCount: 1\n",
+		Readme: "# MySQL",
+		Schema: []byte(`{"type":"object"}`),
+	}, resp)
+}
@@ -0,0 +1,244 @@
+package cluster
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/cluster"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+// URLNamePlaceholder is the route variable holding the cluster name
+const URLNamePlaceholder string = "cluster"
+
+// Cluster is a registered kubernetes cluster.
+// Credentials are accepted on PUT but token and client_key_data are never returned.
+// swagger:model cluster
+type Cluster struct {
+	// example: staging
+	Name string `json:"name"`
+	// example: https://10.0.0.1:6443
+	APIServer string `json:"api_server,omitempty"`
+	// PEM encoded CA bundle of the api server
+	CAData string `json:"ca_data,omitempty"`
+	// example: false
+	InsecureSkipTLSVerify bool `json:"insecure_skip_tls_verify,omitempty"`
+	// Namespace used by kube clients when the request does not have one
+	// example: default
+	DefaultNamespace string `json:"default_namespace,omitempty"`
+	Auth             Auth   `json:"auth"`
+}
+
+// Auth holds the credentials of a cluster
+// swagger:model clusterAuth
+type Auth struct {
+	// one of token, client_certificate or kubeconfig
+	// example: token
+	Method cluster.AuthMethod `json:"method"`
+	Token  string             `json:"token,omitempty"`
+	// Path of a token file on the server, only returned for clusters of the config file
+	// example: /var/run/secrets/staging/token
+	TokenFile string `json:"token_file,omitempty"`
+	// PEM encoded client certificate
+	ClientCertificateData string `json:"client_certificate_data,omitempty"`
+	// PEM encoded client key
+	ClientKeyData string `json:"client_key_data,omitempty"`
+	// Path of a kubeconfig file on the server, only returned for clusters of the config file.
+	// The default loading rules apply when empty
+	// example: /etc/albatross/kubeconfig
+	Kubeconfig string `json:"kubeconfig,omitempty"`
+	// Context of the kubeconfig file, the current context is used when empty
+	// example: gke_staging
+	Context string `json:"context,omitempty"`
+}
+
+type service interface {
+	List(ctx context.Context) ([]Cluster, error)
+	Get(ctx context.Context, name string) (Cluster, error)
+	Put(ctx context.Context, c Cluster) (Cluster, error)
+	Delete(ctx context.Context, name string) error
+}
+
+// ListHandler lists the registered clusters
+// swagger:operation GET /clusters cluster listClusters
+//
+//
+// ---
+// summary: List the registered clusters
+// produces:
+// - application/json
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     type: array
+//     items:
+//      $ref: "#/definitions/cluster"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func ListHandler(s service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		clusters, err := s.List(r.Context())
+		if err != nil {
+			respondClusterError(w, "error listing clusters", err)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(clusters); err != nil {
+			logger.Errorf("[Cluster] error writing response: %v", err)
+		}
+	})
+}
+
+// Handler returns a registered cluster
+// swagger:operation GET /clusters/{cluster} cluster getCluster
+//
+//
+// ---
+// summary: Get a registered cluster
+// produces:
+// - application/json
+// parameters:
+// - name: cluster
+//   in: path
+//   required: true
+//   type: string
+//   format: string
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/cluster"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func Handler(s service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c, err := s.Get(r.Context(), mux.Vars(r)[URLNamePlaceholder])
+		if err != nil {
+			respondClusterError(w, "error getting cluster", err)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(c); err != nil {
+			logger.Errorf("[Cluster] error writing response: %v", err)
+		}
+	})
+}
+
+// PutHandler registers a cluster or replaces a registered one
+// swagger:operation PUT /clusters/{cluster} cluster putCluster
+//
+// Register a cluster.
+// Release routes use the registered api server and credentials for the cluster name,
+// names that are not registered are used as kubeconfig contexts.
+// token_file and kubeconfig are paths on the server and are refused, they can only be set in the config file.
+// ---
+// produces:
+// - application/json
+// parameters:
+// - name: cluster
+//   in: path
+//   required: true
+//   type: string
+//   format: string
+// - name: Body
+//   in: body
+//   required: true
+//   schema:
+//    $ref: "#/definitions/cluster"
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/cluster"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func PutHandler(s service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		var req Cluster
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondClusterError(w, "error decoding request", apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+		req.Name = mux.Vars(r)[URLNamePlaceholder]
+		if err := req.valid(); err != nil {
+			respondClusterError(w, "error validating request", apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+
+		c, err := s.Put(r.Context(), req)
+		if err != nil {
+			respondClusterError(w, "error registering cluster", err)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(c); err != nil {
+			logger.Errorf("[Cluster] error writing response: %v", err)
+		}
+	})
+}
+
+// valid refuses the auth fields that are paths on the server, a caller could otherwise
+// make albatross read any file it has access to
+func (req Cluster) valid() error {
+	if req.Auth.TokenFile != "" || req.Auth.Kubeconfig != "" {
+		return errors.New("token_file and kubeconfig can only be set in the cluster config file")
+	}
+	return req.toCluster().Validate()
+}
+
+// DeleteHandler removes a registered cluster
+// swagger:operation DELETE /clusters/{cluster} cluster deleteCluster
+//
+//
+// ---
+// summary: Remove a registered cluster, its releases are left untouched
+// produces:
+// - application/json
+// parameters:
+// - name: cluster
+//   in: path
+//   required: true
+//   type: string
+//   format: string
+// schemes:
+// - http
+// responses:
+//   '204':
+//    description: The cluster was removed
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func DeleteHandler(s service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err := s.Delete(r.Context(), mux.Vars(r)[URLNamePlaceholder])
+		if err != nil {
+			respondClusterError(w, "error removing cluster", err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func respondClusterError(w http.ResponseWriter, logprefix string, err error) {
+	logger.Errorf("[Cluster] %s: %v", logprefix, err)
+	apiErrors.Write(w, err)
+}
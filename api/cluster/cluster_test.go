@@ -0,0 +1,180 @@
+package cluster
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/gojekfarm/albatross/pkg/cluster"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+type mockService struct {
+	mock.Mock
+}
+
+func (m *mockService) List(ctx context.Context) ([]Cluster, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]Cluster), args.Error(1)
+}
+
+func (m *mockService) Get(ctx context.Context, name string) (Cluster, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(Cluster), args.Error(1)
+}
+
+func (m *mockService) Put(ctx context.Context, c Cluster) (Cluster, error) {
+	args := m.Called(ctx, c)
+	return args.Get(0).(Cluster), args.Error(1)
+}
+
+func (m *mockService) Delete(ctx context.Context, name string) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+type ClusterTestSuite struct {
+	suite.Suite
+	server      *httptest.Server
+	mockService *mockService
+}
+
+func (s *ClusterTestSuite) SetupSuite() {
+	logger.Setup("default")
+}
+
+func (s *ClusterTestSuite) SetupTest() {
+	s.mockService = new(mockService)
+	router := mux.NewRouter()
+	path := fmt.Sprintf("/clusters/{%s}", URLNamePlaceholder)
+	router.Handle("/clusters", ListHandler(s.mockService)).Methods(http.MethodGet)
+	router.Handle(path, Handler(s.mockService)).Methods(http.MethodGet)
+	router.Handle(path, PutHandler(s.mockService)).Methods(http.MethodPut)
+	router.Handle(path, DeleteHandler(s.mockService)).Methods(http.MethodDelete)
+	s.server = httptest.NewServer(router)
+}
+
+func (s *ClusterTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *ClusterTestSuite) do(method, path, body string) (int, string) {
+	req, err := http.NewRequest(method, s.server.URL+path, strings.NewReader(body))
+	require.NoError(s.T(), err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	respBody, err := ioutil.ReadAll(resp.Body)
+	require.NoError(s.T(), err)
+	return resp.StatusCode, strings.TrimSpace(string(respBody))
+}
+
+func (s *ClusterTestSuite) TestShouldListClusters() {
+	clusters := []Cluster{{Name: "staging", APIServer: "https://10.0.0.1", Auth: Auth{Method: cluster.TokenAuth}}}
+	s.mockService.On("List", mock.Anything).Return(clusters, nil)
+
+	status, body := s.do(http.MethodGet, "/clusters", "")
+
+	assert.Equal(s.T(), http.StatusOK, status)
+	assert.Equal(s.T(), `[{"name":"staging","api_server":"https://10.0.0.1","auth":{"method":"token"}}]`, body)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ClusterTestSuite) TestShouldReturnNotFoundForUnknownCluster() {
+	s.mockService.On("Get", mock.Anything, "staging").Return(Cluster{}, cluster.ErrNotFound)
+
+	status, body := s.do(http.MethodGet, "/clusters/staging", "")
+
+	assert.Equal(s.T(), http.StatusNotFound, status)
+	assert.Equal(s.T(), `{"code":"not_found","message":"cluster not found"}`, body)
+}
+
+func (s *ClusterTestSuite) TestShouldPutClusterWithNameFromPath() {
+	req := Cluster{
+		Name:             "staging",
+		APIServer:        "https://10.0.0.1",
+		DefaultNamespace: "albatross",
+		Auth:             Auth{Method: cluster.TokenAuth, Token: "secret-token"},
+	}
+	resp := req
+	resp.Auth.Token = ""
+	s.mockService.On("Put", mock.Anything, req).Return(resp, nil)
+
+	status, body := s.do(http.MethodPut, "/clusters/staging",
+		`{"name":"ignored","api_server":"https://10.0.0.1","default_namespace":"albatross","auth":{"method":"token","token":"secret-token"}}`)
+
+	assert.Equal(s.T(), http.StatusOK, status)
+	assert.Equal(s.T(), `{"name":"staging","api_server":"https://10.0.0.1","default_namespace":"albatross","auth":{"method":"token"}}`, body)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ClusterTestSuite) TestShouldRejectInvalidCluster() {
+	status, body := s.do(http.MethodPut, "/clusters/staging", `{"api_server":"https://10.0.0.1","auth":{"method":"token"}}`)
+
+	assert.Equal(s.T(), http.StatusBadRequest, status)
+	assert.Equal(s.T(), `{"code":"invalid","message":"token auth requires a token or a token_file"}`, body)
+	s.mockService.AssertNotCalled(s.T(), "Put", mock.Anything, mock.Anything)
+}
+
+func (s *ClusterTestSuite) TestShouldRejectPathsOnTheServer() {
+	for _, auth := range []string{
+		`{"method":"token","token_file":"/etc/shadow"}`,
+		`{"method":"kubeconfig","kubeconfig":"/root/.kube/config"}`,
+	} {
+		status, body := s.do(http.MethodPut, "/clusters/staging", `{"api_server":"https://10.0.0.1","auth":`+auth+`}`)
+
+		assert.Equal(s.T(), http.StatusBadRequest, status)
+		assert.Equal(s.T(), `{"code":"invalid","message":"token_file and kubeconfig can only be set in the cluster config file"}`, body)
+	}
+	s.mockService.AssertNotCalled(s.T(), "Put", mock.Anything, mock.Anything)
+}
+
+func (s *ClusterTestSuite) TestShouldRejectKubeconfigWithAPIServer() {
+	status, body := s.do(http.MethodPut, "/clusters/staging", `{"api_server":"https://10.0.0.1","auth":{"method":"kubeconfig","context":"gke_staging"}}`)
+
+	assert.Equal(s.T(), http.StatusBadRequest, status)
+	assert.Equal(s.T(), `{"code":"invalid","message":"kubeconfig auth cannot be combined with api_server or token"}`, body)
+	s.mockService.AssertNotCalled(s.T(), "Put", mock.Anything, mock.Anything)
+}
+
+func (s *ClusterTestSuite) TestShouldReturnInternalServerErrorWhenPutFails() {
+	s.mockService.On("Put", mock.Anything, mock.Anything).Return(Cluster{}, errors.New("disk full"))
+
+	status, body := s.do(http.MethodPut, "/clusters/staging", `{"api_server":"https://10.0.0.1","auth":{"method":"token","token":"t"}}`)
+
+	assert.Equal(s.T(), http.StatusInternalServerError, status)
+	assert.Equal(s.T(), `{"code":"internal","message":"disk full"}`, body)
+}
+
+func (s *ClusterTestSuite) TestShouldDeleteCluster() {
+	s.mockService.On("Delete", mock.Anything, "staging").Return(nil)
+
+	status, body := s.do(http.MethodDelete, "/clusters/staging", "")
+
+	assert.Equal(s.T(), http.StatusNoContent, status)
+	assert.Empty(s.T(), body)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ClusterTestSuite) TestShouldReturnNotFoundWhenDeletingUnknownCluster() {
+	s.mockService.On("Delete", mock.Anything, "staging").Return(cluster.ErrNotFound)
+
+	status, _ := s.do(http.MethodDelete, "/clusters/staging", "")
+
+	assert.Equal(s.T(), http.StatusNotFound, status)
+}
+
+func TestClusterAPI(t *testing.T) {
+	suite.Run(t, new(ClusterTestSuite))
+}
@@ -0,0 +1,110 @@
+package cluster
+
+import (
+	"context"
+
+	"github.com/gojekfarm/albatross/pkg/cluster"
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+type registry interface {
+	List() ([]cluster.Cluster, error)
+	Get(name string) (cluster.Cluster, error)
+	Put(ctx context.Context, c cluster.Cluster) error
+	Delete(ctx context.Context, name string) error
+}
+
+// Service manages the cluster registry, the clusters it returns never contain secrets
+type Service struct {
+	registry registry
+}
+
+// NewService returns a service backed by the registry
+func NewService(r registry) Service {
+	return Service{r}
+}
+
+// List returns the registered clusters
+func (s Service) List(ctx context.Context) (_ []Cluster, err error) {
+	_, span := tracing.Start(ctx, "cluster.List")
+	defer func() { tracing.End(span, err) }()
+
+	clusters, err := s.registry.List()
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]Cluster, 0, len(clusters))
+	for _, c := range clusters {
+		resp = append(resp, redacted(c))
+	}
+	return resp, nil
+}
+
+// Get returns a registered cluster
+func (s Service) Get(ctx context.Context, name string) (_ Cluster, err error) {
+	_, span := tracing.Start(ctx, "cluster.Get")
+	defer func() { tracing.End(span, err) }()
+
+	c, err := s.registry.Get(name)
+	if err != nil {
+		return Cluster{}, err
+	}
+	return redacted(c), nil
+}
+
+// Put registers the cluster
+func (s Service) Put(ctx context.Context, req Cluster) (_ Cluster, err error) {
+	ctx, span := tracing.Start(ctx, "cluster.Put")
+	defer func() { tracing.End(span, err) }()
+
+	c := req.toCluster()
+	if err := s.registry.Put(ctx, c); err != nil {
+		return Cluster{}, err
+	}
+	return redacted(c), nil
+}
+
+// Delete removes a registered cluster
+func (s Service) Delete(ctx context.Context, name string) (err error) {
+	ctx, span := tracing.Start(ctx, "cluster.Delete")
+	defer func() { tracing.End(span, err) }()
+
+	return s.registry.Delete(ctx, name)
+}
+
+func (c Cluster) toCluster() cluster.Cluster {
+	return cluster.Cluster{
+		Name:                  c.Name,
+		APIServer:             c.APIServer,
+		CAData:                c.CAData,
+		InsecureSkipTLSVerify: c.InsecureSkipTLSVerify,
+		DefaultNamespace:      c.DefaultNamespace,
+		Auth: cluster.Auth{
+			Method:                c.Auth.Method,
+			Token:                 c.Auth.Token,
+			TokenFile:             c.Auth.TokenFile,
+			ClientCertificateData: c.Auth.ClientCertificateData,
+			ClientKeyData:         c.Auth.ClientKeyData,
+			Kubeconfig:            c.Auth.Kubeconfig,
+			Context:               c.Auth.Context,
+		},
+	}
+}
+
+// redacted converts a registered cluster to its response, leaving out the token and client key
+func redacted(c cluster.Cluster) Cluster {
+	return Cluster{
+		Name:                  c.Name,
+		APIServer:             c.APIServer,
+		CAData:                c.CAData,
+		InsecureSkipTLSVerify: c.InsecureSkipTLSVerify,
+		DefaultNamespace:      c.DefaultNamespace,
+		Auth: Auth{
+			Method:                c.Auth.Method,
+			TokenFile:             c.Auth.TokenFile,
+			ClientCertificateData: c.Auth.ClientCertificateData,
+			Kubeconfig:            c.Auth.Kubeconfig,
+			Context:               c.Auth.Context,
+		},
+	}
+}
@@ -0,0 +1,58 @@
+package cluster
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/albatross/pkg/cluster"
+)
+
+func TestServiceShouldNotReturnSecrets(t *testing.T) {
+	registry, err := cluster.NewRegistry("")
+	require.NoError(t, err)
+	service := NewService(registry)
+	req := Cluster{
+		Name:      "staging",
+		APIServer: "https://10.0.0.1",
+		CAData:    "ca",
+		Auth: Auth{
+			Method:                cluster.ClientCertificateAuth,
+			Token:                 "secret-token",
+			ClientCertificateData: "cert",
+			ClientKeyData:         "key",
+		},
+	}
+	want := Cluster{
+		Name:      "staging",
+		APIServer: "https://10.0.0.1",
+		CAData:    "ca",
+		Auth:      Auth{Method: cluster.ClientCertificateAuth, ClientCertificateData: "cert"},
+	}
+
+	put, err := service.Put(context.Background(), req)
+	require.NoError(t, err)
+	got, err := service.Get(context.Background(), "staging")
+	require.NoError(t, err)
+	list, err := service.List(context.Background())
+	require.NoError(t, err)
+
+	assert.Equal(t, want, put)
+	assert.Equal(t, want, got)
+	assert.Equal(t, []Cluster{want}, list)
+	stored, err := registry.Get("staging")
+	require.NoError(t, err)
+	assert.Equal(t, "key", stored.Auth.ClientKeyData)
+}
+
+func TestServiceDelete(t *testing.T) {
+	registry, err := cluster.NewRegistry("")
+	require.NoError(t, err)
+	service := NewService(registry)
+
+	err = service.Delete(context.Background(), "staging")
+
+	assert.Equal(t, cluster.ErrNotFound, err)
+}
@@ -0,0 +1,214 @@
+// Package errors is the error model of the API. Every handler answers its errors with the same JSON body,
+// a machine-readable code with a message and details, and the HTTP status of that code.
+// The errors of helm, kubernetes and the packages behind the handlers are classified here, handlers
+// give a code with Wrap to the errors only they can tell apart, such as invalid requests.
+package errors
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"helm.sh/helm/v3/pkg/storage/driver"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	"github.com/gojekfarm/albatross/pkg/cluster"
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/helmcli/config"
+	"github.com/gojekfarm/albatross/pkg/helmcli/registry"
+	"github.com/gojekfarm/albatross/pkg/helmcli/repository"
+	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/operation"
+	"github.com/gojekfarm/albatross/pkg/secret"
+	"github.com/gojekfarm/albatross/pkg/upload"
+)
+
+// Code tells clients what kind of error a response is, unlike messages it does not change
+type Code string
+
+const (
+	// Invalid requests are malformed or ask for something impossible
+	Invalid Code = "invalid"
+	// Unauthorized requests have missing or invalid credentials
+	Unauthorized Code = "unauthorized"
+	// Forbidden requests are not allowed to their principal
+	Forbidden Code = "forbidden"
+	// NotFound requests name a release, cluster, repository or other resource that does not exist
+	NotFound Code = "not_found"
+	// ChartNotFound requests name a chart that cannot be found on disk, in its repository or registry
+	ChartNotFound Code = "chart_not_found"
+	// Conflict requests clash with the state of a release, such as an existing name or a held lock
+	Conflict Code = "conflict"
+	// TooLarge requests have a body larger than the server reads
+	TooLarge Code = "too_large"
+	// Timeout requests did not finish in time
+	Timeout Code = "timeout"
+	// Upstream requests failed in the kubernetes API
+	Upstream Code = "upstream_error"
+	// Unavailable requests were refused for now, such as while the server shuts down
+	Unavailable Code = "unavailable"
+	// Internal requests failed for any other reason
+	Internal Code = "internal"
+)
+
+var statuses = map[Code]int{
+	Invalid:       http.StatusBadRequest,
+	Unauthorized:  http.StatusUnauthorized,
+	Forbidden:     http.StatusForbidden,
+	NotFound:      http.StatusNotFound,
+	ChartNotFound: http.StatusNotFound,
+	Conflict:      http.StatusConflict,
+	TooLarge:      http.StatusRequestEntityTooLarge,
+	Timeout:       http.StatusGatewayTimeout,
+	Upstream:      http.StatusBadGateway,
+	Unavailable:   http.StatusServiceUnavailable,
+	Internal:      http.StatusInternalServerError,
+}
+
+// Status is the HTTP status of the responses with code
+func Status(code Code) int {
+	if status, ok := statuses[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+// Error is an error with a code, and details for clients
+type Error struct {
+	Code    Code
+	Details interface{}
+	err     error
+}
+
+// New returns an error with code and message
+func New(code Code, message string) *Error {
+	return &Error{Code: code, err: errors.New(message)}
+}
+
+// Wrap gives err a code, keeping its message
+func Wrap(code Code, err error) *Error {
+	return &Error{Code: code, err: err}
+}
+
+// WithDetails returns the error with details
+func (e *Error) WithDetails(details interface{}) *Error {
+	return &Error{Code: e.Code, Details: details, err: e.err}
+}
+
+func (e *Error) Error() string {
+	return e.err.Error()
+}
+
+func (e *Error) Unwrap() error {
+	return e.err
+}
+
+// Body is the body of every error response
+// swagger:model errorResponse
+type Body struct {
+	// example: not_found
+	Code Code `json:"code"`
+	// example: release: not found
+	Message string `json:"message"`
+	// Details depend on the code, such as the holder of a lock for conflicts
+	Details interface{} `json:"details,omitempty"`
+}
+
+// ReleaseDetails are the details of errors of actions that left their release behind in some status
+type ReleaseDetails struct {
+	// example: failed
+	Status string `json:"status"`
+}
+
+// WithReleaseStatus adds the status of the release a failed action left behind to its error, if any
+func WithReleaseStatus(err error, status string) error {
+	if status == "" {
+		return err
+	}
+	return From(err).WithDetails(ReleaseDetails{Status: status})
+}
+
+// From returns err with a code. Errors without one are classified when they come from helm, kubernetes
+// or contexts, and are internal otherwise.
+func From(err error) *Error {
+	var e *Error
+	if errors.As(err, &e) {
+		// err may add context to the message of the error with the code
+		return &Error{Code: e.Code, Details: e.Details, err: err}
+	}
+	return &Error{Code: classify(err), err: err}
+}
+
+// kinds are the codes of the errors returned by helm and the packages behind the handlers
+var kinds = []struct {
+	err  error
+	code Code
+}{
+	{driver.ErrReleaseNotFound, NotFound},
+	{cluster.ErrNotFound, NotFound},
+	{operation.ErrNotFound, NotFound},
+	{repository.ErrNotFound, NotFound},
+	{secret.ErrNotFound, NotFound},
+	{helmcli.ErrChartNotFound, ChartNotFound},
+	{registry.ErrNotFound, ChartNotFound},
+	{helmcli.ErrReleaseExists, Conflict},
+	{helmcli.ErrNotStuck, Conflict},
+	{helmcli.ErrNoDeployedRevision, Conflict},
+	{operation.ErrFinished, Conflict},
+	{operation.ErrRunning, Conflict},
+	{upload.ErrInvalidChart, Invalid},
+	{upload.ErrBodyTooLarge, TooLarge},
+	{helmcli.ErrVerification, Invalid},
+	{repository.ErrInvalidConstraint, Invalid},
+	{repository.ErrNoSecretStore, Invalid},
+	{registry.ErrNoSecretStore, Invalid},
+	{secret.ErrInvalidName, Invalid},
+	{config.ErrClusterOverride, Invalid},
+	{registry.ErrUnauthorized, Unauthorized},
+	{operation.ErrQueueFull, Unavailable},
+	{context.DeadlineExceeded, Timeout},
+	{wait.ErrWaitTimeout, Timeout},
+}
+
+func classify(err error) Code {
+	for _, kind := range kinds {
+		if errors.Is(err, kind.err) {
+			return kind.code
+		}
+	}
+	var status apierrors.APIStatus
+	if errors.As(err, &status) {
+		return kubeCode(apierrors.ReasonForError(err))
+	}
+	return Internal
+}
+
+// kubeCode classifies the errors of the kubernetes API. Albatross calls it with its own credentials,
+// so their rejection is an upstream error rather than a forbidden request.
+func kubeCode(reason metav1.StatusReason) Code {
+	switch reason {
+	case metav1.StatusReasonNotFound:
+		return NotFound
+	case metav1.StatusReasonAlreadyExists, metav1.StatusReasonConflict:
+		return Conflict
+	case metav1.StatusReasonInvalid, metav1.StatusReasonBadRequest:
+		return Invalid
+	case metav1.StatusReasonTimeout, metav1.StatusReasonServerTimeout:
+		return Timeout
+	}
+	return Upstream
+}
+
+// Write answers err with its body and the status of its code
+func Write(w http.ResponseWriter, err error) {
+	e := From(err)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(Status(e.Code))
+	body := Body{Code: e.Code, Message: e.Error(), Details: e.Details}
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		logger.Errorf("[Errors] error writing response: %v", err)
+	}
+}
@@ -0,0 +1,87 @@
+package errors
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"github.com/gojekfarm/albatross/pkg/cluster"
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+func TestFromClassifiesErrors(t *testing.T) {
+	deployments := schema.GroupResource{Group: "apps", Resource: "deployments"}
+	for _, tc := range []struct {
+		err  error
+		code Code
+	}{
+		{fmt.Errorf("error getting status: %w", driver.ErrReleaseNotFound), NotFound},
+		{fmt.Errorf("error while initializing installer: %w", cluster.ErrNotFound), NotFound},
+		{fmt.Errorf("%w: failed to download \"stable/unknown\"", helmcli.ErrChartNotFound), ChartNotFound},
+		{helmcli.ErrReleaseExists, Conflict},
+		{apierrors.NewNotFound(deployments, "api"), NotFound},
+		{apierrors.NewAlreadyExists(deployments, "api"), Conflict},
+		{apierrors.NewConflict(deployments, "api", errors.New("modified")), Conflict},
+		{apierrors.NewForbidden(deployments, "api", errors.New("rbac")), Upstream},
+		{apierrors.NewInternalError(errors.New("etcd")), Upstream},
+		{context.DeadlineExceeded, Timeout},
+		{errors.New("disk full"), Internal},
+	} {
+		assert.Equal(t, tc.code, From(tc.err).Code, tc.err.Error())
+	}
+}
+
+func TestFromKeepsTheCodeOfWrappedErrors(t *testing.T) {
+	err := fmt.Errorf("error adding repo: %w", Wrap(Invalid, driver.ErrReleaseNotFound).WithDetails("details"))
+
+	e := From(err)
+
+	assert.Equal(t, Invalid, e.Code)
+	assert.Equal(t, "details", e.Details)
+	assert.Equal(t, "error adding repo: release: not found", e.Error())
+	assert.True(t, errors.Is(e, driver.ErrReleaseNotFound))
+}
+
+func TestWriteAnswersTheStatusOfTheCode(t *testing.T) {
+	logger.Setup("default")
+	for code, status := range map[Code]int{
+		Invalid:       http.StatusBadRequest,
+		Unauthorized:  http.StatusUnauthorized,
+		Forbidden:     http.StatusForbidden,
+		NotFound:      http.StatusNotFound,
+		ChartNotFound: http.StatusNotFound,
+		Conflict:      http.StatusConflict,
+		TooLarge:      http.StatusRequestEntityTooLarge,
+		Timeout:       http.StatusGatewayTimeout,
+		Upstream:      http.StatusBadGateway,
+		Unavailable:   http.StatusServiceUnavailable,
+		Internal:      http.StatusInternalServerError,
+	} {
+		w := httptest.NewRecorder()
+
+		Write(w, New(code, "failed"))
+
+		assert.Equal(t, status, w.Code, code)
+		assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+		assert.JSONEq(t, `{"code":"`+string(code)+`","message":"failed"}`, w.Body.String())
+	}
+}
+
+func TestWriteAddsTheStatusOfTheRelease(t *testing.T) {
+	logger.Setup("default")
+	w := httptest.NewRecorder()
+
+	Write(w, WithReleaseStatus(errors.New("timed out waiting for the condition"), "failed"))
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"code":"internal","message":"timed out waiting for the condition","details":{"status":"failed"}}`, w.Body.String())
+}
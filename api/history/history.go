@@ -0,0 +1,144 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/logger"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/schema"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+var decoder = schema.NewDecoder()
+
+var errInvalidReleaseName = errors.New("history: invalid release name")
+
+type Request struct {
+	name string
+	Max  int `schema:"max"`
+	flags.GlobalFlags
+}
+
+// Revision is a single entry in the history of a release
+// swagger:model historyRevision
+type Revision struct {
+	// example: 2
+	Revision int `json:"revision"`
+	// example: superseded
+	Status release.Status `json:"status"`
+	// example: mysql
+	Chart string `json:"chart"`
+	// example: 1.6.9
+	ChartVersion string `json:"chart_version"`
+	// example: 5.7.30
+	AppVersion string `json:"app_version"`
+	// example: Upgrade complete
+	Description string `json:"description"`
+	// example: 2021-03-24T12:24:18.450869+05:30
+	FirstDeployed time.Time `json:"first_deployed,omitempty"`
+	// example: 2021-03-25T10:04:58.210315+05:30
+	LastDeployed time.Time `json:"last_deployed,omitempty"`
+}
+
+// Response is the body of the history route
+// swagger:model historyResponseBody
+type Response struct {
+	History []Revision `json:"history,omitempty"`
+}
+
+type service interface {
+	History(ctx context.Context, req Request) (Response, error)
+}
+
+// Handler handles a history request
+// swagger:operation GET /clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/history release historyOperation
+//
+//
+// ---
+// summary: List the stored revisions of a helm release, oldest first
+// produces:
+// - application/json
+// parameters:
+// - name: cluster
+//   in: path
+//   required: true
+//   default: minikube
+//   type: string
+//   format: string
+// - name: namespace
+//   in: path
+//   required: true
+//   default: default
+//   type: string
+//   format: string
+// - name: release_name
+//   in: path
+//   required: true
+//   default: mysql
+//   type: string
+//   format: string
+// - name: max
+//   in: query
+//   type: integer
+//   description: maximum number of latest revisions to return
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/historyResponseBody"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func Handler(s service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		var req Request
+		if err := decoder.Decode(&req, r.URL.Query()); err != nil {
+			logger.Errorf("[History] error decoding request: %v", err.Error())
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+		values := mux.Vars(r)
+		req.KubeContext = values["cluster"]
+		req.Namespace = values["namespace"]
+		req.name = values["release_name"]
+		if err := req.valid(); err != nil {
+			logger.Errorf("[History] error in request parameters: %v", err)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+		resp, err := s.History(r.Context(), req)
+		if err != nil {
+			logger.Errorf("[History] error while fetching history: %v", err)
+			apiErrors.Write(w, err)
+			return
+		}
+
+		if err = json.NewEncoder(w).Encode(resp); err != nil {
+			logger.Errorf("[History] error writing response: %v", err)
+		}
+	})
+}
+
+func (req Request) valid() error {
+	releaseName := req.name
+	if releaseName == "" || !action.ValidName.MatchString(releaseName) || len(releaseName) > 53 {
+		return errInvalidReleaseName
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package history
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gotest.tools/assert"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+type mockService struct {
+	mock.Mock
+}
+
+func (m *mockService) History(ctx context.Context, req Request) (Response, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(Response), args.Error(1)
+}
+
+type HistoryTestSuite struct {
+	suite.Suite
+	recorder    *httptest.ResponseRecorder
+	server      *httptest.Server
+	mockService *mockService
+}
+
+func (s *HistoryTestSuite) SetupSuite() {
+	logger.Setup("default")
+}
+
+func (s *HistoryTestSuite) SetupTest() {
+	s.recorder = httptest.NewRecorder()
+	s.mockService = new(mockService)
+	router := mux.NewRouter()
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/history", Handler(s.mockService)).Methods(http.MethodGet)
+	s.server = httptest.NewServer(router)
+}
+
+func (s *HistoryTestSuite) TestShouldReturnHistoryWhenSuccessfulAPICall() {
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/clusters/staging/namespaces/test/releases/mysql-test/history?max=2", s.server.URL), nil)
+	expectedRequestStruct := Request{
+		name: "mysql-test",
+		Max:  2,
+		GlobalFlags: flags.GlobalFlags{
+			KubeContext: "staging",
+			Namespace:   "test",
+		},
+	}
+	response := Response{History: []Revision{
+		{Revision: 1, Status: release.StatusSuperseded, Chart: "mysql", ChartVersion: "1.6.8", Description: "Install complete"},
+		{Revision: 2, Status: release.StatusDeployed, Chart: "mysql", ChartVersion: "1.6.9", Description: "Upgrade complete"},
+	}}
+	s.mockService.On("History", mock.Anything, expectedRequestStruct).Return(response, nil)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, res.StatusCode)
+
+	var actualResponse Response
+	err = json.NewDecoder(res.Body).Decode(&actualResponse)
+	require.NoError(s.T(), err)
+	assert.DeepEqual(s.T(), response, actualResponse)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *HistoryTestSuite) TestShouldReturnBadRequestForInvalidQuery() {
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/clusters/staging/namespaces/test/releases/mysql-test/history?max=all", s.server.URL), nil)
+
+	res, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, res.StatusCode)
+}
+
+func (s *HistoryTestSuite) TestShouldReturnBadRequestForInvalidReleaseName() {
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/clusters/staging/namespaces/test/releases/mysql-test-/history", s.server.URL), nil)
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, res.StatusCode)
+
+	var actualResponse apiErrors.Body
+	require.NoError(s.T(), json.NewDecoder(res.Body).Decode(&actualResponse))
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Invalid, Message: "history: invalid release name"}, actualResponse)
+	s.mockService.AssertNotCalled(s.T(), "History", mock.Anything, mock.Anything)
+}
+
+func (s *HistoryTestSuite) TestShouldReturnNotFoundIfReleaseDoesNotExist() {
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/clusters/staging/namespaces/test/releases/not-available/history", s.server.URL), nil)
+	s.mockService.On("History", mock.Anything, mock.AnythingOfType("Request")).Return(Response{}, driver.ErrReleaseNotFound)
+
+	res, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusNotFound, res.StatusCode)
+}
+
+func (s *HistoryTestSuite) TestShouldReturnInternalServerErrorIfServiceFails() {
+	req, _ := http.NewRequest(http.MethodGet, fmt.Sprintf("%s/clusters/staging/namespaces/test/releases/mysql-test/history", s.server.URL), nil)
+	s.mockService.On("History", mock.Anything, mock.AnythingOfType("Request")).Return(Response{}, errors.New("test error"))
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusInternalServerError, res.StatusCode)
+
+	var actualResponse apiErrors.Body
+	err = json.NewDecoder(res.Body).Decode(&actualResponse)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Internal, Message: "test error"}, actualResponse)
+}
+
+func (s *HistoryTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func TestHistoryAPI(t *testing.T) {
+	suite.Run(t, new(HistoryTestSuite))
+}
@@ -0,0 +1,62 @@
+package history
+
+import (
+	"context"
+	"fmt"
+
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+type Service struct {
+	cli helmcli.Client
+}
+
+// History returns the revisions of a release.
+func (s Service) History(ctx context.Context, req Request) (_ Response, err error) {
+	ctx, span := tracing.Start(ctx, "history.History")
+	defer func() { tracing.End(span, err) }()
+
+	historyFlags := flags.HistoryFlags{
+		Max:         req.Max,
+		GlobalFlags: req.GlobalFlags,
+	}
+	hcli, err := s.cli.NewHistoryGiver(historyFlags)
+	if err != nil {
+		return Response{}, fmt.Errorf("error while initializing history giver: %w", err)
+	}
+
+	releases, err := hcli.History(ctx, req.name)
+	if err != nil {
+		return Response{}, err
+	}
+
+	revisions := []Revision{}
+	for _, rel := range releases {
+		revisions = append(revisions, revisionInfo(rel))
+	}
+	return Response{History: revisions}, nil
+}
+
+func revisionInfo(rel *release.Release) Revision {
+	revision := Revision{Revision: rel.Version}
+	if rel.Info != nil {
+		revision.Status = rel.Info.Status
+		revision.Description = rel.Info.Description
+		revision.FirstDeployed = rel.Info.FirstDeployed.Local().Time
+		revision.LastDeployed = rel.Info.LastDeployed.Local().Time
+	}
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		revision.Chart = rel.Chart.ChartFullPath()
+		revision.ChartVersion = rel.Chart.Metadata.Version
+		revision.AppVersion = rel.Chart.AppVersion()
+	}
+	return revision
+}
+
+func NewService(cli helmcli.Client) Service {
+	return Service{cli}
+}
@@ -0,0 +1,147 @@
+package history
+
+import (
+	"context"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+)
+
+const testReleaseName = "test-release-name"
+
+// To satisfy the client interface, we have to define all methods(NewUpgrade, NewInstaller) on the mock struct
+// TODO: Find a way to isolate interface only for upgrade.
+type mockHelmClient struct{ mock.Mock }
+
+func (m *mockHelmClient) NewUpgrader(fl flags.UpgradeFlags) (helmcli.Upgrader, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Upgrader), args.Error(1)
+}
+
+func (m *mockHelmClient) NewInstaller(fl flags.InstallFlags) (helmcli.Installer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Installer), args.Error(1)
+}
+
+func (m *mockHelmClient) NewLister(fl flags.ListFlags) (helmcli.Lister, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Lister), args.Error(1)
+}
+
+func (m *mockHelmClient) NewStatusGiver(fl flags.StatusFlags) (helmcli.StatusGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.StatusGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewUninstaller(fl flags.UninstallFlags) (helmcli.Uninstaller, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Uninstaller), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRollbacker(fl flags.RollbackFlags) (helmcli.Rollbacker, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Rollbacker), args.Error(1)
+}
+
+func (m *mockHelmClient) NewHistoryGiver(fl flags.HistoryFlags) (helmcli.HistoryGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.HistoryGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewValuesGiver(fl flags.GetValuesFlags) (helmcli.ValuesGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.ValuesGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTemplater(fl flags.TemplateFlags) (helmcli.Templater, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Templater), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTester(fl flags.TestFlags) (helmcli.Tester, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Tester), args.Error(1)
+}
+
+func (m *mockHelmClient) NewShower(fl flags.ShowFlags) (helmcli.Shower, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Shower), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRecoverer(fl flags.RecoverFlags) (helmcli.Recoverer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Recoverer), args.Error(1)
+}
+
+type mockHistoryGiver struct{ mock.Mock }
+
+func (m *mockHistoryGiver) History(ctx context.Context, releaseName string) ([]*release.Release, error) {
+	args := m.Called(ctx, releaseName)
+	if len(args) < 1 {
+		log.Fatalf("error while mocking response for history")
+	}
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*release.Release), args.Error(1)
+}
+
+func TestShouldReturnRevisionsOnSuccess(t *testing.T) {
+	cli := new(mockHelmClient)
+	hgc := new(mockHistoryGiver)
+	service := NewService(cli)
+	ctx := context.Background()
+	globalFlags := flags.GlobalFlags{KubeContext: "minikube", Namespace: "default"}
+	req := Request{name: testReleaseName, Max: 2, GlobalFlags: globalFlags}
+	historyFlags := flags.HistoryFlags{Max: 2, GlobalFlags: globalFlags}
+	releases := []*release.Release{
+		release.Mock(&release.MockReleaseOptions{Name: testReleaseName, Version: 1, Status: release.StatusSuperseded}),
+		release.Mock(&release.MockReleaseOptions{Name: testReleaseName, Version: 2, Status: release.StatusDeployed}),
+	}
+	cli.On("NewHistoryGiver", historyFlags).Return(hgc, nil).Once()
+	hgc.On("History", mock.Anything, testReleaseName).Return(releases, nil).Once()
+
+	resp, err := service.History(ctx, req)
+
+	require.NoError(t, err)
+	require.Len(t, resp.History, 2)
+	for i, rel := range releases {
+		revision := resp.History[i]
+		assert.Equal(t, rel.Version, revision.Revision)
+		assert.Equal(t, rel.Info.Status, revision.Status)
+		assert.Equal(t, rel.Info.Description, revision.Description)
+		assert.Equal(t, rel.Chart.ChartFullPath(), revision.Chart)
+		assert.Equal(t, rel.Chart.Metadata.Version, revision.ChartVersion)
+		assert.Equal(t, rel.Chart.AppVersion(), revision.AppVersion)
+		assert.Equal(t, rel.Info.FirstDeployed.Local().Time, revision.FirstDeployed)
+		assert.Equal(t, rel.Info.LastDeployed.Local().Time, revision.LastDeployed)
+	}
+	cli.AssertExpectations(t)
+	hgc.AssertExpectations(t)
+}
+
+func TestShouldReturnErrorWhenReleaseIsNotFound(t *testing.T) {
+	cli := new(mockHelmClient)
+	hgc := new(mockHistoryGiver)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{name: testReleaseName}
+	cli.On("NewHistoryGiver", flags.HistoryFlags{}).Return(hgc, nil).Once()
+	hgc.On("History", mock.Anything, testReleaseName).Return(nil, driver.ErrReleaseNotFound).Once()
+
+	resp, err := service.History(ctx, req)
+
+	assert.True(t, errors.Is(err, driver.ErrReleaseNotFound))
+	assert.Empty(t, resp.History)
+	cli.AssertExpectations(t)
+	hgc.AssertExpectations(t)
+}
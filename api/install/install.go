@@ -10,16 +10,15 @@ import (
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/release"
 
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
 	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/upload"
 
 	"github.com/gorilla/mux"
 )
 
-const (
-	alreadyPresent    = "cannot re-use a name that is still in use"
-	releaseNameMaxLen = 53
-)
+const releaseNameMaxLen = 53
 
 // Request is the body for installing a release
 // swagger:model installRequestBody
@@ -27,6 +26,8 @@ type Request struct {
 	Name string `json:"name"`
 	// example: stable/mysql
 	Chart string `json:"chart"`
+	// Packaged chart, base64 encoded, to install instead of chart
+	ChartArchive []byte `json:"chart_archive,omitempty"`
 	// example: {"replicaCount": 1}
 	Values map[string]interface{} `json:"values"`
 	Flags  Flags                  `json:"flags"`
@@ -39,6 +40,30 @@ type Flags struct {
 	DryRun bool `json:"dry_run"`
 	// example: 1
 	Version string `json:"version"`
+	// Wait for the release resources to be ready before marking the release deployed
+	// example: false
+	Wait bool `json:"wait"`
+	// Wait for the jobs of the release to complete, only applies when wait is set.
+	// Failed jobs fail the release, which atomic then uninstalls
+	// example: false
+	WaitForJobs bool `json:"wait_for_jobs"`
+	// Timeout in seconds for kubernetes operations and waiting
+	// example: 300
+	Timeout int `json:"timeout"`
+	// Roll back or uninstall the release when the operation fails, implies wait
+	// example: false
+	Atomic bool `json:"atomic"`
+	// example: false
+	DisableHooks bool `json:"disable_hooks"`
+	// example: false
+	SkipCRDs bool `json:"skip_crds"`
+	// example: false
+	CreateNamespace bool `json:"create_namespace"`
+	// example: deployed by albatross
+	Description string `json:"description"`
+	// Verify the chart against its provenance file, always done when the server requires signed charts
+	// example: false
+	Verify bool `json:"verify"`
 	flags.GlobalFlags
 }
 
@@ -64,8 +89,6 @@ type Release struct {
 // Response body of install response
 // swagger:model installResponseBody
 type Response struct {
-	// Error error message, field is available only when status code is non 2xx
-	Error string `json:"error,omitempty"`
 	// example: deployed
 	Status  string `json:"status,omitempty"`
 	Data    string `json:"data,omitempty"`
@@ -80,10 +103,13 @@ type service interface {
 // swagger:operation POST /clusters/{cluster}/namespaces/{namespace}/releases release installOperation
 //
 //
+// Install the chart named in chart, or the packaged chart given base64 encoded in chart_archive.
+// A multipart/form-data body carries the JSON body in the request field and the packaged chart in the chart_archive file.
 // ---
 // summary: Install helm release at the specified cluster and namespace
 // consumes:
 // - application/json
+// - multipart/form-data
 // produces:
 // - application/json
 // parameters:
@@ -104,69 +130,68 @@ type service interface {
 //   required: true
 //   schema:
 //    "$ref": "#/definitions/installRequestBody"
+// - name: async
+//   in: query
+//   type: boolean
+//   default: false
+//   description: run the action in the background and return the operation to poll
 // schemes:
 // - http
 // responses:
 //   '200':
 //    "$ref": "#/responses/installResponse"
+//   '202':
+//    schema:
+//     $ref: "#/definitions/operation"
 //   '400':
-//    description: Invalid request
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
 //   '409':
 //    schema:
-//     $ref: "#/definitions/installResponseErrorBody"
+//     $ref: "#/definitions/errorResponse"
 //   '500':
-//    "$ref": "#/responses/installResponse"
+//    schema:
+//     $ref: "#/definitions/errorResponse"
 
 func Handler(service service) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer r.Body.Close()
 
 		var req Request
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		archive, err := upload.Decode(r, &req)
+		if err != nil {
 			logger.Errorf("[Install] error decoding request: %v", err)
-			w.WriteHeader(http.StatusBadRequest)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
 			return
 		}
+		if archive != nil {
+			req.ChartArchive = archive
+		}
 		values := mux.Vars(r)
 		req.Flags.KubeContext = values["cluster"]
 		req.Flags.Namespace = values["namespace"]
 		if err := req.valid(); err != nil {
 			logger.Errorf("[Install] error in request parameters: %v", err)
-			w.WriteHeader(http.StatusBadRequest)
-			respondInstallError(w, "", err, http.StatusBadRequest)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
 			return
 		}
 
 		resp, err := service.Install(r.Context(), req)
 		if err != nil {
-			code := http.StatusInternalServerError
-			if err.Error() == alreadyPresent {
-				code = http.StatusConflict
-			}
-			respondInstallError(w, "error while installing chart: %v", err, code)
+			logger.Errorf("[Install] error while installing chart: %v", err)
+			apiErrors.Write(w, apiErrors.WithReleaseStatus(err, resp.Status))
 			return
 		}
 
 		if err := json.NewEncoder(w).Encode(&resp); err != nil {
-			respondInstallError(w, "error writing response: %v", err, http.StatusInternalServerError)
-			return
+			logger.Errorf("[Install] error writing response: %v", err)
 		}
 	})
 }
 
-// TODO: This does not handle different status codes.
-func respondInstallError(w http.ResponseWriter, logprefix string, err error, statusCode int) {
-	response := Response{Error: err.Error()}
-	if statusCode > 0 {
-		w.WriteHeader(statusCode)
-	}
-	if err := json.NewEncoder(w).Encode(&response); err != nil {
-		logger.Errorf("[Install] %s %v", logprefix, err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-}
-
 func (req Request) valid() error {
 	switch releaseName := req.Name; {
 	case releaseName == "":
@@ -176,5 +201,8 @@ func (req Request) valid() error {
 	case len(releaseName) > releaseNameMaxLen:
 		return fmt.Errorf("release name %s exceeds max length of %d", releaseName, releaseNameMaxLen)
 	}
+	if (req.Chart == "") == (len(req.ChartArchive) == 0) {
+		return fmt.Errorf("either chart or chart_archive is required")
+	}
 	return nil
 }
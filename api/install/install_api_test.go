@@ -1,10 +1,13 @@
 package install
 
 import (
+	"bytes"
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -18,8 +21,10 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/gojekfarm/albatross/pkg/helmcli"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
 	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/upload"
 
 	"helm.sh/helm/v3/pkg/release"
 )
@@ -104,7 +109,7 @@ func (s *InstallerTestSuite) TestShouldReturnInternalServerErrorOnFailure() {
 	resp, err := http.DefaultClient.Do(req)
 
 	assert.Equal(s.T(), http.StatusInternalServerError, resp.StatusCode)
-	expectedResponse := `{"error":"invalid chart"}` + "\n"
+	expectedResponse := `{"code":"internal","message":"invalid chart"}` + "\n"
 	respBody, _ := ioutil.ReadAll(resp.Body)
 	assert.Equal(s.T(), expectedResponse, string(respBody))
 	require.NoError(s.T(), err)
@@ -134,15 +139,15 @@ func (s *InstallerTestSuite) TestShouldReturnBadRequestWhenReleaseNameIsInvalid(
 	testCases := []testCase{
 		{
 			body:             fmt.Sprintf(`{"name":"%s","chart":"%s", "values": {"replicas": 2}, "flags": {}}`, releaseNames[0], chartName),
-			expectedResponse: fmt.Sprintf("{\"error\":\"release name %s must match regex %s\"}\n", releaseNames[0], action.ValidName.String()),
+			expectedResponse: fmt.Sprintf("{\"code\":\"invalid\",\"message\":\"release name %s must match regex %s\"}\n", releaseNames[0], action.ValidName.String()),
 		},
 		{
 			body:             fmt.Sprintf(`{"name":"%s","chart":"%s", "values": {"replicas": 2}, "flags": {}}`, releaseNames[1], chartName),
-			expectedResponse: "{\"error\":\"release name cannot be empty string\"}\n",
+			expectedResponse: "{\"code\":\"invalid\",\"message\":\"release name cannot be empty string\"}\n",
 		},
 		{
 			body:             fmt.Sprintf(`{"name":"%s","chart":"%s", "values": {"replicas": 2}, "flags": {}}`, releaseNames[2], chartName),
-			expectedResponse: fmt.Sprintf("{\"error\":\"release name %s exceeds max length of %d\"}\n", releaseNames[2], releaseNameMaxLen),
+			expectedResponse: fmt.Sprintf("{\"code\":\"invalid\",\"message\":\"release name %s exceeds max length of %d\"}\n", releaseNames[2], releaseNameMaxLen),
 		},
 	}
 	for _, tc := range testCases {
@@ -154,6 +159,110 @@ func (s *InstallerTestSuite) TestShouldReturnBadRequestWhenReleaseNameIsInvalid(
 	}
 }
 
+func (s *InstallerTestSuite) TestShouldInstallUploadedChartArchive() {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	require.NoError(s.T(), w.WriteField(upload.RequestField, `{"name":"redis-v5","values":{"replicas":2}}`))
+	part, err := w.CreateFormFile(upload.ChartField, "redis-ha-4.4.2.tgz")
+	require.NoError(s.T(), err)
+	_, err = part.Write([]byte("archive"))
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), w.Close())
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/minikube/namespaces/albatross/releases", s.server.URL), body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	requestStruct := Request{
+		Name:         "redis-v5",
+		ChartArchive: []byte("archive"),
+		Values:       map[string]interface{}{"replicas": float64(2)},
+		Flags: Flags{
+			GlobalFlags: flags.GlobalFlags{
+				Namespace:   "albatross",
+				KubeContext: "minikube",
+			},
+		},
+	}
+	s.mockService.On("Install", mock.Anything, requestStruct).Return(Response{Status: release.StatusDeployed.String()}, nil)
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *InstallerTestSuite) TestShouldReturnBadRequestForInvalidChartArchive() {
+	body := `{"name":"redis-v5","chart_archive":"` + base64.StdEncoding.EncodeToString([]byte("archive")) + `"}`
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/minikube/namespaces/albatross/releases", s.server.URL), strings.NewReader(body))
+	invalid := fmt.Errorf("%w: gzip: invalid header", upload.ErrInvalidChart)
+	s.mockService.On("Install", mock.Anything, mock.MatchedBy(func(r Request) bool { return string(r.ChartArchive) == "archive" })).Return(Response{}, invalid)
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(s.T(), `{"code":"invalid","message":"invalid chart archive: gzip: invalid header"}`+"\n", string(respBody))
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *InstallerTestSuite) TestShouldReturnBadRequestWhenVerificationFails() {
+	body := `{"name":"redis-v5","chart":"stable/redis-ha","flags":{"verify":true}}`
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/minikube/namespaces/albatross/releases", s.server.URL), strings.NewReader(body))
+	verification := fmt.Errorf("%w: could not load provenance file", helmcli.ErrVerification)
+	s.mockService.On("Install", mock.Anything, mock.MatchedBy(func(r Request) bool { return r.Flags.Verify })).Return(Response{}, verification)
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(s.T(), `{"code":"invalid","message":"chart verification failed: could not load provenance file"}`+"\n", string(respBody))
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *InstallerTestSuite) TestShouldRequireEitherChartOrChartArchive() {
+	for _, body := range []string{`{"name":"redis-v5"}`, `{"name":"redis-v5","chart":"stable/redis-ha","chart_archive":"YXJjaGl2ZQ=="}`} {
+		req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/minikube/namespaces/albatross/releases", s.server.URL), strings.NewReader(body))
+
+		resp, err := http.DefaultClient.Do(req)
+
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+		respBody, _ := ioutil.ReadAll(resp.Body)
+		assert.Equal(s.T(), `{"code":"invalid","message":"either chart or chart_archive is required"}`+"\n", string(respBody))
+	}
+}
+
+func (s *InstallerTestSuite) TestShouldReturnConflictWhenReleaseExists() {
+	body := `{"name":"redis-v5","chart":"stable/redis-ha"}`
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/minikube/namespaces/albatross/releases", s.server.URL), strings.NewReader(body))
+	exists := fmt.Errorf("%w: cannot re-use a name that is still in use", helmcli.ErrReleaseExists)
+	s.mockService.On("Install", mock.Anything, mock.Anything).Return(Response{}, exists)
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusConflict, resp.StatusCode)
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(s.T(), `{"code":"conflict","message":"release already exists: cannot re-use a name that is still in use"}`+"\n", string(respBody))
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *InstallerTestSuite) TestShouldReturnChartNotFoundWithTheStatusOfTheRelease() {
+	body := `{"name":"redis-v5","chart":"stable/unknown"}`
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/minikube/namespaces/albatross/releases", s.server.URL), strings.NewReader(body))
+	missing := fmt.Errorf("%w: failed to download \"stable/unknown\"", helmcli.ErrChartNotFound)
+	s.mockService.On("Install", mock.Anything, mock.Anything).Return(Response{Status: "failed"}, missing)
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusNotFound, resp.StatusCode)
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(s.T(), `{"code":"chart_not_found","message":"chart not found: failed to download \"stable/unknown\"","details":{"status":"failed"}}`+"\n", string(respBody))
+	s.mockService.AssertExpectations(s.T())
+}
+
 func (s *InstallerTestSuite) TearDownTest() {
 	s.server.Close()
 }
@@ -3,29 +3,52 @@ package install
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"helm.sh/helm/v3/pkg/release"
 
+	"github.com/gojekfarm/albatross/pkg/audit"
 	"github.com/gojekfarm/albatross/pkg/helmcli"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/tracing"
+	"github.com/gojekfarm/albatross/pkg/upload"
 )
 
+const defaultTimeout = 300 * time.Second
+
 type Service struct {
 	cli helmcli.Client
 }
 
-func (s Service) Install(ctx context.Context, req Request) (Response, error) {
+func (s Service) Install(ctx context.Context, req Request) (_ Response, err error) {
+	ctx, span := tracing.Start(ctx, "install.Install")
+	defer func() { tracing.End(span, err) }()
+
+	timeout := defaultTimeout
+	if req.Flags.Timeout > 0 {
+		timeout = time.Second * time.Duration(req.Flags.Timeout)
+	}
 	installflags := flags.InstallFlags{
-		DryRun:      req.Flags.DryRun,
-		Version:     req.Flags.Version,
-		GlobalFlags: req.Flags.GlobalFlags,
+		DryRun:          req.Flags.DryRun,
+		Version:         req.Flags.Version,
+		Wait:            req.Flags.Wait,
+		WaitForJobs:     req.Flags.WaitForJobs,
+		Timeout:         timeout,
+		Atomic:          req.Flags.Atomic,
+		DisableHooks:    req.Flags.DisableHooks,
+		SkipCRDs:        req.Flags.SkipCRDs,
+		CreateNamespace: req.Flags.CreateNamespace,
+		Description:     req.Flags.Description,
+		Verify:          req.Flags.Verify,
+		GlobalFlags:     req.Flags.GlobalFlags,
 	}
 	icli, err := s.cli.NewInstaller(installflags)
 	if err != nil {
-		return Response{}, fmt.Errorf("error while initializing the installer: %s", err)
+		return Response{}, fmt.Errorf("error while initializing the installer: %w", err)
 	}
 
-	rel, err := icli.Install(ctx, req.Name, req.Chart, req.Values)
+	rel, err := install(ctx, icli, req)
+	audit.SetRelease(ctx, rel)
 	if err != nil {
 		return responseWithStatus(rel), err
 	}
@@ -36,6 +59,18 @@ func (s Service) Install(ctx context.Context, req Request) (Response, error) {
 	return resp, nil
 }
 
+// install installs the uploaded chart archive of the request, or else its named chart
+func install(ctx context.Context, icli helmcli.Installer, req Request) (*release.Release, error) {
+	if len(req.ChartArchive) == 0 {
+		return icli.Install(ctx, req.Name, req.Chart, req.Values)
+	}
+	ch, err := upload.Load(req.ChartArchive)
+	if err != nil {
+		return nil, err
+	}
+	return icli.InstallChart(ctx, req.Name, ch, req.Values)
+}
+
 func releaseInfo(rel *release.Release) Release {
 	return Release{
 		Name:       rel.Name,
@@ -3,18 +3,23 @@ package install
 import (
 	"context"
 	"errors"
+	"io/ioutil"
 	"log"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/release"
 	"helm.sh/helm/v3/pkg/time"
 
 	"github.com/gojekfarm/albatross/pkg/helmcli"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/upload"
 )
 
 // To satisfy the client interface, we have to define all methods(NewUpgrade, NewInstaller) on the mock struct
@@ -46,6 +51,41 @@ func (m *mockHelmClient) NewStatusGiver(fl flags.StatusFlags) (helmcli.StatusGiv
 	return args.Get(0).(helmcli.StatusGiver), args.Error(1)
 }
 
+func (m *mockHelmClient) NewRollbacker(fl flags.RollbackFlags) (helmcli.Rollbacker, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Rollbacker), args.Error(1)
+}
+
+func (m *mockHelmClient) NewHistoryGiver(fl flags.HistoryFlags) (helmcli.HistoryGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.HistoryGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewValuesGiver(fl flags.GetValuesFlags) (helmcli.ValuesGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.ValuesGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTemplater(fl flags.TemplateFlags) (helmcli.Templater, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Templater), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTester(fl flags.TestFlags) (helmcli.Tester, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Tester), args.Error(1)
+}
+
+func (m *mockHelmClient) NewShower(fl flags.ShowFlags) (helmcli.Shower, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Shower), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRecoverer(fl flags.RecoverFlags) (helmcli.Recoverer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Recoverer), args.Error(1)
+}
+
 type mockInstaller struct{ mock.Mock }
 
 func (m *mockInstaller) Install(ctx context.Context, relName, chart string, values map[string]interface{}) (*release.Release, error) {
@@ -56,6 +96,11 @@ func (m *mockInstaller) Install(ctx context.Context, relName, chart string, valu
 	return args.Get(0).(*release.Release), args.Error(1)
 }
 
+func (m *mockInstaller) InstallChart(ctx context.Context, relName string, ch *chart.Chart, values map[string]interface{}) (*release.Release, error) {
+	args := m.Called(ctx, relName, ch, values)
+	return args.Get(0).(*release.Release), args.Error(1)
+}
+
 func TestShouldReturnErrorOnInvalidChart(t *testing.T) {
 	cli := new(mockHelmClient)
 	inc := new(mockInstaller)
@@ -64,18 +109,71 @@ func TestShouldReturnErrorOnInvalidChart(t *testing.T) {
 	req := Request{Name: "invalid_release", Chart: "stable/invalid_chart"}
 	cli.On("NewInstaller", mock.AnythingOfType("flags.InstallFlags")).Return(inc, nil)
 	rel := &release.Release{Info: &release.Info{Status: release.StatusFailed}}
-	inc.On("Install", ctx, req.Name, req.Chart, req.Values).Return(rel, errors.New("failed to download invalid-chart"))
+	inc.On("Install", mock.Anything, req.Name, req.Chart, req.Values).Return(rel, errors.New("failed to download invalid-chart"))
 
 	resp, err := service.Install(ctx, req)
 
 	assert.EqualError(t, err, "failed to download invalid-chart")
 	require.NotNil(t, resp)
-	assert.Empty(t, resp.Error)
 	assert.Equal(t, "failed", resp.Status)
 	cli.AssertExpectations(t)
 	inc.AssertExpectations(t)
 }
 
+func TestShouldPassDeployOptionsToInstaller(t *testing.T) {
+	cli := new(mockHelmClient)
+	inc := new(mockInstaller)
+	service := NewService(cli)
+	ctx := context.Background()
+	globalFlags := flags.GlobalFlags{KubeContext: "minikube", Namespace: "test-namespace"}
+	req := Request{Name: "test-release", Chart: "stable/albatross", Flags: Flags{
+		Wait:            true,
+		WaitForJobs:     true,
+		Timeout:         600,
+		Atomic:          true,
+		DisableHooks:    true,
+		SkipCRDs:        true,
+		CreateNamespace: true,
+		Description:     "deployed by albatross",
+		GlobalFlags:     globalFlags,
+	}}
+	installFlags := flags.InstallFlags{
+		Wait:            true,
+		WaitForJobs:     true,
+		Timeout:         2 * defaultTimeout,
+		Atomic:          true,
+		DisableHooks:    true,
+		SkipCRDs:        true,
+		CreateNamespace: true,
+		Description:     "deployed by albatross",
+		GlobalFlags:     globalFlags,
+	}
+	rel := &release.Release{Info: &release.Info{Status: release.StatusFailed}}
+	cli.On("NewInstaller", installFlags).Return(inc, nil).Once()
+	inc.On("Install", mock.Anything, req.Name, req.Chart, req.Values).Return(rel, errors.New("timed out waiting for the condition")).Once()
+
+	_, err := service.Install(ctx, req)
+
+	assert.EqualError(t, err, "timed out waiting for the condition")
+	cli.AssertExpectations(t)
+}
+
+func TestShouldUseDefaultTimeout(t *testing.T) {
+	cli := new(mockHelmClient)
+	inc := new(mockInstaller)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{Name: "test-release", Chart: "stable/albatross"}
+	rel := &release.Release{Info: &release.Info{Status: release.StatusFailed}}
+	cli.On("NewInstaller", flags.InstallFlags{Timeout: defaultTimeout}).Return(inc, nil).Once()
+	inc.On("Install", mock.Anything, req.Name, req.Chart, req.Values).Return(rel, errors.New("install failed")).Once()
+
+	_, err := service.Install(ctx, req)
+
+	assert.Error(t, err)
+	cli.AssertExpectations(t)
+}
+
 func TestShouldReturnValidResponseOnSuccess(t *testing.T) {
 	cli := new(mockHelmClient)
 	inc := new(mockInstaller)
@@ -104,7 +202,7 @@ func TestShouldReturnValidResponseOnSuccess(t *testing.T) {
 		Chart: chart,
 	}
 
-	inc.On("Install", ctx, req.Name, req.Chart, req.Values).Return(rel, nil)
+	inc.On("Install", mock.Anything, req.Name, req.Chart, req.Values).Return(rel, nil)
 
 	resp, err := service.Install(ctx, req)
 
@@ -117,7 +215,44 @@ func TestShouldReturnValidResponseOnSuccess(t *testing.T) {
 	assert.Equal(t, resp.Chart, rel.Chart.ChartFullPath())
 	assert.Equal(t, resp.Updated, rel.Info.FirstDeployed.Local().Time)
 	assert.Equal(t, resp.AppVersion, rel.Chart.AppVersion())
-	assert.Empty(t, resp.Error)
 	cli.AssertExpectations(t)
 	inc.AssertExpectations(t)
 }
+
+func TestShouldInstallUploadedChartArchive(t *testing.T) {
+	cli := new(mockHelmClient)
+	inc := new(mockInstaller)
+	service := NewService(cli)
+	ctx := context.Background()
+	ch, err := loader.Load("../testdata/albatross")
+	require.NoError(t, err)
+	dir, err := ioutil.TempDir("", "install")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path, err := chartutil.Save(ch, dir)
+	require.NoError(t, err)
+	archive, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	req := Request{Name: "test-release", ChartArchive: archive}
+	rel := &release.Release{Name: "test-release", Info: &release.Info{Status: release.StatusDeployed}, Chart: ch}
+	cli.On("NewInstaller", mock.AnythingOfType("flags.InstallFlags")).Return(inc, nil)
+	inc.On("InstallChart", mock.Anything, req.Name, mock.MatchedBy(func(c *chart.Chart) bool { return c.Name() == "albatross" }), req.Values).Return(rel, nil)
+
+	resp, err := service.Install(ctx, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, "deployed", resp.Status)
+	inc.AssertExpectations(t)
+}
+
+func TestShouldRejectInvalidChartArchive(t *testing.T) {
+	cli := new(mockHelmClient)
+	inc := new(mockInstaller)
+	service := NewService(cli)
+	cli.On("NewInstaller", mock.AnythingOfType("flags.InstallFlags")).Return(inc, nil)
+
+	_, err := service.Install(context.Background(), Request{Name: "test-release", ChartArchive: []byte("archive")})
+
+	assert.True(t, errors.Is(err, upload.ErrInvalidChart), err)
+	inc.AssertExpectations(t)
+}
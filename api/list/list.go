@@ -8,6 +8,7 @@ import (
 
 	"helm.sh/helm/v3/pkg/release"
 
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
 	"github.com/gojekfarm/albatross/pkg/logger"
 
@@ -28,6 +29,10 @@ type Flags struct {
 	Pending       bool `schema:"pending"`
 	Uninstalled   bool `schema:"uninstalled"`
 	Uninstalling  bool `schema:"uninstalling"`
+	// Stuck only lists releases whose last revision is pending-install, pending-upgrade or pending-rollback
+	Stuck bool `schema:"stuck"`
+	// StuckFor only lists stuck releases last updated at least this many seconds ago
+	StuckFor int `schema:"stuck_for"`
 	flags.GlobalFlags
 }
 
@@ -53,8 +58,6 @@ type Release struct {
 // Response is the body of /list
 // swagger:model listReponseBody
 type Response struct {
-	// Error field is available only when the response status code is non 2xx
-	Error    string    `json:"error,omitempty"`
 	Releases []Release `json:"releases,omitempty"`
 }
 
@@ -97,6 +100,16 @@ type service interface {
 //   in: query
 //   type: boolean
 //   default: false
+// - name: stuck
+//   in: query
+//   description: only list releases whose last revision is pending-install, pending-upgrade or pending-rollback, ignoring the other filters
+//   type: boolean
+//   default: false
+// - name: stuck_for
+//   in: query
+//   description: with stuck, only list releases last updated at least this many seconds ago
+//   type: integer
+//   default: 0
 // schemes:
 // - http
 // responses:
@@ -106,17 +119,20 @@ type service interface {
 //    description: No releases found
 //   '400':
 //    schema:
-//     $ref: "#/definitions/listErrorResponse"
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
 //   '500':
 //    schema:
-//     $ref: "#/definitions/listErrorResponse"
+//     $ref: "#/definitions/errorResponse"
 func Handler(service service) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer r.Body.Close()
 		var req Request
 		if err := decoder.Decode(&req, r.URL.Query()); err != nil {
 			logger.Errorf("[List] error decoding request: %v", err.Error())
-			w.WriteHeader(http.StatusBadRequest)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
 			return
 		}
 		values := mux.Vars(r)
@@ -124,7 +140,8 @@ func Handler(service service) http.Handler {
 		populateRequestFlags(&req, values)
 		resp, err := service.List(r.Context(), req)
 		if err != nil {
-			respondListError(w, "error while listing charts: %v", err)
+			logger.Errorf("[List] error while listing charts: %v", err)
+			apiErrors.Write(w, err)
 			return
 		}
 
@@ -134,8 +151,7 @@ func Handler(service service) http.Handler {
 		}
 
 		if err = json.NewEncoder(w).Encode(resp); err != nil {
-			respondListError(w, "error writing response: %v", err)
-			return
+			logger.Errorf("[List] error writing response: %v", err)
 		}
 	})
 }
@@ -181,6 +197,16 @@ func Handler(service service) http.Handler {
 //   in: query
 //   type: boolean
 //   default: false
+// - name: stuck
+//   in: query
+//   description: only list releases whose last revision is pending-install, pending-upgrade or pending-rollback, ignoring the other filters
+//   type: boolean
+//   default: false
+// - name: stuck_for
+//   in: query
+//   description: with stuck, only list releases last updated at least this many seconds ago
+//   type: integer
+//   default: 0
 // schemes:
 // - http
 // responses:
@@ -189,21 +215,14 @@ func Handler(service service) http.Handler {
 //   '204':
 //    description: No releases found
 //   '400':
-//    "$ref": "#/responses/listResponse"
+//    schema:
+//     $ref: "#/definitions/errorResponse"
 //   '404':
-//    "$ref": "#/responses/listResponse"
+//    schema:
+//     $ref: "#/definitions/errorResponse"
 //   '500':
-//    "$ref": "#/responses/listResponse"
-
-func respondListError(w http.ResponseWriter, logprefix string, err error) {
-	response := Response{Error: err.Error()}
-	w.WriteHeader(http.StatusInternalServerError)
-	if err := json.NewEncoder(w).Encode(&response); err != nil {
-		logger.Errorf("[List] %s %v", logprefix, err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-}
+//    schema:
+//     $ref: "#/definitions/errorResponse"
 
 func populateRequestFlags(req *Request, values map[string]string) {
 	if values["namespace"] == "" {
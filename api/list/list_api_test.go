@@ -16,6 +16,8 @@ import (
 	"github.com/stretchr/testify/suite"
 	"gotest.tools/assert"
 
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/cluster"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
 	"github.com/gojekfarm/albatross/pkg/logger"
 
@@ -87,7 +89,6 @@ func (s *ListTestSuite) TestShouldReturnReleasesWhenSuccessfulAPICall() {
 	err = json.NewDecoder(res.Body).Decode(&actualResponse)
 
 	expectedResponse := Response{
-		Error:    "",
 		Releases: response.Releases,
 	}
 
@@ -132,7 +133,6 @@ func (s *ListTestSuite) TestShouldReturnReleasesWhenSuccessfulAPICallNamespace()
 	err = json.NewDecoder(res.Body).Decode(&actualResponse)
 
 	expectedResponse := Response{
-		Error:    "",
 		Releases: response.Releases,
 	}
 
@@ -163,6 +163,27 @@ func (s *ListTestSuite) TestShouldReturnNoContentWhenNoReleasesAreAvailable() {
 
 	s.mockService.AssertExpectations(s.T())
 }
+func (s *ListTestSuite) TestShouldPassStuckFilter() {
+	req, _ := http.NewRequest("GET", fmt.Sprintf("%s/clusters/staging/namespaces/test/releases?stuck=true&stuck_for=600", s.server.URL), nil)
+	expectedRequestStruct := Request{
+		Flags: Flags{
+			Stuck:    true,
+			StuckFor: 600,
+			GlobalFlags: flags.GlobalFlags{
+				Namespace:   "test",
+				KubeContext: "staging",
+			},
+		},
+	}
+	s.mockService.On("List", mock.Anything, expectedRequestStruct).Return(Response{}, nil).Once()
+
+	res, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+
+	assert.Equal(s.T(), 204, res.StatusCode)
+	s.mockService.AssertExpectations(s.T())
+}
+
 func (s *ListTestSuite) TestShouldReturnBadRequestErrorIfItHasInvalidCharacter() {
 	req, _ := http.NewRequest("GET", fmt.Sprintf("%s/clusters/staging/releases?deply=test", s.server.URL), nil)
 
@@ -192,13 +213,25 @@ func (s *ListTestSuite) TestShouldReturnInternalServerErrorIfListServiceReturnsE
 	res, err := http.DefaultClient.Do(req)
 	assert.Equal(s.T(), 500, res.StatusCode)
 	require.NoError(s.T(), err)
-	var actualResponse Response
+	var actualResponse apiErrors.Body
 	err = json.NewDecoder(res.Body).Decode(&actualResponse)
 	require.NoError(s.T(), err)
-	expectedResponse := Response{
-		Error: errorMsg,
-	}
-	assert.Equal(s.T(), expectedResponse.Error, actualResponse.Error)
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Internal, Message: errorMsg}, actualResponse)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ListTestSuite) TestShouldReturnNotFoundForUnknownCluster() {
+	req, _ := http.NewRequest("GET", fmt.Sprintf("%s/clusters/unknown/releases", s.server.URL), nil)
+	listError := fmt.Errorf("error while initializing lister: %w", cluster.ErrNotFound)
+	s.mockService.On("List", mock.Anything, mock.Anything).Return(Response{}, listError).Once()
+
+	res, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusNotFound, res.StatusCode)
+	var actualResponse apiErrors.Body
+	require.NoError(s.T(), json.NewDecoder(res.Body).Decode(&actualResponse))
+	assert.Equal(s.T(), apiErrors.NotFound, actualResponse.Code)
 	s.mockService.AssertExpectations(s.T())
 }
 
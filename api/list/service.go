@@ -3,18 +3,23 @@ package list
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"helm.sh/helm/v3/pkg/release"
 
 	"github.com/gojekfarm/albatross/pkg/helmcli"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/tracing"
 )
 
 type Service struct {
 	cli helmcli.Client
 }
 
-func (s Service) List(ctx context.Context, req Request) (Response, error) {
+func (s Service) List(ctx context.Context, req Request) (_ Response, err error) {
+	ctx, span := tracing.Start(ctx, "list.List")
+	defer func() { tracing.End(span, err) }()
+
 	listflags := flags.ListFlags{
 		GlobalFlags:   req.Flags.GlobalFlags,
 		AllNamespaces: req.AllNamespaces,
@@ -23,10 +28,12 @@ func (s Service) List(ctx context.Context, req Request) (Response, error) {
 		Uninstalled:   req.Uninstalled,
 		Uninstalling:  req.Uninstalling,
 		Pending:       req.Pending,
+		Stuck:         req.Stuck,
+		StuckFor:      time.Duration(req.StuckFor) * time.Second,
 	}
 	lcli, err := s.cli.NewLister(listflags)
 	if err != nil {
-		return Response{}, fmt.Errorf("error while initializing lister: %s", err)
+		return Response{}, fmt.Errorf("error while initializing lister: %w", err)
 	}
 
 	releases, err := lcli.List(ctx)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"testing"
+	gotime "time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -55,6 +56,41 @@ func (m *mockHelmClient) NewUninstaller(fl flags.UninstallFlags) (helmcli.Uninst
 	return args.Get(0).(helmcli.Uninstaller), args.Error(1)
 }
 
+func (m *mockHelmClient) NewRollbacker(fl flags.RollbackFlags) (helmcli.Rollbacker, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Rollbacker), args.Error(1)
+}
+
+func (m *mockHelmClient) NewHistoryGiver(fl flags.HistoryFlags) (helmcli.HistoryGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.HistoryGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewValuesGiver(fl flags.GetValuesFlags) (helmcli.ValuesGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.ValuesGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTemplater(fl flags.TemplateFlags) (helmcli.Templater, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Templater), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTester(fl flags.TestFlags) (helmcli.Tester, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Tester), args.Error(1)
+}
+
+func (m *mockHelmClient) NewShower(fl flags.ShowFlags) (helmcli.Shower, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Shower), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRecoverer(fl flags.RecoverFlags) (helmcli.Recoverer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Recoverer), args.Error(1)
+}
+
 func TestShouldReturnValidResponseOnSuccess(t *testing.T) {
 	cli := new(mockHelmClient)
 	lic := new(mockLister)
@@ -95,7 +131,7 @@ func TestShouldReturnValidResponseOnSuccess(t *testing.T) {
 		},
 	}
 
-	lic.On("List", ctx).Return(releases, nil)
+	lic.On("List", mock.Anything).Return(releases, nil)
 
 	resp, err := service.List(ctx, req)
 
@@ -109,7 +145,27 @@ func TestShouldReturnValidResponseOnSuccess(t *testing.T) {
 	assert.Equal(t, rel.Chart, releases[0].Chart.ChartFullPath())
 	assert.Equal(t, rel.Updated, releases[0].Info.FirstDeployed.Local().Time)
 	assert.Equal(t, rel.AppVersion, releases[0].Chart.AppVersion())
-	assert.Empty(t, resp.Error)
+	cli.AssertExpectations(t)
+	lic.AssertExpectations(t)
+}
+
+func TestShouldListStuckReleasesForTheGivenDuration(t *testing.T) {
+	cli := new(mockHelmClient)
+	lic := new(mockLister)
+	service := NewService(cli)
+	req := Request{Flags: Flags{Stuck: true, StuckFor: 600, GlobalFlags: flags.GlobalFlags{KubeContext: "abc"}}}
+	listFlags := flags.ListFlags{
+		Stuck:       true,
+		StuckFor:    10 * gotime.Minute,
+		GlobalFlags: flags.GlobalFlags{KubeContext: "abc"},
+	}
+	cli.On("NewLister", listFlags).Return(lic, nil)
+	lic.On("List", mock.Anything).Return([]*release.Release{}, nil)
+
+	resp, err := service.List(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Empty(t, resp.Releases)
 	cli.AssertExpectations(t)
 	lic.AssertExpectations(t)
 }
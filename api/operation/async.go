@@ -0,0 +1,119 @@
+package operation
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/internal/response"
+	"github.com/gojekfarm/albatross/pkg/lock"
+	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/operation"
+	"github.com/gojekfarm/albatross/pkg/upload"
+)
+
+// AsyncQueryParam switches a request to asynchronous mode when set to true
+const AsyncQueryParam = "async"
+
+type submitter interface {
+	Submit(ctx context.Context, kind, owner string, fn operation.Func) (operation.Operation, error)
+	Done(id string) <-chan struct{}
+}
+
+// Async runs next in the background when the request sets async=true and answers with 202 Accepted
+// and the operation, whose result is the response next would have written.
+// The operation belongs to the principal of the request, only that principal can get or cancel it.
+// Asynchronous requests go through before, such as the lock of their release, in the request ahead of being queued,
+// so that conflicts are answered right away. Locks taken there are kept by the operation until it finishes.
+// Requests without the flag are served synchronously.
+func Async(s submitter, kind string, next http.Handler, before ...func(http.Handler) http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		async, err := strconv.ParseBool(r.URL.Query().Get(AsyncQueryParam))
+		if err != nil || !async {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := upload.ReadBody(w, r)
+		if err != nil && !errors.Is(err, upload.ErrBodyTooLarge) {
+			err = apiErrors.Wrap(apiErrors.Invalid, fmt.Errorf("error reading request: %w", err))
+		}
+		if err != nil {
+			respondOperationError(w, err)
+			return
+		}
+		// the operation id is known before it is queued, so that the holder of a lock names it
+		ctx, err := operation.NewContext(r.Context())
+		if err != nil {
+			respondOperationError(w, err)
+			return
+		}
+
+		var queue http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			submit(w, r, s, kind, body, next)
+		})
+		for i := len(before) - 1; i >= 0; i-- {
+			queue = before[i](queue)
+		}
+		queue.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// submit queues next as an operation holding the lock taken for r, if any
+func submit(w http.ResponseWriter, r *http.Request, s submitter, kind string, body []byte, next http.Handler) {
+	release := lock.Keep(r.Context())
+	// the request outlives this handler, so the worker gets its own copy
+	background := r.Clone(r.Context())
+	// handlers decoding query parameters reject keys they do not know
+	query := background.URL.Query()
+	query.Del(AsyncQueryParam)
+	background.URL.RawQuery = query.Encode()
+
+	op, err := s.Submit(r.Context(), kind, owner(r), func(ctx context.Context) (interface{}, error) {
+		// the lock is free once the operation is reported finished
+		defer release()
+		req := background.WithContext(ctx)
+		req.Body = ioutil.NopCloser(bytes.NewReader(body))
+		rec := response.Capture()
+		next.ServeHTTP(rec, req)
+		return newResult(rec)
+	})
+	if err != nil {
+		release()
+		respondOperationError(w, err)
+		return
+	}
+	// operations cancelled before they start never run their function
+	if done := s.Done(op.ID); done != nil {
+		go func() {
+			<-done
+			release()
+		}()
+	}
+	logger.Debugf("[Operation] queued %s operation %s", kind, op.ID)
+	w.Header().Set("Location", fmt.Sprintf("/operations/%s", op.ID))
+	respondOperation(w, op, http.StatusAccepted)
+}
+
+// newResult converts the response captured by rec, a non 2xx response is also reported as an error
+// using the message of the body when present
+func newResult(rec *response.Recorder) (*Result, error) {
+	result := &Result{StatusCode: rec.Status()}
+	if body := bytes.TrimSpace(rec.Body()); json.Valid(body) {
+		result.Body = body
+	}
+	if result.StatusCode < http.StatusBadRequest {
+		return result, nil
+	}
+	var body apiErrors.Body
+	if err := json.Unmarshal(result.Body, &body); err == nil && body.Message != "" {
+		return result, errors.New(body.Message)
+	}
+	return result, errors.New(http.StatusText(result.StatusCode))
+}
@@ -0,0 +1,190 @@
+package operation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/auth"
+	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/operation"
+)
+
+// Operation is the state of an asynchronous helm action
+// swagger:model operation
+type Operation struct {
+	// example: 8c5f1c2a9e6b4d0f8a7e3b2c1d0e9f8a
+	ID string `json:"id"`
+	// example: install
+	Kind string `json:"kind"`
+	// one of pending, running, succeeded, failed or cancelled
+	// example: running
+	State operation.State `json:"state"`
+	// example: 2021-03-24T12:24:18.450869+05:30
+	CreatedAt time.Time `json:"created_at"`
+	// example: 2021-03-24T12:24:18.450869+05:30
+	StartedAt *time.Time `json:"started_at,omitempty"`
+	// example: 2021-03-24T12:25:18.450869+05:30
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	// Result is the response the synchronous call would have returned, available once the operation finished
+	Result *Result `json:"result,omitempty"`
+	// example: context canceled
+	Error string `json:"error,omitempty"`
+}
+
+// Result is the recorded response of the helm action
+// swagger:model operationResult
+type Result struct {
+	// example: 200
+	StatusCode int             `json:"status_code"`
+	Body       json.RawMessage `json:"body,omitempty"`
+}
+
+// errNotOwner is returned when a principal gets or cancels an operation submitted by another one
+var errNotOwner = errors.New("operation was submitted by another principal")
+
+type manager interface {
+	Get(id string) (operation.Operation, error)
+	Cancel(id string) (operation.Operation, error)
+}
+
+// Handler returns the state of an operation
+// swagger:operation GET /operations/{id} operation getOperation
+//
+//
+// ---
+// summary: Get the state and result of an asynchronous operation
+// produces:
+// - application/json
+// parameters:
+// - name: id
+//   in: path
+//   required: true
+//   type: string
+//   format: string
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/operation"
+//   '403':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func Handler(m manager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		op, err := get(m, r)
+		if err != nil {
+			respondOperationError(w, err)
+			return
+		}
+		respondOperation(w, op, http.StatusOK)
+	})
+}
+
+// CancelHandler cancels an operation
+// swagger:operation DELETE /operations/{id} operation cancelOperation
+//
+//
+// ---
+// summary: Cancel a pending asynchronous operation
+// description: A cancelled operation is never started. Operations that already started run to their end, cancelling them is answered with 409
+// produces:
+// - application/json
+// parameters:
+// - name: id
+//   in: path
+//   required: true
+//   type: string
+//   format: string
+// schemes:
+// - http
+// responses:
+//   '202':
+//    schema:
+//     $ref: "#/definitions/operation"
+//   '403':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '409':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func CancelHandler(m manager) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := get(m, r); err != nil {
+			respondOperationError(w, err)
+			return
+		}
+		op, err := m.Cancel(mux.Vars(r)["id"])
+		if err != nil {
+			respondOperationError(w, err)
+			return
+		}
+		respondOperation(w, op, http.StatusAccepted)
+	})
+}
+
+// get returns the operation of the request when it belongs to the principal of the request
+func get(m manager, r *http.Request) (operation.Operation, error) {
+	op, err := m.Get(mux.Vars(r)["id"])
+	if err != nil {
+		return operation.Operation{}, err
+	}
+	if op.Owner != owner(r) {
+		return operation.Operation{}, apiErrors.Wrap(apiErrors.Forbidden, errNotOwner)
+	}
+	return op, nil
+}
+
+// owner identifies the principal of the request, by its authentication method as well since names are only
+// unique within one. It is empty when the request is not authenticated.
+func owner(r *http.Request) string {
+	p, ok := auth.FromContext(r.Context())
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%s:%s", p.Method, p.Name)
+}
+
+func respondOperation(w http.ResponseWriter, op operation.Operation, statusCode int) {
+	w.WriteHeader(statusCode)
+	if err := json.NewEncoder(w).Encode(toOperation(op)); err != nil {
+		logger.Errorf("[Operation] error writing response: %v", err)
+	}
+}
+
+func respondOperationError(w http.ResponseWriter, err error) {
+	logger.Errorf("[Operation] %v", err)
+	apiErrors.Write(w, err)
+}
+
+func toOperation(op operation.Operation) Operation {
+	resp := Operation{
+		ID:        op.ID,
+		Kind:      op.Kind,
+		State:     op.State,
+		CreatedAt: op.CreatedAt,
+		Error:     op.Error,
+	}
+	if !op.StartedAt.IsZero() {
+		resp.StartedAt = &op.StartedAt
+	}
+	if !op.FinishedAt.IsZero() {
+		resp.FinishedAt = &op.FinishedAt
+	}
+	if result, ok := op.Result.(*Result); ok {
+		resp.Result = result
+	}
+	return resp
+}
@@ -0,0 +1,301 @@
+package operation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/auth"
+	"github.com/gojekfarm/albatross/pkg/lock"
+	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/operation"
+)
+
+type OperationTestSuite struct {
+	suite.Suite
+	server  *httptest.Server
+	manager *operation.Manager
+	locker  *lock.MemoryLocker
+	release chan struct{}
+}
+
+func (s *OperationTestSuite) SetupSuite() {
+	logger.Setup("default")
+}
+
+func (s *OperationTestSuite) SetupTest() {
+	s.manager = operation.NewManager(1, 1, time.Hour)
+	release := make(chan struct{})
+	s.release = release
+	install := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		select {
+		case <-release:
+		case <-r.Context().Done():
+			w.WriteHeader(http.StatusInternalServerError)
+			fmt.Fprintf(w, `{"code":"internal","message":"%s"}`, r.Context().Err())
+			return
+		}
+		if strings.Contains(string(body), "invalid") {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"code":"invalid","message":"invalid chart"}`)
+			return
+		}
+		if r.URL.Query().Get(AsyncQueryParam) != "" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"code":"invalid","message":"unknown query parameter async"}`)
+			return
+		}
+		fmt.Fprintf(w, `{"status":"deployed","name":"%s"}`, mux.Vars(r)["release_name"])
+	})
+	s.locker = lock.NewMemoryLocker()
+	withLock := lock.Middleware(s.locker, "upgrade", 0)
+	router := mux.NewRouter()
+	router.Use(principalHeader)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}", Async(s.manager, "install", install)).Methods(http.MethodPost)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}", Async(s.manager, "upgrade", withLock(install), withLock)).Methods(http.MethodPut)
+	router.Handle("/operations/{id}", Handler(s.manager)).Methods(http.MethodGet)
+	router.Handle("/operations/{id}", CancelHandler(s.manager)).Methods(http.MethodDelete)
+	s.server = httptest.NewServer(router)
+}
+
+// principalHeader authenticates requests as the principal named by the test header
+func principalHeader(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if name := r.Header.Get(testPrincipalHeader); name != "" {
+			r = r.WithContext(auth.NewContext(r.Context(), auth.Principal{Name: name, Method: auth.APIKey}))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+const testPrincipalHeader = "X-Test-Principal"
+
+func (s *OperationTestSuite) as(principal, method, path string) *http.Response {
+	req, _ := http.NewRequest(method, s.server.URL+path, strings.NewReader(`{}`))
+	req.Header.Set(testPrincipalHeader, principal)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	return resp
+}
+
+func (s *OperationTestSuite) submit(query, body string) (*http.Response, Operation) {
+	url := fmt.Sprintf("%s/clusters/minikube/namespaces/default/releases/mysql%s", s.server.URL, query)
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	require.NoError(s.T(), err)
+	var op Operation
+	if resp.StatusCode == http.StatusAccepted {
+		require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&op))
+	}
+	return resp, op
+}
+
+func (s *OperationTestSuite) upgrade() (*http.Response, Operation) {
+	url := fmt.Sprintf("%s/clusters/minikube/namespaces/default/releases/api?async=true", s.server.URL)
+	req, _ := http.NewRequest(http.MethodPut, url, strings.NewReader(`{}`))
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	var op Operation
+	if resp.StatusCode == http.StatusAccepted {
+		require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&op))
+	}
+	return resp, op
+}
+
+func (s *OperationTestSuite) lockIsFree() bool {
+	unlock, err := s.locker.TryLock(context.Background(), lock.Key{Cluster: "minikube", Namespace: "default", Release: "api"}, lock.Holder{})
+	if err != nil {
+		return false
+	}
+	unlock()
+	return true
+}
+
+func (s *OperationTestSuite) get(id string) (*http.Response, Operation) {
+	resp, err := http.Get(fmt.Sprintf("%s/operations/%s", s.server.URL, id))
+	require.NoError(s.T(), err)
+	var op Operation
+	if resp.StatusCode == http.StatusOK {
+		require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&op))
+	}
+	return resp, op
+}
+
+func (s *OperationTestSuite) waitFor(id string, state operation.State) Operation {
+	var op Operation
+	require.Eventually(s.T(), func() bool {
+		_, op = s.get(id)
+		return op.State == state
+	}, time.Second, 5*time.Millisecond)
+	return op
+}
+
+func (s *OperationTestSuite) TestShouldServeSynchronouslyWithoutAsyncFlag() {
+	close(s.release)
+
+	resp, _ := s.submit("", `{}`)
+
+	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.JSONEq(s.T(), `{"status":"deployed","name":"mysql"}`, string(body))
+}
+
+func (s *OperationTestSuite) TestShouldRunAsyncRequestAndRecordResult() {
+	resp, op := s.submit("?async=true", `{}`)
+
+	assert.Equal(s.T(), http.StatusAccepted, resp.StatusCode)
+	assert.Equal(s.T(), "/operations/"+op.ID, resp.Header.Get("Location"))
+	assert.Equal(s.T(), "install", op.Kind)
+	assert.Equal(s.T(), operation.Pending, op.State)
+	assert.Nil(s.T(), op.Result)
+	s.waitFor(op.ID, operation.Running)
+
+	close(s.release)
+
+	done := s.waitFor(op.ID, operation.Succeeded)
+	require.NotNil(s.T(), done.Result)
+	assert.Equal(s.T(), http.StatusOK, done.Result.StatusCode)
+	assert.JSONEq(s.T(), `{"status":"deployed","name":"mysql"}`, string(done.Result.Body))
+	assert.NotNil(s.T(), done.StartedAt)
+	assert.NotNil(s.T(), done.FinishedAt)
+	assert.Empty(s.T(), done.Error)
+}
+
+func (s *OperationTestSuite) TestShouldMarkOperationFailedOnErrorResponse() {
+	close(s.release)
+
+	_, op := s.submit("?async=true", `{"chart":"invalid"}`)
+
+	done := s.waitFor(op.ID, operation.Failed)
+	assert.Equal(s.T(), "invalid chart", done.Error)
+	assert.Equal(s.T(), http.StatusBadRequest, done.Result.StatusCode)
+}
+
+func (s *OperationTestSuite) TestShouldReturnConflictWhenCancellingRunningOperation() {
+	_, op := s.submit("?async=true", `{}`)
+	s.waitFor(op.ID, operation.Running)
+
+	req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/operations/%s", s.server.URL, op.ID), nil)
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusConflict, resp.StatusCode)
+	close(s.release)
+	s.waitFor(op.ID, operation.Succeeded)
+}
+
+func (s *OperationTestSuite) TestShouldReturnConflictWhenCancellingFinishedOperation() {
+	close(s.release)
+	_, op := s.submit("?async=true", `{}`)
+	s.waitFor(op.ID, operation.Succeeded)
+
+	req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/operations/%s", s.server.URL, op.ID), nil)
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusConflict, resp.StatusCode)
+}
+
+func (s *OperationTestSuite) TestShouldReturnServiceUnavailableWhenQueueIsFull() {
+	_, running := s.submit("?async=true", `{}`)
+	s.waitFor(running.ID, operation.Running)
+	resp, _ := s.submit("?async=true", `{}`)
+	require.Equal(s.T(), http.StatusAccepted, resp.StatusCode)
+
+	resp, _ = s.submit("?async=true", `{}`)
+
+	assert.Equal(s.T(), http.StatusServiceUnavailable, resp.StatusCode)
+	close(s.release)
+}
+
+func (s *OperationTestSuite) TestShouldAnswerConflictWhileAnotherOperationHoldsTheLockOfTheRelease() {
+	resp, first := s.upgrade()
+	require.Equal(s.T(), http.StatusAccepted, resp.StatusCode)
+
+	resp, _ = s.upgrade()
+
+	assert.Equal(s.T(), http.StatusConflict, resp.StatusCode)
+	var body struct {
+		Code    apiErrors.Code `json:"code"`
+		Details lock.Holder    `json:"details"`
+	}
+	require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(s.T(), apiErrors.Conflict, body.Code)
+	assert.Equal(s.T(), "upgrade", body.Details.Action)
+	assert.Equal(s.T(), first.ID, body.Details.OperationID)
+	assert.False(s.T(), s.lockIsFree())
+
+	close(s.release)
+
+	s.waitFor(first.ID, operation.Succeeded)
+	assert.True(s.T(), s.lockIsFree())
+}
+
+func (s *OperationTestSuite) TestShouldReleaseTheLockOfAnOperationCancelledBeforeItStarts() {
+	_, running := s.submit("?async=true", `{}`)
+	s.waitFor(running.ID, operation.Running)
+	resp, pending := s.upgrade()
+	require.Equal(s.T(), http.StatusAccepted, resp.StatusCode)
+
+	req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/operations/%s", s.server.URL, pending.ID), nil)
+	_, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+
+	assert.Eventually(s.T(), s.lockIsFree, time.Second, 5*time.Millisecond)
+	close(s.release)
+}
+
+func (s *OperationTestSuite) TestShouldReturnNotFoundForUnknownOperation() {
+	resp, _ := s.get("unknown")
+	assert.Equal(s.T(), http.StatusNotFound, resp.StatusCode)
+	var body apiErrors.Body
+	require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.NotFound, Message: "operation not found"}, body)
+
+	req, _ := http.NewRequest(http.MethodDelete, fmt.Sprintf("%s/operations/unknown", s.server.URL), nil)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (s *OperationTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func TestOperationAPI(t *testing.T) {
+	suite.Run(t, new(OperationTestSuite))
+}
+
+func (s *OperationTestSuite) TestShouldForbidOperationsOfAnotherPrincipal() {
+	resp := s.as("jane", http.MethodPost, "/clusters/minikube/namespaces/default/releases/mysql?async=true")
+	require.Equal(s.T(), http.StatusAccepted, resp.StatusCode)
+	var op Operation
+	require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&op))
+
+	for _, method := range []string{http.MethodGet, http.MethodDelete} {
+		resp = s.as("john", method, "/operations/"+op.ID)
+
+		assert.Equal(s.T(), http.StatusForbidden, resp.StatusCode, method)
+		var body apiErrors.Body
+		require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&body))
+		assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Forbidden, Message: "operation was submitted by another principal"}, body)
+	}
+	resp, _ = s.get(op.ID)
+	assert.Equal(s.T(), http.StatusForbidden, resp.StatusCode)
+
+	resp = s.as("jane", http.MethodGet, "/operations/"+op.ID)
+	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	close(s.release)
+}
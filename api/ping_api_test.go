@@ -33,7 +33,7 @@ func (s *PingTestSuite) TestShouldReturnPongWhenPingCall() {
 	var pingResponse api.PingResponse
 	err := json.NewDecoder(res.Body).Decode(&pingResponse)
 
-	assert.Equal(s.T(), api.PingResponse{"", "pong"}, pingResponse)
+	assert.Equal(s.T(), api.PingResponse{Data: "pong"}, pingResponse)
 	assert.Equal(s.T(), 200, res.StatusCode)
 	require.NoError(s.T(), httpErr)
 	require.NoError(s.T(), err)
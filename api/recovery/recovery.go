@@ -0,0 +1,182 @@
+package recovery
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/logger"
+
+	"github.com/gorilla/mux"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const (
+	// MarkFailed marks the stuck revision failed, the next upgrade starts from it
+	MarkFailed = "mark_failed"
+	// Rollback marks the stuck revision failed and rolls back to the last deployed revision
+	Rollback = "rollback"
+)
+
+var (
+	errInvalidReleaseName = errors.New("recover: invalid release name")
+	errInvalidStrategy    = fmt.Errorf("recover: strategy must be %s or %s", MarkFailed, Rollback)
+)
+
+// Request is the body for recovering a release stuck in a pending state
+// swagger:model recoverRequestBody
+type Request struct {
+	name string
+	// mark_failed, the default, or rollback to the last deployed revision
+	// example: rollback
+	Strategy string `json:"strategy"`
+	// Only recover the release when its last revision was updated at least this many seconds ago
+	// example: 600
+	StuckFor int `json:"stuck_for"`
+	// Wait for the resources of the rollback to be ready
+	// example: false
+	Wait bool `json:"wait"`
+	// Timeout of the rollback in seconds
+	// example: 300
+	Timeout int `json:"timeout"`
+	flags.GlobalFlags
+}
+
+// Release contains metadata about a helm release object
+// swagger:model recoverRelease
+type Release struct {
+	// example: mysql-5.7
+	Name string `json:"name"`
+	// example: default
+	Namespace string `json:"namespace"`
+	// example: 3
+	Version int `json:"version"`
+	// example: 2021-03-24T12:24:18.450869+05:30
+	Updated time.Time `json:"updated_at,omitempty"`
+	// example: failed
+	Status release.Status `json:"status"`
+	// example: mysql
+	Chart string `json:"chart"`
+	// example: 5.7.30
+	AppVersion string `json:"app_version"`
+}
+
+// Response is the body of recover route
+// swagger:model recoverResponseBody
+type Response struct {
+	// Status status of the release once recovered, field is available only when status code is 2xx
+	// example: failed
+	Status string `json:"status,omitempty"`
+	// Release the last revision once recovered, field is available only when status code is 2xx
+	Release *Release `json:"release,omitempty"`
+}
+
+type service interface {
+	Recover(context.Context, Request) (Response, error)
+}
+
+// Handler handles a recover request
+// swagger:operation POST /clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/recover release recoverOperation
+//
+//
+// ---
+// summary: Recover a helm release stuck in pending-install, pending-upgrade or pending-rollback
+// description: The stuck revision is marked failed, then the release is rolled back to its last deployed revision with the rollback strategy.
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// parameters:
+// - name: cluster
+//   in: path
+//   required: true
+//   default: minikube
+//   type: string
+//   format: string
+// - name: namespace
+//   in: path
+//   required: true
+//   default: default
+//   type: string
+//   format: string
+// - name: release_name
+//   in: path
+//   required: true
+//   type: string
+//   format: string
+//   default: mysql-final
+// - name: Body
+//   in: body
+//   required: false
+//   schema:
+//    "$ref": "#/definitions/recoverRequestBody"
+// schemes:
+// - http
+// responses:
+//   '200':
+//    "$ref": "#/responses/recoverResponse"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '409':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//    schema:
+//     $ref: "#/definitions/recoverResponseBody"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func Handler(s service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		var req Request
+		// An empty body is valid and marks the stuck revision failed
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			logger.Errorf("[Recover] error decoding request: %v", err)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+		values := mux.Vars(r)
+		req.name = values["release_name"]
+		req.KubeContext = values["cluster"]
+		req.Namespace = values["namespace"]
+		if err := req.valid(); err != nil {
+			logger.Errorf("[Recover] error in request parameters: %v", err)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+
+		resp, err := s.Recover(r.Context(), req)
+		if err != nil {
+			logger.Errorf("[Recover] error while recovering %s: %v", req.name, err)
+			apiErrors.Write(w, err)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(&resp); err != nil {
+			logger.Errorf("[Recover] error writing response: %v", err)
+		}
+	})
+}
+
+func (req Request) valid() error {
+	releaseName := req.name
+	if releaseName == "" || !action.ValidName.MatchString(releaseName) || len(releaseName) > 53 {
+		return errInvalidReleaseName
+	}
+	if req.Strategy != "" && req.Strategy != MarkFailed && req.Strategy != Rollback {
+		return errInvalidStrategy
+	}
+	return nil
+}
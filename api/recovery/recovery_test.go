@@ -0,0 +1,151 @@
+package recovery
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/logger"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gotest.tools/assert"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+type mockService struct {
+	mock.Mock
+}
+
+func (m *mockService) Recover(ctx context.Context, req Request) (Response, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(Response), args.Error(1)
+}
+
+type RecoverTestSuite struct {
+	suite.Suite
+	server      *httptest.Server
+	mockService *mockService
+}
+
+func (s *RecoverTestSuite) SetupSuite() {
+	logger.Setup("default")
+}
+
+func (s *RecoverTestSuite) SetupTest() {
+	s.mockService = new(mockService)
+	router := mux.NewRouter()
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/recover", Handler(s.mockService)).Methods(http.MethodPost)
+	s.server = httptest.NewServer(router)
+}
+
+func (s *RecoverTestSuite) url(releaseName string) string {
+	return fmt.Sprintf("%s/clusters/minikube/namespaces/default/releases/%s/recover", s.server.URL, releaseName)
+}
+
+func (s *RecoverTestSuite) TestShouldReturnRecoveredRelease() {
+	body := `{"strategy": "rollback", "stuck_for": 600, "wait": true, "timeout": 60}`
+	req, _ := http.NewRequest(http.MethodPost, s.url(testReleaseName), strings.NewReader(body))
+	requestStruct := Request{
+		name:     testReleaseName,
+		Strategy: Rollback,
+		StuckFor: 600,
+		Wait:     true,
+		Timeout:  60,
+		GlobalFlags: flags.GlobalFlags{
+			KubeContext: "minikube",
+			Namespace:   "default",
+		},
+	}
+	mockRelease := releaseInfo(release.Mock(&release.MockReleaseOptions{
+		Name:      testReleaseName,
+		Version:   4,
+		Namespace: "default",
+		Status:    release.StatusDeployed,
+	}))
+	response := Response{Status: release.StatusDeployed.String(), Release: mockRelease}
+	s.mockService.On("Recover", mock.Anything, requestStruct).Times(1).Return(response, nil)
+
+	res, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, res.StatusCode)
+	var actualResponse Response
+	err = json.NewDecoder(res.Body).Decode(&actualResponse)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), response.Status, actualResponse.Status)
+	assert.Equal(s.T(), mockRelease.Version, actualResponse.Release.Version)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RecoverTestSuite) TestShouldMarkFailedWhenBodyIsEmpty() {
+	req, _ := http.NewRequest(http.MethodPost, s.url(testReleaseName), nil)
+	requestStruct := Request{
+		name: testReleaseName,
+		GlobalFlags: flags.GlobalFlags{
+			KubeContext: "minikube",
+			Namespace:   "default",
+		},
+	}
+	s.mockService.On("Recover", mock.Anything, requestStruct).Times(1).Return(Response{}, nil)
+
+	res, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, res.StatusCode)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RecoverTestSuite) TestShouldReturnBadRequestForUnknownStrategy() {
+	req, _ := http.NewRequest(http.MethodPost, s.url(testReleaseName), strings.NewReader(`{"strategy": "delete"}`))
+
+	res, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, res.StatusCode)
+	s.mockService.AssertNotCalled(s.T(), "Recover")
+}
+
+func (s *RecoverTestSuite) TestShouldReturnNotFoundWhenReleaseDoesNotExist() {
+	req, _ := http.NewRequest(http.MethodPost, s.url("unknown"), strings.NewReader(`{}`))
+	s.mockService.On("Recover", mock.Anything, mock.AnythingOfType("Request")).Times(1).Return(Response{}, driver.ErrReleaseNotFound)
+
+	res, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusNotFound, res.StatusCode)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RecoverTestSuite) TestShouldReturnConflictWhenReleaseIsNotStuck() {
+	req, _ := http.NewRequest(http.MethodPost, s.url(testReleaseName), strings.NewReader(`{}`))
+	s.mockService.On("Recover", mock.Anything, mock.AnythingOfType("Request")).Times(1).Return(Response{}, fmt.Errorf("%w: revision 3 is deployed", helmcli.ErrNotStuck))
+
+	res, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusConflict, res.StatusCode)
+	var actualResponse apiErrors.Body
+	err = json.NewDecoder(res.Body).Decode(&actualResponse)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Conflict, Message: "release is not stuck in a pending state: revision 3 is deployed"}, actualResponse)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RecoverTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func TestRecoverAPI(t *testing.T) {
+	suite.Run(t, new(RecoverTestSuite))
+}
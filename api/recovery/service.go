@@ -0,0 +1,75 @@
+package recovery
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/gojekfarm/albatross/pkg/audit"
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+const defaultTimeout = 300 * time.Second
+
+type Service struct {
+	cli helmcli.Client
+}
+
+// Recover recovers a release stuck in a pending state with the requested strategy.
+func (s Service) Recover(ctx context.Context, req Request) (_ Response, err error) {
+	ctx, span := tracing.Start(ctx, "recovery.Recover")
+	defer func() { tracing.End(span, err) }()
+
+	timeout := defaultTimeout
+	if req.Timeout > 0 {
+		timeout = time.Second * time.Duration(req.Timeout)
+	}
+	recoverFlags := flags.RecoverFlags{
+		Rollback:    req.Strategy == Rollback,
+		StuckFor:    time.Second * time.Duration(req.StuckFor),
+		Timeout:     timeout,
+		Wait:        req.Wait,
+		GlobalFlags: req.GlobalFlags,
+	}
+	rcli, err := s.cli.NewRecoverer(recoverFlags)
+	if err != nil {
+		return Response{}, fmt.Errorf("error while initializing recoverer: %w", err)
+	}
+
+	rel, err := rcli.Recover(ctx, req.name)
+	audit.SetRelease(ctx, rel)
+	if err != nil {
+		return Response{}, err
+	}
+	return responseWithStatus(rel), nil
+}
+
+func responseWithStatus(rel *release.Release) Response {
+	resp := Response{}
+	if rel != nil && rel.Info != nil {
+		resp.Release = releaseInfo(rel)
+		resp.Status = rel.Info.Status.String()
+	}
+	return resp
+}
+
+func releaseInfo(rel *release.Release) *Release {
+	return &Release{
+		Name:       rel.Name,
+		Namespace:  rel.Namespace,
+		Version:    rel.Version,
+		Updated:    rel.Info.LastDeployed.Local().Time,
+		Status:     rel.Info.Status,
+		Chart:      rel.Chart.ChartFullPath(),
+		AppVersion: rel.Chart.AppVersion(),
+	}
+}
+
+// NewService returns a recovery service.
+func NewService(cli helmcli.Client) Service {
+	return Service{cli}
+}
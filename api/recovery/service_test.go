@@ -0,0 +1,166 @@
+package recovery
+
+import (
+	"context"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+)
+
+const testReleaseName = "test-release-name"
+
+var errNewRecovererError = errors.New("new recoverer error")
+
+// To satisfy the client interface, we have to define all methods(NewUpgrade, NewInstaller) on the mock struct
+// TODO: Find a way to isolate interface only for upgrade.
+type mockHelmClient struct{ mock.Mock }
+
+func (m *mockHelmClient) NewUpgrader(fl flags.UpgradeFlags) (helmcli.Upgrader, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Upgrader), args.Error(1)
+}
+
+func (m *mockHelmClient) NewInstaller(fl flags.InstallFlags) (helmcli.Installer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Installer), args.Error(1)
+}
+
+func (m *mockHelmClient) NewLister(fl flags.ListFlags) (helmcli.Lister, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Lister), args.Error(1)
+}
+
+func (m *mockHelmClient) NewUninstaller(fl flags.UninstallFlags) (helmcli.Uninstaller, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Uninstaller), args.Error(1)
+}
+
+func (m *mockHelmClient) NewStatusGiver(fl flags.StatusFlags) (helmcli.StatusGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.StatusGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRollbacker(fl flags.RollbackFlags) (helmcli.Rollbacker, error) {
+	args := m.Called(fl)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(helmcli.Rollbacker), args.Error(1)
+}
+
+func (m *mockHelmClient) NewHistoryGiver(fl flags.HistoryFlags) (helmcli.HistoryGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.HistoryGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewValuesGiver(fl flags.GetValuesFlags) (helmcli.ValuesGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.ValuesGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTemplater(fl flags.TemplateFlags) (helmcli.Templater, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Templater), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTester(fl flags.TestFlags) (helmcli.Tester, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Tester), args.Error(1)
+}
+
+func (m *mockHelmClient) NewShower(fl flags.ShowFlags) (helmcli.Shower, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Shower), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRecoverer(fl flags.RecoverFlags) (helmcli.Recoverer, error) {
+	args := m.Called(fl)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(helmcli.Recoverer), args.Error(1)
+}
+
+type mockRecoverer struct{ mock.Mock }
+
+func (m *mockRecoverer) Recover(ctx context.Context, releaseName string) (*release.Release, error) {
+	args := m.Called(ctx, releaseName)
+	if len(args) < 1 {
+		log.Fatalf("error while mocking response for recover")
+	}
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*release.Release), args.Error(1)
+}
+
+func TestShouldRollBackStuckReleaseWithRollbackStrategy(t *testing.T) {
+	cli := new(mockHelmClient)
+	rc := new(mockRecoverer)
+	service := NewService(cli)
+	globalFlags := flags.GlobalFlags{KubeContext: "minikube", Namespace: "default"}
+	req := Request{name: testReleaseName, Strategy: Rollback, StuckFor: 600, Wait: true, Timeout: 10, GlobalFlags: globalFlags}
+	recoverFlags := flags.RecoverFlags{
+		Rollback:    true,
+		StuckFor:    10 * time.Minute,
+		Wait:        true,
+		Timeout:     10 * time.Second,
+		GlobalFlags: globalFlags,
+	}
+	mockRelease := release.Mock(&release.MockReleaseOptions{
+		Name:      testReleaseName,
+		Version:   4,
+		Namespace: "default",
+		Status:    release.StatusDeployed,
+	})
+	cli.On("NewRecoverer", recoverFlags).Times(1).Return(rc, nil)
+	rc.On("Recover", mock.Anything, testReleaseName).Times(1).Return(mockRelease, nil)
+
+	resp, err := service.Recover(context.Background(), req)
+
+	require.NoError(t, err)
+	require.NotNil(t, resp.Release)
+	assert.Equal(t, release.StatusDeployed.String(), resp.Status)
+	assert.Equal(t, 4, resp.Release.Version)
+	assert.Equal(t, mockRelease.Chart.ChartFullPath(), resp.Release.Chart)
+	cli.AssertExpectations(t)
+	rc.AssertExpectations(t)
+}
+
+func TestShouldMarkFailedByDefault(t *testing.T) {
+	cli := new(mockHelmClient)
+	service := NewService(cli)
+	req := Request{name: testReleaseName}
+	recoverFlags := flags.RecoverFlags{Timeout: defaultTimeout}
+	cli.On("NewRecoverer", recoverFlags).Times(1).Return(nil, errNewRecovererError)
+
+	resp, err := service.Recover(context.Background(), req)
+
+	assert.True(t, errors.Is(err, errNewRecovererError))
+	assert.Nil(t, resp.Release)
+	cli.AssertExpectations(t)
+}
+
+func TestShouldReturnErrorWhenReleaseIsNotStuck(t *testing.T) {
+	cli := new(mockHelmClient)
+	rc := new(mockRecoverer)
+	service := NewService(cli)
+	cli.On("NewRecoverer", mock.AnythingOfType("flags.RecoverFlags")).Times(1).Return(rc, nil)
+	rc.On("Recover", mock.Anything, testReleaseName).Times(1).Return(nil, helmcli.ErrNotStuck)
+
+	resp, err := service.Recover(context.Background(), Request{name: testReleaseName})
+
+	assert.True(t, errors.Is(err, helmcli.ErrNotStuck))
+	assert.Nil(t, resp.Release)
+	cli.AssertExpectations(t)
+	rc.AssertExpectations(t)
+}
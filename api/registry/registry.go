@@ -0,0 +1,135 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+// HostPlaceholder is the path variable holding the registry host
+const HostPlaceholder string = "host"
+
+// LoginRequest is the body for login requests to a registry
+// swagger:model registryLoginRequestBody
+type LoginRequest struct {
+	Host string `json:"-"`
+	// example: robot
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Response is the body of registry responses, the credentials are never returned
+// swagger:model registryResponseBody
+type Response struct {
+	// example: registry.example.com:5000
+	Host string `json:"host,omitempty"`
+}
+
+type service interface {
+	Login(ctx context.Context, req LoginRequest) error
+	Logout(ctx context.Context, host string) error
+}
+
+// LoginHandler checks credentials against a registry and stores them
+// swagger:operation POST /registries/{host}/login registry login
+//
+// Log in to an OCI registry.
+// The credentials are checked against the registry and kept in the secret store,
+// oci:// charts of the registry are pulled with them.
+// ---
+// produces:
+// - application/json
+// parameters:
+// - name: host
+//   in: path
+//   required: true
+//   type: string
+// - name: Body
+//   in: body
+//   required: true
+//   schema:
+//    "$ref": "#/definitions/registryLoginRequestBody"
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/registryResponseBody"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '401':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func LoginHandler(s service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		var req LoginRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondError(w, "error decoding request", apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+		req.Host = mux.Vars(r)[HostPlaceholder]
+		if req.Username == "" || req.Password == "" {
+			respondError(w, "error validating request", apiErrors.New(apiErrors.Invalid, "username and password are required"))
+			return
+		}
+
+		if err := s.Login(r.Context(), req); err != nil {
+			respondError(w, "error logging in", err)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(Response{Host: req.Host}); err != nil {
+			logger.Errorf("[Registry] error writing response: %v", err)
+		}
+	})
+}
+
+// LogoutHandler removes the stored credentials of a registry
+// swagger:operation POST /registries/{host}/logout registry logout
+//
+// ---
+// produces:
+// - application/json
+// parameters:
+// - name: host
+//   in: path
+//   required: true
+//   type: string
+// schemes:
+// - http
+// responses:
+//   '204':
+//    description: "The credentials were removed"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func LogoutHandler(s service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := mux.Vars(r)[HostPlaceholder]
+		if err := s.Logout(r.Context(), host); err != nil {
+			respondError(w, "error logging out", err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func respondError(w http.ResponseWriter, logprefix string, err error) {
+	logger.Errorf("[Registry] %s: %v", logprefix, err)
+	apiErrors.Write(w, err)
+}
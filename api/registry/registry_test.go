@@ -0,0 +1,112 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/registry"
+	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/secret"
+)
+
+type mockService struct{ mock.Mock }
+
+func (m *mockService) Login(ctx context.Context, req LoginRequest) error {
+	return m.Called(ctx, req).Error(0)
+}
+
+func (m *mockService) Logout(ctx context.Context, host string) error {
+	return m.Called(ctx, host).Error(0)
+}
+
+type RegistryTestSuite struct {
+	suite.Suite
+	server      *httptest.Server
+	mockService *mockService
+}
+
+func (s *RegistryTestSuite) SetupSuite() {
+	logger.Setup("default")
+}
+
+func (s *RegistryTestSuite) SetupTest() {
+	s.mockService = new(mockService)
+	router := mux.NewRouter()
+	router.Handle(fmt.Sprintf("/registries/{%s}/login", HostPlaceholder), LoginHandler(s.mockService)).Methods(http.MethodPost)
+	router.Handle(fmt.Sprintf("/registries/{%s}/logout", HostPlaceholder), LogoutHandler(s.mockService)).Methods(http.MethodPost)
+	s.server = httptest.NewServer(router)
+}
+
+func (s *RegistryTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *RegistryTestSuite) TestLoginDoesNotReturnPassword() {
+	req := LoginRequest{Host: "registry.example.com:5000", Username: "robot", Password: "s3cr3t"}
+	s.mockService.On("Login", mock.Anything, req).Return(nil)
+
+	resp, err := http.Post(s.server.URL+"/registries/registry.example.com:5000/login", "application/json", strings.NewReader(`{"username":"robot","password":"s3cr3t"}`))
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(s.T(), `{"host":"registry.example.com:5000"}`+"\n", string(body))
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RegistryTestSuite) TestLoginRequiresUsernameAndPassword() {
+	resp, err := http.Post(s.server.URL+"/registries/registry.example.com/login", "application/json", strings.NewReader(`{"username":"robot"}`))
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RegistryTestSuite) TestLoginErrors() {
+	for _, tc := range []struct {
+		err  error
+		code int
+	}{
+		{fmt.Errorf("%w: registry.example.com answered 401 Unauthorized", registry.ErrUnauthorized), http.StatusUnauthorized},
+		{registry.ErrNoSecretStore, http.StatusBadRequest},
+		{fmt.Errorf("connection refused"), http.StatusInternalServerError},
+	} {
+		s.mockService.On("Login", mock.Anything, mock.Anything).Return(tc.err).Once()
+
+		resp, err := http.Post(s.server.URL+"/registries/registry.example.com/login", "application/json", strings.NewReader(`{"username":"robot","password":"wrong"}`))
+
+		require.NoError(s.T(), err)
+		assert.Equal(s.T(), tc.code, resp.StatusCode, tc.err)
+		body, _ := ioutil.ReadAll(resp.Body)
+		assert.Contains(s.T(), string(body), tc.err.Error())
+	}
+}
+
+func (s *RegistryTestSuite) TestLogout() {
+	s.mockService.On("Logout", mock.Anything, "registry.example.com").Return(nil).Once()
+	s.mockService.On("Logout", mock.Anything, "unknown.example.com").Return(secret.ErrNotFound).Once()
+
+	resp, err := http.Post(s.server.URL+"/registries/registry.example.com/logout", "application/json", nil)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusNoContent, resp.StatusCode)
+
+	resp, err = http.Post(s.server.URL+"/registries/unknown.example.com/logout", "application/json", nil)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusNotFound, resp.StatusCode)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func TestRegistryAPI(t *testing.T) {
+	suite.Run(t, new(RegistryTestSuite))
+}
@@ -0,0 +1,40 @@
+package registry
+
+import (
+	"context"
+
+	"github.com/gojekfarm/albatross/pkg/secret"
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+// Client logs in to registries
+type Client interface {
+	Login(ctx context.Context, host string, creds secret.Credentials) error
+	Logout(ctx context.Context, host string) error
+}
+
+// Service manages the stored credentials of OCI registries
+type Service struct {
+	cli Client
+}
+
+// Login checks and stores the credentials of a registry
+func (s Service) Login(ctx context.Context, req LoginRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "registry.Login")
+	defer func() { tracing.End(span, err) }()
+
+	return s.cli.Login(ctx, req.Host, secret.Credentials{Username: req.Username, Password: req.Password})
+}
+
+// Logout removes the stored credentials of a registry
+func (s Service) Logout(ctx context.Context, host string) (err error) {
+	ctx, span := tracing.Start(ctx, "registry.Logout")
+	defer func() { tracing.End(span, err) }()
+
+	return s.cli.Logout(ctx, host)
+}
+
+// NewService returns a service logging in to registries with cli
+func NewService(cli Client) Service {
+	return Service{cli}
+}
@@ -0,0 +1,164 @@
+package releasetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/logger"
+
+	"github.com/gorilla/mux"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+var errInvalidReleaseName = errors.New("test: invalid release name")
+
+// Request is the body for running the tests of a release
+// swagger:model testRequestBody
+type Request struct {
+	name string
+	// Timeout in seconds for each test hook
+	// example: 300
+	Timeout int `json:"timeout"`
+	// Filters selects the tests to run by hook name, prefix with ! to skip a test
+	// example: ["name=mysql-test", "!name=mysql-slow-test"]
+	Filters []string `json:"filters"`
+	// Logs includes the logs of the test pods in the response
+	// example: false
+	Logs bool `json:"logs"`
+	flags.GlobalFlags
+}
+
+// Test is the result of a single test hook
+// swagger:model testResult
+type Test struct {
+	// example: mysql-test
+	Name string `json:"name"`
+	// example: Pod
+	Kind string `json:"kind"`
+	// example: Succeeded
+	Phase release.HookPhase `json:"phase"`
+	// example: 2021-03-24T12:24:18.450869+05:30
+	StartedAt time.Time `json:"started_at,omitempty"`
+	// example: 2021-03-24T12:24:28.450869+05:30
+	CompletedAt time.Time `json:"completed_at,omitempty"`
+	// Logs of the test pod, field is available only when logs are requested
+	Logs string `json:"logs,omitempty"`
+}
+
+// Response is the body of test route
+// swagger:model testResponseBody
+type Response struct {
+	// Status is passed when every executed test succeeded and failed otherwise
+	// example: passed
+	Status string `json:"status,omitempty"`
+	Tests  []Test `json:"tests,omitempty"`
+}
+
+type service interface {
+	Test(context.Context, Request) (Response, error)
+}
+
+// Handler handles a release test request
+// swagger:operation POST /clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/test release testOperation
+//
+//
+// ---
+// summary: Run the tests of a helm release
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// parameters:
+// - name: cluster
+//   in: path
+//   required: true
+//   default: minikube
+//   type: string
+//   format: string
+// - name: namespace
+//   in: path
+//   required: true
+//   default: default
+//   type: string
+//   format: string
+// - name: release_name
+//   in: path
+//   required: true
+//   type: string
+//   format: string
+//   default: mysql-final
+// - name: Body
+//   in: body
+//   required: false
+//   schema:
+//    "$ref": "#/definitions/testRequestBody"
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/testResponseBody"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func Handler(s service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		var req Request
+		// An empty body is valid and runs every test with default options
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			logger.Errorf("[Test] error decoding request: %v", err)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+		values := mux.Vars(r)
+		req.name = values["release_name"]
+		req.KubeContext = values["cluster"]
+		req.Namespace = values["namespace"]
+		if err := req.valid(); err != nil {
+			logger.Errorf("[Test] error in request parameters: %v", err)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+
+		resp, err := s.Test(r.Context(), req)
+		if err != nil {
+			logger.Errorf("[Test] error while testing %s: %v", req.name, err)
+			apiErrors.Write(w, err)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(&resp); err != nil {
+			logger.Errorf("[Test] error writing response: %v", err)
+		}
+	})
+}
+
+func (req Request) valid() error {
+	releaseName := req.name
+	if releaseName == "" || !action.ValidName.MatchString(releaseName) || len(releaseName) > 53 {
+		return errInvalidReleaseName
+	}
+	for _, f := range req.Filters {
+		if !strings.HasPrefix(f, "name=") && !strings.HasPrefix(f, "!name=") {
+			return fmt.Errorf("test: invalid filter %q, expected name=<hook> or !name=<hook>", f)
+		}
+	}
+	return nil
+}
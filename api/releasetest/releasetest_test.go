@@ -0,0 +1,143 @@
+package releasetest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+type mockService struct {
+	mock.Mock
+}
+
+func (m *mockService) Test(ctx context.Context, req Request) (Response, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(Response), args.Error(1)
+}
+
+type TestAPITestSuite struct {
+	suite.Suite
+	server      *httptest.Server
+	mockService *mockService
+}
+
+func (s *TestAPITestSuite) SetupSuite() {
+	logger.Setup("default")
+}
+
+func (s *TestAPITestSuite) SetupTest() {
+	s.mockService = new(mockService)
+	router := mux.NewRouter()
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/test", Handler(s.mockService)).Methods(http.MethodPost)
+	s.server = httptest.NewServer(router)
+}
+
+func (s *TestAPITestSuite) url(releaseName string) string {
+	return fmt.Sprintf("%s/clusters/minikube/namespaces/default/releases/%s/test", s.server.URL, releaseName)
+}
+
+func (s *TestAPITestSuite) TestShouldReturnTestResultsOnSuccess() {
+	body := `{"timeout": 60, "filters": ["name=mysql-test"], "logs": true}`
+	req, _ := http.NewRequest(http.MethodPost, s.url("mysql"), strings.NewReader(body))
+	requestStruct := Request{
+		name:        "mysql",
+		Timeout:     60,
+		Filters:     []string{"name=mysql-test"},
+		Logs:        true,
+		GlobalFlags: flags.GlobalFlags{KubeContext: "minikube", Namespace: "default"},
+	}
+	response := Response{
+		Status: statusPassed,
+		Tests:  []Test{{Name: "mysql-test", Kind: "Pod", Phase: release.HookPhaseSucceeded, Logs: "ok"}},
+	}
+	s.mockService.On("Test", mock.Anything, requestStruct).Return(response, nil).Once()
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
+
+	var actual Response
+	require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&actual))
+	assert.Equal(s.T(), response, actual)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *TestAPITestSuite) TestShouldAcceptEmptyBody() {
+	req, _ := http.NewRequest(http.MethodPost, s.url("mysql"), nil)
+	requestStruct := Request{name: "mysql", GlobalFlags: flags.GlobalFlags{KubeContext: "minikube", Namespace: "default"}}
+	s.mockService.On("Test", mock.Anything, requestStruct).Return(Response{Status: statusFailed}, nil).Once()
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *TestAPITestSuite) TestShouldReturnBadRequestForInvalidFilter() {
+	req, _ := http.NewRequest(http.MethodPost, s.url("mysql"), strings.NewReader(`{"filters": ["mysql-test"]}`))
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+
+	var actual apiErrors.Body
+	require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&actual))
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Invalid, Message: `test: invalid filter "mysql-test", expected name=<hook> or !name=<hook>`}, actual)
+	s.mockService.AssertNotCalled(s.T(), "Test")
+}
+
+func (s *TestAPITestSuite) TestShouldReturnBadRequestForInvalidReleaseName() {
+	req, _ := http.NewRequest(http.MethodPost, s.url(strings.Repeat("a", 54)), nil)
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+	s.mockService.AssertNotCalled(s.T(), "Test")
+}
+
+func (s *TestAPITestSuite) TestShouldReturnNotFoundWhenReleaseIsMissing() {
+	req, _ := http.NewRequest(http.MethodPost, s.url("mysql"), nil)
+	s.mockService.On("Test", mock.Anything, mock.AnythingOfType("releasetest.Request")).Return(Response{}, driver.ErrReleaseNotFound).Once()
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusNotFound, resp.StatusCode)
+}
+
+func (s *TestAPITestSuite) TestShouldReturnInternalServerErrorOnFailure() {
+	req, _ := http.NewRequest(http.MethodPost, s.url("mysql"), nil)
+	s.mockService.On("Test", mock.Anything, mock.AnythingOfType("releasetest.Request")).Return(Response{}, errors.New("cluster unreachable")).Once()
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusInternalServerError, resp.StatusCode)
+}
+
+func (s *TestAPITestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func TestReleaseTestAPI(t *testing.T) {
+	suite.Run(t, new(TestAPITestSuite))
+}
@@ -0,0 +1,119 @@
+package releasetest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+const (
+	defaultTimeout = 300 * time.Second
+	statusPassed   = "passed"
+	statusFailed   = "failed"
+)
+
+type Service struct {
+	cli helmcli.Client
+}
+
+// Test runs the test hooks of a release.
+// A failing test is reported in the response rather than as an error.
+func (s Service) Test(ctx context.Context, req Request) (_ Response, err error) {
+	ctx, span := tracing.Start(ctx, "releasetest.Test")
+	defer func() { tracing.End(span, err) }()
+
+	timeout := defaultTimeout
+	if req.Timeout > 0 {
+		timeout = time.Second * time.Duration(req.Timeout)
+	}
+	testFlags := flags.TestFlags{
+		Timeout:     timeout,
+		Filters:     req.Filters,
+		GlobalFlags: req.GlobalFlags,
+	}
+	tcli, err := s.cli.NewTester(testFlags)
+	if err != nil {
+		return Response{}, fmt.Errorf("error while initializing tester: %w", err)
+	}
+
+	rel, err := tcli.Test(ctx, req.name)
+	hooks := testHooks(rel)
+	failed := hasFailed(hooks)
+	if err != nil && !failed {
+		return Response{}, err
+	}
+
+	resp := Response{Status: statusPassed, Tests: []Test{}}
+	if failed {
+		resp.Status = statusFailed
+	}
+	for _, h := range hooks {
+		test := testResult(h)
+		if req.Logs && test.Phase != release.HookPhaseUnknown {
+			test.Logs = s.logs(ctx, tcli, h)
+		}
+		resp.Tests = append(resp.Tests, test)
+	}
+	return resp, nil
+}
+
+// logs fetches the logs of a test pod, pods removed by their delete policy have no logs left.
+func (s Service) logs(ctx context.Context, tcli helmcli.Tester, hook *release.Hook) string {
+	logs, err := tcli.Logs(ctx, hook)
+	if err != nil {
+		logger.Errorf("[Test] error fetching logs for %s: %v", hook.Name, err)
+		return ""
+	}
+	return logs
+}
+
+func testHooks(rel *release.Release) []*release.Hook {
+	var hooks []*release.Hook
+	if rel == nil {
+		return hooks
+	}
+	for _, h := range rel.Hooks {
+		for _, e := range h.Events {
+			if e == release.HookTest {
+				hooks = append(hooks, h)
+				break
+			}
+		}
+	}
+	return hooks
+}
+
+func hasFailed(hooks []*release.Hook) bool {
+	for _, h := range hooks {
+		if h.LastRun.Phase == release.HookPhaseFailed {
+			return true
+		}
+	}
+	return false
+}
+
+func testResult(hook *release.Hook) Test {
+	test := Test{
+		Name:        hook.Name,
+		Kind:        hook.Kind,
+		Phase:       hook.LastRun.Phase,
+		StartedAt:   hook.LastRun.StartedAt.Time,
+		CompletedAt: hook.LastRun.CompletedAt.Time,
+	}
+	if test.Phase == "" {
+		test.Phase = release.HookPhaseUnknown
+	}
+	return test
+}
+
+// NewService returns a release test service.
+func NewService(cli helmcli.Client) Service {
+	return Service{cli}
+}
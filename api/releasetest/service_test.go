@@ -0,0 +1,210 @@
+package releasetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	helmtime "helm.sh/helm/v3/pkg/time"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+)
+
+const testReleaseName = "test-release-name"
+
+// To satisfy the client interface, we have to define all methods(NewUpgrade, NewInstaller) on the mock struct
+// TODO: Find a way to isolate interface only for upgrade.
+type mockHelmClient struct{ mock.Mock }
+
+func (m *mockHelmClient) NewUpgrader(fl flags.UpgradeFlags) (helmcli.Upgrader, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Upgrader), args.Error(1)
+}
+
+func (m *mockHelmClient) NewInstaller(fl flags.InstallFlags) (helmcli.Installer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Installer), args.Error(1)
+}
+
+func (m *mockHelmClient) NewLister(fl flags.ListFlags) (helmcli.Lister, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Lister), args.Error(1)
+}
+
+func (m *mockHelmClient) NewStatusGiver(fl flags.StatusFlags) (helmcli.StatusGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.StatusGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewUninstaller(fl flags.UninstallFlags) (helmcli.Uninstaller, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Uninstaller), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRollbacker(fl flags.RollbackFlags) (helmcli.Rollbacker, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Rollbacker), args.Error(1)
+}
+
+func (m *mockHelmClient) NewHistoryGiver(fl flags.HistoryFlags) (helmcli.HistoryGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.HistoryGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewValuesGiver(fl flags.GetValuesFlags) (helmcli.ValuesGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.ValuesGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTemplater(fl flags.TemplateFlags) (helmcli.Templater, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Templater), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTester(fl flags.TestFlags) (helmcli.Tester, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Tester), args.Error(1)
+}
+
+func (m *mockHelmClient) NewShower(fl flags.ShowFlags) (helmcli.Shower, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Shower), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRecoverer(fl flags.RecoverFlags) (helmcli.Recoverer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Recoverer), args.Error(1)
+}
+
+type mockTester struct{ mock.Mock }
+
+func (m *mockTester) Test(ctx context.Context, releaseName string) (*release.Release, error) {
+	args := m.Called(ctx, releaseName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*release.Release), args.Error(1)
+}
+
+func (m *mockTester) Logs(ctx context.Context, hook *release.Hook) (string, error) {
+	args := m.Called(ctx, hook)
+	return args.String(0), args.Error(1)
+}
+
+func testRelease(phases ...release.HookPhase) *release.Release {
+	started := helmtime.Now()
+	rel := &release.Release{Name: testReleaseName}
+	for i, phase := range phases {
+		hook := &release.Hook{
+			Name:   []string{"first-test", "second-test"}[i],
+			Kind:   "Pod",
+			Events: []release.HookEvent{release.HookTest},
+		}
+		if phase != "" {
+			hook.LastRun = release.HookExecution{StartedAt: started, CompletedAt: started.Add(time.Second), Phase: phase}
+		}
+		rel.Hooks = append(rel.Hooks, hook)
+	}
+	rel.Hooks = append(rel.Hooks, &release.Hook{Name: "pre-install", Events: []release.HookEvent{release.HookPreInstall}})
+	return rel
+}
+
+func TestShouldReturnPassedWhenAllTestsSucceed(t *testing.T) {
+	cli := new(mockHelmClient)
+	tc := new(mockTester)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{name: testReleaseName, Timeout: 10, Filters: []string{"name=first-test"}}
+	testFlags := flags.TestFlags{Timeout: 10 * time.Second, Filters: []string{"name=first-test"}}
+	rel := testRelease(release.HookPhaseSucceeded)
+	cli.On("NewTester", testFlags).Return(tc, nil).Once()
+	tc.On("Test", mock.Anything, testReleaseName).Return(rel, nil).Once()
+
+	resp, err := service.Test(ctx, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, statusPassed, resp.Status)
+	require.Len(t, resp.Tests, 1)
+	assert.Equal(t, "first-test", resp.Tests[0].Name)
+	assert.Equal(t, "Pod", resp.Tests[0].Kind)
+	assert.Equal(t, release.HookPhaseSucceeded, resp.Tests[0].Phase)
+	assert.Equal(t, rel.Hooks[0].LastRun.StartedAt.Time, resp.Tests[0].StartedAt)
+	assert.Equal(t, rel.Hooks[0].LastRun.CompletedAt.Time, resp.Tests[0].CompletedAt)
+	assert.Empty(t, resp.Tests[0].Logs)
+	cli.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func TestShouldReportFailedTestsWithLogs(t *testing.T) {
+	cli := new(mockHelmClient)
+	tc := new(mockTester)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{name: testReleaseName, Logs: true}
+	rel := testRelease(release.HookPhaseFailed, "")
+	cli.On("NewTester", flags.TestFlags{Timeout: defaultTimeout}).Return(tc, nil).Once()
+	tc.On("Test", mock.Anything, testReleaseName).Return(rel, errors.New("pod first-test failed")).Once()
+	tc.On("Logs", mock.Anything, rel.Hooks[0]).Return("connection refused", nil).Once()
+
+	resp, err := service.Test(ctx, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, statusFailed, resp.Status)
+	require.Len(t, resp.Tests, 2)
+	assert.Equal(t, release.HookPhaseFailed, resp.Tests[0].Phase)
+	assert.Equal(t, "connection refused", resp.Tests[0].Logs)
+	assert.Equal(t, release.HookPhaseUnknown, resp.Tests[1].Phase)
+	assert.True(t, resp.Tests[1].StartedAt.IsZero())
+	tc.AssertExpectations(t)
+}
+
+func TestShouldIgnoreLogErrors(t *testing.T) {
+	cli := new(mockHelmClient)
+	tc := new(mockTester)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{name: testReleaseName, Logs: true}
+	rel := testRelease(release.HookPhaseSucceeded)
+	cli.On("NewTester", flags.TestFlags{Timeout: defaultTimeout}).Return(tc, nil).Once()
+	tc.On("Test", mock.Anything, testReleaseName).Return(rel, nil).Once()
+	tc.On("Logs", mock.Anything, rel.Hooks[0]).Return("", errors.New("pods \"first-test\" not found")).Once()
+
+	resp, err := service.Test(ctx, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, statusPassed, resp.Status)
+	assert.Empty(t, resp.Tests[0].Logs)
+}
+
+func TestShouldReturnErrorWhenReleaseIsNotFound(t *testing.T) {
+	cli := new(mockHelmClient)
+	tc := new(mockTester)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{name: testReleaseName}
+	cli.On("NewTester", flags.TestFlags{Timeout: defaultTimeout}).Return(tc, nil).Once()
+	tc.On("Test", mock.Anything, testReleaseName).Return(nil, driver.ErrReleaseNotFound).Once()
+
+	resp, err := service.Test(ctx, req)
+
+	assert.True(t, errors.Is(err, driver.ErrReleaseNotFound))
+	assert.Empty(t, resp.Status)
+}
+
+func TestShouldReturnErrorWhenTesterCannotBeCreated(t *testing.T) {
+	cli := new(mockHelmClient)
+	service := NewService(cli)
+	req := Request{name: testReleaseName}
+	cli.On("NewTester", flags.TestFlags{Timeout: defaultTimeout}).Return((*mockTester)(nil), errors.New("invalid kube context")).Once()
+
+	_, err := service.Test(context.Background(), req)
+
+	assert.EqualError(t, err, "error while initializing tester: invalid kube context")
+}
@@ -6,7 +6,9 @@ import (
 	"errors"
 	"net/http"
 
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
 	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/secret"
 
 	"github.com/gorilla/mux"
 )
@@ -14,10 +16,14 @@ import (
 // AddRequest is the body for PUT request to repository
 // swagger:model addRepoRequestBody
 type AddRequest struct {
-	Name     string `json:"-"`
-	URL      string `json:"url"`
+	Name string `json:"-"`
+	URL  string `json:"url"`
+	// Username and Password are kept in the secret store under the repository name and are never returned
 	Username string `json:"username"`
 	Password string `json:"password"`
+	// Name of credentials in the secret store, it cannot be used with username and password
+	// example: shared-registry
+	CredentialsRef string `json:"credentials_ref"`
 
 	// example: false
 	ForceUpdate bool `json:"force_update"`
@@ -30,19 +36,14 @@ type addService interface {
 	Add(context.Context, AddRequest) (Entry, error)
 }
 
-// AddErrorResponse body of non 2xx response
-// swagger:model addRepoErrorResponseBody
-type AddErrorResponse struct {
-	Error string `json:"error"`
-}
-
 // Entry contains metadata about a helm repository entry object
 // swagger:model addRepoEntry
 type Entry struct {
-	Name     string `json:"name"`
-	URL      string `json:"url"`
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// Name of the credentials of the repository in the secret store
+	// example: gojek-incubator
+	CredentialsRef string `json:"credentials_ref,omitempty"`
 }
 
 const URLNamePlaceholder string = "repository_name"
@@ -74,13 +75,13 @@ const URLNamePlaceholder string = "repository_name"
 //    schema:
 //     $ref: "#/definitions/addRepoEntry"
 //   '400':
-//    description: "Invalid Request"
+//    description: "Invalid Request or unknown credentials_ref"
 //    schema:
-//     $ref: "#/definitions/addRepoErrorResponseBody"
+//     $ref: "#/definitions/errorResponse"
 //   '500':
 //    description: "Something went with the server"
 //    schema:
-//     $ref: "#/definitions/addRepoErrorResponseBody"
+//     $ref: "#/definitions/errorResponse"
 func AddHandler(s addService) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer r.Body.Close()
@@ -89,13 +90,13 @@ func AddHandler(s addService) http.Handler {
 		var req AddRequest
 		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 			logger.Errorf("[RepoAdd] error decoding request: %v", err)
-			w.WriteHeader(http.StatusBadRequest)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
 			return
 		}
 
 		if err := req.isValid(); err != nil {
 			logger.Errorf("[RepoAdd] error validating request %v", err)
-			respondAddError(w, "error adding repo", err, http.StatusBadRequest)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
 			return
 		}
 
@@ -105,29 +106,29 @@ func AddHandler(s addService) http.Handler {
 
 		if err != nil {
 			logger.Errorf("[RepoAdd] error adding repo: %v", err)
-			respondAddError(w, "error adding repo", err, http.StatusInternalServerError)
+			apiErrors.Write(w, addError(err))
 			return
 		}
 		if err := json.NewEncoder(w).Encode(&resp); err != nil {
-			respondAddError(w, "error writing response: %v", err, http.StatusInternalServerError)
-			return
+			logger.Errorf("[RepoAdd] error writing response: %v", err)
 		}
 	})
 }
 
-func respondAddError(w http.ResponseWriter, logprefix string, err error, errorCode int) {
-	response := AddErrorResponse{Error: err.Error()}
-	w.WriteHeader(errorCode)
-	if err := json.NewEncoder(w).Encode(&response); err != nil {
-		logger.Errorf("[AddRepo] %s %v", logprefix, err)
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+// addError makes unknown credentials an invalid request, they are named by its credentials_ref
+func addError(err error) error {
+	if errors.Is(err, secret.ErrNotFound) {
+		return apiErrors.Wrap(apiErrors.Invalid, err)
 	}
+	return err
 }
 
 func (req AddRequest) isValid() error {
 	if req.URL == "" {
 		return errors.New("url cannot be empty")
 	}
+	if req.CredentialsRef != "" && (req.Username != "" || req.Password != "") {
+		return errors.New("credentials_ref cannot be used with username and password")
+	}
 	return nil
 }
@@ -12,6 +12,7 @@ import (
 	"testing"
 
 	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/secret"
 
 	"github.com/gorilla/mux"
 	"github.com/stretchr/testify/mock"
@@ -66,10 +67,9 @@ func (s *RepoAddTestSuite) TestRepoAddSuccessFul() {
 	}
 
 	mockAddResponse := Entry{
-		Name:     request.Name,
-		URL:      request.URL,
-		Username: request.Username,
-		Password: request.Password,
+		Name:           request.Name,
+		URL:            request.URL,
+		CredentialsRef: request.Name,
 	}
 	s.mockService.On("Add", mock.Anything, request).Return(mockAddResponse, nil)
 
@@ -84,8 +84,36 @@ func (s *RepoAddTestSuite) TestRepoAddSuccessFul() {
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), mockAddResponse.Name, parsedResponse.Name)
 	assert.Equal(s.T(), mockAddResponse.URL, parsedResponse.URL)
-	assert.Equal(s.T(), mockAddResponse.Username, parsedResponse.Username)
-	assert.Equal(s.T(), mockAddResponse.Password, parsedResponse.Password)
+	assert.Equal(s.T(), mockAddResponse.CredentialsRef, parsedResponse.CredentialsRef)
+	assert.Assert(s.T(), !strings.Contains(string(respBody), "123"))
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RepoAddTestSuite) TestRepoAddRejectsCredentialsRefWithInlineCredentials() {
+	body := `{"url":"https://gojek.github.io/charts/incubator/", "username":"admin", "password":"123", "credentials_ref":"shared"}`
+
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/repositories/%s", s.server.URL, "gojek-incubator"), strings.NewReader(body))
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+	respBody, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(s.T(), `{"code":"invalid","message":"credentials_ref cannot be used with username and password"}`+"\n", string(respBody))
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RepoAddTestSuite) TestRepoAddUnknownCredentialsRef() {
+	repoName := "gojek-incubator"
+	urlName := "https://gojek.github.io/charts/incubator/"
+	body := fmt.Sprintf(`{"url":"%s", "credentials_ref":"shared"}`, urlName)
+
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/repositories/%s", s.server.URL, repoName), strings.NewReader(body))
+	request := AddRequest{Name: repoName, URL: urlName, CredentialsRef: "shared"}
+	s.mockService.On("Add", mock.Anything, request).Return(Entry{}, fmt.Errorf("error reading credentials shared: %w", secret.ErrNotFound))
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
 	s.mockService.AssertExpectations(s.T())
 }
 
@@ -97,7 +125,7 @@ func (s *RepoAddTestSuite) TestRepoAddInvalidRequest() {
 
 	resp, err := http.DefaultClient.Do(req)
 	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
-	expectedResponse := `{"error":"url cannot be empty"}` + "\n"
+	expectedResponse := `{"code":"invalid","message":"url cannot be empty"}` + "\n"
 	respBody, _ := ioutil.ReadAll(resp.Body)
 	assert.Equal(s.T(), expectedResponse, string(respBody))
 	require.NoError(s.T(), err)
@@ -121,7 +149,7 @@ func (s *RepoAddTestSuite) TestRepoAddFailure() {
 
 	resp, err := http.DefaultClient.Do(req)
 	assert.Equal(s.T(), http.StatusInternalServerError, resp.StatusCode)
-	expectedResponse := `{"error":"error adding repository"}` + "\n"
+	expectedResponse := `{"code":"internal","message":"error adding repository"}` + "\n"
 	respBody, _ := ioutil.ReadAll(resp.Body)
 	assert.Equal(s.T(), expectedResponse, string(respBody))
 	require.NoError(s.T(), err)
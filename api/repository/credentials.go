@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+// CredentialsNamePlaceholder is the path variable holding the name of credentials
+const CredentialsNamePlaceholder string = "credentials_name"
+
+// CredentialsRequest is the body for PUT request to credentials
+// swagger:model credentialsRequestBody
+type CredentialsRequest struct {
+	Name string `json:"-"`
+	// example: admin
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// CredentialsResponse is the body of credentials responses, the credentials themselves are never returned
+// swagger:model credentialsResponseBody
+type CredentialsResponse struct {
+	// example: shared-registry
+	Name string `json:"name,omitempty"`
+}
+
+type credentialsService interface {
+	PutCredentials(ctx context.Context, req CredentialsRequest) error
+	DeleteCredentials(ctx context.Context, name string) error
+}
+
+// PutCredentialsHandler stores credentials referenced by repositories
+// swagger:operation PUT /credentials/{credentials_name} repository putCredentials
+//
+// Store repository credentials in the secret store.
+// Repositories use them through credentials_ref, the password is never returned.
+// ---
+// produces:
+// - application/json
+// parameters:
+// - name: credentials_name
+//   in: path
+//   required: true
+//   type: string
+// - name: Body
+//   in: body
+//   required: true
+//   schema:
+//    "$ref": "#/definitions/credentialsRequestBody"
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/credentialsResponseBody"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func PutCredentialsHandler(s credentialsService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		var req CredentialsRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			respondCredentialsError(w, "error decoding request", apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+		req.Name = mux.Vars(r)[CredentialsNamePlaceholder]
+		if req.Username == "" && req.Password == "" {
+			respondCredentialsError(w, "error validating request", apiErrors.New(apiErrors.Invalid, "username or password is required"))
+			return
+		}
+
+		if err := s.PutCredentials(r.Context(), req); err != nil {
+			respondCredentialsError(w, "error storing credentials", err)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(CredentialsResponse{Name: req.Name}); err != nil {
+			logger.Errorf("[Credentials] error writing response: %v", err)
+		}
+	})
+}
+
+// DeleteCredentialsHandler removes stored credentials
+// swagger:operation DELETE /credentials/{credentials_name} repository deleteCredentials
+//
+// ---
+// produces:
+// - application/json
+// parameters:
+// - name: credentials_name
+//   in: path
+//   required: true
+//   type: string
+// schemes:
+// - http
+// responses:
+//   '204':
+//    description: "The credentials were removed"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func DeleteCredentialsHandler(s credentialsService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := mux.Vars(r)[CredentialsNamePlaceholder]
+		if err := s.DeleteCredentials(r.Context(), name); err != nil {
+			respondCredentialsError(w, "error deleting credentials", err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func respondCredentialsError(w http.ResponseWriter, logprefix string, err error) {
+	logger.Errorf("[Credentials] %s: %v", logprefix, err)
+	apiErrors.Write(w, err)
+}
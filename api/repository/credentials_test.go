@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/secret"
+)
+
+type mockCredentialsService struct{ mock.Mock }
+
+func (m *mockCredentialsService) PutCredentials(ctx context.Context, req CredentialsRequest) error {
+	return m.Called(ctx, req).Error(0)
+}
+
+func (m *mockCredentialsService) DeleteCredentials(ctx context.Context, name string) error {
+	return m.Called(ctx, name).Error(0)
+}
+
+type CredentialsTestSuite struct {
+	suite.Suite
+	server      *httptest.Server
+	mockService *mockCredentialsService
+}
+
+func (s *CredentialsTestSuite) SetupSuite() {
+	logger.Setup("default")
+}
+
+func (s *CredentialsTestSuite) SetupTest() {
+	s.mockService = new(mockCredentialsService)
+	router := mux.NewRouter()
+	path := fmt.Sprintf("/credentials/{%s}", CredentialsNamePlaceholder)
+	router.Handle(path, PutCredentialsHandler(s.mockService)).Methods(http.MethodPut)
+	router.Handle(path, DeleteCredentialsHandler(s.mockService)).Methods(http.MethodDelete)
+	s.server = httptest.NewServer(router)
+}
+
+func (s *CredentialsTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *CredentialsTestSuite) TestPutDoesNotReturnPassword() {
+	req, _ := http.NewRequest(http.MethodPut, s.server.URL+"/credentials/shared", strings.NewReader(`{"username":"admin","password":"s3cr3t"}`))
+	s.mockService.On("PutCredentials", mock.Anything, CredentialsRequest{Name: "shared", Username: "admin", Password: "s3cr3t"}).Return(nil)
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	body, _ := ioutil.ReadAll(resp.Body)
+	assert.Equal(s.T(), `{"name":"shared"}`+"\n", string(body))
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *CredentialsTestSuite) TestPutRejectsEmptyCredentials() {
+	req, _ := http.NewRequest(http.MethodPut, s.server.URL+"/credentials/shared", strings.NewReader(`{}`))
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *CredentialsTestSuite) TestDelete() {
+	s.mockService.On("DeleteCredentials", mock.Anything, "shared").Return(nil).Once()
+	s.mockService.On("DeleteCredentials", mock.Anything, "unknown").Return(secret.ErrNotFound).Once()
+
+	req, _ := http.NewRequest(http.MethodDelete, s.server.URL+"/credentials/shared", nil)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusNoContent, resp.StatusCode)
+
+	req, _ = http.NewRequest(http.MethodDelete, s.server.URL+"/credentials/unknown", nil)
+	resp, err = http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusNotFound, resp.StatusCode)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func TestCredentialsAPI(t *testing.T) {
+	suite.Run(t, new(CredentialsTestSuite))
+}
@@ -0,0 +1,93 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+// ListResponse is the body of /repositories
+// swagger:model listRepoResponseBody
+type ListResponse struct {
+	Repositories []Entry `json:"repositories"`
+}
+
+type listService interface {
+	List(ctx context.Context) ([]Entry, error)
+	Get(ctx context.Context, name string) (Entry, error)
+}
+
+// ListHandler handles a repository list request
+// swagger:operation GET /repositories repository listRepositories
+//
+// List the chart repositories of the server, credentials are never returned
+// ---
+// produces:
+// - application/json
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/listRepoResponseBody"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func ListHandler(s listService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entries, err := s.List(r.Context())
+		if err != nil {
+			respondRepositoryError(w, "[RepoList] error listing repositories", err)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(ListResponse{Repositories: entries}); err != nil {
+			logger.Errorf("[RepoList] error writing response: %v", err)
+		}
+	})
+}
+
+// GetHandler handles a repository get request
+// swagger:operation GET /repositories/{repository_name} repository getRepository
+//
+// ---
+// produces:
+// - application/json
+// parameters:
+// - name: repository_name
+//   in: path
+//   required: true
+//   type: string
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/addRepoEntry"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func GetHandler(s listService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		entry, err := s.Get(r.Context(), mux.Vars(r)[URLNamePlaceholder])
+		if err != nil {
+			respondRepositoryError(w, "[RepoGet] error getting repository", err)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(entry); err != nil {
+			logger.Errorf("[RepoGet] error writing response: %v", err)
+		}
+	})
+}
+
+func respondRepositoryError(w http.ResponseWriter, logprefix string, err error) {
+	logger.Errorf("%s: %v", logprefix, err)
+	apiErrors.Write(w, err)
+}
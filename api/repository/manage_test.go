@@ -0,0 +1,127 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/repository"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+type mockManageService struct{ mock.Mock }
+
+func (m *mockManageService) List(ctx context.Context) ([]Entry, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]Entry), args.Error(1)
+}
+
+func (m *mockManageService) Get(ctx context.Context, name string) (Entry, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(Entry), args.Error(1)
+}
+
+func (m *mockManageService) Remove(ctx context.Context, name string) error {
+	return m.Called(ctx, name).Error(0)
+}
+
+func (m *mockManageService) Update(ctx context.Context, names ...string) ([]UpdateResult, error) {
+	args := m.Called(ctx, names)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]UpdateResult), args.Error(1)
+}
+
+type RepoManageTestSuite struct {
+	suite.Suite
+	server      *httptest.Server
+	mockService *mockManageService
+}
+
+func (s *RepoManageTestSuite) SetupSuite() {
+	logger.Setup("default")
+}
+
+func (s *RepoManageTestSuite) SetupTest() {
+	s.mockService = new(mockManageService)
+	router := mux.NewRouter()
+	named := fmt.Sprintf("/repositories/{%s}", URLNamePlaceholder)
+	router.Handle("/repositories", ListHandler(s.mockService)).Methods(http.MethodGet)
+	router.Handle("/repositories/update", UpdateHandler(s.mockService)).Methods(http.MethodPost)
+	router.Handle(named, GetHandler(s.mockService)).Methods(http.MethodGet)
+	router.Handle(named, DeleteHandler(s.mockService)).Methods(http.MethodDelete)
+	router.Handle(named+"/update", UpdateHandler(s.mockService)).Methods(http.MethodPost)
+	s.server = httptest.NewServer(router)
+}
+
+func (s *RepoManageTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func (s *RepoManageTestSuite) do(method, path string) (int, string) {
+	req, _ := http.NewRequest(method, s.server.URL+path, nil)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(resp.Body)
+	return resp.StatusCode, string(body)
+}
+
+func (s *RepoManageTestSuite) TestList() {
+	s.mockService.On("List", mock.Anything).Return([]Entry{{Name: "stable", URL: "https://charts.helm.sh/stable"}}, nil)
+
+	code, body := s.do(http.MethodGet, "/repositories")
+
+	assert.Equal(s.T(), http.StatusOK, code)
+	assert.Equal(s.T(), `{"repositories":[{"name":"stable","url":"https://charts.helm.sh/stable"}]}`+"\n", body)
+}
+
+func (s *RepoManageTestSuite) TestGetUnknownRepository() {
+	s.mockService.On("Get", mock.Anything, "stable").Return(Entry{}, repository.ErrNotFound)
+
+	code, body := s.do(http.MethodGet, "/repositories/stable")
+
+	assert.Equal(s.T(), http.StatusNotFound, code)
+	assert.Equal(s.T(), `{"code":"not_found","message":"repository not found"}`+"\n", body)
+}
+
+func (s *RepoManageTestSuite) TestDelete() {
+	s.mockService.On("Remove", mock.Anything, "stable").Return(nil)
+
+	code, _ := s.do(http.MethodDelete, "/repositories/stable")
+
+	assert.Equal(s.T(), http.StatusNoContent, code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RepoManageTestSuite) TestUpdateAll() {
+	s.mockService.On("Update", mock.Anything, []string(nil)).Return([]UpdateResult{{Name: "stable"}, {Name: "private", Error: "unreachable"}}, nil)
+
+	code, body := s.do(http.MethodPost, "/repositories/update")
+
+	assert.Equal(s.T(), http.StatusOK, code)
+	assert.Equal(s.T(), `{"repositories":[{"name":"stable"},{"name":"private","error":"unreachable"}]}`+"\n", body)
+}
+
+func (s *RepoManageTestSuite) TestUpdateOne() {
+	s.mockService.On("Update", mock.Anything, []string{"stable"}).Return(nil, fmt.Errorf("%w: stable", repository.ErrNotFound))
+
+	code, _ := s.do(http.MethodPost, "/repositories/stable/update")
+
+	assert.Equal(s.T(), http.StatusNotFound, code)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func TestRepoManageAPI(t *testing.T) {
+	suite.Run(t, new(RepoManageTestSuite))
+}
@@ -0,0 +1,46 @@
+package repository
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type removeService interface {
+	Remove(ctx context.Context, name string) error
+}
+
+// DeleteHandler handles a repository remove request
+// swagger:operation DELETE /repositories/{repository_name} repository removeRepository
+//
+// Remove a chart repository and its cached index.
+// Its credentials are kept in the secret store and can be removed with DELETE /credentials/{credentials_name}.
+// ---
+// produces:
+// - application/json
+// parameters:
+// - name: repository_name
+//   in: path
+//   required: true
+//   type: string
+// schemes:
+// - http
+// responses:
+//   '204':
+//    description: "The repository was removed"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func DeleteHandler(s removeService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := s.Remove(r.Context(), mux.Vars(r)[URLNamePlaceholder]); err != nil {
+			respondRepositoryError(w, "[RepoRemove] error removing repository", err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
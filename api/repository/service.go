@@ -7,19 +7,28 @@ import (
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
 	"github.com/gojekfarm/albatross/pkg/helmcli/repository"
 	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/secret"
+	"github.com/gojekfarm/albatross/pkg/tracing"
 
 	"helm.sh/helm/v3/pkg/repo"
 )
 
 type Service struct {
-	cli repository.Client
+	cli     repository.Client
+	secrets secret.Store
 }
 
-func (s Service) Add(ctx context.Context, req AddRequest) (Entry, error) {
+func (s Service) Add(ctx context.Context, req AddRequest) (_ Entry, err error) {
+	ctx, span := tracing.Start(ctx, "repository.Add")
+	defer func() { tracing.End(span, err) }()
+
 	addFlags := flags.AddFlags{
-		Name:        req.Name,
-		URL:         req.URL,
-		ForceUpdate: req.ForceUpdate,
+		Name:           req.Name,
+		URL:            req.URL,
+		Username:       req.Username,
+		Password:       req.Password,
+		CredentialsRef: req.CredentialsRef,
+		ForceUpdate:    req.ForceUpdate,
 	}
 
 	adder, err := s.cli.NewAdder(addFlags)
@@ -31,21 +40,133 @@ func (s Service) Add(ctx context.Context, req AddRequest) (Entry, error) {
 	if err != nil {
 		return Entry{}, err
 	}
-	return getEntry(entry)
+	return getEntry(entry, credentialsRef(req))
+}
+
+// List returns the configured repositories
+func (s Service) List(ctx context.Context) (_ []Entry, err error) {
+	ctx, span := tracing.Start(ctx, "repository.List")
+	defer func() { tracing.End(span, err) }()
+
+	lister, err := s.cli.NewLister()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := lister.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]Entry, 0, len(entries))
+	for _, e := range entries {
+		resp = append(resp, toEntry(e))
+	}
+	return resp, nil
+}
+
+// Get returns the repository name
+func (s Service) Get(ctx context.Context, name string) (_ Entry, err error) {
+	ctx, span := tracing.Start(ctx, "repository.Get")
+	defer func() { tracing.End(span, err) }()
+
+	lister, err := s.cli.NewLister()
+	if err != nil {
+		return Entry{}, err
+	}
+	e, err := lister.Get(ctx, name)
+	if err != nil {
+		return Entry{}, err
+	}
+	return toEntry(e), nil
+}
+
+// Remove removes the repository name and its cached index
+func (s Service) Remove(ctx context.Context, name string) (err error) {
+	ctx, span := tracing.Start(ctx, "repository.Remove")
+	defer func() { tracing.End(span, err) }()
+
+	remover, err := s.cli.NewRemover(flags.RepoRemoveFlags{Name: name})
+	if err != nil {
+		return err
+	}
+	if err := remover.Remove(ctx); err != nil {
+		return err
+	}
+	logger.Infof("Repository %s has been removed", name)
+	return nil
+}
+
+// Update refreshes the index of the repositories names, or of every repository when there are none
+func (s Service) Update(ctx context.Context, names ...string) (_ []UpdateResult, err error) {
+	ctx, span := tracing.Start(ctx, "repository.Update")
+	defer func() { tracing.End(span, err) }()
+
+	updater, err := s.cli.NewUpdater(flags.RepoUpdateFlags{Names: names})
+	if err != nil {
+		return nil, err
+	}
+	results, err := updater.Update(ctx)
+	if err != nil {
+		return nil, err
+	}
+	resp := make([]UpdateResult, 0, len(results))
+	for _, r := range results {
+		result := UpdateResult{Name: r.Name}
+		if r.Err != nil {
+			logger.Errorf("[RepoUpdate] error updating repository %s: %v", r.Name, r.Err)
+			result.Error = r.Err.Error()
+		}
+		resp = append(resp, result)
+	}
+	return resp, nil
+}
+
+// PutCredentials stores credentials that repositories can reference by name
+func (s Service) PutCredentials(ctx context.Context, req CredentialsRequest) (err error) {
+	ctx, span := tracing.Start(ctx, "repository.PutCredentials")
+	defer func() { tracing.End(span, err) }()
+
+	if s.secrets == nil {
+		return repository.ErrNoSecretStore
+	}
+	return s.secrets.Put(ctx, req.Name, secret.Credentials{Username: req.Username, Password: req.Password})
+}
+
+// DeleteCredentials removes stored credentials
+func (s Service) DeleteCredentials(ctx context.Context, name string) (err error) {
+	ctx, span := tracing.Start(ctx, "repository.DeleteCredentials")
+	defer func() { tracing.End(span, err) }()
+
+	if s.secrets == nil {
+		return repository.ErrNoSecretStore
+	}
+	return s.secrets.Delete(ctx, name)
+}
+
+// NewService returns a service adding repositories with cli,
+// secrets keeps repository credentials and can be nil when credentials are not used.
+func NewService(cli repository.Client, secrets secret.Store) Service {
+	return Service{cli, secrets}
+}
+
+// credentialsRef is the name the credentials of the request are stored under
+func credentialsRef(req AddRequest) string {
+	if req.Username != "" || req.Password != "" {
+		return req.Name
+	}
+	return req.CredentialsRef
 }
 
-func NewService(cli repository.Client) Service {
-	return Service{cli}
+func toEntry(e repository.Entry) Entry {
+	return Entry{Name: e.Name, URL: e.URL, CredentialsRef: e.CredentialsRef}
 }
 
-func getEntry(entry *repo.Entry) (Entry, error) {
+func getEntry(entry *repo.Entry, ref string) (Entry, error) {
 	if entry != nil {
 		logger.Infof("Repository %s with URL: %s has been added", entry.Name, entry.URL)
 		return Entry{
-			Name:     entry.Name,
-			URL:      entry.URL,
-			Username: entry.Username,
-			Password: entry.Password,
+			Name:           entry.Name,
+			URL:            entry.URL,
+			CredentialsRef: ref,
 		}, nil
 	}
 
@@ -24,6 +24,66 @@ func (m *mockRepositoryClient) NewAdder(addFlags flags.AddFlags) (repository.Add
 	return args.Get(0).(repository.Adder), args.Error(1)
 }
 
+func (m *mockRepositoryClient) NewLister() (repository.Lister, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(repository.Lister), args.Error(1)
+}
+
+func (m *mockRepositoryClient) NewRemover(removeFlags flags.RepoRemoveFlags) (repository.Remover, error) {
+	args := m.Called(removeFlags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(repository.Remover), args.Error(1)
+}
+
+func (m *mockRepositoryClient) NewUpdater(updateFlags flags.RepoUpdateFlags) (repository.Updater, error) {
+	args := m.Called(updateFlags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(repository.Updater), args.Error(1)
+}
+
+func (m *mockRepositoryClient) NewSearcher(searchFlags flags.SearchFlags) (repository.Searcher, error) {
+	args := m.Called(searchFlags)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(repository.Searcher), args.Error(1)
+}
+
+type mockLister struct{ mock.Mock }
+
+func (m *mockLister) List(ctx context.Context) ([]repository.Entry, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]repository.Entry), args.Error(1)
+}
+
+func (m *mockLister) Get(ctx context.Context, name string) (repository.Entry, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(repository.Entry), args.Error(1)
+}
+
+type mockRemover struct{ mock.Mock }
+
+func (m *mockRemover) Remove(ctx context.Context) error {
+	return m.Called(ctx).Error(0)
+}
+
+type mockUpdater struct{ mock.Mock }
+
+func (m *mockUpdater) Update(ctx context.Context) ([]repository.UpdateResult, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]repository.UpdateResult), args.Error(1)
+}
+
 type mockAdder struct{ mock.Mock }
 
 func (m *mockAdder) Add(ctx context.Context) (*repo.Entry, error) {
@@ -37,7 +97,7 @@ func (m *mockAdder) Add(ctx context.Context) (*repo.Entry, error) {
 func TestServiceAddSuccessful(t *testing.T) {
 	mockCli := new(mockRepositoryClient)
 	adder := new(mockAdder)
-	s := NewService(mockCli)
+	s := NewService(mockCli, nil)
 	req := AddRequest{
 		Name: "repoName",
 		URL:  "https://gojek.github.io/charts/incubator/",
@@ -63,7 +123,7 @@ func TestServiceAddSuccessful(t *testing.T) {
 
 func TestServiceNewAdderError(t *testing.T) {
 	mockCli := new(mockRepositoryClient)
-	s := NewService(mockCli)
+	s := NewService(mockCli, nil)
 	req := AddRequest{
 		Name: "repoName",
 		URL:  "https://gojek.github.io/charts/incubator/",
@@ -86,7 +146,7 @@ func TestServiceNewAdderError(t *testing.T) {
 func TestServiceAddError(t *testing.T) {
 	mockCli := new(mockRepositoryClient)
 	adder := new(mockAdder)
-	s := NewService(mockCli)
+	s := NewService(mockCli, nil)
 	req := AddRequest{
 		Name: "repoName",
 		URL:  "https://gojek.github.io/charts/incubator/",
@@ -106,3 +166,80 @@ func TestServiceAddError(t *testing.T) {
 	require.Error(t, addError, err)
 	assert.Equal(t, Entry{}, resp)
 }
+
+func TestServiceAddStoresInlineCredentials(t *testing.T) {
+	mockCli := new(mockRepositoryClient)
+	adder := new(mockAdder)
+	s := NewService(mockCli, nil)
+	req := AddRequest{
+		Name:     "private",
+		URL:      "https://charts.example.com",
+		Username: "admin",
+		Password: "1234",
+	}
+	addFlags := flags.AddFlags{
+		Name:     "private",
+		URL:      "https://charts.example.com",
+		Username: "admin",
+		Password: "1234",
+	}
+	mockCli.On("NewAdder", addFlags).Return(adder, nil).Once()
+	adder.On("Add", mock.Anything).Return(&repo.Entry{Name: "private", URL: "https://charts.example.com"}, nil).Once()
+
+	resp, err := s.Add(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.Equal(t, Entry{Name: "private", URL: "https://charts.example.com", CredentialsRef: "private"}, resp)
+}
+
+func TestServiceCredentialsRequireSecretStore(t *testing.T) {
+	s := NewService(new(mockRepositoryClient), nil)
+
+	err := s.PutCredentials(context.Background(), CredentialsRequest{Name: "shared", Username: "admin"})
+
+	assert.Equal(t, repository.ErrNoSecretStore, err)
+}
+
+func TestServiceListRedactsEntries(t *testing.T) {
+	mockCli := new(mockRepositoryClient)
+	lister := new(mockLister)
+	s := NewService(mockCli, nil)
+	mockCli.On("NewLister").Return(lister, nil).Once()
+	lister.On("List", mock.Anything).Return([]repository.Entry{
+		{Entry: repo.Entry{Name: "private", URL: "https://charts.example.com", CAFile: "/etc/ca.pem"}, CredentialsRef: "shared"},
+	}, nil).Once()
+
+	resp, err := s.List(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, []Entry{{Name: "private", URL: "https://charts.example.com", CredentialsRef: "shared"}}, resp)
+}
+
+func TestServiceRemove(t *testing.T) {
+	mockCli := new(mockRepositoryClient)
+	remover := new(mockRemover)
+	s := NewService(mockCli, nil)
+	mockCli.On("NewRemover", flags.RepoRemoveFlags{Name: "stable"}).Return(remover, nil).Once()
+	remover.On("Remove", mock.Anything).Return(repository.ErrNotFound).Once()
+
+	err := s.Remove(context.Background(), "stable")
+
+	assert.Equal(t, repository.ErrNotFound, err)
+	mockCli.AssertExpectations(t)
+}
+
+func TestServiceUpdateReportsFailedRepositories(t *testing.T) {
+	mockCli := new(mockRepositoryClient)
+	updater := new(mockUpdater)
+	s := NewService(mockCli, nil)
+	mockCli.On("NewUpdater", flags.RepoUpdateFlags{Names: []string{"stable", "private"}}).Return(updater, nil).Once()
+	updater.On("Update", mock.Anything).Return([]repository.UpdateResult{
+		{Name: "stable"},
+		{Name: "private", Err: errors.New("401 Unauthorized")},
+	}, nil).Once()
+
+	resp, err := s.Update(context.Background(), "stable", "private")
+
+	require.NoError(t, err)
+	assert.Equal(t, []UpdateResult{{Name: "stable"}, {Name: "private", Error: "401 Unauthorized"}}, resp)
+}
@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+// UpdateResult is the outcome of refreshing the index of a repository
+type UpdateResult struct {
+	// example: stable
+	Name string `json:"name"`
+	// Error is set when the index could not be downloaded
+	Error string `json:"error,omitempty"`
+}
+
+// UpdateResponse is the body of a repository update
+// swagger:model updateRepoResponseBody
+type UpdateResponse struct {
+	Repositories []UpdateResult `json:"repositories"`
+}
+
+type updateService interface {
+	Update(ctx context.Context, names ...string) ([]UpdateResult, error)
+}
+
+// UpdateHandler handles a repository index update request
+// swagger:operation POST /repositories/update repository updateRepositories
+//
+// Refresh the index of every chart repository.
+// A repository that cannot be reached does not stop the others, its error is part of the response.
+// ---
+// produces:
+// - application/json
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/updateRepoResponseBody"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+
+// swagger:operation POST /repositories/{repository_name}/update repository updateRepository
+//
+// Refresh the index of a chart repository
+// ---
+// produces:
+// - application/json
+// parameters:
+// - name: repository_name
+//   in: path
+//   required: true
+//   type: string
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/updateRepoResponseBody"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func UpdateHandler(s updateService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var names []string
+		if name, ok := mux.Vars(r)[URLNamePlaceholder]; ok {
+			names = append(names, name)
+		}
+
+		results, err := s.Update(r.Context(), names...)
+		if err != nil {
+			respondRepositoryError(w, "[RepoUpdate] error updating repositories", err)
+			return
+		}
+		if err := json.NewEncoder(w).Encode(UpdateResponse{Repositories: results}); err != nil {
+			logger.Errorf("[RepoUpdate] error writing response: %v", err)
+		}
+	})
+}
@@ -0,0 +1,164 @@
+package rollback
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/logger"
+
+	"github.com/gorilla/mux"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+var errInvalidReleaseName = errors.New("rollback: invalid release name")
+
+// Request is the body for rolling back a release
+// swagger:model rollbackRequestBody
+type Request struct {
+	name string
+	// Revision to roll back to, the previous revision is used when it is not set
+	// example: 1
+	Version int `json:"revision"`
+	// example: false
+	Wait bool `json:"wait"`
+	// Timeout in seconds
+	// example: 300
+	Timeout int `json:"timeout"`
+	// example: false
+	Force bool `json:"force"`
+	// example: false
+	Recreate bool `json:"recreate"`
+	// example: false
+	CleanupOnFail bool `json:"cleanup_on_fail"`
+	flags.GlobalFlags
+}
+
+// Release contains metadata about a helm release object
+// swagger:model rollbackRelease
+type Release struct {
+	// example: mysql-5.7
+	Name string `json:"name"`
+	// example: default
+	Namespace string `json:"namespace"`
+	// example: 3
+	Version int `json:"version"`
+	// example: 2021-03-24T12:24:18.450869+05:30
+	Updated time.Time `json:"updated_at,omitempty"`
+	// example: deployed
+	Status release.Status `json:"status"`
+	// example: mysql
+	Chart string `json:"chart"`
+	// example: 5.7.30
+	AppVersion string `json:"app_version"`
+}
+
+// Response is the body of rollback route
+// swagger:model rollbackResponseBody
+type Response struct {
+	// Status status of the release, field is available only when status code is 2xx
+	// example: deployed
+	Status string `json:"status,omitempty"`
+	// Release release meta data, field is available only when status code is 2xx
+	Release *Release `json:"release,omitempty"`
+}
+
+type service interface {
+	Rollback(context.Context, Request) (Response, error)
+}
+
+// Handler handles a rollback request
+// swagger:operation POST /clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/rollback release rollbackOperation
+//
+//
+// ---
+// summary: Roll back a helm release to a previous revision
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// parameters:
+// - name: cluster
+//   in: path
+//   required: true
+//   default: minikube
+//   type: string
+//   format: string
+// - name: namespace
+//   in: path
+//   required: true
+//   default: default
+//   type: string
+//   format: string
+// - name: release_name
+//   in: path
+//   required: true
+//   type: string
+//   format: string
+//   default: mysql-final
+// - name: Body
+//   in: body
+//   required: false
+//   schema:
+//    "$ref": "#/definitions/rollbackRequestBody"
+// schemes:
+// - http
+// responses:
+//   '200':
+//    "$ref": "#/responses/rollbackResponse"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func Handler(s service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		var req Request
+		// An empty body is valid and rolls back to the previous revision with default options
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF {
+			logger.Errorf("[Rollback] error decoding request: %v", err)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+		values := mux.Vars(r)
+		req.name = values["release_name"]
+		req.KubeContext = values["cluster"]
+		req.Namespace = values["namespace"]
+		if err := req.valid(); err != nil {
+			logger.Errorf("[Rollback] error in request parameters: %v", err)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+
+		resp, err := s.Rollback(r.Context(), req)
+		if err != nil {
+			logger.Errorf("[Rollback] error while rolling back %s: %v", req.name, err)
+			apiErrors.Write(w, err)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(&resp); err != nil {
+			logger.Errorf("[Rollback] error writing response: %v", err)
+		}
+	})
+}
+
+func (req Request) valid() error {
+	releaseName := req.name
+	if releaseName == "" || !action.ValidName.MatchString(releaseName) || len(releaseName) > 53 {
+		return errInvalidReleaseName
+	}
+	return nil
+}
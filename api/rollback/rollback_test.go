@@ -0,0 +1,151 @@
+package rollback
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/logger"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gotest.tools/assert"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+type mockService struct {
+	mock.Mock
+}
+
+func (m *mockService) Rollback(ctx context.Context, req Request) (Response, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(Response), args.Error(1)
+}
+
+type RollbackTestSuite struct {
+	suite.Suite
+	recorder    *httptest.ResponseRecorder
+	server      *httptest.Server
+	mockService *mockService
+}
+
+func (s *RollbackTestSuite) SetupSuite() {
+	logger.Setup("default")
+}
+
+func (s *RollbackTestSuite) SetupTest() {
+	s.recorder = httptest.NewRecorder()
+	s.mockService = new(mockService)
+	router := mux.NewRouter()
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/rollback", Handler(s.mockService)).Methods(http.MethodPost)
+	s.server = httptest.NewServer(router)
+}
+
+func (s *RollbackTestSuite) TestShouldReturnReleaseWhenSuccessfulAPICall() {
+	body := `{"revision": 2, "wait": true, "timeout": 60, "cleanup_on_fail": true}`
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/minikube/namespaces/default/releases/%s/rollback", s.server.URL, testReleaseName), strings.NewReader(body))
+	requestStruct := Request{
+		name:          testReleaseName,
+		Version:       2,
+		Wait:          true,
+		Timeout:       60,
+		CleanupOnFail: true,
+		GlobalFlags: flags.GlobalFlags{
+			KubeContext: "minikube",
+			Namespace:   "default",
+		},
+	}
+	mockRelease := releaseInfo(release.Mock(&release.MockReleaseOptions{
+		Name:      testReleaseName,
+		Version:   3,
+		Namespace: "default",
+		Status:    release.StatusDeployed,
+	}))
+	response := Response{Status: release.StatusDeployed.String(), Release: mockRelease}
+	s.mockService.On("Rollback", mock.Anything, requestStruct).Times(1).Return(response, nil)
+
+	res, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, res.StatusCode)
+	var actualResponse Response
+	err = json.NewDecoder(res.Body).Decode(&actualResponse)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), response.Status, actualResponse.Status)
+	assert.Equal(s.T(), mockRelease.Name, actualResponse.Release.Name)
+	assert.Equal(s.T(), mockRelease.Version, actualResponse.Release.Version)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RollbackTestSuite) TestShouldRollbackToPreviousRevisionWhenBodyIsEmpty() {
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/minikube/namespaces/default/releases/%s/rollback", s.server.URL, testReleaseName), nil)
+	requestStruct := Request{
+		name: testReleaseName,
+		GlobalFlags: flags.GlobalFlags{
+			KubeContext: "minikube",
+			Namespace:   "default",
+		},
+	}
+	s.mockService.On("Rollback", mock.Anything, requestStruct).Times(1).Return(Response{}, nil)
+
+	res, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, res.StatusCode)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RollbackTestSuite) TestShouldReturnNotFoundWhenReleaseDoesNotExist() {
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/minikube/namespaces/default/releases/unknown/rollback", s.server.URL), strings.NewReader(`{}`))
+	s.mockService.On("Rollback", mock.Anything, mock.AnythingOfType("Request")).Times(1).Return(Response{}, driver.ErrReleaseNotFound)
+
+	res, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusNotFound, res.StatusCode)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RollbackTestSuite) TestShouldReturnInternalServerErrorWhenRollbackFails() {
+	errMsg := "test error message"
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/minikube/namespaces/default/releases/%s/rollback", s.server.URL, testReleaseName), strings.NewReader(`{}`))
+	s.mockService.On("Rollback", mock.Anything, mock.AnythingOfType("Request")).Times(1).Return(Response{}, errors.New(errMsg))
+
+	res, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusInternalServerError, res.StatusCode)
+	var actualResponse apiErrors.Body
+	err = json.NewDecoder(res.Body).Decode(&actualResponse)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Internal, Message: errMsg}, actualResponse)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *RollbackTestSuite) TestShouldReturnBadRequestForInvalidBody() {
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/minikube/namespaces/default/releases/%s/rollback", s.server.URL, testReleaseName), strings.NewReader(`{"revision": "two"}`))
+
+	res, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, res.StatusCode)
+	s.mockService.AssertNotCalled(s.T(), "Rollback")
+}
+
+func (s *RollbackTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func TestRollbackAPI(t *testing.T) {
+	suite.Run(t, new(RollbackTestSuite))
+}
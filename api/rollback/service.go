@@ -0,0 +1,77 @@
+package rollback
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/gojekfarm/albatross/pkg/audit"
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+const defaultTimeout = 300 * time.Second
+
+type Service struct {
+	cli helmcli.Client
+}
+
+// Rollback rolls a release back to the requested revision.
+func (s Service) Rollback(ctx context.Context, req Request) (_ Response, err error) {
+	ctx, span := tracing.Start(ctx, "rollback.Rollback")
+	defer func() { tracing.End(span, err) }()
+
+	timeout := defaultTimeout
+	if req.Timeout > 0 {
+		timeout = time.Second * time.Duration(req.Timeout)
+	}
+	rollbackFlags := flags.RollbackFlags{
+		Version:       req.Version,
+		Timeout:       timeout,
+		Wait:          req.Wait,
+		Force:         req.Force,
+		Recreate:      req.Recreate,
+		CleanupOnFail: req.CleanupOnFail,
+		GlobalFlags:   req.GlobalFlags,
+	}
+	rcli, err := s.cli.NewRollbacker(rollbackFlags)
+	if err != nil {
+		return Response{}, fmt.Errorf("error while initializing rollbacker: %w", err)
+	}
+
+	rel, err := rcli.Rollback(ctx, req.name)
+	audit.SetRelease(ctx, rel)
+	if err != nil {
+		return Response{}, err
+	}
+	return responseWithStatus(rel), nil
+}
+
+func responseWithStatus(rel *release.Release) Response {
+	resp := Response{}
+	if rel != nil && rel.Info != nil {
+		resp.Release = releaseInfo(rel)
+		resp.Status = rel.Info.Status.String()
+	}
+	return resp
+}
+
+func releaseInfo(rel *release.Release) *Release {
+	return &Release{
+		Name:       rel.Name,
+		Namespace:  rel.Namespace,
+		Version:    rel.Version,
+		Updated:    rel.Info.LastDeployed.Local().Time,
+		Status:     rel.Info.Status,
+		Chart:      rel.Chart.ChartFullPath(),
+		AppVersion: rel.Chart.AppVersion(),
+	}
+}
+
+// NewService returns a rollback service.
+func NewService(cli helmcli.Client) Service {
+	return Service{cli}
+}
@@ -0,0 +1,171 @@
+package rollback
+
+import (
+	"context"
+	"errors"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+)
+
+const testReleaseName = "test-release-name"
+
+var errNewRollbackerError = errors.New("new rollbacker error")
+
+// To satisfy the client interface, we have to define all methods(NewUpgrade, NewInstaller) on the mock struct
+// TODO: Find a way to isolate interface only for upgrade.
+type mockHelmClient struct{ mock.Mock }
+
+func (m *mockHelmClient) NewUpgrader(fl flags.UpgradeFlags) (helmcli.Upgrader, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Upgrader), args.Error(1)
+}
+
+func (m *mockHelmClient) NewInstaller(fl flags.InstallFlags) (helmcli.Installer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Installer), args.Error(1)
+}
+
+func (m *mockHelmClient) NewLister(fl flags.ListFlags) (helmcli.Lister, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Lister), args.Error(1)
+}
+
+func (m *mockHelmClient) NewUninstaller(fl flags.UninstallFlags) (helmcli.Uninstaller, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Uninstaller), args.Error(1)
+}
+
+func (m *mockHelmClient) NewStatusGiver(fl flags.StatusFlags) (helmcli.StatusGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.StatusGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRollbacker(fl flags.RollbackFlags) (helmcli.Rollbacker, error) {
+	args := m.Called(fl)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(helmcli.Rollbacker), args.Error(1)
+}
+
+func (m *mockHelmClient) NewHistoryGiver(fl flags.HistoryFlags) (helmcli.HistoryGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.HistoryGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewValuesGiver(fl flags.GetValuesFlags) (helmcli.ValuesGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.ValuesGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTemplater(fl flags.TemplateFlags) (helmcli.Templater, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Templater), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTester(fl flags.TestFlags) (helmcli.Tester, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Tester), args.Error(1)
+}
+
+func (m *mockHelmClient) NewShower(fl flags.ShowFlags) (helmcli.Shower, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Shower), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRecoverer(fl flags.RecoverFlags) (helmcli.Recoverer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Recoverer), args.Error(1)
+}
+
+type mockRollbacker struct{ mock.Mock }
+
+func (m *mockRollbacker) Rollback(ctx context.Context, releaseName string) (*release.Release, error) {
+	args := m.Called(ctx, releaseName)
+	if len(args) < 1 {
+		log.Fatalf("error while mocking response for rollback")
+	}
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*release.Release), args.Error(1)
+}
+
+func TestShouldReturnValidResponseOnSuccess(t *testing.T) {
+	cli := new(mockHelmClient)
+	rbc := new(mockRollbacker)
+	service := NewService(cli)
+	ctx := context.Background()
+	globalFlags := flags.GlobalFlags{KubeContext: "minikube", Namespace: "default"}
+	req := Request{name: testReleaseName, Version: 1, Wait: true, Timeout: 10, GlobalFlags: globalFlags}
+	rollbackFlags := flags.RollbackFlags{
+		Version:     1,
+		Wait:        true,
+		Timeout:     10 * time.Second,
+		GlobalFlags: globalFlags,
+	}
+	mockRelease := release.Mock(&release.MockReleaseOptions{
+		Name:      testReleaseName,
+		Version:   3,
+		Namespace: "default",
+		Status:    release.StatusDeployed,
+	})
+	cli.On("NewRollbacker", rollbackFlags).Times(1).Return(rbc, nil)
+	rbc.On("Rollback", mock.Anything, testReleaseName).Times(1).Return(mockRelease, nil)
+
+	resp, err := service.Rollback(ctx, req)
+
+	require.NoError(t, err)
+	require.NotNil(t, resp.Release)
+	assert.Equal(t, release.StatusDeployed.String(), resp.Status)
+	rel := resp.Release
+	assert.Equal(t, mockRelease.Name, rel.Name)
+	assert.Equal(t, mockRelease.Namespace, rel.Namespace)
+	assert.Equal(t, mockRelease.Version, rel.Version)
+	assert.Equal(t, mockRelease.Info.Status, rel.Status)
+	assert.Equal(t, mockRelease.Chart.ChartFullPath(), rel.Chart)
+	assert.Equal(t, mockRelease.Chart.AppVersion(), rel.AppVersion)
+	cli.AssertExpectations(t)
+	rbc.AssertExpectations(t)
+}
+
+func TestShouldUseDefaultTimeoutWhenNotSet(t *testing.T) {
+	cli := new(mockHelmClient)
+	service := NewService(cli)
+	req := Request{name: testReleaseName}
+	rollbackFlags := flags.RollbackFlags{Timeout: defaultTimeout}
+	cli.On("NewRollbacker", rollbackFlags).Times(1).Return(nil, errNewRollbackerError)
+
+	resp, err := service.Rollback(context.Background(), req)
+
+	assert.True(t, errors.Is(err, errNewRollbackerError))
+	assert.Nil(t, resp.Release)
+	cli.AssertExpectations(t)
+}
+
+func TestShouldReturnErrorWhenReleaseIsNotFound(t *testing.T) {
+	cli := new(mockHelmClient)
+	rbc := new(mockRollbacker)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{name: testReleaseName}
+	cli.On("NewRollbacker", mock.AnythingOfType("flags.RollbackFlags")).Times(1).Return(rbc, nil)
+	rbc.On("Rollback", mock.Anything, testReleaseName).Times(1).Return(nil, driver.ErrReleaseNotFound)
+
+	resp, err := service.Rollback(ctx, req)
+
+	assert.True(t, errors.Is(err, driver.ErrReleaseNotFound))
+	assert.Nil(t, resp.Release)
+	cli.AssertExpectations(t)
+	rbc.AssertExpectations(t)
+}
@@ -0,0 +1,341 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// ValuesRequest is the request for the values of a release
+type ValuesRequest struct {
+	AllValues bool `schema:"all"`
+	Request
+}
+
+// ValuesResponse is the response of a successful values request
+// swagger:model valuesOkResponse
+type ValuesResponse struct {
+	// example: {"replicaCount": 1}
+	Values map[string]interface{} `json:"values"`
+}
+
+// ManifestResponse is the response of a successful manifest request
+// swagger:model manifestOkResponse
+type ManifestResponse struct {
+	Manifest string `json:"manifest"`
+}
+
+// NotesResponse is the response of a successful notes request
+// swagger:model notesOkResponse
+type NotesResponse struct {
+	Notes string `json:"notes"`
+}
+
+// Hook wraps a helm release hook
+// swagger:model releaseHook
+type Hook struct {
+	// example: mysql-test
+	Name string `json:"name"`
+	// example: Pod
+	Kind string `json:"kind"`
+	// example: mysql/templates/tests/test-connection.yaml
+	Path     string `json:"path"`
+	Manifest string `json:"manifest"`
+	// example: ["test"]
+	Events []string `json:"events"`
+	// example: 0
+	Weight int `json:"weight"`
+	// example: ["before-hook-creation"]
+	DeletePolicies []string `json:"delete_policies,omitempty"`
+	// example: Succeeded
+	LastRunPhase string `json:"last_run_phase,omitempty"`
+	// Unset for hooks that never ran
+	// example: 2021-03-24T12:24:18.450869+05:30
+	LastRunStartedAt *time.Time `json:"last_run_started_at,omitempty"`
+	// example: 2021-03-24T12:24:28.450869+05:30
+	LastRunCompletedAt *time.Time `json:"last_run_completed_at,omitempty"`
+}
+
+// HooksResponse is the response of a successful hooks request
+// swagger:model hooksOkResponse
+type HooksResponse struct {
+	Hooks []Hook `json:"hooks"`
+}
+
+type contentService interface {
+	Values(ctx context.Context, req ValuesRequest) (*ValuesResponse, error)
+	Manifest(ctx context.Context, req Request) (*ManifestResponse, error)
+	Notes(ctx context.Context, req Request) (*NotesResponse, error)
+	Hooks(ctx context.Context, req Request) (*HooksResponse, error)
+}
+
+// ValuesHandler handles a release values request
+// swagger:operation GET /clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/values release valuesOperation
+//
+//
+// ---
+// summary: Get the values a helm release was deployed with
+// produces:
+// - application/json
+// parameters:
+// - name: cluster
+//   in: path
+//   required: true
+//   default: minikube
+//   type: string
+//   format: string
+// - name: namespace
+//   in: path
+//   required: true
+//   default: default
+//   type: string
+//   format: string
+// - name: release_name
+//   in: path
+//   required: true
+//   default: mysql
+//   type: string
+//   format: string
+// - name: revision
+//   in: query
+//   type: number
+// - name: all
+//   in: query
+//   type: boolean
+//   default: false
+//   description: return the computed values instead of only the user supplied ones
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/valuesOkResponse"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func ValuesHandler(s contentService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		var req ValuesRequest
+		if err := decoder.Decode(&req, r.URL.Query()); err != nil {
+			logger.Errorf("[Values] error decoding request: %v", err.Error())
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+		populateRequest(&req.Request, mux.Vars(r))
+		resp, err := s.Values(r.Context(), req)
+		respondContent(w, "[Values]", resp, err)
+	})
+}
+
+// ManifestHandler handles a release manifest request
+// swagger:operation GET /clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/manifest release manifestOperation
+//
+//
+// ---
+// summary: Get the rendered manifest of a helm release
+// produces:
+// - application/json
+// parameters:
+// - name: cluster
+//   in: path
+//   required: true
+//   default: minikube
+//   type: string
+//   format: string
+// - name: namespace
+//   in: path
+//   required: true
+//   default: default
+//   type: string
+//   format: string
+// - name: release_name
+//   in: path
+//   required: true
+//   default: mysql
+//   type: string
+//   format: string
+// - name: revision
+//   in: query
+//   type: number
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/manifestOkResponse"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func ManifestHandler(s contentService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		req, ok := decodeRequest(w, r, "[Manifest]")
+		if !ok {
+			return
+		}
+		resp, err := s.Manifest(r.Context(), req)
+		respondContent(w, "[Manifest]", resp, err)
+	})
+}
+
+// NotesHandler handles a release notes request
+// swagger:operation GET /clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/notes release notesOperation
+//
+//
+// ---
+// summary: Get the rendered notes of a helm release
+// produces:
+// - application/json
+// parameters:
+// - name: cluster
+//   in: path
+//   required: true
+//   default: minikube
+//   type: string
+//   format: string
+// - name: namespace
+//   in: path
+//   required: true
+//   default: default
+//   type: string
+//   format: string
+// - name: release_name
+//   in: path
+//   required: true
+//   default: mysql
+//   type: string
+//   format: string
+// - name: revision
+//   in: query
+//   type: number
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/notesOkResponse"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func NotesHandler(s contentService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		req, ok := decodeRequest(w, r, "[Notes]")
+		if !ok {
+			return
+		}
+		resp, err := s.Notes(r.Context(), req)
+		respondContent(w, "[Notes]", resp, err)
+	})
+}
+
+// HooksHandler handles a release hooks request
+// swagger:operation GET /clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/hooks release hooksOperation
+//
+//
+// ---
+// summary: Get the hooks of a helm release
+// produces:
+// - application/json
+// parameters:
+// - name: cluster
+//   in: path
+//   required: true
+//   default: minikube
+//   type: string
+//   format: string
+// - name: namespace
+//   in: path
+//   required: true
+//   default: default
+//   type: string
+//   format: string
+// - name: release_name
+//   in: path
+//   required: true
+//   default: mysql
+//   type: string
+//   format: string
+// - name: revision
+//   in: query
+//   type: number
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/hooksOkResponse"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func HooksHandler(s contentService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+		req, ok := decodeRequest(w, r, "[Hooks]")
+		if !ok {
+			return
+		}
+		resp, err := s.Hooks(r.Context(), req)
+		respondContent(w, "[Hooks]", resp, err)
+	})
+}
+
+func decodeRequest(w http.ResponseWriter, r *http.Request, logprefix string) (Request, bool) {
+	var req Request
+	if err := decoder.Decode(&req, r.URL.Query()); err != nil {
+		logger.Errorf("%s error decoding request: %v", logprefix, err.Error())
+		apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
+		return req, false
+	}
+	populateRequest(&req, mux.Vars(r))
+	return req, true
+}
+
+func populateRequest(req *Request, values map[string]string) {
+	req.KubeContext = values["cluster"]
+	req.Namespace = values["namespace"]
+	req.name = values["release_name"]
+}
+
+func respondContent(w http.ResponseWriter, logprefix string, resp interface{}, err error) {
+	if err != nil {
+		logger.Errorf("%s error while fetching release: %v", logprefix, err)
+		apiErrors.Write(w, err)
+		return
+	}
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		logger.Errorf("%s error writing response: %v", logprefix, err)
+	}
+}
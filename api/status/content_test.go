@@ -0,0 +1,174 @@
+package status
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	"gotest.tools/assert"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+type mockContentService struct {
+	mock.Mock
+}
+
+func (m *mockContentService) Values(ctx context.Context, req ValuesRequest) (*ValuesResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) != nil {
+		return args.Get(0).(*ValuesResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockContentService) Manifest(ctx context.Context, req Request) (*ManifestResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) != nil {
+		return args.Get(0).(*ManifestResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockContentService) Notes(ctx context.Context, req Request) (*NotesResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) != nil {
+		return args.Get(0).(*NotesResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+func (m *mockContentService) Hooks(ctx context.Context, req Request) (*HooksResponse, error) {
+	args := m.Called(ctx, req)
+	if args.Get(0) != nil {
+		return args.Get(0).(*HooksResponse), args.Error(1)
+	}
+	return nil, args.Error(1)
+}
+
+type ContentTestSuite struct {
+	suite.Suite
+	server      *httptest.Server
+	mockService *mockContentService
+}
+
+func (s *ContentTestSuite) SetupSuite() {
+	logger.Setup("default")
+}
+
+func (s *ContentTestSuite) SetupTest() {
+	s.mockService = new(mockContentService)
+	router := mux.NewRouter()
+	base := "/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}"
+	router.Handle(base+"/values", ValuesHandler(s.mockService)).Methods(http.MethodGet)
+	router.Handle(base+"/manifest", ManifestHandler(s.mockService)).Methods(http.MethodGet)
+	router.Handle(base+"/notes", NotesHandler(s.mockService)).Methods(http.MethodGet)
+	router.Handle(base+"/hooks", HooksHandler(s.mockService)).Methods(http.MethodGet)
+	s.server = httptest.NewServer(router)
+}
+
+func (s *ContentTestSuite) expectedRequest(version int) Request {
+	return Request{
+		name:    "mysql-test",
+		Version: version,
+		GlobalFlags: flags.GlobalFlags{
+			KubeContext: "staging",
+			Namespace:   "test",
+		},
+	}
+}
+
+func (s *ContentTestSuite) TestShouldReturnValuesOfRevision() {
+	url := fmt.Sprintf("%s/clusters/staging/namespaces/test/releases/mysql-test/values?revision=2&all=true", s.server.URL)
+	expected := ValuesRequest{AllValues: true, Request: s.expectedRequest(2)}
+	response := &ValuesResponse{Values: map[string]interface{}{"replicaCount": float64(2)}}
+	s.mockService.On("Values", mock.Anything, expected).Return(response, nil)
+
+	res, err := http.Get(url)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, res.StatusCode)
+
+	var actual ValuesResponse
+	require.NoError(s.T(), json.NewDecoder(res.Body).Decode(&actual))
+	assert.DeepEqual(s.T(), *response, actual)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *ContentTestSuite) TestShouldReturnManifestOfRevision() {
+	url := fmt.Sprintf("%s/clusters/staging/namespaces/test/releases/mysql-test/manifest?revision=1", s.server.URL)
+	response := &ManifestResponse{Manifest: "kind: Secret"}
+	s.mockService.On("Manifest", mock.Anything, s.expectedRequest(1)).Return(response, nil)
+
+	res, err := http.Get(url)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, res.StatusCode)
+
+	var actual ManifestResponse
+	require.NoError(s.T(), json.NewDecoder(res.Body).Decode(&actual))
+	assert.Equal(s.T(), *response, actual)
+}
+
+func (s *ContentTestSuite) TestShouldReturnNotesOfLatestRevision() {
+	url := fmt.Sprintf("%s/clusters/staging/namespaces/test/releases/mysql-test/notes", s.server.URL)
+	response := &NotesResponse{Notes: "Thank you for installing"}
+	s.mockService.On("Notes", mock.Anything, s.expectedRequest(0)).Return(response, nil)
+
+	res, err := http.Get(url)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, res.StatusCode)
+
+	var actual NotesResponse
+	require.NoError(s.T(), json.NewDecoder(res.Body).Decode(&actual))
+	assert.Equal(s.T(), *response, actual)
+}
+
+func (s *ContentTestSuite) TestShouldReturnNotFoundIfReleaseDoesNotExist() {
+	url := fmt.Sprintf("%s/clusters/staging/namespaces/test/releases/mysql-test/hooks", s.server.URL)
+	s.mockService.On("Hooks", mock.Anything, s.expectedRequest(0)).Return(nil, driver.ErrReleaseNotFound)
+
+	res, err := http.Get(url)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusNotFound, res.StatusCode)
+}
+
+func (s *ContentTestSuite) TestShouldReturnInternalServerErrorIfServiceFails() {
+	url := fmt.Sprintf("%s/clusters/staging/namespaces/test/releases/mysql-test/hooks", s.server.URL)
+	s.mockService.On("Hooks", mock.Anything, s.expectedRequest(0)).Return(nil, errors.New("test error"))
+
+	res, err := http.Get(url)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusInternalServerError, res.StatusCode)
+
+	var actual apiErrors.Body
+	require.NoError(s.T(), json.NewDecoder(res.Body).Decode(&actual))
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Internal, Message: "test error"}, actual)
+}
+
+func (s *ContentTestSuite) TestShouldReturnBadRequestForInvalidRevision() {
+	url := fmt.Sprintf("%s/clusters/staging/namespaces/test/releases/mysql-test/values?revision=latest", s.server.URL)
+
+	res, err := http.Get(url)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, res.StatusCode)
+}
+
+func (s *ContentTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func TestReleaseContentAPI(t *testing.T) {
+	suite.Run(t, new(ContentTestSuite))
+}
@@ -2,16 +2,24 @@ package status
 
 import (
 	"context"
+	"time"
+
+	"helm.sh/helm/v3/pkg/release"
+	helmtime "helm.sh/helm/v3/pkg/time"
 
 	"github.com/gojekfarm/albatross/pkg/helmcli"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/tracing"
 )
 
 type Service struct {
 	cli helmcli.Client
 }
 
-func (s Service) Status(ctx context.Context, req Request) (*Release, error) {
+func (s Service) Status(ctx context.Context, req Request) (_ *Release, err error) {
+	ctx, span := tracing.Start(ctx, "status.Status")
+	defer func() { tracing.End(span, err) }()
+
 	flg := flags.StatusFlags{
 		Version:     req.Version,
 		GlobalFlags: req.GlobalFlags,
@@ -38,6 +46,119 @@ func (s Service) Status(ctx context.Context, req Request) (*Release, error) {
 	}, err
 }
 
+// Values returns the user supplied or computed values of a release.
+func (s Service) Values(ctx context.Context, req ValuesRequest) (_ *ValuesResponse, err error) {
+	ctx, span := tracing.Start(ctx, "status.Values")
+	defer func() { tracing.End(span, err) }()
+
+	flg := flags.GetValuesFlags{
+		Version:     req.Version,
+		AllValues:   req.AllValues,
+		GlobalFlags: req.GlobalFlags,
+	}
+
+	valuesGiver, err := s.cli.NewValuesGiver(flg)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := valuesGiver.Values(ctx, req.name)
+	if err != nil {
+		return nil, err
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	return &ValuesResponse{Values: values}, nil
+}
+
+// Manifest returns the rendered manifest of a release.
+func (s Service) Manifest(ctx context.Context, req Request) (_ *ManifestResponse, err error) {
+	ctx, span := tracing.Start(ctx, "status.Manifest")
+	defer func() { tracing.End(span, err) }()
+
+	rel, err := s.release(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	return &ManifestResponse{Manifest: rel.Manifest}, nil
+}
+
+// Notes returns the rendered notes of a release.
+func (s Service) Notes(ctx context.Context, req Request) (_ *NotesResponse, err error) {
+	ctx, span := tracing.Start(ctx, "status.Notes")
+	defer func() { tracing.End(span, err) }()
+
+	rel, err := s.release(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp := &NotesResponse{}
+	if rel.Info != nil {
+		resp.Notes = rel.Info.Notes
+	}
+	return resp, nil
+}
+
+// Hooks returns the hooks of a release along with their last run.
+func (s Service) Hooks(ctx context.Context, req Request) (_ *HooksResponse, err error) {
+	ctx, span := tracing.Start(ctx, "status.Hooks")
+	defer func() { tracing.End(span, err) }()
+
+	rel, err := s.release(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	hooks := []Hook{}
+	for _, h := range rel.Hooks {
+		hooks = append(hooks, hookInfo(h))
+	}
+	return &HooksResponse{Hooks: hooks}, nil
+}
+
+// release fetches the whole release, the status action returns the release content for the requested revision.
+func (s Service) release(ctx context.Context, req Request) (*release.Release, error) {
+	flg := flags.StatusFlags{
+		Version:     req.Version,
+		GlobalFlags: req.GlobalFlags,
+	}
+
+	statusGiver, err := s.cli.NewStatusGiver(flg)
+	if err != nil {
+		return nil, err
+	}
+
+	return statusGiver.Status(ctx, req.name)
+}
+
+func hookInfo(h *release.Hook) Hook {
+	hook := Hook{
+		Name:               h.Name,
+		Kind:               h.Kind,
+		Path:               h.Path,
+		Manifest:           h.Manifest,
+		Weight:             h.Weight,
+		LastRunPhase:       h.LastRun.Phase.String(),
+		LastRunStartedAt:   runTime(h.LastRun.StartedAt),
+		LastRunCompletedAt: runTime(h.LastRun.CompletedAt),
+	}
+	for _, event := range h.Events {
+		hook.Events = append(hook.Events, event.String())
+	}
+	for _, policy := range h.DeletePolicies {
+		hook.DeletePolicies = append(hook.DeletePolicies, policy.String())
+	}
+	return hook
+}
+
+// runTime is nil for the zero times of hooks that never ran
+func runTime(t helmtime.Time) *time.Time {
+	if t.IsZero() {
+		return nil
+	}
+	return &t.Time
+}
+
 func NewService(cli helmcli.Client) Service {
 	return Service{cli}
 }
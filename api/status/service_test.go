@@ -45,6 +45,41 @@ func (m *mockHelmClient) NewUninstaller(fl flags.UninstallFlags) (helmcli.Uninst
 	return args.Get(0).(helmcli.Uninstaller), args.Error(1)
 }
 
+func (m *mockHelmClient) NewRollbacker(fl flags.RollbackFlags) (helmcli.Rollbacker, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Rollbacker), args.Error(1)
+}
+
+func (m *mockHelmClient) NewHistoryGiver(fl flags.HistoryFlags) (helmcli.HistoryGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.HistoryGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewValuesGiver(fl flags.GetValuesFlags) (helmcli.ValuesGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.ValuesGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTemplater(fl flags.TemplateFlags) (helmcli.Templater, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Templater), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTester(fl flags.TestFlags) (helmcli.Tester, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Tester), args.Error(1)
+}
+
+func (m *mockHelmClient) NewShower(fl flags.ShowFlags) (helmcli.Shower, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Shower), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRecoverer(fl flags.RecoverFlags) (helmcli.Recoverer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Recoverer), args.Error(1)
+}
+
 type mockStatusGiver struct{ mock.Mock }
 
 func (m *mockStatusGiver) Status(ctx context.Context, releaseName string) (*release.Release, error) {
@@ -93,7 +128,7 @@ func TestShouldReturnValidResponseOnSuccess(t *testing.T) {
 		Chart: chart,
 	}
 
-	sic.On("Status", ctx, "test-release").Return(&releases, nil).Once()
+	sic.On("Status", mock.Anything, "test-release").Return(&releases, nil).Once()
 
 	resp, err := service.Status(ctx, req)
 
@@ -109,3 +144,91 @@ func TestShouldReturnValidResponseOnSuccess(t *testing.T) {
 	cli.AssertExpectations(t)
 	sic.AssertExpectations(t)
 }
+
+type mockValuesGiver struct{ mock.Mock }
+
+func (m *mockValuesGiver) Values(ctx context.Context, releaseName string) (map[string]interface{}, error) {
+	args := m.Called(ctx, releaseName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(map[string]interface{}), args.Error(1)
+}
+
+func TestValuesShouldReturnValuesOfRelease(t *testing.T) {
+	cli := new(mockHelmClient)
+	vgc := new(mockValuesGiver)
+	service := NewService(cli)
+	ctx := context.Background()
+	globalFlags := flags.GlobalFlags{KubeContext: "abc", Namespace: "test"}
+	req := ValuesRequest{AllValues: true, Request: Request{name: "test-release", Version: 2, GlobalFlags: globalFlags}}
+	valuesFlags := flags.GetValuesFlags{Version: 2, AllValues: true, GlobalFlags: globalFlags}
+	values := map[string]interface{}{"replicaCount": 1}
+	cli.On("NewValuesGiver", valuesFlags).Return(vgc, nil).Once()
+	vgc.On("Values", mock.Anything, "test-release").Return(values, nil).Once()
+
+	resp, err := service.Values(ctx, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, values, resp.Values)
+	cli.AssertExpectations(t)
+	vgc.AssertExpectations(t)
+}
+
+func TestValuesShouldReturnEmptyValuesWhenNoneWereSupplied(t *testing.T) {
+	cli := new(mockHelmClient)
+	vgc := new(mockValuesGiver)
+	service := NewService(cli)
+	ctx := context.Background()
+	cli.On("NewValuesGiver", flags.GetValuesFlags{}).Return(vgc, nil).Once()
+	vgc.On("Values", mock.Anything, "test-release").Return(nil, nil).Once()
+
+	resp, err := service.Values(ctx, ValuesRequest{Request: Request{name: "test-release"}})
+
+	require.NoError(t, err)
+	assert.NotNil(t, resp.Values)
+	assert.Empty(t, resp.Values)
+}
+
+func TestReleaseContentShouldBeReadFromTheRequestedRevision(t *testing.T) {
+	cli := new(mockHelmClient)
+	sic := new(mockStatusGiver)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{name: "test-release", Version: 1}
+	rel := release.Mock(&release.MockReleaseOptions{Name: "test-release", Version: 1, Status: release.StatusDeployed})
+	cli.On("NewStatusGiver", flags.StatusFlags{Version: 1}).Return(sic, nil)
+	sic.On("Status", mock.Anything, "test-release").Return(rel, nil)
+
+	manifest, err := service.Manifest(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, rel.Manifest, manifest.Manifest)
+
+	notes, err := service.Notes(ctx, req)
+	require.NoError(t, err)
+	assert.Equal(t, rel.Info.Notes, notes.Notes)
+
+	hooks, err := service.Hooks(ctx, req)
+	require.NoError(t, err)
+	require.Len(t, hooks.Hooks, len(rel.Hooks))
+	hook := hooks.Hooks[0]
+	assert.Equal(t, rel.Hooks[0].Name, hook.Name)
+	assert.Equal(t, rel.Hooks[0].Kind, hook.Kind)
+	assert.Equal(t, rel.Hooks[0].Path, hook.Path)
+	assert.Equal(t, rel.Hooks[0].Manifest, hook.Manifest)
+	assert.Equal(t, []string{"pre-install"}, hook.Events)
+	assert.Nil(t, hook.LastRunStartedAt)
+	assert.Nil(t, hook.LastRunCompletedAt)
+	cli.AssertExpectations(t)
+	sic.AssertExpectations(t)
+}
+
+func TestHookInfoShouldKeepTheTimesOfTheLastRun(t *testing.T) {
+	started := time.Now()
+	hook := hookInfo(&release.Hook{Name: "mysql-test", LastRun: release.HookExecution{StartedAt: started, Phase: release.HookPhaseRunning}})
+
+	require.NotNil(t, hook.LastRunStartedAt)
+	assert.True(t, started.Time.Equal(*hook.LastRunStartedAt))
+	assert.Nil(t, hook.LastRunCompletedAt)
+	assert.Equal(t, "Running", hook.LastRunPhase)
+}
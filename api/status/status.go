@@ -6,6 +6,7 @@ import (
 	"net/http"
 	"time"
 
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
 	"github.com/gojekfarm/albatross/pkg/logger"
 
@@ -14,8 +15,6 @@ import (
 	"helm.sh/helm/v3/pkg/release"
 )
 
-const releaseNotFound = "release: not found"
-
 var decoder = schema.NewDecoder()
 
 type Request struct {
@@ -24,12 +23,6 @@ type Request struct {
 	flags.GlobalFlags
 }
 
-// ErrorResponse is the body of /list
-// swagger:model statusErrorResponse
-type ErrorResponse struct {
-	Error string `json:"error"`
-}
-
 // Release is the response of a successful status request
 //swagger:model statusOkResponse
 type Release struct {
@@ -91,19 +84,20 @@ type service interface {
 //     $ref: "#/definitions/statusOkResponse"
 //   '400':
 //    schema:
-//     $ref: "#/definitions/statusErrorResponse"
+//     $ref: "#/definitions/errorResponse"
 //   '404':
-//    description: Release not found
+//    schema:
+//     $ref: "#/definitions/errorResponse"
 //   '500':
 //    schema:
-//     $ref: "#/definitions/statusErrorResponse"
+//     $ref: "#/definitions/errorResponse"
 func Handler(s service) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer r.Body.Close()
 		var req Request
 		if err := decoder.Decode(&req, r.URL.Query()); err != nil {
 			logger.Errorf("[Status] error decoding request: %v", err.Error())
-			w.WriteHeader(http.StatusBadRequest)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
 			return
 		}
 		values := mux.Vars(r)
@@ -112,26 +106,13 @@ func Handler(s service) http.Handler {
 		req.name = values["release_name"]
 		rel, err := s.Status(r.Context(), req)
 		if err != nil {
-			if err.Error() == releaseNotFound {
-				w.WriteHeader(http.StatusNotFound)
-				return
-			}
-			respondStatusError(w, "error while listing charts: %v", err, http.StatusInternalServerError)
+			logger.Errorf("[Status] error while fetching release: %v", err)
+			apiErrors.Write(w, err)
 			return
 		}
 
 		if err = json.NewEncoder(w).Encode(rel); err != nil {
-			respondStatusError(w, "error writing response: %v", err, http.StatusInternalServerError)
-			return
+			logger.Errorf("[Status] error writing response: %v", err)
 		}
 	})
 }
-
-func respondStatusError(w http.ResponseWriter, msg string, err error, statusCode int) {
-	response := ErrorResponse{Error: err.Error()}
-	w.WriteHeader(statusCode)
-	if err := json.NewEncoder(w).Encode(&response); err != nil {
-		logger.Errorf("[Status] %s %v", msg, err)
-		return
-	}
-}
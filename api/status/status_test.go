@@ -16,10 +16,12 @@ import (
 	"github.com/stretchr/testify/suite"
 	"gotest.tools/assert"
 
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
 	"github.com/gojekfarm/albatross/pkg/logger"
 
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
 type mockService struct {
@@ -108,12 +110,15 @@ func (s *TestSuite) TestShouldReturnNotFoundIfReleaseDoesNotExist() {
 			Namespace:   "test",
 		},
 	}
-	s.mockService.On("Status", mock.Anything, expectedRequestStruct).Return(nil, errors.New(releaseNotFound))
+	s.mockService.On("Status", mock.Anything, expectedRequestStruct).Return(nil, fmt.Errorf("error getting status: %w", driver.ErrReleaseNotFound))
 	res, err := http.DefaultClient.Do(req)
 	require.NoError(s.T(), err)
 
 	assert.Equal(s.T(), 404, res.StatusCode)
-	require.NoError(s.T(), err)
+	var actualResponse apiErrors.Body
+	require.NoError(s.T(), json.NewDecoder(res.Body).Decode(&actualResponse))
+	assert.Equal(s.T(), apiErrors.NotFound, actualResponse.Code)
+	assert.Equal(s.T(), "error getting status: release: not found", actualResponse.Message)
 }
 
 func (s *TestSuite) TestShouldReturnInternalServerErrorIfListServiceReturnsError() {
@@ -133,13 +138,10 @@ func (s *TestSuite) TestShouldReturnInternalServerErrorIfListServiceReturnsError
 	res, err := http.DefaultClient.Do(req)
 	assert.Equal(s.T(), 500, res.StatusCode)
 	require.NoError(s.T(), err)
-	var actualResponse ErrorResponse
+	var actualResponse apiErrors.Body
 	err = json.NewDecoder(res.Body).Decode(&actualResponse)
 	require.NoError(s.T(), err)
-	expectedResponse := ErrorResponse{
-		Error: errorMsg,
-	}
-	assert.Equal(s.T(), expectedResponse.Error, actualResponse.Error)
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Internal, Message: errorMsg}, actualResponse)
 	s.mockService.AssertExpectations(s.T())
 }
 
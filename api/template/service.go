@@ -0,0 +1,63 @@
+package template
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+type Service struct {
+	cli helmcli.Client
+}
+
+// Template renders a chart without installing it.
+func (s Service) Template(ctx context.Context, req Request) (_ Response, err error) {
+	ctx, span := tracing.Start(ctx, "template.Template")
+	defer func() { tracing.End(span, err) }()
+
+	templateFlags := flags.TemplateFlags{
+		Version:     req.Flags.Version,
+		Namespace:   req.Flags.Namespace,
+		IncludeCRDs: req.Flags.IncludeCRDs,
+		IsUpgrade:   req.Flags.IsUpgrade,
+		APIVersions: req.Flags.APIVersions,
+	}
+	tcli, err := s.cli.NewTemplater(templateFlags)
+	if err != nil {
+		return Response{}, fmt.Errorf("error while initializing the templater: %w", err)
+	}
+
+	rel, err := tcli.Template(ctx, req.Name, req.Chart, req.Values)
+	if err != nil {
+		return Response{}, err
+	}
+
+	resp := Response{Manifest: manifest(rel, req.Flags.DisableHooks)}
+	if rel.Info != nil {
+		resp.Notes = rel.Info.Notes
+	}
+	return resp, nil
+}
+
+// manifest joins the rendered resources and hooks the way helm template prints them.
+func manifest(rel *release.Release, disableHooks bool) string {
+	var b strings.Builder
+	fmt.Fprintln(&b, strings.TrimSpace(rel.Manifest))
+	if disableHooks {
+		return b.String()
+	}
+	for _, h := range rel.Hooks {
+		fmt.Fprintf(&b, "---\n# Source: %s\n%s\n", h.Path, h.Manifest)
+	}
+	return b.String()
+}
+
+func NewService(cli helmcli.Client) Service {
+	return Service{cli}
+}
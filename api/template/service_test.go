@@ -0,0 +1,160 @@
+package template
+
+import (
+	"context"
+	"errors"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+)
+
+// To satisfy the client interface, we have to define all methods(NewUpgrade, NewInstaller) on the mock struct
+// TODO: Find a way to isolate interface only for upgrade.
+type mockHelmClient struct{ mock.Mock }
+
+func (m *mockHelmClient) NewUpgrader(fl flags.UpgradeFlags) (helmcli.Upgrader, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Upgrader), args.Error(1)
+}
+
+func (m *mockHelmClient) NewInstaller(fl flags.InstallFlags) (helmcli.Installer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Installer), args.Error(1)
+}
+
+func (m *mockHelmClient) NewLister(fl flags.ListFlags) (helmcli.Lister, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Lister), args.Error(1)
+}
+
+func (m *mockHelmClient) NewStatusGiver(fl flags.StatusFlags) (helmcli.StatusGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.StatusGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewUninstaller(fl flags.UninstallFlags) (helmcli.Uninstaller, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Uninstaller), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRollbacker(fl flags.RollbackFlags) (helmcli.Rollbacker, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Rollbacker), args.Error(1)
+}
+
+func (m *mockHelmClient) NewHistoryGiver(fl flags.HistoryFlags) (helmcli.HistoryGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.HistoryGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewValuesGiver(fl flags.GetValuesFlags) (helmcli.ValuesGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.ValuesGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTemplater(fl flags.TemplateFlags) (helmcli.Templater, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Templater), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTester(fl flags.TestFlags) (helmcli.Tester, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Tester), args.Error(1)
+}
+
+func (m *mockHelmClient) NewShower(fl flags.ShowFlags) (helmcli.Shower, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Shower), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRecoverer(fl flags.RecoverFlags) (helmcli.Recoverer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Recoverer), args.Error(1)
+}
+
+type mockTemplater struct{ mock.Mock }
+
+func (m *mockTemplater) Template(ctx context.Context, relName, chart string, values map[string]interface{}) (*release.Release, error) {
+	args := m.Called(ctx, relName, chart, values)
+	if len(args) < 2 {
+		log.Fatalf("error while mocking response for template")
+	}
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*release.Release), args.Error(1)
+}
+
+func TestShouldReturnManifestWithHooksOnSuccess(t *testing.T) {
+	cli := new(mockHelmClient)
+	tc := new(mockTemplater)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{
+		Name:   "test-release",
+		Chart:  "stable/mysql",
+		Values: map[string]interface{}{"replicaCount": 1},
+		Flags:  Flags{Version: "1.6.9", Namespace: "test", IncludeCRDs: true},
+	}
+	templateFlags := flags.TemplateFlags{Version: "1.6.9", Namespace: "test", IncludeCRDs: true}
+	rel := &release.Release{
+		Name:     "test-release",
+		Manifest: "---\n# Source: mysql/templates/secret.yaml\nkind: Secret\n",
+		Hooks: []*release.Hook{
+			{Path: "mysql/templates/tests/test.yaml", Manifest: "kind: Pod"},
+		},
+		Info: &release.Info{Notes: "some notes"},
+	}
+	cli.On("NewTemplater", templateFlags).Return(tc, nil).Once()
+	tc.On("Template", mock.Anything, req.Name, req.Chart, req.Values).Return(rel, nil).Once()
+
+	resp, err := service.Template(ctx, req)
+
+	require.NoError(t, err)
+	expected := "---\n# Source: mysql/templates/secret.yaml\nkind: Secret\n---\n# Source: mysql/templates/tests/test.yaml\nkind: Pod\n"
+	assert.Equal(t, expected, resp.Manifest)
+	assert.Equal(t, "some notes", resp.Notes)
+	cli.AssertExpectations(t)
+	tc.AssertExpectations(t)
+}
+
+func TestShouldSkipHooksWhenDisabled(t *testing.T) {
+	cli := new(mockHelmClient)
+	tc := new(mockTemplater)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{Chart: "stable/mysql", Flags: Flags{DisableHooks: true}}
+	rel := &release.Release{
+		Manifest: "kind: Secret",
+		Hooks:    []*release.Hook{{Path: "mysql/templates/tests/test.yaml", Manifest: "kind: Pod"}},
+	}
+	cli.On("NewTemplater", flags.TemplateFlags{}).Return(tc, nil).Once()
+	tc.On("Template", mock.Anything, "", req.Chart, req.Values).Return(rel, nil).Once()
+
+	resp, err := service.Template(ctx, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, "kind: Secret\n", resp.Manifest)
+}
+
+func TestShouldReturnErrorOnInvalidChart(t *testing.T) {
+	cli := new(mockHelmClient)
+	tc := new(mockTemplater)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{Chart: "stable/invalid_chart"}
+	cli.On("NewTemplater", flags.TemplateFlags{}).Return(tc, nil).Once()
+	tc.On("Template", mock.Anything, "", req.Chart, req.Values).Return(nil, errors.New("failed to download invalid-chart")).Once()
+
+	resp, err := service.Template(ctx, req)
+
+	assert.EqualError(t, err, "failed to download invalid-chart")
+	assert.Empty(t, resp.Manifest)
+}
@@ -0,0 +1,133 @@
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"helm.sh/helm/v3/pkg/action"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+const releaseNameMaxLen = 53
+
+// Request is the body for rendering a chart
+// swagger:model templateRequestBody
+type Request struct {
+	// Release name used while rendering, defaults to release-name
+	// example: mysql-final
+	Name string `json:"name"`
+	// example: stable/mysql
+	Chart string `json:"chart"`
+	// example: {"replicaCount": 1}
+	Values map[string]interface{} `json:"values"`
+	Flags  Flags                  `json:"flags"`
+}
+
+// Flags additional flags for rendering a chart
+// swagger:model templateFlags
+type Flags struct {
+	// example: 1.6.9
+	Version string `json:"version"`
+	// example: default
+	Namespace string `json:"namespace"`
+	// example: false
+	IncludeCRDs bool `json:"include_crds"`
+	// example: false
+	IsUpgrade bool `json:"is_upgrade"`
+	// example: false
+	DisableHooks bool `json:"disable_hooks"`
+	// Additional kubernetes api versions used for capabilities checks
+	// example: ["monitoring.coreos.com/v1"]
+	APIVersions []string `json:"api_versions"`
+}
+
+// Response body of template response
+// swagger:model templateResponseBody
+type Response struct {
+	// Rendered manifests including hooks, in the format of helm template
+	Manifest string `json:"manifest,omitempty"`
+	Notes    string `json:"notes,omitempty"`
+}
+
+type service interface {
+	Template(ctx context.Context, req Request) (Response, error)
+}
+
+// Handler handles a template request
+// swagger:operation POST /charts/template chart templateOperation
+//
+// Renders a chart locally without connecting to any cluster, like helm template.
+// ---
+// summary: Render the manifests of a chart
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// parameters:
+// - name: Body
+//   in: body
+//   required: true
+//   schema:
+//    "$ref": "#/definitions/templateRequestBody"
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/templateResponseBody"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func Handler(s service) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		var req Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			logger.Errorf("[Template] error decoding request: %v", err)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+		if err := req.valid(); err != nil {
+			logger.Errorf("[Template] error in request parameters: %v", err)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+
+		resp, err := s.Template(r.Context(), req)
+		if err != nil {
+			logger.Errorf("[Template] error while rendering chart: %v", err)
+			apiErrors.Write(w, err)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(&resp); err != nil {
+			logger.Errorf("[Template] error writing response: %v", err)
+		}
+	})
+}
+
+func (req Request) valid() error {
+	if req.Chart == "" {
+		return fmt.Errorf("chart cannot be empty string")
+	}
+	switch releaseName := req.Name; {
+	case releaseName == "":
+		return nil
+	case !action.ValidName.MatchString(releaseName):
+		return fmt.Errorf("release name %s must match regex %s", releaseName, action.ValidName.String())
+	case len(releaseName) > releaseNameMaxLen:
+		return fmt.Errorf("release name %s exceeds max length of %d", releaseName, releaseNameMaxLen)
+	}
+	return nil
+}
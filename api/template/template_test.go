@@ -0,0 +1,115 @@
+package template
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+type mockService struct {
+	mock.Mock
+}
+
+func (m *mockService) Template(ctx context.Context, req Request) (Response, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(Response), args.Error(1)
+}
+
+type TemplateTestSuite struct {
+	suite.Suite
+	server      *httptest.Server
+	mockService *mockService
+}
+
+func (s *TemplateTestSuite) SetupSuite() {
+	logger.Setup("default")
+}
+
+func (s *TemplateTestSuite) SetupTest() {
+	s.mockService = new(mockService)
+	router := mux.NewRouter()
+	router.Handle("/charts/template", Handler(s.mockService)).Methods(http.MethodPost)
+	s.server = httptest.NewServer(router)
+}
+
+func (s *TemplateTestSuite) TestShouldReturnManifestOnSuccess() {
+	body := `{"name":"redis-v5", "chart":"stable/redis-ha", "values": {"usePassword": false},
+		"flags": {"version": "4.4.2", "namespace": "cache", "api_versions": ["monitoring.coreos.com/v1"]}}`
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/charts/template", s.server.URL), strings.NewReader(body))
+	requestStruct := Request{
+		Name:   "redis-v5",
+		Chart:  "stable/redis-ha",
+		Values: map[string]interface{}{"usePassword": false},
+		Flags: Flags{
+			Version:     "4.4.2",
+			Namespace:   "cache",
+			APIVersions: []string{"monitoring.coreos.com/v1"},
+		},
+	}
+	response := Response{Manifest: "kind: Secret\n", Notes: "notes"}
+	s.mockService.On("Template", mock.Anything, requestStruct).Return(response, nil)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
+
+	var actual Response
+	require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&actual))
+	assert.Equal(s.T(), response, actual)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *TemplateTestSuite) TestShouldReturnBadRequestWhenChartIsMissing() {
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/charts/template", s.server.URL), strings.NewReader(`{"name":"redis-v5"}`))
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+
+	var actual apiErrors.Body
+	require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&actual))
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Invalid, Message: "chart cannot be empty string"}, actual)
+	s.mockService.AssertNotCalled(s.T(), "Template")
+}
+
+func (s *TemplateTestSuite) TestShouldReturnBadRequestForInvalidReleaseName() {
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/charts/template", s.server.URL), strings.NewReader(`{"name":"redis-v5-", "chart":"stable/redis-ha"}`))
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+	s.mockService.AssertNotCalled(s.T(), "Template")
+}
+
+func (s *TemplateTestSuite) TestShouldReturnInternalServerErrorOnFailure() {
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/charts/template", s.server.URL), strings.NewReader(`{"chart":"stable/redis-ha"}`))
+	s.mockService.On("Template", mock.Anything, Request{Chart: "stable/redis-ha"}).Return(Response{}, errors.New("invalid chart"))
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusInternalServerError, resp.StatusCode)
+}
+
+func (s *TemplateTestSuite) TearDownTest() {
+	s.server.Close()
+}
+
+func TestTemplateAPI(t *testing.T) {
+	suite.Run(t, new(TemplateTestSuite))
+}
@@ -5,8 +5,10 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/gojekfarm/albatross/pkg/audit"
 	"github.com/gojekfarm/albatross/pkg/helmcli"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/tracing"
 
 	"helm.sh/helm/v3/pkg/release"
 )
@@ -18,7 +20,10 @@ type Service struct {
 }
 
 // Uninstall a release according to the request provided and fails if req is incorrect.
-func (s Service) Uninstall(ctx context.Context, req Request) (Response, error) {
+func (s Service) Uninstall(ctx context.Context, req Request) (_ Response, err error) {
+	ctx, span := tracing.Start(ctx, "uninstall.Uninstall")
+	defer func() { tracing.End(span, err) }()
+
 	var timeout time.Duration
 	if req.Timeout < 1 {
 		timeout = defaultTimeout
@@ -38,6 +43,9 @@ func (s Service) Uninstall(ctx context.Context, req Request) (Response, error) {
 		return Response{}, fmt.Errorf("error while initializing uninstaller: %w", err)
 	}
 	resp, err := u.Uninstall(ctx, req.releaseName)
+	if resp != nil {
+		audit.SetRelease(ctx, resp.Release)
+	}
 	if err != nil {
 		if resp != nil {
 			return responseWithStatus(resp.Release), err
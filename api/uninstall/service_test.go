@@ -56,6 +56,41 @@ func (m *mockHelmClient) NewStatusGiver(fl flags.StatusFlags) (helmcli.StatusGiv
 	return args.Get(0).(helmcli.StatusGiver), args.Error(1)
 }
 
+func (m *mockHelmClient) NewRollbacker(fl flags.RollbackFlags) (helmcli.Rollbacker, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Rollbacker), args.Error(1)
+}
+
+func (m *mockHelmClient) NewHistoryGiver(fl flags.HistoryFlags) (helmcli.HistoryGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.HistoryGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewValuesGiver(fl flags.GetValuesFlags) (helmcli.ValuesGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.ValuesGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTemplater(fl flags.TemplateFlags) (helmcli.Templater, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Templater), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTester(fl flags.TestFlags) (helmcli.Tester, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Tester), args.Error(1)
+}
+
+func (m *mockHelmClient) NewShower(fl flags.ShowFlags) (helmcli.Shower, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Shower), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRecoverer(fl flags.RecoverFlags) (helmcli.Recoverer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Recoverer), args.Error(1)
+}
+
 type mockUninstaller struct{ mock.Mock }
 
 func (m *mockUninstaller) Uninstall(ctx context.Context, releaseName string) (*release.UninstallReleaseResponse, error) {
@@ -89,7 +124,7 @@ func TestShouldReturnValidResponseOnSuccess(t *testing.T) {
 	mockRelease := release.Mock(releaseOptions)
 	uiResponse := release.UninstallReleaseResponse{Release: mockRelease}
 	cli.On("NewUninstaller", uninstallFlags).Times(1).Return(uic, nil)
-	uic.On("Uninstall", ctx, testReleaseName).Times(1).Return(&uiResponse, nil)
+	uic.On("Uninstall", mock.Anything, testReleaseName).Times(1).Return(&uiResponse, nil)
 
 	resp, err := service.Uninstall(ctx, req)
 
@@ -97,7 +132,6 @@ func TestShouldReturnValidResponseOnSuccess(t *testing.T) {
 	require.NotNil(t, resp)
 	require.NotNil(t, resp.Release)
 	assert.NotEmpty(t, resp.Status)
-	assert.Empty(t, resp.Error)
 	rel := resp.Release
 	assert.Equal(t, rel.Name, mockRelease.Name)
 	assert.Equal(t, rel.Namespace, mockRelease.Namespace)
@@ -106,7 +140,6 @@ func TestShouldReturnValidResponseOnSuccess(t *testing.T) {
 	assert.Equal(t, rel.Chart, mockRelease.Chart.ChartFullPath())
 	assert.Equal(t, rel.Updated, mockRelease.Info.FirstDeployed.Local().Time)
 	assert.Equal(t, rel.AppVersion, mockRelease.Chart.AppVersion())
-	assert.Empty(t, resp.Error)
 	cli.AssertExpectations(t)
 	uic.AssertExpectations(t)
 }
@@ -140,7 +173,7 @@ func TestShouldReturnResponseAndProperErrorWhenReleaseIsNotFound(t *testing.T) {
 	req := Request{releaseName: testReleaseName, GlobalFlags: globalFlag}
 	uninstallFlags := flags.UninstallFlags{Release: testReleaseName, GlobalFlags: globalFlag, Timeout: defaultTimeout}
 	cli.On("NewUninstaller", uninstallFlags).Times(1).Return(uic, nil)
-	uic.On("Uninstall", ctx, testReleaseName).Times(1).Return(nil, driver.ErrReleaseNotFound)
+	uic.On("Uninstall", mock.Anything, testReleaseName).Times(1).Return(nil, driver.ErrReleaseNotFound)
 
 	resp, err := service.Uninstall(ctx, req)
 
@@ -160,7 +193,7 @@ func TestShouldReturnResponseAndProperErrorWhenUninstallActionFails(t *testing.T
 	req := Request{releaseName: testReleaseName, KeepHistory: true, DryRun: true, DisableHooks: true}
 	uninstallFlags := flags.UninstallFlags{Release: testReleaseName, KeepHistory: true, DryRun: true, DisableHooks: true, Timeout: defaultTimeout}
 	cli.On("NewUninstaller", uninstallFlags).Times(1).Return(uic, nil)
-	uic.On("Uninstall", ctx, testReleaseName).Times(1).Return(&release.UninstallReleaseResponse{}, errUninstallActionError)
+	uic.On("Uninstall", mock.Anything, testReleaseName).Times(1).Return(&release.UninstallReleaseResponse{}, errUninstallActionError)
 
 	resp, err := service.Uninstall(ctx, req)
 
@@ -4,10 +4,10 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
-	"io"
 	"net/http"
 	"time"
 
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
 	"github.com/gojekfarm/albatross/pkg/logger"
 
@@ -15,7 +15,6 @@ import (
 	"github.com/gorilla/schema"
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/release"
-	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
 var (
@@ -55,8 +54,6 @@ type Release struct {
 // Response is the body of uninstall route
 // swagger:model uninstallResponseBody
 type Response struct {
-	// Error error message, field is available only when status code is non 2xx
-	Error string `json:"error,omitempty"`
 	// Status status of the release, field is available only when status code is 2xx
 	// example: uninstalled
 	Status string `json:"status,omitempty"`
@@ -111,19 +108,31 @@ type service interface {
 //   in: query
 //   type: integer
 //   default: 300
+// - name: async
+//   in: query
+//   type: boolean
+//   default: false
+//   description: run the action in the background and return the operation to poll
 // schemes:
 // - http
 // responses:
 //   '200':
 //    "$ref": "#/responses/uninstallResponse"
+//   '202':
+//    schema:
+//     $ref: "#/definitions/operation"
 //   '400':
 //    schema:
-//     $ref: "#/definitions/uninstallErrorResponse"
+//     $ref: "#/definitions/errorResponse"
 //   '404':
 //    schema:
-//     $ref: "#/definitions/uninstallErrorResponse"
+//     $ref: "#/definitions/errorResponse"
+//   '409':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
 //   '500':
-//    "$ref": "#/responses/uninstallResponse"
+//    schema:
+//     $ref: "#/definitions/errorResponse"
 func Handler(s service) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer r.Body.Close()
@@ -131,8 +140,7 @@ func Handler(s service) http.Handler {
 		var req Request
 		if err := decoder.Decode(&req, r.URL.Query()); err != nil {
 			logger.Errorf("[Uninstall] error decoding request: %v", err)
-			w.WriteHeader(http.StatusBadRequest)
-			respondWithUninstallError(w, "", errUnableToDecodeRequest)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, errUnableToDecodeRequest))
 			return
 		}
 		values := mux.Vars(r)
@@ -141,32 +149,19 @@ func Handler(s service) http.Handler {
 		req.GlobalFlags.Namespace = values["namespace"]
 		if err := req.valid(); err != nil {
 			logger.Errorf("[Uninstall] error in request parameters: %v", err)
-			w.WriteHeader(http.StatusBadRequest)
-			respondWithUninstallError(w, "", err)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
 			return
 		}
 
 		resp, err := s.Uninstall(r.Context(), req)
 		if err != nil {
-			if errors.Is(err, driver.ErrReleaseNotFound) {
-				logger.Errorf("[Uninstall] no release found for %v", req.releaseName)
-				w.WriteHeader(http.StatusNotFound)
-			} else {
-				logger.Errorf("[Uninstall] unexpected error occurred: %v", err)
-				w.WriteHeader(http.StatusInternalServerError)
-			}
-			resp.Error = err.Error()
-			err := json.NewEncoder(w).Encode(&resp)
-			if err != nil {
-				logger.Errorf("[Uninstall] Error writing response", err)
-			}
+			logger.Errorf("[Uninstall] error uninstalling %s: %v", req.releaseName, err)
+			apiErrors.Write(w, apiErrors.WithReleaseStatus(err, resp.Status))
 			return
 		}
 
 		if err := json.NewEncoder(w).Encode(&resp); err != nil {
-			w.WriteHeader(http.StatusInternalServerError)
-			respondWithUninstallError(w, "error writing response: %v", err)
-			return
+			logger.Errorf("[Uninstall] error writing response: %v", err)
 		}
 	})
 }
@@ -180,11 +175,3 @@ func (req Request) valid() error {
 	}
 	return nil
 }
-
-func respondWithUninstallError(w io.Writer, logPrefix string, err error) {
-	response := Response{Error: err.Error()}
-	if err := json.NewEncoder(w).Encode(&response); err != nil {
-		logger.Errorf("[Uninstall] %s %v", logPrefix, err)
-		return
-	}
-}
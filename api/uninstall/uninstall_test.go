@@ -9,6 +9,7 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
 	"github.com/gojekfarm/albatross/pkg/logger"
 
@@ -104,7 +105,9 @@ func (s *UninstallTestSuite) TestShouldReturnNotFoundErrorIfItHasUnavailableRele
 
 	require.NoError(s.T(), err)
 	assert.Equal(s.T(), 404, res.StatusCode)
-	require.NoError(s.T(), err)
+	var actualResponse apiErrors.Body
+	require.NoError(s.T(), json.NewDecoder(res.Body).Decode(&actualResponse))
+	assert.Equal(s.T(), apiErrors.NotFound, actualResponse.Code)
 	s.mockService.AssertExpectations(s.T())
 }
 
@@ -136,10 +139,10 @@ func (s *UninstallTestSuite) TestShouldReturnInternalServerErrorIfUninstallThrow
 	assert.Equal(s.T(), 500, res.StatusCode)
 	require.NoError(s.T(), err)
 
-	var actualResponse Response
+	var actualResponse apiErrors.Body
 	err = json.NewDecoder(res.Body).Decode(&actualResponse)
 	require.NoError(s.T(), err)
-	assert.Equal(s.T(), errMsg, actualResponse.Error)
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Internal, Message: errMsg}, actualResponse)
 	s.mockService.AssertExpectations(s.T())
 }
 
@@ -151,10 +154,10 @@ func (s *UninstallTestSuite) TestShouldReturnBadRequestErrorIfItHasInvalidReleas
 
 	require.NoError(s.T(), err)
 	require.NotNil(s.T(), res)
-	var actualResponse Response
+	var actualResponse apiErrors.Body
 	err = json.NewDecoder(res.Body).Decode(&actualResponse)
 	require.NoError(s.T(), err)
-	assert.Equal(s.T(), actualResponse.Error, errInvalidReleaseName.Error())
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Invalid, Message: errInvalidReleaseName.Error()}, actualResponse)
 	assert.Equal(s.T(), 400, res.StatusCode)
 	require.NoError(s.T(), err)
 	s.mockService.AssertExpectations(s.T())
@@ -0,0 +1,116 @@
+package upgrade
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/diff"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+// Change is the difference of a single kubernetes resource between the deployed and the proposed release
+// swagger:model upgradeDiffChange
+type Change struct {
+	// example: apps/v1
+	APIVersion string `json:"api_version"`
+	// example: Deployment
+	Kind string `json:"kind"`
+	// example: default
+	Namespace string `json:"namespace"`
+	// example: mysql
+	Name string `json:"name"`
+	// one of added, removed or changed
+	// example: changed
+	Change diff.ChangeType `json:"change"`
+	// unified diff of the resource manifest
+	Diff string `json:"diff"`
+}
+
+// DiffResponse represents the api response for a diff request.
+// swagger:model upgradeDiffResponseBody
+type DiffResponse struct {
+	Changes []Change `json:"changes"`
+}
+
+type diffService interface {
+	Diff(ctx context.Context, req Request) (DiffResponse, error)
+}
+
+// DiffHandler handles a diff request for a proposed upgrade
+// swagger:operation POST /clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/diff release diffOperation
+//
+//
+// ---
+// summary: Show the changes an upgrade would make to the deployed release without applying them
+// consumes:
+// - application/json
+// produces:
+// - application/json
+// parameters:
+// - name: cluster
+//   in: path
+//   required: true
+//   default: minikube
+//   type: string
+//   format: string
+// - name: namespace
+//   in: path
+//   required: true
+//   default: default
+//   type: string
+//   format: string
+// - name: release_name
+//   in: path
+//   required: true
+//   type: string
+//   format: string
+//   default: mysql-final
+// - name: Body
+//   in: body
+//   required: true
+//   schema:
+//    "$ref": "#/definitions/upgradeRequestBody"
+// schemes:
+// - http
+// responses:
+//   '200':
+//    schema:
+//     $ref: "#/definitions/upgradeDiffResponseBody"
+//   '400':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '500':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+func DiffHandler(service diffService) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer r.Body.Close()
+
+		req, err := decodeRequest(r)
+		if err != nil {
+			logger.Errorf("[Diff] error decoding request: %v", err)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+		if err := req.valid(); err != nil {
+			logger.Errorf("[Diff] error in request parameters: %v", err)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+		resp, err := service.Diff(r.Context(), req)
+		if err != nil {
+			logger.Errorf("[Diff] error while computing diff: %v", err)
+			apiErrors.Write(w, err)
+			return
+		}
+
+		if err := json.NewEncoder(w).Encode(&resp); err != nil {
+			logger.Errorf("[Diff] error writing response: %v", err)
+		}
+	})
+}
@@ -2,32 +2,38 @@ package upgrade
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"time"
 
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
 
+	"github.com/gojekfarm/albatross/pkg/audit"
+	"github.com/gojekfarm/albatross/pkg/diff"
 	"github.com/gojekfarm/albatross/pkg/helmcli"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/tracing"
+	"github.com/gojekfarm/albatross/pkg/upload"
 )
 
+const defaultTimeout = 300 * time.Second
+
 type Service struct {
 	cli helmcli.Client
 }
 
-func (s Service) Upgrade(ctx context.Context, req Request) (Response, error) {
-	upgradeflags := flags.UpgradeFlags{
-		DryRun:      req.Flags.DryRun,
-		Version:     req.Flags.Version,
-		Install:     req.Flags.Install,
-		GlobalFlags: req.Flags.GlobalFlags,
-	}
+func (s Service) Upgrade(ctx context.Context, req Request) (_ Response, err error) {
+	ctx, span := tracing.Start(ctx, "upgrade.Upgrade")
+	defer func() { tracing.End(span, err) }()
 
-	ucli, err := s.cli.NewUpgrader(upgradeflags)
+	ucli, err := s.cli.NewUpgrader(upgradeFlags(req))
 	if err != nil {
-		return Response{}, fmt.Errorf("error while initializing upgrader: %s", err)
+		return Response{}, fmt.Errorf("error while initializing upgrader: %w", err)
 	}
 
-	rel, err := ucli.Upgrade(ctx, req.name, req.Chart, req.Values)
+	rel, err := upgrade(ctx, ucli, req)
+	audit.SetRelease(ctx, rel)
 	if err != nil {
 		return responseWithStatus(rel), err
 	}
@@ -38,6 +44,106 @@ func (s Service) Upgrade(ctx context.Context, req Request) (Response, error) {
 	return resp, nil
 }
 
+// Diff renders the upgrade as a dry run and compares it with the deployed manifest.
+// A missing release is compared against an empty manifest when install is set.
+func (s Service) Diff(ctx context.Context, req Request) (_ DiffResponse, err error) {
+	ctx, span := tracing.Start(ctx, "upgrade.Diff")
+	defer func() { tracing.End(span, err) }()
+
+	current, err := s.deployedManifest(ctx, req)
+	if err != nil {
+		return DiffResponse{}, err
+	}
+
+	upgradeflags := upgradeFlags(req)
+	upgradeflags.DryRun = true
+	ucli, err := s.cli.NewUpgrader(upgradeflags)
+	if err != nil {
+		return DiffResponse{}, fmt.Errorf("error while initializing upgrader: %w", err)
+	}
+	rel, err := upgrade(ctx, ucli, req)
+	if err != nil {
+		return DiffResponse{}, err
+	}
+
+	changes, err := diff.Manifests(current, rel.Manifest, req.Flags.Namespace)
+	if err != nil {
+		return DiffResponse{}, err
+	}
+	resp := DiffResponse{Changes: []Change{}}
+	for _, c := range changes {
+		resp.Changes = append(resp.Changes, Change{
+			APIVersion: c.APIVersion,
+			Kind:       c.Kind,
+			Namespace:  c.Namespace,
+			Name:       c.Name,
+			Change:     c.Type,
+			Diff:       c.Diff,
+		})
+	}
+	return resp, nil
+}
+
+// upgrade upgrades to the uploaded chart archive of the request, or else to its named chart
+func upgrade(ctx context.Context, ucli helmcli.Upgrader, req Request) (*release.Release, error) {
+	if len(req.ChartArchive) == 0 {
+		return ucli.Upgrade(ctx, req.name, req.Chart, req.Values)
+	}
+	ch, err := upload.Load(req.ChartArchive)
+	if err != nil {
+		return nil, err
+	}
+	return ucli.UpgradeChart(ctx, req.name, ch, req.Values)
+}
+
+func upgradeFlags(req Request) flags.UpgradeFlags {
+	timeout := defaultTimeout
+	if req.Flags.Timeout > 0 {
+		timeout = time.Second * time.Duration(req.Flags.Timeout)
+	}
+	return flags.UpgradeFlags{
+		DryRun:          req.Flags.DryRun,
+		Version:         req.Flags.Version,
+		Install:         req.Flags.Install,
+		Wait:            req.Flags.Wait,
+		WaitForJobs:     req.Flags.WaitForJobs,
+		Timeout:         timeout,
+		Atomic:          req.Flags.Atomic,
+		CleanupOnFail:   req.Flags.CleanupOnFail,
+		Force:           req.Flags.Force,
+		ResetValues:     req.Flags.ResetValues,
+		ReuseValues:     req.Flags.ReuseValues,
+		DisableHooks:    req.Flags.DisableHooks,
+		SkipCRDs:        req.Flags.SkipCRDs,
+		CreateNamespace: req.Flags.CreateNamespace,
+		Description:     req.Flags.Description,
+		Verify:          req.Flags.Verify,
+		GlobalFlags:     req.Flags.GlobalFlags,
+	}
+}
+
+// deployedManifest is the manifest of the last deployed revision, the latest one may be failed or pending
+// and not what is running. Releases without a deployed revision are compared against an empty manifest.
+func (s Service) deployedManifest(ctx context.Context, req Request) (string, error) {
+	hcli, err := s.cli.NewHistoryGiver(flags.HistoryFlags{GlobalFlags: req.Flags.GlobalFlags})
+	if err != nil {
+		return "", fmt.Errorf("error while initializing history giver: %w", err)
+	}
+	revisions, err := hcli.History(ctx, req.name)
+	if errors.Is(err, driver.ErrReleaseNotFound) && req.Flags.Install {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	for i := len(revisions) - 1; i >= 0; i-- {
+		if revisions[i].Info != nil && revisions[i].Info.Status == release.StatusDeployed {
+			return revisions[i].Manifest, nil
+		}
+	}
+	return "", nil
+}
+
 func releaseInfo(rel *release.Release) Release {
 	return Release{
 		Name:       rel.Name,
@@ -3,16 +3,22 @@ package upgrade
 import (
 	"context"
 	"errors"
+	"io/ioutil"
 	"log"
+	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
 	"helm.sh/helm/v3/pkg/time"
 
+	"github.com/gojekfarm/albatross/pkg/diff"
 	"github.com/gojekfarm/albatross/pkg/helmcli"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
 )
@@ -46,6 +52,41 @@ func (m *mockHelmClient) NewStatusGiver(fl flags.StatusFlags) (helmcli.StatusGiv
 	return args.Get(0).(helmcli.StatusGiver), args.Error(1)
 }
 
+func (m *mockHelmClient) NewRollbacker(fl flags.RollbackFlags) (helmcli.Rollbacker, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Rollbacker), args.Error(1)
+}
+
+func (m *mockHelmClient) NewHistoryGiver(fl flags.HistoryFlags) (helmcli.HistoryGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.HistoryGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewValuesGiver(fl flags.GetValuesFlags) (helmcli.ValuesGiver, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.ValuesGiver), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTemplater(fl flags.TemplateFlags) (helmcli.Templater, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Templater), args.Error(1)
+}
+
+func (m *mockHelmClient) NewTester(fl flags.TestFlags) (helmcli.Tester, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Tester), args.Error(1)
+}
+
+func (m *mockHelmClient) NewShower(fl flags.ShowFlags) (helmcli.Shower, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Shower), args.Error(1)
+}
+
+func (m *mockHelmClient) NewRecoverer(fl flags.RecoverFlags) (helmcli.Recoverer, error) {
+	args := m.Called(fl)
+	return args.Get(0).(helmcli.Recoverer), args.Error(1)
+}
+
 type mockUpgrader struct{ mock.Mock }
 
 func (m *mockUpgrader) Upgrade(ctx context.Context, relName, chart string, values map[string]interface{}) (*release.Release, error) {
@@ -56,6 +97,25 @@ func (m *mockUpgrader) Upgrade(ctx context.Context, relName, chart string, value
 	return args.Get(0).(*release.Release), args.Error(1)
 }
 
+func (m *mockUpgrader) UpgradeChart(ctx context.Context, relName string, ch *chart.Chart, values map[string]interface{}) (*release.Release, error) {
+	args := m.Called(ctx, relName, ch, values)
+	return args.Get(0).(*release.Release), args.Error(1)
+}
+
+type mockHistoryGiver struct{ mock.Mock }
+
+func (m *mockHistoryGiver) History(ctx context.Context, releaseName string) ([]*release.Release, error) {
+	args := m.Called(ctx, releaseName)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*release.Release), args.Error(1)
+}
+
+func revision(version int, status release.Status, manifest string) *release.Release {
+	return &release.Release{Version: version, Info: &release.Info{Status: status}, Manifest: manifest}
+}
+
 func TestShouldReturnErrorOnInvalidChart(t *testing.T) {
 	cli := new(mockHelmClient)
 	upgc := new(mockUpgrader)
@@ -64,13 +124,12 @@ func TestShouldReturnErrorOnInvalidChart(t *testing.T) {
 	req := Request{name: "invalid_release", Chart: "stable/invalid_chart"}
 	cli.On("NewUpgrader", mock.AnythingOfType("flags.UpgradeFlags")).Return(upgc, nil)
 	rel := &release.Release{Info: &release.Info{Status: release.StatusFailed}}
-	upgc.On("Upgrade", ctx, req.name, req.Chart, req.Values).Return(rel, errors.New("failed to download invalid-chart"))
+	upgc.On("Upgrade", mock.Anything, req.name, req.Chart, req.Values).Return(rel, errors.New("failed to download invalid-chart"))
 
 	resp, err := service.Upgrade(ctx, req)
 
 	assert.EqualError(t, err, "failed to download invalid-chart")
 	require.NotNil(t, resp)
-	assert.Empty(t, resp.Error)
 	assert.Equal(t, "failed", resp.Status)
 	cli.AssertExpectations(t)
 	upgc.AssertExpectations(t)
@@ -104,7 +163,7 @@ func TestShouldReturnValidResponseOnSuccess(t *testing.T) {
 		Chart: chart,
 	}
 
-	upgc.On("Upgrade", ctx, req.name, req.Chart, req.Values).Return(rel, nil)
+	upgc.On("Upgrade", mock.Anything, req.name, req.Chart, req.Values).Return(rel, nil)
 
 	resp, err := service.Upgrade(ctx, req)
 
@@ -117,7 +176,222 @@ func TestShouldReturnValidResponseOnSuccess(t *testing.T) {
 	assert.Equal(t, resp.Chart, rel.Chart.ChartFullPath())
 	assert.Equal(t, resp.Updated, rel.Info.FirstDeployed.Local().Time)
 	assert.Equal(t, resp.AppVersion, rel.Chart.AppVersion())
-	assert.Empty(t, resp.Error)
 	cli.AssertExpectations(t)
 	upgc.AssertExpectations(t)
 }
+
+func TestShouldPassDeployOptionsToUpgrader(t *testing.T) {
+	cli := new(mockHelmClient)
+	upgc := new(mockUpgrader)
+	service := NewService(cli)
+	ctx := context.Background()
+	globalFlags := flags.GlobalFlags{KubeContext: "minikube", Namespace: "test-namespace"}
+	req := Request{name: "test-release", Chart: "stable/albatross", Flags: Flags{
+		Install:         true,
+		Wait:            true,
+		WaitForJobs:     true,
+		Timeout:         600,
+		Atomic:          true,
+		CleanupOnFail:   true,
+		Force:           true,
+		ReuseValues:     true,
+		DisableHooks:    true,
+		SkipCRDs:        true,
+		CreateNamespace: true,
+		Description:     "upgraded by albatross",
+		GlobalFlags:     globalFlags,
+	}}
+	upgradeFlags := flags.UpgradeFlags{
+		Install:         true,
+		Wait:            true,
+		WaitForJobs:     true,
+		Timeout:         2 * defaultTimeout,
+		Atomic:          true,
+		CleanupOnFail:   true,
+		Force:           true,
+		ReuseValues:     true,
+		DisableHooks:    true,
+		SkipCRDs:        true,
+		CreateNamespace: true,
+		Description:     "upgraded by albatross",
+		GlobalFlags:     globalFlags,
+	}
+	rel := &release.Release{Info: &release.Info{Status: release.StatusFailed}}
+	cli.On("NewUpgrader", upgradeFlags).Return(upgc, nil).Once()
+	upgc.On("Upgrade", mock.Anything, req.name, req.Chart, req.Values).Return(rel, errors.New("timed out waiting for the condition")).Once()
+
+	_, err := service.Upgrade(ctx, req)
+
+	assert.EqualError(t, err, "timed out waiting for the condition")
+	cli.AssertExpectations(t)
+}
+
+const deployedManifest = `---
+# Source: albatross/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test-release-config
+data:
+  author: "gojek"
+`
+
+const proposedManifest = `---
+# Source: albatross/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: test-release-config
+data:
+  author: "gojekfarm"
+`
+
+func TestDiffShouldCompareDryRunWithDeployedManifest(t *testing.T) {
+	cli := new(mockHelmClient)
+	upgc := new(mockUpgrader)
+	hg := new(mockHistoryGiver)
+	service := NewService(cli)
+	ctx := context.Background()
+	globalFlags := flags.GlobalFlags{KubeContext: "minikube", Namespace: "test-namespace"}
+	req := Request{name: "test-release", Chart: "stable/albatross", Flags: Flags{Version: "0.1.0", GlobalFlags: globalFlags}}
+	cli.On("NewHistoryGiver", flags.HistoryFlags{GlobalFlags: globalFlags}).Return(hg, nil).Once()
+	cli.On("NewUpgrader", flags.UpgradeFlags{DryRun: true, Version: "0.1.0", Timeout: defaultTimeout, GlobalFlags: globalFlags}).Return(upgc, nil).Once()
+	hg.On("History", mock.Anything, "test-release").Return([]*release.Release{revision(1, release.StatusDeployed, deployedManifest)}, nil).Once()
+	upgc.On("Upgrade", mock.Anything, req.name, req.Chart, req.Values).Return(&release.Release{Manifest: proposedManifest}, nil).Once()
+
+	resp, err := service.Diff(ctx, req)
+
+	require.NoError(t, err)
+	require.Len(t, resp.Changes, 1)
+	change := resp.Changes[0]
+	assert.Equal(t, "v1", change.APIVersion)
+	assert.Equal(t, "ConfigMap", change.Kind)
+	assert.Equal(t, "test-namespace", change.Namespace)
+	assert.Equal(t, "test-release-config", change.Name)
+	assert.Equal(t, diff.Changed, change.Change)
+	assert.Contains(t, change.Diff, "+  author: \"gojekfarm\"\n")
+	cli.AssertExpectations(t)
+	upgc.AssertExpectations(t)
+	hg.AssertExpectations(t)
+}
+
+func TestDiffShouldCompareWithTheLastDeployedRevision(t *testing.T) {
+	cli := new(mockHelmClient)
+	upgc := new(mockUpgrader)
+	hg := new(mockHistoryGiver)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{name: "test-release", Chart: "stable/albatross"}
+	cli.On("NewHistoryGiver", flags.HistoryFlags{}).Return(hg, nil).Once()
+	cli.On("NewUpgrader", flags.UpgradeFlags{DryRun: true, Timeout: defaultTimeout}).Return(upgc, nil).Once()
+	hg.On("History", mock.Anything, "test-release").Return([]*release.Release{
+		revision(1, release.StatusSuperseded, ""),
+		revision(2, release.StatusDeployed, deployedManifest),
+		revision(3, release.StatusFailed, proposedManifest),
+	}, nil).Once()
+	upgc.On("Upgrade", mock.Anything, req.name, req.Chart, req.Values).Return(&release.Release{Manifest: proposedManifest}, nil).Once()
+
+	resp, err := service.Diff(ctx, req)
+
+	require.NoError(t, err)
+	require.Len(t, resp.Changes, 1)
+	assert.Equal(t, diff.Changed, resp.Changes[0].Change)
+	assert.Contains(t, resp.Changes[0].Diff, "-  author: \"gojek\"\n")
+}
+
+func TestDiffShouldReportAllResourcesAddedWithoutDeployedRevision(t *testing.T) {
+	cli := new(mockHelmClient)
+	upgc := new(mockUpgrader)
+	hg := new(mockHistoryGiver)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{name: "test-release", Chart: "stable/albatross"}
+	cli.On("NewHistoryGiver", flags.HistoryFlags{}).Return(hg, nil).Once()
+	cli.On("NewUpgrader", flags.UpgradeFlags{DryRun: true, Timeout: defaultTimeout}).Return(upgc, nil).Once()
+	hg.On("History", mock.Anything, "test-release").Return([]*release.Release{revision(1, release.StatusFailed, deployedManifest)}, nil).Once()
+	upgc.On("Upgrade", mock.Anything, req.name, req.Chart, req.Values).Return(&release.Release{Manifest: proposedManifest}, nil).Once()
+
+	resp, err := service.Diff(ctx, req)
+
+	require.NoError(t, err)
+	require.Len(t, resp.Changes, 1)
+	assert.Equal(t, diff.Added, resp.Changes[0].Change)
+}
+
+func TestDiffShouldReportAllResourcesAddedWhenInstallingMissingRelease(t *testing.T) {
+	cli := new(mockHelmClient)
+	upgc := new(mockUpgrader)
+	hg := new(mockHistoryGiver)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{name: "test-release", Chart: "stable/albatross", Flags: Flags{Install: true}}
+	cli.On("NewHistoryGiver", flags.HistoryFlags{}).Return(hg, nil).Once()
+	cli.On("NewUpgrader", flags.UpgradeFlags{DryRun: true, Install: true, Timeout: defaultTimeout}).Return(upgc, nil).Once()
+	hg.On("History", mock.Anything, "test-release").Return(nil, driver.ErrReleaseNotFound).Once()
+	upgc.On("Upgrade", mock.Anything, req.name, req.Chart, req.Values).Return(&release.Release{Manifest: proposedManifest}, nil).Once()
+
+	resp, err := service.Diff(ctx, req)
+
+	require.NoError(t, err)
+	require.Len(t, resp.Changes, 1)
+	assert.Equal(t, diff.Added, resp.Changes[0].Change)
+}
+
+func TestDiffShouldReturnErrorWhenReleaseIsNotFound(t *testing.T) {
+	cli := new(mockHelmClient)
+	hg := new(mockHistoryGiver)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{name: "test-release", Chart: "stable/albatross"}
+	cli.On("NewHistoryGiver", flags.HistoryFlags{}).Return(hg, nil).Once()
+	hg.On("History", mock.Anything, "test-release").Return(nil, driver.ErrReleaseNotFound).Once()
+
+	_, err := service.Diff(ctx, req)
+
+	assert.True(t, errors.Is(err, driver.ErrReleaseNotFound))
+	cli.AssertNotCalled(t, "NewUpgrader", mock.Anything)
+}
+
+func TestDiffShouldReturnErrorWhenDryRunFails(t *testing.T) {
+	cli := new(mockHelmClient)
+	upgc := new(mockUpgrader)
+	hg := new(mockHistoryGiver)
+	service := NewService(cli)
+	ctx := context.Background()
+	req := Request{name: "test-release", Chart: "stable/invalid_chart"}
+	cli.On("NewHistoryGiver", flags.HistoryFlags{}).Return(hg, nil).Once()
+	cli.On("NewUpgrader", flags.UpgradeFlags{DryRun: true, Timeout: defaultTimeout}).Return(upgc, nil).Once()
+	hg.On("History", mock.Anything, "test-release").Return([]*release.Release{revision(1, release.StatusDeployed, deployedManifest)}, nil).Once()
+	upgc.On("Upgrade", mock.Anything, req.name, req.Chart, req.Values).Return((*release.Release)(nil), errors.New("failed to download invalid-chart")).Once()
+
+	resp, err := service.Diff(ctx, req)
+
+	assert.EqualError(t, err, "failed to download invalid-chart")
+	assert.Empty(t, resp.Changes)
+}
+
+func TestShouldUpgradeToUploadedChartArchive(t *testing.T) {
+	cli := new(mockHelmClient)
+	upgc := new(mockUpgrader)
+	service := NewService(cli)
+	ctx := context.Background()
+	ch, err := loader.Load("../testdata/albatross")
+	require.NoError(t, err)
+	dir, err := ioutil.TempDir("", "upgrade")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path, err := chartutil.Save(ch, dir)
+	require.NoError(t, err)
+	archive, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	req := Request{name: "test-release", ChartArchive: archive}
+	rel := &release.Release{Name: "test-release", Version: 2, Info: &release.Info{Status: release.StatusDeployed}, Chart: ch}
+	cli.On("NewUpgrader", mock.AnythingOfType("flags.UpgradeFlags")).Return(upgc, nil)
+	upgc.On("UpgradeChart", mock.Anything, req.name, mock.MatchedBy(func(c *chart.Chart) bool { return c.Name() == "albatross" }), req.Values).Return(rel, nil)
+
+	resp, err := service.Upgrade(ctx, req)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, resp.Version)
+	upgc.AssertExpectations(t)
+}
@@ -3,24 +3,33 @@ package upgrade
 import (
 	"context"
 	"encoding/json"
-	"io"
+	"errors"
 	"net/http"
 	"time"
 
+	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/release"
 
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
 	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/upload"
 
 	"github.com/gorilla/mux"
 )
 
+const releaseNameMaxLen = 53
+
+var errInvalidReleaseName = errors.New("upgrade: invalid release name")
+
 // Request is the body for upgrading a release
 // swagger:model upgradeRequestBody
 type Request struct {
 	name string
 	// example: stable/mysql
 	Chart string `json:"chart"`
+	// Packaged chart, base64 encoded, to upgrade to instead of chart
+	ChartArchive []byte `json:"chart_archive,omitempty"`
 	// example: {"replicaCount": 1}
 	Values map[string]interface{} `json:"values"`
 	// Deprecated field
@@ -37,6 +46,44 @@ type Flags struct {
 	Version string `json:"version"`
 	// example: true
 	Install bool `json:"install"`
+	// Wait for the release resources to be ready before marking the release deployed
+	// example: false
+	Wait bool `json:"wait"`
+	// Wait for the jobs of the release to complete, only applies when wait is set.
+	// Failed jobs fail the release, which atomic then rolls back
+	// example: false
+	WaitForJobs bool `json:"wait_for_jobs"`
+	// Timeout in seconds for kubernetes operations and waiting
+	// example: 300
+	Timeout int `json:"timeout"`
+	// Roll back or uninstall the release when the operation fails, implies wait
+	// example: false
+	Atomic bool `json:"atomic"`
+	// Delete resources created by the upgrade when it fails
+	// example: false
+	CleanupOnFail bool `json:"cleanup_on_fail"`
+	// Force resource updates through a replacement strategy
+	// example: false
+	Force bool `json:"force"`
+	// Reset the values to the ones built into the chart
+	// example: false
+	ResetValues bool `json:"reset_values"`
+	// Reuse the values of the last release and merge the request values into them
+	// example: false
+	ReuseValues bool `json:"reuse_values"`
+	// example: false
+	DisableHooks bool `json:"disable_hooks"`
+	// Skip installing CRDs when the release is installed
+	// example: false
+	SkipCRDs bool `json:"skip_crds"`
+	// Create the namespace when the release is installed
+	// example: false
+	CreateNamespace bool `json:"create_namespace"`
+	// example: upgraded by albatross
+	Description string `json:"description"`
+	// Verify the chart against its provenance file, always done when the server requires signed charts
+	// example: false
+	Verify bool `json:"verify"`
 	flags.GlobalFlags
 }
 
@@ -61,8 +108,6 @@ type Release struct {
 
 // Response represents the api response for upgrade request.
 type Response struct {
-	// Error field is available only when the response status code is non 2xx
-	Error string `json:"error,omitempty"`
 	// example: deployed
 	Status  string `json:"status,omitempty"`
 	Data    string `json:"data,omitempty"`
@@ -76,11 +121,13 @@ type service interface {
 // Handler handles an upgrade request
 // swagger:operation PUT /clusters/{cluster}/namespaces/{namespace}/releases/{release_name} release upgradeOperation
 //
-//
+// Upgrade to the chart named in chart, or the packaged chart given base64 encoded in chart_archive.
+// A multipart/form-data body carries the JSON body in the request field and the packaged chart in the chart_archive file.
 // ---
 // summary: Upgrade a helm release deployed at the specified cluster and namespace
 // consumes:
 // - application/json
+// - multipart/form-data
 // produces:
 // - application/json
 // parameters:
@@ -107,48 +154,82 @@ type service interface {
 //   required: true
 //   schema:
 //    "$ref": "#/definitions/upgradeRequestBody"
+// - name: async
+//   in: query
+//   type: boolean
+//   default: false
+//   description: run the action in the background and return the operation to poll
 // schemes:
 // - http
 // responses:
 //   '200':
 //    "$ref": "#/responses/upgradeResponse"
+//   '202':
+//    schema:
+//     $ref: "#/definitions/operation"
 //   '400':
-//    description: "Invalid request"
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '404':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
+//   '409':
+//    schema:
+//     $ref: "#/definitions/errorResponse"
 //   '500':
-//    "$ref": "#/responses/upgradeResponse"
+//    schema:
+//     $ref: "#/definitions/errorResponse"
 func Handler(service service) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer r.Body.Close()
 
-		var req Request
-		if err := json.NewDecoder(r.Body).Decode(&req); err == io.EOF || err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+		req, err := decodeRequest(r)
+		if err != nil {
 			logger.Errorf("[Upgrade] error decoding request: %v", err)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
+			return
+		}
+		if err := req.valid(); err != nil {
+			logger.Errorf("[Upgrade] error in request parameters: %v", err)
+			apiErrors.Write(w, apiErrors.Wrap(apiErrors.Invalid, err))
 			return
 		}
-		values := mux.Vars(r)
-		req.Flags.KubeContext = values["cluster"]
-		req.Flags.Namespace = values["namespace"]
-		req.name = values["release_name"]
 		resp, err := service.Upgrade(r.Context(), req)
 		if err != nil {
-			respondUpgradeError(w, "error while upgrading release: %v", err)
+			logger.Errorf("[Upgrade] error while upgrading release: %v", err)
+			apiErrors.Write(w, apiErrors.WithReleaseStatus(err, resp.Status))
 			return
 		}
 
 		if err := json.NewEncoder(w).Encode(&resp); err != nil {
-			respondUpgradeError(w, "error writing response: %v", err)
-			return
+			logger.Errorf("[Upgrade] error writing response: %v", err)
 		}
 	})
 }
 
-func respondUpgradeError(w http.ResponseWriter, logprefix string, err error) {
-	response := Response{Error: err.Error()}
-	logger.Errorf("[Upgrade] %s %v", logprefix, err)
-	w.WriteHeader(http.StatusInternalServerError)
-	if err := json.NewEncoder(w).Encode(&response); err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+// decodeRequest decodes the JSON or multipart body of an upgrade or diff request and the route variables
+func decodeRequest(r *http.Request) (Request, error) {
+	var req Request
+	archive, err := upload.Decode(r, &req)
+	if err != nil {
+		return Request{}, err
+	}
+	if archive != nil {
+		req.ChartArchive = archive
+	}
+	values := mux.Vars(r)
+	req.Flags.KubeContext = values["cluster"]
+	req.Flags.Namespace = values["namespace"]
+	req.name = values["release_name"]
+	return req, nil
+}
+
+func (req Request) valid() error {
+	if req.name == "" || !action.ValidName.MatchString(req.name) || len(req.name) > releaseNameMaxLen {
+		return errInvalidReleaseName
+	}
+	if (req.Chart == "") == (len(req.ChartArchive) == 0) {
+		return errors.New("either chart or chart_archive is required")
 	}
+	return nil
 }
@@ -1,9 +1,12 @@
 package upgrade
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -15,10 +18,15 @@ import (
 	"github.com/stretchr/testify/require"
 	"github.com/stretchr/testify/suite"
 
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/diff"
+	"github.com/gojekfarm/albatross/pkg/helmcli"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
 	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/upload"
 
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
 type mockService struct {
@@ -30,6 +38,11 @@ func (m *mockService) Upgrade(ctx context.Context, req Request) (Response, error
 	return args.Get(0).(Response), args.Error(1)
 }
 
+func (m *mockService) Diff(ctx context.Context, req Request) (DiffResponse, error) {
+	args := m.Called(ctx, req)
+	return args.Get(0).(DiffResponse), args.Error(1)
+}
+
 type UpgradeTestSuite struct {
 	suite.Suite
 	recorder    *httptest.ResponseRecorder
@@ -46,6 +59,7 @@ func (s *UpgradeTestSuite) SetupTest() {
 	s.mockService = new(mockService)
 	router := mux.NewRouter()
 	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}", Handler(s.mockService)).Methods(http.MethodPut)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/diff", DiffHandler(s.mockService)).Methods(http.MethodPost)
 	s.server = httptest.NewServer(router)
 }
 
@@ -86,6 +100,42 @@ func (s *UpgradeTestSuite) TestShouldReturnDeployedStatusOnSuccessfulUpgrade() {
 	s.mockService.AssertExpectations(s.T())
 }
 
+func (s *UpgradeTestSuite) TestShouldDecodeDeployOptions() {
+	body := `{"chart":"stable/redis-ha", "flags": {"wait": true, "wait_for_jobs": true, "timeout": 600, "atomic": true,
+		"cleanup_on_fail": true, "force": true, "reset_values": true, "reuse_values": false, "disable_hooks": true,
+		"skip_crds": true, "create_namespace": true, "description": "upgraded by ci", "verify": true}}`
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/clusters/staging/namespaces/something/releases/redis-v5", s.server.URL), strings.NewReader(body))
+	requestStruct := Request{
+		name:  "redis-v5",
+		Chart: "stable/redis-ha",
+		Flags: Flags{
+			Wait:            true,
+			WaitForJobs:     true,
+			Timeout:         600,
+			Atomic:          true,
+			CleanupOnFail:   true,
+			Force:           true,
+			ResetValues:     true,
+			DisableHooks:    true,
+			SkipCRDs:        true,
+			CreateNamespace: true,
+			Description:     "upgraded by ci",
+			Verify:          true,
+			GlobalFlags: flags.GlobalFlags{
+				Namespace:   "something",
+				KubeContext: "staging",
+			},
+		},
+	}
+	s.mockService.On("Upgrade", mock.Anything, requestStruct).Return(Response{Status: release.StatusDeployed.String()}, nil)
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	s.mockService.AssertExpectations(s.T())
+}
+
 func (s *UpgradeTestSuite) TestShouldReturnInternalServerErrorOnFailure() {
 	chartName := "stable/redis-ha"
 	body := fmt.Sprintf(`{
@@ -113,6 +163,9 @@ func (s *UpgradeTestSuite) TestShouldReturnInternalServerErrorOnFailure() {
 
 	assert.Equal(s.T(), http.StatusInternalServerError, resp.StatusCode)
 	require.NoError(s.T(), err)
+	var actual apiErrors.Body
+	require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&actual))
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Internal, Message: "invalid chart"}, actual)
 }
 
 func (s *UpgradeTestSuite) TestShouldBadRequestOnInvalidRequest() {
@@ -132,6 +185,146 @@ func (s *UpgradeTestSuite) TestShouldBadRequestOnInvalidRequest() {
 	require.NoError(s.T(), err)
 }
 
+func (s *UpgradeTestSuite) TestShouldUpgradeToUploadedChartArchive() {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	require.NoError(s.T(), w.WriteField(upload.RequestField, `{"flags":{"install":true}}`))
+	part, err := w.CreateFormFile(upload.ChartField, "redis-ha-4.4.2.tgz")
+	require.NoError(s.T(), err)
+	_, err = part.Write([]byte("archive"))
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), w.Close())
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/clusters/staging/namespaces/something/releases/redis-v5", s.server.URL), body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	requestStruct := Request{
+		name:         "redis-v5",
+		ChartArchive: []byte("archive"),
+		Flags: Flags{
+			Install: true,
+			GlobalFlags: flags.GlobalFlags{
+				Namespace:   "something",
+				KubeContext: "staging",
+			},
+		},
+	}
+	s.mockService.On("Upgrade", mock.Anything, requestStruct).Return(Response{Status: release.StatusDeployed.String()}, nil)
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *UpgradeTestSuite) TestShouldReturnBadRequestWhenVerificationFails() {
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/clusters/staging/namespaces/something/releases/redis-v5", s.server.URL), strings.NewReader(`{"chart":"stable/redis-ha","flags":{"verify":true}}`))
+	verification := fmt.Errorf("error loading chart: %w: openpgp: signature made by unknown entity", helmcli.ErrVerification)
+	s.mockService.On("Upgrade", mock.Anything, mock.AnythingOfType("upgrade.Request")).Return(Response{}, verification)
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+	var body apiErrors.Body
+	require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Invalid, Message: verification.Error()}, body)
+}
+
+func (s *UpgradeTestSuite) TestShouldReturnBadRequestForMissingChart() {
+	req, _ := http.NewRequest(http.MethodPut, fmt.Sprintf("%s/clusters/staging/namespaces/something/releases/redis-v5", s.server.URL), strings.NewReader(`{"flags":{}}`))
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+	var body apiErrors.Body
+	require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&body))
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Invalid, Message: "either chart or chart_archive is required"}, body)
+}
+
+func (s *UpgradeTestSuite) TestDiffShouldReturnChangesOnSuccess() {
+	body := `{"chart":"stable/redis-ha", "flags": {"version": "4.4.2"}}`
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/staging/namespaces/something/releases/redis-v5/diff", s.server.URL), strings.NewReader(body))
+	requestStruct := Request{
+		name:  "redis-v5",
+		Chart: "stable/redis-ha",
+		Flags: Flags{
+			Version: "4.4.2",
+			GlobalFlags: flags.GlobalFlags{
+				Namespace:   "something",
+				KubeContext: "staging",
+			},
+		},
+	}
+	response := DiffResponse{Changes: []Change{{
+		APIVersion: "v1",
+		Kind:       "ConfigMap",
+		Namespace:  "something",
+		Name:       "redis-v5-config",
+		Change:     diff.Changed,
+		Diff:       "-a\n+b\n",
+	}}}
+	s.mockService.On("Diff", mock.Anything, requestStruct).Return(response, nil)
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusOK, resp.StatusCode)
+	var actual DiffResponse
+	require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&actual))
+	assert.Equal(s.T(), response, actual)
+	s.mockService.AssertExpectations(s.T())
+}
+
+func (s *UpgradeTestSuite) TestDiffShouldReturnNotFoundWhenReleaseIsMissing() {
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/staging/namespaces/something/releases/redis-v5/diff", s.server.URL), strings.NewReader(`{"chart":"stable/redis-ha"}`))
+	s.mockService.On("Diff", mock.Anything, mock.AnythingOfType("upgrade.Request")).Return(DiffResponse{}, driver.ErrReleaseNotFound)
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusNotFound, resp.StatusCode)
+	var actual apiErrors.Body
+	require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&actual))
+	assert.Equal(s.T(), apiErrors.NotFound, actual.Code)
+}
+
+func (s *UpgradeTestSuite) TestDiffShouldReturnInternalServerErrorOnFailure() {
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/staging/namespaces/something/releases/redis-v5/diff", s.server.URL), strings.NewReader(`{"chart":"stable/redis-ha"}`))
+	s.mockService.On("Diff", mock.Anything, mock.AnythingOfType("upgrade.Request")).Return(DiffResponse{}, errors.New("invalid chart"))
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusInternalServerError, resp.StatusCode)
+	var actual apiErrors.Body
+	require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&actual))
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Internal, Message: "invalid chart"}, actual)
+}
+
+func (s *UpgradeTestSuite) TestDiffShouldReturnBadRequestOnInvalidBody() {
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/staging/namespaces/something/releases/redis-v5/diff", s.server.URL), strings.NewReader(`{"chart": 1}`))
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+	s.mockService.AssertNotCalled(s.T(), "Diff")
+}
+
+func (s *UpgradeTestSuite) TestDiffShouldReturnBadRequestOnInvalidReleaseName() {
+	req, _ := http.NewRequest(http.MethodPost, fmt.Sprintf("%s/clusters/staging/namespaces/something/releases/redis-v5-/diff", s.server.URL), strings.NewReader(`{"chart":"stable/redis-ha"}`))
+
+	resp, err := http.DefaultClient.Do(req)
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), http.StatusBadRequest, resp.StatusCode)
+	var actual apiErrors.Body
+	require.NoError(s.T(), json.NewDecoder(resp.Body).Decode(&actual))
+	assert.Equal(s.T(), apiErrors.Body{Code: apiErrors.Invalid, Message: "upgrade: invalid release name"}, actual)
+	s.mockService.AssertNotCalled(s.T(), "Diff")
+}
+
 func (s *UpgradeTestSuite) TearDownTest() {
 	s.server.Close()
 }
@@ -1,30 +1,81 @@
 package main
 
 import (
+	"context"
+	"crypto/tls"
+	"flag"
 	"fmt"
 	"net/http"
 	"os"
-	"strconv"
+	"os/signal"
+	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 
 	"github.com/gojekfarm/albatross/api"
+	auditAPI "github.com/gojekfarm/albatross/api/audit"
+	"github.com/gojekfarm/albatross/api/chart"
+	"github.com/gojekfarm/albatross/api/cluster"
+	"github.com/gojekfarm/albatross/api/history"
 	"github.com/gojekfarm/albatross/api/install"
 	"github.com/gojekfarm/albatross/api/list"
+	"github.com/gojekfarm/albatross/api/operation"
+	"github.com/gojekfarm/albatross/api/recovery"
+	"github.com/gojekfarm/albatross/api/registry"
+	"github.com/gojekfarm/albatross/api/releasetest"
 	"github.com/gojekfarm/albatross/api/repository"
+	"github.com/gojekfarm/albatross/api/rollback"
 	"github.com/gojekfarm/albatross/api/status"
+	"github.com/gojekfarm/albatross/api/template"
 	"github.com/gojekfarm/albatross/api/uninstall"
 	"github.com/gojekfarm/albatross/api/upgrade"
+	"github.com/gojekfarm/albatross/pkg/audit"
+	"github.com/gojekfarm/albatross/pkg/auth"
+	"github.com/gojekfarm/albatross/pkg/authz"
+	clusterRegistry "github.com/gojekfarm/albatross/pkg/cluster"
+	"github.com/gojekfarm/albatross/pkg/config"
 	"github.com/gojekfarm/albatross/pkg/helmcli"
+	helmRegistry "github.com/gojekfarm/albatross/pkg/helmcli/registry"
 	helmRepository "github.com/gojekfarm/albatross/pkg/helmcli/repository"
+	"github.com/gojekfarm/albatross/pkg/lock"
 	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/metrics"
+	operationManager "github.com/gojekfarm/albatross/pkg/operation"
+	"github.com/gojekfarm/albatross/pkg/secret"
+	"github.com/gojekfarm/albatross/pkg/shutdown"
+	"github.com/gojekfarm/albatross/pkg/tracing"
 	_ "github.com/gojekfarm/albatross/swagger"
 
+	"helm.sh/helm/v3/pkg/kube"
+	helmProvenance "helm.sh/helm/v3/pkg/provenance"
+	"k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/gcp"
 )
 
+const (
+	operationWorkers    = 4
+	operationQueueSize  = 100
+	operationRetention  = 24 * time.Hour
+	auditMemoryEvents   = 1000
+	auditWebhookTimeout = 5 * time.Second
+	// serverStopTimeout bounds the wait for read requests once mutating ones are drained
+	serverStopTimeout = 10 * time.Second
+)
+
 func main() {
-	startServer()
+	configFile := flag.String("config", os.Getenv("ALBATROSS_CONFIG"), "path of the YAML configuration file")
+	flag.Parse()
+	cfg, err := config.Load(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error loading configuration: %v\n", err)
+		os.Exit(1)
+	}
+	if err := logger.Configure(cfg.Log.Level, cfg.Log.Format); err != nil {
+		fmt.Fprintf(os.Stderr, "error configuring logger: %v\n", err)
+		os.Exit(1)
+	}
+	startServer(cfg)
 }
 
 func ContentTypeMiddle(next http.Handler) http.Handler {
@@ -34,46 +85,358 @@ func ContentTypeMiddle(next http.Handler) http.Handler {
 	})
 }
 
-func startServer() {
-	router := mux.NewRouter()
-	logger.Setup("debug")
-	cli := helmcli.New()
-
-	installHandler := install.Handler(install.NewService(cli))
-	upgradeHandler := upgrade.Handler(upgrade.NewService(cli))
-	listHandler := list.Handler(list.NewService(cli))
-	uninstallHandler := uninstall.Handler(uninstall.NewService(cli))
-	statusHandler := status.Handler(status.NewService(cli))
-
-	router.Handle("/ping", ContentTypeMiddle(api.Ping())).Methods(http.MethodGet)
-	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}", ContentTypeMiddle(uninstallHandler)).Methods(http.MethodDelete)
-	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases", ContentTypeMiddle(installHandler)).Methods(http.MethodPost)
-	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}", ContentTypeMiddle(upgradeHandler)).Methods(http.MethodPut)
-	router.Handle("/clusters/{cluster}/releases", ContentTypeMiddle(listHandler)).Methods(http.MethodGet)
-	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases", ContentTypeMiddle(listHandler)).Methods(http.MethodGet)
-	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}", ContentTypeMiddle(statusHandler)).Methods(http.MethodGet)
+func startServer(cfg config.Config) {
+	root := mux.NewRouter()
+	applyHelmSettings(cfg.Helm)
+	clusters, err := clusterRegistry.NewRegistry(cfg.ClusterConfig)
+	if err != nil {
+		logger.Fatalf("error loading clusters: %v", err)
+	}
+	secrets, err := secretStore(cfg.SecretStore)
+	if err != nil {
+		logger.Fatalf("error configuring secret store: %v", err)
+	}
+	chartProvenance, err := provenance(cfg.Provenance)
+	if err != nil {
+		logger.Fatalf("error configuring chart verification: %v", err)
+	}
+	registryClient := helmRegistry.NewClient(secrets)
+	helmOptions := helmcli.Options{Clusters: clusters, Registry: registryClient, Provenance: chartProvenance}
+	if secrets != nil {
+		helmOptions.Credentials = helmRepository.NewCredentialsResolver(secrets)
+	}
+	cli := helmcli.NewWithOptions(helmOptions)
+	operations := operationManager.NewManager(operationWorkers, operationQueueSize, operationRetention)
+	auditLog, err := newAuditLog(cfg.Audit)
+	if err != nil {
+		logger.Fatalf("error configuring audit log: %v", err)
+	}
+	shutdownTracing, err := tracing.Setup(tracing.Config{Exporter: cfg.Tracing.Exporter, File: cfg.Tracing.File, SampleRatio: cfg.Tracing.SampleRatio})
+	if err != nil {
+		logger.Fatalf("error configuring tracing: %v", err)
+	}
+	defer shutdownTracing(context.Background())
+	serverMetrics := metrics.New()
+	root.Use(tracing.Middleware, serverMetrics.Middleware)
+
+	locker, err := releaseLocker(cfg.Locks)
+	if err != nil {
+		logger.Fatalf("error configuring release locks: %v", err)
+	}
+	// the lock of a release is held while its action runs. Asynchronous requests take it before they are queued,
+	// so conflicts are answered right away, and their operation keeps it until it finishes.
+	withLock := func(action string) func(http.Handler) http.Handler {
+		return lock.Middleware(locker, action, cfg.Locks.QueueTimeout)
+	}
 
+	// mutating requests are refused once the server is shutting down
+	gate := shutdown.NewGate()
+	installHandler := gate.Middleware("install")(operation.Async(operations, "install", serverMetrics.Action("install")(audit.Middleware(auditLog, "install")(withLock("install")(install.Handler(install.NewService(cli))))), withLock("install")))
+	upgradeService := upgrade.NewService(cli)
+	upgradeHandler := gate.Middleware("upgrade")(operation.Async(operations, "upgrade", serverMetrics.Action("upgrade")(audit.Middleware(auditLog, "upgrade")(withLock("upgrade")(upgrade.Handler(upgradeService)))), withLock("upgrade")))
+	listHandler := serverMetrics.Action("list")(list.Handler(list.NewService(cli)))
+	uninstallHandler := gate.Middleware("uninstall")(operation.Async(operations, "uninstall", serverMetrics.Action("uninstall")(audit.Middleware(auditLog, "uninstall")(withLock("uninstall")(uninstall.Handler(uninstall.NewService(cli))))), withLock("uninstall")))
+	statusService := status.NewService(cli)
+	statusHandler := serverMetrics.Action("status")(status.Handler(statusService))
+	rollbackHandler := gate.Middleware("rollback")(serverMetrics.Action("rollback")(audit.Middleware(auditLog, "rollback")(withLock("rollback")(rollback.Handler(rollback.NewService(cli))))))
+	recoverHandler := gate.Middleware("recover")(serverMetrics.Action("recover")(audit.Middleware(auditLog, "recover")(withLock("recover")(recovery.Handler(recovery.NewService(cli))))))
+	historyHandler := serverMetrics.Action("history")(history.Handler(history.NewService(cli)))
+	templateHandler := serverMetrics.Action("template")(template.Handler(template.NewService(cli)))
+	testHandler := gate.Middleware("test")(serverMetrics.Action("test")(releasetest.Handler(releasetest.NewService(cli))))
+	diffHandler := serverMetrics.Action("diff")(upgrade.DiffHandler(upgradeService))
+
+	root.Handle("/ping", ContentTypeMiddle(api.Ping())).Methods(http.MethodGet)
+	if cfg.Features.Metrics {
+		root.Handle("/metrics", serverMetrics.Handler()).Methods(http.MethodGet)
+	}
+	if cfg.Features.Documentation {
+		serveDocumentation(root)
+	}
+
+	authenticators, err := authenticators(cfg.Auth, cfg.Server.TLS)
+	if err != nil {
+		logger.Fatalf("error configuring authentication: %v", err)
+	}
+	router := root.PathPrefix("/").Subrouter()
+	if len(authenticators) > 0 {
+		router.Use(auth.Middleware(authenticators...))
+	} else {
+		logger.Infof("no authentication configured, every request is allowed")
+	}
+	authorize, err := authorizer(cfg.Authz)
+	if err != nil {
+		logger.Fatalf("error configuring authorization: %v", err)
+	}
+
+	clusterService := cluster.NewService(clusters)
+	router.Handle("/clusters", ContentTypeMiddle(authorize(authz.ManageClusters, cluster.ListHandler(clusterService)))).Methods(http.MethodGet)
+	router.Handle(fmt.Sprintf("/clusters/{%s}", cluster.URLNamePlaceholder), ContentTypeMiddle(authorize(authz.ManageClusters, cluster.Handler(clusterService)))).Methods(http.MethodGet)
+	router.Handle(fmt.Sprintf("/clusters/{%s}", cluster.URLNamePlaceholder), ContentTypeMiddle(authorize(authz.ManageClusters, cluster.PutHandler(clusterService)))).Methods(http.MethodPut)
+	router.Handle(fmt.Sprintf("/clusters/{%s}", cluster.URLNamePlaceholder), ContentTypeMiddle(authorize(authz.ManageClusters, cluster.DeleteHandler(clusterService)))).Methods(http.MethodDelete)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}", ContentTypeMiddle(authorize(authz.Uninstall, uninstallHandler))).Methods(http.MethodDelete)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases", ContentTypeMiddle(authorize(authz.Install, installHandler))).Methods(http.MethodPost)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}", ContentTypeMiddle(authorize(authz.Upgrade, upgradeHandler))).Methods(http.MethodPut)
+	router.Handle("/clusters/{cluster}/releases", ContentTypeMiddle(authorize(authz.List, listHandler))).Methods(http.MethodGet)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases", ContentTypeMiddle(authorize(authz.List, listHandler))).Methods(http.MethodGet)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}", ContentTypeMiddle(authorize(authz.Status, statusHandler))).Methods(http.MethodGet)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/rollback", ContentTypeMiddle(authorize(authz.Rollback, rollbackHandler))).Methods(http.MethodPost)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/recover", ContentTypeMiddle(authorize(authz.Recover, recoverHandler))).Methods(http.MethodPost)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/history", ContentTypeMiddle(authorize(authz.History, historyHandler))).Methods(http.MethodGet)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/values", ContentTypeMiddle(authorize(authz.Status, status.ValuesHandler(statusService)))).Methods(http.MethodGet)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/manifest", ContentTypeMiddle(authorize(authz.Status, status.ManifestHandler(statusService)))).Methods(http.MethodGet)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/notes", ContentTypeMiddle(authorize(authz.Status, status.NotesHandler(statusService)))).Methods(http.MethodGet)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/hooks", ContentTypeMiddle(authorize(authz.Status, status.HooksHandler(statusService)))).Methods(http.MethodGet)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/diff", ContentTypeMiddle(authorize(authz.Diff, diffHandler))).Methods(http.MethodPost)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}/test", ContentTypeMiddle(authorize(authz.Test, testHandler))).Methods(http.MethodPost)
+	router.Handle("/charts/template", ContentTypeMiddle(authorize(authz.Template, templateHandler))).Methods(http.MethodPost)
+	router.Handle("/audit", ContentTypeMiddle(authorize(authz.ReadAudit, auditAPI.Handler(auditAPI.NewService(auditLog))))).Methods(http.MethodGet)
+	router.Handle("/operations/{id}", ContentTypeMiddle(operation.Handler(operations))).Methods(http.MethodGet)
+	router.Handle("/operations/{id}", ContentTypeMiddle(operation.CancelHandler(operations))).Methods(http.MethodDelete)
+
+	repoClient := serverMetrics.Repositories(helmRepository.NewClient(secrets))
+	chartService := chart.NewService(cli, repoClient)
+	router.Handle("/charts/search", ContentTypeMiddle(authorize(authz.ReadCharts, chart.SearchHandler(chartService)))).Methods(http.MethodGet)
+	router.Handle("/charts/show", ContentTypeMiddle(authorize(authz.ReadCharts, chart.ShowHandler(chartService)))).Methods(http.MethodGet)
+	repoService := repository.NewService(repoClient, secrets)
+	router.Handle(fmt.Sprintf("/credentials/{%s}", repository.CredentialsNamePlaceholder), ContentTypeMiddle(authorize(authz.ManageRepositories, repository.PutCredentialsHandler(repoService)))).Methods(http.MethodPut)
+	router.Handle(fmt.Sprintf("/credentials/{%s}", repository.CredentialsNamePlaceholder), ContentTypeMiddle(authorize(authz.ManageRepositories, repository.DeleteCredentialsHandler(repoService)))).Methods(http.MethodDelete)
+	registryService := registry.NewService(registryClient)
+	router.Handle(fmt.Sprintf("/registries/{%s}/login", registry.HostPlaceholder), ContentTypeMiddle(authorize(authz.ManageRepositories, registry.LoginHandler(registryService)))).Methods(http.MethodPost)
+	router.Handle(fmt.Sprintf("/registries/{%s}/logout", registry.HostPlaceholder), ContentTypeMiddle(authorize(authz.ManageRepositories, registry.LogoutHandler(registryService)))).Methods(http.MethodPost)
 	repositorySubrouter := router.PathPrefix("/repositories").Subrouter()
-	handleRepositoryRoutes(repositorySubrouter)
+	handleRepositoryRoutes(repositorySubrouter, repoService, authorize, auditLog, gate)
+
+	server := &http.Server{
+		Addr:              cfg.Server.Address,
+		Handler:           root,
+		ReadTimeout:       cfg.Server.Timeouts.Read,
+		ReadHeaderTimeout: cfg.Server.Timeouts.ReadHeader,
+		WriteTimeout:      cfg.Server.Timeouts.Write,
+		IdleTimeout:       cfg.Server.Timeouts.Idle,
+	}
+	serveUntilSignalled(server, cfg.Server, gate, operations)
+}
+
+// serveUntilSignalled serves until SIGTERM or SIGINT, then refuses new mutating requests and waits up to
+// the grace period for the ones in flight and for queued operations before stopping the server.
+// Whatever is still running is logged, a second signal stops waiting right away.
+func serveUntilSignalled(server *http.Server, cfg config.Server, gate *shutdown.Gate, operations shutdown.Operations) {
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, syscall.SIGTERM, syscall.SIGINT)
+	defer signal.Stop(signals)
+	served := make(chan error, 1)
+	go func() {
+		served <- listenAndServe(server, cfg.TLS)
+	}()
+
+	select {
+	case err := <-served:
+		logger.Errorf("error starting server: %v", err)
+		return
+	case sig := <-signals:
+		logger.Infof("[Shutdown] received %s, waiting up to %s for helm actions to finish", sig, cfg.ShutdownGracePeriod)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownGracePeriod)
+	defer cancel()
+	go func() {
+		select {
+		case sig := <-signals:
+			logger.Errorf("[Shutdown] received %s again, stopping without waiting", sig)
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	requests, ops := shutdown.Drain(ctx, gate, operations)
+	for _, r := range requests {
+		logger.Errorf("[Shutdown] %s request %s %s still running after %s", r.Action, r.Method, r.Path, time.Since(r.Started).Round(time.Second))
+	}
+	for _, op := range ops {
+		logger.Errorf("[Shutdown] %s operation %s still %s", op.Kind, op.ID, op.State)
+	}
+
+	stopCtx, stop := context.WithTimeout(context.Background(), serverStopTimeout)
+	defer stop()
+	if err := server.Shutdown(stopCtx); err != nil {
+		logger.Errorf("[Shutdown] error stopping server: %v", err)
+		return
+	}
+	logger.Infof("[Shutdown] server stopped")
+}
+
+// applyHelmSettings hands the helm settings to helm, which reads them from the environment
+// every time an action or repository client is created
+func applyHelmSettings(settings config.Helm) {
+	env := map[string]string{
+		"HELM_DRIVER":            settings.Driver,
+		"HELM_REPOSITORY_CONFIG": settings.RepositoryConfig,
+		"HELM_REPOSITORY_CACHE":  settings.RepositoryCache,
+	}
+	for name, value := range env {
+		if value != "" {
+			os.Setenv(name, value)
+		}
+	}
+}
+
+// authenticators returns the enabled authentication methods, client certificates are verified by the TLS server
+func authenticators(cfg config.Auth, tlsConfig config.TLS) ([]auth.Authenticator, error) {
+	var authenticators []auth.Authenticator
+	if path := cfg.APIKeysFile; path != "" {
+		a, err := auth.NewAPIKeyAuthenticator(path)
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, a)
+	}
+	if path := cfg.JWKSFile; path != "" {
+		a, err := auth.NewJWTAuthenticator(auth.JWTConfig{
+			JWKSFile: path,
+			Issuer:   cfg.JWTIssuer,
+			Audience: cfg.JWTAudience,
+		})
+		if err != nil {
+			return nil, err
+		}
+		authenticators = append(authenticators, a)
+	}
+	if tlsConfig.ClientCAFile != "" {
+		authenticators = append(authenticators, auth.NewMTLSAuthenticator())
+	}
+	return authenticators, nil
+}
+
+// newAuditLog returns the audit log writing to the configured sinks.
+// Queries read the audit file when it is set and the most recent events kept in memory otherwise.
+func newAuditLog(cfg config.Audit) (*audit.Log, error) {
+	var sinks []audit.Sink
+	var store audit.Store
+	if path := cfg.File; path != "" {
+		file, err := audit.NewFileSink(path)
+		if err != nil {
+			return nil, err
+		}
+		sinks = append(sinks, file)
+		store = file
+	} else {
+		memory := audit.NewMemorySink(auditMemoryEvents)
+		sinks = append(sinks, memory)
+		store = memory
+	}
+	if cfg.Stdout {
+		sinks = append(sinks, audit.NewWriterSink(os.Stdout))
+	}
+	if url := cfg.WebhookURL; url != "" {
+		sinks = append(sinks, audit.NewWebhookSink(url, auditWebhookTimeout))
+	}
+	return audit.NewLog(store, sinks...), nil
+}
 
-	serveDocumentation(router)
-	err := http.ListenAndServe(fmt.Sprintf(":%d", 8080), router)
+// secretStore returns the configured store of repository and registry credentials,
+// credentials are rejected when no store is configured.
+func secretStore(cfg config.SecretStore) (secret.Store, error) {
+	switch cfg.Kind {
+	case "":
+		return nil, nil
+	case "file":
+		return secret.NewFileStore(cfg.File, cfg.KeyFile)
+	case "kubernetes":
+		client, err := kubeClient(cfg.KubeContext, cfg.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		return secret.NewKubernetesStore(client, cfg.Namespace), nil
+	default:
+		return nil, fmt.Errorf("unknown secret store %q", cfg.Kind)
+	}
+}
+
+// releaseLocker returns the configured backend of the per-release locks
+func releaseLocker(cfg config.Locks) (lock.Locker, error) {
+	switch cfg.Backend {
+	case "memory":
+		return lock.NewMemoryLocker(), nil
+	case "kubernetes":
+		client, err := kubeClient(cfg.KubeContext, cfg.Namespace)
+		if err != nil {
+			return nil, err
+		}
+		return lock.NewLeaseLocker(client, cfg.Namespace, cfg.LeaseDuration), nil
+	default:
+		return nil, fmt.Errorf("unknown lock backend %q", cfg.Backend)
+	}
+}
+
+// kubeClient returns a client of the cluster albatross keeps its own state in
+func kubeClient(kubeContext, namespace string) (kubernetes.Interface, error) {
+	restConfig, err := kube.GetConfig("", kubeContext, namespace).ToRESTConfig()
 	if err != nil {
-		logger.Errorf("error starting server", err)
+		return nil, err
 	}
+	return kubernetes.NewForConfig(restConfig)
 }
 
-func serveDocumentation(r *mux.Router) {
-	docEnv := os.Getenv("DOCUMENTATION")
-	serveDoc, err := strconv.ParseBool(docEnv)
-	if err == nil && serveDoc {
-		fs := http.FileServer(http.Dir("./docs"))
-		r.PathPrefix("/docs/").Handler(http.StripPrefix("/docs/", fs))
+// provenance returns the verification of charts against the configured keyring,
+// every installed and upgraded chart is verified when it is required.
+func provenance(cfg config.Provenance) (helmcli.Provenance, error) {
+	p := helmcli.Provenance{Keyring: cfg.Keyring, Required: cfg.Required}
+	if p.Keyring == "" {
+		return p, nil
 	}
+	// fail at startup rather than on the first verified chart when the keyring cannot be read
+	if _, err := helmProvenance.NewFromKeyring(p.Keyring, ""); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// authorizer returns a decorator checking the configured policy before a handler runs,
+// handlers are left as they are when no policy is configured.
+func authorizer(cfg config.Authz) (func(authz.Verb, http.Handler) http.Handler, error) {
+	if cfg.PolicyFile == "" {
+		return func(_ authz.Verb, h http.Handler) http.Handler { return h }, nil
+	}
+	policy, err := authz.Load(cfg.PolicyFile)
+	if err != nil {
+		return nil, err
+	}
+	return func(verb authz.Verb, h http.Handler) http.Handler {
+		return authz.Middleware(policy, verb)(h)
+	}, nil
+}
+
+// listenAndServe serves TLS when a certificate and key are configured,
+// client certificates are verified against the client CA when it is set.
+func listenAndServe(server *http.Server, cfg config.TLS) error {
+	if !cfg.Enabled() {
+		return server.ListenAndServe()
+	}
+
+	if cfg.ClientCAFile != "" {
+		clientCAs, err := auth.LoadClientCAs(cfg.ClientCAFile)
+		if err != nil {
+			return err
+		}
+		// other authentication methods keep working for clients without a certificate
+		server.TLSConfig = &tls.Config{ClientCAs: clientCAs, ClientAuth: tls.VerifyClientCertIfGiven}
+	}
+	return server.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile)
+}
+
+func serveDocumentation(r *mux.Router) {
+	fs := http.FileServer(http.Dir("./docs"))
+	r.PathPrefix("/docs/").Handler(http.StripPrefix("/docs/", fs))
 }
 
-func handleRepositoryRoutes(router *mux.Router) {
-	repoClient := helmRepository.NewClient()
-	repoService := repository.NewService(repoClient)
-	router.Handle(fmt.Sprintf("/{%s}", repository.URLNamePlaceholder), ContentTypeMiddle(repository.AddHandler(repoService))).Methods(http.MethodPut)
+func handleRepositoryRoutes(router *mux.Router, repoService repository.Service, authorize func(authz.Verb, http.Handler) http.Handler, auditLog *audit.Log, gate *shutdown.Gate) {
+	addHandler := gate.Middleware("repo_add")(audit.Middleware(auditLog, "repo_add")(repository.AddHandler(repoService)))
+	removeHandler := gate.Middleware("repo_remove")(audit.Middleware(auditLog, "repo_remove")(repository.DeleteHandler(repoService)))
+	updateHandler := gate.Middleware("repo_update")(audit.Middleware(auditLog, "repo_update")(repository.UpdateHandler(repoService)))
+	named := fmt.Sprintf("/{%s}", repository.URLNamePlaceholder)
+	router.Handle("", ContentTypeMiddle(authorize(authz.ManageRepositories, repository.ListHandler(repoService)))).Methods(http.MethodGet)
+	router.Handle("/update", ContentTypeMiddle(authorize(authz.ManageRepositories, updateHandler))).Methods(http.MethodPost)
+	router.Handle(named, ContentTypeMiddle(authorize(authz.ManageRepositories, repository.GetHandler(repoService)))).Methods(http.MethodGet)
+	router.Handle(named, ContentTypeMiddle(authorize(authz.ManageRepositories, addHandler))).Methods(http.MethodPut)
+	router.Handle(named, ContentTypeMiddle(authorize(authz.ManageRepositories, removeHandler))).Methods(http.MethodDelete)
+	router.Handle(named+"/update", ContentTypeMiddle(authorize(authz.ManageRepositories, updateHandler))).Methods(http.MethodPost)
 }
@@ -0,0 +1,105 @@
+// Package response records the status and the body of the responses written by handlers,
+// for the middlewares that report on them once the handler returned.
+package response
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"net"
+	"net/http"
+)
+
+// KeepAll makes a recorder keep the whole body
+const KeepAll = -1
+
+// Recorder keeps the status of a response and up to a limit of its body.
+// A recorder wrapping a writer passes the response through, including flushes and hijacks,
+// a recorder from Capture only keeps it.
+type Recorder struct {
+	w          http.ResponseWriter
+	header     http.Header
+	statusCode int
+	body       bytes.Buffer
+	keep       int
+}
+
+// Wrap returns a recorder passing the response through to w and keeping the first keep bytes of its body
+func Wrap(w http.ResponseWriter, keep int) *Recorder {
+	return &Recorder{w: w, keep: keep}
+}
+
+// Capture returns a recorder keeping the whole response without writing it anywhere,
+// for handlers that run after their request was answered
+func Capture() *Recorder {
+	return &Recorder{header: http.Header{}, keep: KeepAll}
+}
+
+// Header returns the header of the wrapped writer, or of the captured response
+func (rec *Recorder) Header() http.Header {
+	if rec.w == nil {
+		return rec.header
+	}
+	return rec.w.Header()
+}
+
+// WriteHeader keeps the first status written
+func (rec *Recorder) WriteHeader(statusCode int) {
+	if rec.statusCode == 0 {
+		rec.statusCode = statusCode
+	}
+	if rec.w != nil {
+		rec.w.WriteHeader(statusCode)
+	}
+}
+
+func (rec *Recorder) Write(b []byte) (int, error) {
+	if rec.statusCode == 0 {
+		rec.statusCode = http.StatusOK
+	}
+	room := rec.keep - rec.body.Len()
+	if rec.keep == KeepAll || room > len(b) {
+		room = len(b)
+	}
+	if room > 0 {
+		rec.body.Write(b[:room])
+	}
+	if rec.w == nil {
+		return len(b), nil
+	}
+	return rec.w.Write(b)
+}
+
+// Flush flushes the wrapped writer when it supports it
+func (rec *Recorder) Flush() {
+	if f, ok := rec.w.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// Hijack hands the connection of the wrapped writer over, the status is then unknown to the recorder
+func (rec *Recorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := rec.w.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("response writer does not support hijacking")
+	}
+	return h.Hijack()
+}
+
+// Unwrap returns the wrapped writer, for http.ResponseController
+func (rec *Recorder) Unwrap() http.ResponseWriter {
+	return rec.w
+}
+
+// Status returns the status of the response, 200 when the handler did not write one
+func (rec *Recorder) Status() int {
+	if rec.statusCode == 0 {
+		return http.StatusOK
+	}
+	return rec.statusCode
+}
+
+// Body returns the kept part of the body
+func (rec *Recorder) Body() []byte {
+	return rec.body.Bytes()
+}
@@ -0,0 +1,59 @@
+package response
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWrapPassesTheResponseThroughAndKeepsItsStart(t *testing.T) {
+	w := httptest.NewRecorder()
+	rec := Wrap(w, 4)
+
+	rec.Header().Set("Content-Type", "application/json")
+	rec.WriteHeader(http.StatusConflict)
+	rec.WriteHeader(http.StatusOK)
+	fmt.Fprint(rec, `{"code":"conflict"}`)
+	rec.Flush()
+
+	assert.Equal(t, http.StatusConflict, rec.Status())
+	assert.Equal(t, `{"co`, string(rec.Body()))
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, `{"code":"conflict"}`, w.Body.String())
+	assert.True(t, w.Flushed)
+}
+
+func TestRecorderDefaultsToOK(t *testing.T) {
+	rec := Wrap(httptest.NewRecorder(), 0)
+
+	assert.Equal(t, http.StatusOK, rec.Status())
+	fmt.Fprint(rec, "ok")
+	assert.Equal(t, http.StatusOK, rec.Status())
+	assert.Empty(t, rec.Body())
+}
+
+func TestCaptureKeepsTheWholeResponse(t *testing.T) {
+	rec := Capture()
+
+	rec.Header().Set("Location", "/operations/1")
+	rec.WriteHeader(http.StatusCreated)
+	n, err := fmt.Fprint(rec, `{"status":"deployed"}`)
+	rec.Flush()
+
+	require.NoError(t, err)
+	assert.Equal(t, 21, n)
+	assert.Equal(t, http.StatusCreated, rec.Status())
+	assert.Equal(t, `{"status":"deployed"}`, string(rec.Body()))
+	assert.Equal(t, "/operations/1", rec.Header().Get("Location"))
+}
+
+func TestHijackNeedsAWriterSupportingIt(t *testing.T) {
+	_, _, err := Wrap(httptest.NewRecorder(), 0).Hijack()
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,111 @@
+package audit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+// Outcome tells whether the audited operation succeeded
+type Outcome string
+
+const (
+	// Success is the outcome of operations answered with a 2xx or 3xx status
+	Success Outcome = "success"
+	// Failure is the outcome of every other operation
+	Failure Outcome = "failure"
+)
+
+// Event is the audit record of a mutating operation
+type Event struct {
+	ID           string    `json:"id"`
+	Time         time.Time `json:"time"`
+	Action       string    `json:"action"`
+	Principal    string    `json:"principal,omitempty"`
+	SourceIP     string    `json:"source_ip,omitempty"`
+	Cluster      string    `json:"cluster,omitempty"`
+	Namespace    string    `json:"namespace,omitempty"`
+	Release      string    `json:"release,omitempty"`
+	Repository   string    `json:"repository,omitempty"`
+	URL          string    `json:"url,omitempty"`
+	Chart        string    `json:"chart,omitempty"`
+	ChartVersion string    `json:"chart_version,omitempty"`
+	ValuesHash   string    `json:"values_hash,omitempty"`
+	DryRun       bool      `json:"dry_run"`
+	Outcome      Outcome   `json:"outcome"`
+	StatusCode   int       `json:"status_code"`
+	Error        string    `json:"error,omitempty"`
+	Revision     int       `json:"revision,omitempty"`
+	DurationMs   int64     `json:"duration_ms"`
+}
+
+// Sink receives every recorded event
+type Sink interface {
+	Write(e Event) error
+}
+
+// Store answers audit queries
+type Store interface {
+	Query(f Filter) ([]Event, error)
+}
+
+// Log records events to its sinks and answers queries from its store
+type Log struct {
+	sinks []Sink
+	store Store
+}
+
+// NewLog returns a log writing to sinks and querying store
+func NewLog(store Store, sinks ...Sink) *Log {
+	return &Log{sinks: sinks, store: store}
+}
+
+// Record writes the event to every sink, failing sinks are logged and do not stop the others
+func (l *Log) Record(e Event) {
+	for _, s := range l.sinks {
+		if err := s.Write(e); err != nil {
+			logger.Errorf("[Audit] error writing event %s: %v", e.ID, err)
+		}
+	}
+}
+
+// Query returns the events matching the filter, newest first
+func (l *Log) Query(f Filter) ([]Event, error) {
+	return l.store.Query(f)
+}
+
+type eventKey struct{}
+
+// pending is the event of the request being served, services complete it through the context
+type pending struct {
+	mu    sync.Mutex
+	event *Event
+}
+
+func newContext(ctx context.Context, e *Event) (context.Context, *pending) {
+	p := &pending{event: e}
+	return context.WithValue(ctx, eventKey{}, p), p
+}
+
+// SetRelease records the chart, chart version and revision of the release an audited operation produced.
+// It does nothing when the request is not audited.
+func SetRelease(ctx context.Context, rel *release.Release) {
+	p, ok := ctx.Value(eventKey{}).(*pending)
+	if !ok || rel == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.event.Revision = rel.Version
+	if rel.Chart != nil && rel.Chart.Metadata != nil {
+		p.event.ChartVersion = rel.Chart.Metadata.Version
+		// uploaded charts are not named in the request
+		if p.event.Chart == "" {
+			p.event.Chart = rel.Chart.Metadata.Name
+		}
+	}
+}
@@ -0,0 +1,150 @@
+package audit
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/internal/response"
+	"github.com/gojekfarm/albatross/pkg/auth"
+	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/upload"
+)
+
+// maxErrorBody bounds the part of a response kept to read its error
+const maxErrorBody = 64 * 1024
+
+type recorder interface {
+	Record(e Event)
+}
+
+// requestBody holds the audited fields of the install, upgrade and repository add bodies
+type requestBody struct {
+	Name   string          `json:"name"`
+	Chart  string          `json:"chart"`
+	URL    string          `json:"url"`
+	Values json.RawMessage `json:"values"`
+	Flags  struct {
+		DryRun  bool   `json:"dry_run"`
+		Version string `json:"version"`
+	} `json:"flags"`
+}
+
+// Middleware records an event of kind action for every request served by next.
+// The target comes from the route variables and the request body, the outcome from the response
+// and the resolved chart version and revision from the service through SetRelease.
+// Bodies larger than upload.MaxBodySize are answered with 413 Request Entity Too Large and recorded as failures.
+func Middleware(r recorder, action string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+			start := time.Now()
+			e, err := newEvent(w, req, action)
+			ctx, p := newContext(req.Context(), &e)
+
+			rw := response.Wrap(w, maxErrorBody)
+			if errors.Is(err, upload.ErrBodyTooLarge) {
+				apiErrors.Write(rw, err)
+			} else {
+				next.ServeHTTP(rw, req.WithContext(ctx))
+			}
+
+			p.mu.Lock()
+			defer p.mu.Unlock()
+			e.DurationMs = time.Since(start).Milliseconds()
+			e.StatusCode = rw.Status()
+			e.Outcome = Success
+			if e.StatusCode >= http.StatusBadRequest {
+				e.Outcome = Failure
+				e.Error = errorMessage(rw)
+			}
+			r.Record(e)
+		})
+	}
+}
+
+// newEvent returns the event of r, and upload.ErrBodyTooLarge for bodies that are not given to the handler
+func newEvent(w http.ResponseWriter, r *http.Request, action string) (Event, error) {
+	vars := mux.Vars(r)
+	e := Event{
+		ID:         newID(),
+		Time:       time.Now().UTC(),
+		Action:     action,
+		SourceIP:   sourceIP(r),
+		Cluster:    vars["cluster"],
+		Namespace:  vars["namespace"],
+		Release:    vars["release_name"],
+		Repository: vars["repository_name"],
+	}
+	if p, ok := auth.FromContext(r.Context()); ok {
+		e.Principal = p.Name
+	}
+	// uninstall takes its flags from the query
+	if dryRun, err := strconv.ParseBool(r.URL.Query().Get("dry_run")); err == nil {
+		e.DryRun = dryRun
+	}
+	body, err := upload.ReadBody(w, r)
+	if errors.Is(err, upload.ErrBodyTooLarge) {
+		return e, err
+	}
+	var b requestBody
+	if err != nil || body == nil || json.Unmarshal(upload.RequestJSON(r, body), &b) != nil {
+		return e, nil
+	}
+	if e.Release == "" {
+		e.Release = b.Name
+	}
+	e.Chart = b.Chart
+	e.ChartVersion = b.Flags.Version
+	e.DryRun = b.Flags.DryRun
+	e.URL = b.URL
+	if len(b.Values) > 0 {
+		e.ValuesHash = valuesHash(b.Values)
+	}
+	return e, nil
+}
+
+// valuesHash is the sha256 of the values re-encoded with sorted keys, so formatting does not change it
+func valuesHash(raw json.RawMessage) string {
+	var values interface{}
+	if err := json.Unmarshal(raw, &values); err == nil {
+		if canonical, err := json.Marshal(values); err == nil {
+			raw = canonical
+		}
+	}
+	sum := sha256.Sum256(raw)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+func sourceIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		logger.Errorf("[Audit] error generating event id: %v", err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// errorMessage returns the message of the error response kept by rw
+func errorMessage(rw *response.Recorder) string {
+	var body apiErrors.Body
+	if err := json.Unmarshal(rw.Body(), &body); err == nil && body.Message != "" {
+		return body.Message
+	}
+	return http.StatusText(rw.Status())
+}
@@ -0,0 +1,147 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/gojekfarm/albatross/pkg/auth"
+	"github.com/gojekfarm/albatross/pkg/upload"
+)
+
+type recorded struct {
+	events []Event
+}
+
+func (r *recorded) Record(e Event) {
+	r.events = append(r.events, e)
+}
+
+func serve(t *testing.T, path, target string, body string, handler http.HandlerFunc) Event {
+	rec := &recorded{}
+	router := mux.NewRouter()
+	router.Handle(path, Middleware(rec, "upgrade")(handler))
+	req := httptest.NewRequest(http.MethodPut, target, strings.NewReader(body))
+	req.RemoteAddr = "10.0.0.12:41234"
+	req = req.WithContext(auth.NewContext(req.Context(), auth.Principal{Name: "jane"}))
+
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, rec.events, 1)
+	return rec.events[0]
+}
+
+func TestMiddlewareRecordsSuccessfulUpgrade(t *testing.T) {
+	body := `{"chart":"stable/mysql","values":{"b":1,"a":{"c":true}},"flags":{"dry_run":true,"version":"~1.6"}}`
+	var received string
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		received = string(b)
+		SetRelease(r.Context(), &release.Release{Version: 4, Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "mysql", Version: "1.6.9"}}})
+		w.Write([]byte(`{"status":"deployed"}`))
+	}
+
+	e := serve(t, "/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}",
+		"/clusters/staging/namespaces/default/releases/mysql-final", body, handler)
+
+	assert.Equal(t, body, received)
+	assert.Len(t, e.ID, 32)
+	assert.False(t, e.Time.IsZero())
+	e.ID, e.DurationMs = "", 0
+	assert.Equal(t, Event{
+		Time:         e.Time,
+		Action:       "upgrade",
+		Principal:    "jane",
+		SourceIP:     "10.0.0.12",
+		Cluster:      "staging",
+		Namespace:    "default",
+		Release:      "mysql-final",
+		Chart:        "stable/mysql",
+		ChartVersion: "1.6.9",
+		ValuesHash:   valuesHash(json.RawMessage(`{"a":{"c":true},"b":1}`)),
+		DryRun:       true,
+		Outcome:      Success,
+		StatusCode:   http.StatusOK,
+		Revision:     4,
+	}, e)
+}
+
+func TestMiddlewareRecordsFailures(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte(`{"code":"not_found","message":"release: not found"}`))
+	}
+
+	e := serve(t, "/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}",
+		"/clusters/staging/namespaces/default/releases/mysql-final?dry_run=true", "", handler)
+
+	assert.Equal(t, Failure, e.Outcome)
+	assert.Equal(t, http.StatusNotFound, e.StatusCode)
+	assert.Equal(t, "release: not found", e.Error)
+	assert.True(t, e.DryRun)
+	assert.Empty(t, e.ValuesHash)
+	assert.Zero(t, e.Revision)
+}
+
+func TestMiddlewareRejectsBodiesLargerThanAnUpload(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called")
+	}
+	body := `{"chart":"stable/mysql","values":"` + strings.Repeat("x", upload.MaxBodySize) + `"}`
+
+	e := serve(t, "/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}",
+		"/clusters/staging/namespaces/default/releases/mysql-final", body, handler)
+
+	assert.Equal(t, Failure, e.Outcome)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, e.StatusCode)
+	assert.Equal(t, upload.ErrBodyTooLarge.Error(), e.Error)
+	assert.Empty(t, e.Chart)
+}
+
+func TestMiddlewareRecordsRepositoryAdd(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {}
+
+	e := serve(t, "/repositories/{repository_name}", "/repositories/stable", `{"url":"https://charts.helm.sh/stable"}`, handler)
+
+	assert.Equal(t, "stable", e.Repository)
+	assert.Equal(t, "https://charts.helm.sh/stable", e.URL)
+	assert.Equal(t, Success, e.Outcome)
+}
+
+func TestMiddlewareRecordsUploadedChart(t *testing.T) {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	require.NoError(t, w.WriteField(upload.RequestField, `{"name":"mysql-final","values":{"a":1}}`))
+	require.NoError(t, w.WriteField(upload.ChartField, "archive"))
+	require.NoError(t, w.Close())
+	rec := &recorded{}
+	handler := Middleware(rec, "install")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		SetRelease(r.Context(), &release.Release{Version: 1, Chart: &chart.Chart{Metadata: &chart.Metadata{Name: "mysql", Version: "1.6.9"}}})
+	}))
+	req := httptest.NewRequest(http.MethodPost, "/releases", body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	require.Len(t, rec.events, 1)
+	assert.Equal(t, "mysql-final", rec.events[0].Release)
+	assert.Equal(t, "mysql", rec.events[0].Chart)
+	assert.Equal(t, "1.6.9", rec.events[0].ChartVersion)
+	assert.Equal(t, valuesHash(json.RawMessage(`{"a":1}`)), rec.events[0].ValuesHash)
+}
+
+func TestValuesHashIgnoresFormatting(t *testing.T) {
+	assert.Equal(t, valuesHash(json.RawMessage(`{"a": 1, "b": [1, 2]}`)), valuesHash(json.RawMessage(`{"b":[1,2],"a":1}`)))
+	assert.NotEqual(t, valuesHash(json.RawMessage(`{"a": 1}`)), valuesHash(json.RawMessage(`{"a": 2}`)))
+}
@@ -0,0 +1,125 @@
+package audit
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+var (
+	t0       = time.Date(2021, 3, 24, 12, 0, 0, 0, time.UTC)
+	install  = Event{ID: "1", Time: t0, Action: "install", Principal: "jane", Cluster: "staging", Namespace: "default", Release: "mysql", Outcome: Success}
+	upgrade  = Event{ID: "2", Time: t0.Add(time.Minute), Action: "upgrade", Principal: "john", Cluster: "staging", Namespace: "default", Release: "mysql", Outcome: Failure}
+	rollback = Event{ID: "3", Time: t0.Add(2 * time.Minute), Action: "rollback", Principal: "jane", Cluster: "production", Namespace: "payments", Release: "api", Outcome: Success}
+)
+
+func TestFileSinkWritesJSONLinesAndAnswersQueries(t *testing.T) {
+	dir, err := ioutil.TempDir("", "audit")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "audit.log")
+	sink, err := NewFileSink(path)
+	require.NoError(t, err)
+
+	for _, e := range []Event{install, upgrade, rollback} {
+		require.NoError(t, sink.Write(e))
+	}
+
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	lines := bytes.Split(bytes.TrimSpace(b), []byte("\n"))
+	require.Len(t, lines, 3)
+	var first Event
+	require.NoError(t, json.Unmarshal(lines[0], &first))
+	assert.Equal(t, install, first)
+
+	events, err := sink.Query(Filter{Principal: "jane"})
+	require.NoError(t, err)
+	assert.Equal(t, []Event{rollback, install}, events)
+}
+
+func TestMemorySinkKeepsTheLatestEvents(t *testing.T) {
+	sink := NewMemorySink(2)
+	for _, e := range []Event{install, upgrade, rollback} {
+		require.NoError(t, sink.Write(e))
+	}
+
+	events, err := sink.Query(Filter{})
+
+	require.NoError(t, err)
+	assert.Equal(t, []Event{rollback, upgrade}, events)
+}
+
+func TestFilter(t *testing.T) {
+	events := []Event{install, upgrade, rollback}
+	tests := []struct {
+		name   string
+		filter Filter
+		want   []Event
+	}{
+		{name: "everything", filter: Filter{}, want: []Event{rollback, upgrade, install}},
+		{name: "release", filter: Filter{Cluster: "staging", Namespace: "default", Release: "mysql"}, want: []Event{upgrade, install}},
+		{name: "action", filter: Filter{Action: "rollback"}, want: []Event{rollback}},
+		{name: "outcome", filter: Filter{Outcome: Failure}, want: []Event{upgrade}},
+		{name: "time range", filter: Filter{Since: t0.Add(time.Minute), Until: t0.Add(2 * time.Minute)}, want: []Event{upgrade}},
+		{name: "limit", filter: Filter{Limit: 1}, want: []Event{rollback}},
+		{name: "no match", filter: Filter{Principal: "nobody"}, want: []Event{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.filter.apply(events))
+		})
+	}
+}
+
+func TestWebhookSink(t *testing.T) {
+	var received Event
+	status := http.StatusNoContent
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+	sink := NewWebhookSink(server.URL, time.Second)
+
+	require.NoError(t, sink.Write(install))
+	assert.Equal(t, install, received)
+
+	status = http.StatusInternalServerError
+	assert.Error(t, sink.Write(install))
+}
+
+type failingSink struct{}
+
+func (failingSink) Write(e Event) error {
+	return errors.New("disk full")
+}
+
+func TestLogWritesToEverySink(t *testing.T) {
+	logger.Setup("default")
+	var stdout bytes.Buffer
+	memory := NewMemorySink(10)
+	log := NewLog(memory, failingSink{}, memory, NewWriterSink(&stdout))
+
+	log.Record(install)
+
+	events, err := log.Query(Filter{})
+	require.NoError(t, err)
+	assert.Equal(t, []Event{install}, events)
+	var written Event
+	require.NoError(t, json.Unmarshal(stdout.Bytes(), &written))
+	assert.Equal(t, install, written)
+}
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// DefaultLimit is the number of events returned by queries without a limit
+const DefaultLimit = 100
+
+// Filter selects events, empty fields match every event
+type Filter struct {
+	Action    string
+	Principal string
+	Cluster   string
+	Namespace string
+	Release   string
+	Outcome   Outcome
+	Since     time.Time
+	Until     time.Time
+	Limit     int
+}
+
+func (f Filter) matches(e Event) bool {
+	return (f.Action == "" || f.Action == e.Action) &&
+		(f.Principal == "" || f.Principal == e.Principal) &&
+		(f.Cluster == "" || f.Cluster == e.Cluster) &&
+		(f.Namespace == "" || f.Namespace == e.Namespace) &&
+		(f.Release == "" || f.Release == e.Release) &&
+		(f.Outcome == "" || f.Outcome == e.Outcome) &&
+		(f.Since.IsZero() || !e.Time.Before(f.Since)) &&
+		(f.Until.IsZero() || e.Time.Before(f.Until))
+}
+
+// apply returns the newest matching events up to the limit
+func (f Filter) apply(events []Event) []Event {
+	limit := f.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	matched := []Event{}
+	for _, e := range events {
+		if f.matches(e) {
+			matched = append(matched, e)
+		}
+	}
+	sort.SliceStable(matched, func(i, j int) bool { return matched[i].Time.After(matched[j].Time) })
+	if len(matched) > limit {
+		matched = matched[:limit]
+	}
+	return matched
+}
+
+// MemorySink keeps the most recent events in memory, it is the store when no audit file is configured
+type MemorySink struct {
+	mu     sync.RWMutex
+	events []Event
+	size   int
+}
+
+// NewMemorySink returns a sink keeping the last size events
+func NewMemorySink(size int) *MemorySink {
+	return &MemorySink{size: size}
+}
+
+// Write keeps the event, dropping the oldest one when full
+func (s *MemorySink) Write(e Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+	if len(s.events) > s.size {
+		s.events = s.events[len(s.events)-s.size:]
+	}
+	return nil
+}
+
+// Query returns the kept events matching the filter
+func (s *MemorySink) Query(f Filter) ([]Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return f.apply(s.events), nil
+}
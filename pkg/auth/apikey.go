@@ -0,0 +1,81 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+
+	"gopkg.in/yaml.v2"
+)
+
+// APIKeyHeader is the header carrying static API keys
+const APIKeyHeader = "X-API-Key"
+
+// Key is an entry of the API keys file, only the sha256 of the key is stored
+type Key struct {
+	Name   string   `yaml:"name"`
+	SHA256 string   `yaml:"sha256"`
+	Groups []string `yaml:"groups"`
+}
+
+type keysFile struct {
+	Keys []Key `yaml:"keys"`
+}
+
+type apiKeyAuthenticator struct {
+	keys []apiKey
+}
+
+type apiKey struct {
+	hash      []byte
+	principal Principal
+}
+
+// NewAPIKeyAuthenticator authenticates requests with the keys listed in a YAML file of the form
+//  keys:
+//  - name: ci
+//    sha256: <hex encoded sha256 of the key>
+//    groups: [deployers]
+func NewAPIKeyAuthenticator(path string) (Authenticator, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f keysFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("error parsing api keys %s: %w", path, err)
+	}
+	return newAPIKeyAuthenticator(f.Keys)
+}
+
+func newAPIKeyAuthenticator(keys []Key) (Authenticator, error) {
+	a := &apiKeyAuthenticator{}
+	for _, k := range keys {
+		if k.Name == "" {
+			return nil, fmt.Errorf("api key name cannot be empty")
+		}
+		hash, err := hex.DecodeString(k.SHA256)
+		if err != nil || len(hash) != sha256.Size {
+			return nil, fmt.Errorf("api key %s must have a hex encoded sha256", k.Name)
+		}
+		a.keys = append(a.keys, apiKey{hash: hash, principal: Principal{Name: k.Name, Method: APIKey, Groups: k.Groups}})
+	}
+	return a, nil
+}
+
+func (a *apiKeyAuthenticator) Authenticate(r *http.Request) (Principal, error) {
+	key := r.Header.Get(APIKeyHeader)
+	if key == "" {
+		return Principal{}, ErrNoCredentials
+	}
+	hash := sha256.Sum256([]byte(key))
+	for _, k := range a.keys {
+		if subtle.ConstantTimeCompare(hash[:], k.hash) == 1 {
+			return k.principal, nil
+		}
+	}
+	return Principal{}, fmt.Errorf("%w: unknown api key", ErrInvalidCredentials)
+}
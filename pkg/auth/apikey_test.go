@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func hashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAPIKeyAuthenticator(t *testing.T) {
+	f, err := ioutil.TempFile("", "keys")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString("keys:\n- name: ci\n  sha256: " + hashKey("s3cret") + "\n  groups: [deployers]\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	a, err := NewAPIKeyAuthenticator(f.Name())
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		key  string
+		want Principal
+		err  error
+	}{
+		{name: "valid key", key: "s3cret", want: Principal{Name: "ci", Method: APIKey, Groups: []string{"deployers"}}},
+		{name: "unknown key", key: "guess", err: ErrInvalidCredentials},
+		{name: "no key", err: ErrNoCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.key != "" {
+				r.Header.Set(APIKeyHeader, tt.key)
+			}
+
+			got, err := a.Authenticate(r)
+
+			assert.True(t, errors.Is(err, tt.err), "got error %v", err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestAPIKeyAuthenticatorRejectsInvalidHashes(t *testing.T) {
+	_, err := newAPIKeyAuthenticator([]Key{{Name: "ci", SHA256: "s3cret"}})
+
+	assert.EqualError(t, err, "api key ci must have a hex encoded sha256")
+}
@@ -0,0 +1,84 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+// Method is the way a principal was authenticated
+type Method string
+
+const (
+	// APIKey principals sent a static key in the X-API-Key header
+	APIKey Method = "api_key"
+	// JWT principals sent a bearer token signed by a key of the configured JWKS
+	JWT Method = "jwt"
+	// MTLS principals presented a client certificate signed by the configured CA
+	MTLS Method = "mtls"
+)
+
+var (
+	// ErrNoCredentials is returned by an authenticator when the request has no credentials for it,
+	// the next authenticator is tried.
+	ErrNoCredentials = errors.New("no credentials")
+	// ErrInvalidCredentials is returned when the credentials are present but do not identify a principal
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	Name   string
+	Method Method
+	Groups []string
+}
+
+// Authenticator identifies the principal of a request
+type Authenticator interface {
+	Authenticate(r *http.Request) (Principal, error)
+}
+
+type principalKey struct{}
+
+// NewContext returns a copy of ctx carrying the principal
+func NewContext(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext returns the principal of the request ctx belongs to
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Middleware rejects requests none of the authenticators identify with 401 Unauthorized
+// and puts the principal in the request context otherwise.
+// Authenticators are tried in order, the first one finding credentials decides.
+func Middleware(authenticators ...Authenticator) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			p, err := authenticate(r, authenticators)
+			if err != nil {
+				logger.Errorf("[Auth] rejecting %s %s from %s: %v", r.Method, r.URL.Path, r.RemoteAddr, err)
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				apiErrors.Write(w, apiErrors.Wrap(apiErrors.Unauthorized, err))
+				return
+			}
+			next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), p)))
+		})
+	}
+}
+
+func authenticate(r *http.Request, authenticators []Authenticator) (Principal, error) {
+	for _, a := range authenticators {
+		p, err := a.Authenticate(r)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return p, err
+	}
+	return Principal{}, ErrNoCredentials
+}
@@ -0,0 +1,62 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+type stubAuthenticator struct {
+	principal Principal
+	err       error
+}
+
+func (s stubAuthenticator) Authenticate(r *http.Request) (Principal, error) {
+	return s.principal, s.err
+}
+
+func serve(authenticators ...Authenticator) (*httptest.ResponseRecorder, *Principal) {
+	logger.Setup("default")
+	var got *Principal
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p, ok := FromContext(r.Context()); ok {
+			got = &p
+		}
+	})
+	rec := httptest.NewRecorder()
+	Middleware(authenticators...)(next).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/clusters", nil))
+	return rec, got
+}
+
+func TestMiddlewarePutsPrincipalInContext(t *testing.T) {
+	ci := Principal{Name: "ci", Method: APIKey}
+
+	rec, got := serve(stubAuthenticator{err: ErrNoCredentials}, stubAuthenticator{principal: ci})
+
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, &ci, got)
+}
+
+func TestMiddlewareRejectsRequestsWithoutCredentials(t *testing.T) {
+	rec, got := serve(stubAuthenticator{err: ErrNoCredentials})
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+	assert.JSONEq(t, `{"code":"unauthorized","message":"no credentials"}`, rec.Body.String())
+	assert.Nil(t, got)
+}
+
+func TestMiddlewareStopsAtInvalidCredentials(t *testing.T) {
+	invalid := stubAuthenticator{err: errors.New("invalid credentials: expired")}
+
+	rec, got := serve(invalid, stubAuthenticator{principal: Principal{Name: "ci"}})
+
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.JSONEq(t, `{"code":"unauthorized","message":"invalid credentials: expired"}`, rec.Body.String())
+	assert.Nil(t, got)
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+// JWTConfig configures the validation of bearer tokens
+type JWTConfig struct {
+	// JWKSFile is the path of the JSON web key set holding the signing keys
+	JWKSFile string
+	// Issuer is the expected iss claim
+	Issuer string
+	// Audience is the expected aud claim, the claim is not checked when empty
+	Audience string
+}
+
+type jwtAuthenticator struct {
+	keys     jose.JSONWebKeySet
+	expected jwt.Expected
+	now      func() time.Time
+}
+
+type claims struct {
+	jwt.Claims
+	Groups []string `json:"groups"`
+}
+
+// NewJWTAuthenticator authenticates requests with an Authorization: Bearer token signed by a key of the JWKS.
+// The sub claim is the principal name and the groups claim its groups.
+func NewJWTAuthenticator(cfg JWTConfig) (Authenticator, error) {
+	if cfg.Issuer == "" {
+		return nil, errors.New("jwt issuer cannot be empty")
+	}
+	b, err := ioutil.ReadFile(cfg.JWKSFile)
+	if err != nil {
+		return nil, err
+	}
+	var keys jose.JSONWebKeySet
+	if err := json.Unmarshal(b, &keys); err != nil {
+		return nil, fmt.Errorf("error parsing jwks %s: %w", cfg.JWKSFile, err)
+	}
+	if len(keys.Keys) == 0 {
+		return nil, fmt.Errorf("jwks %s has no keys", cfg.JWKSFile)
+	}
+	for _, k := range keys.Keys {
+		if !k.IsPublic() {
+			return nil, fmt.Errorf("jwks %s must only contain public keys, %q is not", cfg.JWKSFile, k.KeyID)
+		}
+	}
+
+	expected := jwt.Expected{Issuer: cfg.Issuer}
+	if cfg.Audience != "" {
+		expected.Audience = jwt.Audience{cfg.Audience}
+	}
+	return &jwtAuthenticator{keys: keys, expected: expected, now: time.Now}, nil
+}
+
+func (a *jwtAuthenticator) Authenticate(r *http.Request) (Principal, error) {
+	header := r.Header.Get("Authorization")
+	if !strings.HasPrefix(header, "Bearer ") {
+		return Principal{}, ErrNoCredentials
+	}
+
+	token, err := jwt.ParseSigned(strings.TrimPrefix(header, "Bearer "))
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	c, err := a.verify(token)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	expected := a.expected.WithTime(a.now())
+	if err := c.Claims.ValidateWithLeeway(expected, jwt.DefaultLeeway); err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	if c.Expiry == nil {
+		return Principal{}, fmt.Errorf("%w: token has no expiry", ErrInvalidCredentials)
+	}
+	if c.Subject == "" {
+		return Principal{}, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+	return Principal{Name: c.Subject, Method: JWT, Groups: c.Groups}, nil
+}
+
+// verify checks the signature with the key named by the token, or with every key when the token names none
+func (a *jwtAuthenticator) verify(token *jwt.JSONWebToken) (claims, error) {
+	var c claims
+	if len(token.Headers) == 0 {
+		return c, errors.New("token has no header")
+	}
+	keys := a.keys.Keys
+	if kid := token.Headers[0].KeyID; kid != "" {
+		keys = a.keys.Key(kid)
+		if len(keys) == 0 {
+			return c, fmt.Errorf("unknown signing key %q", kid)
+		}
+	}
+	for _, k := range keys {
+		if err := token.Claims(k.Key, &c); err == nil {
+			return c, nil
+		}
+	}
+	return c, errors.New("invalid signature")
+}
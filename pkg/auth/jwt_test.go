@@ -0,0 +1,162 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+	jose "gopkg.in/square/go-jose.v2"
+	"gopkg.in/square/go-jose.v2/jwt"
+)
+
+const testIssuer = "https://auth.example.com"
+
+type JWTTestSuite struct {
+	suite.Suite
+	key      *rsa.PrivateKey
+	jwksFile string
+	auth     Authenticator
+	now      time.Time
+}
+
+func (s *JWTTestSuite) SetupTest() {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(s.T(), err)
+	s.key = key
+	jwks := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: &key.PublicKey, KeyID: "albatross", Algorithm: string(jose.RS256), Use: "sig"}}}
+	b, err := json.Marshal(jwks)
+	require.NoError(s.T(), err)
+	f, err := ioutil.TempFile("", "jwks")
+	require.NoError(s.T(), err)
+	_, err = f.Write(b)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), f.Close())
+	s.jwksFile = f.Name()
+
+	s.auth, err = NewJWTAuthenticator(JWTConfig{JWKSFile: s.jwksFile, Issuer: testIssuer, Audience: "albatross"})
+	require.NoError(s.T(), err)
+	s.now = time.Date(2021, 3, 24, 12, 0, 0, 0, time.UTC)
+	s.auth.(*jwtAuthenticator).now = func() time.Time { return s.now }
+}
+
+func (s *JWTTestSuite) TearDownTest() {
+	os.Remove(s.jwksFile)
+}
+
+func (s *JWTTestSuite) token(key *rsa.PrivateKey, kid string, c claims) string {
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.RS256, Key: key}, (&jose.SignerOptions{}).WithHeader("kid", kid))
+	require.NoError(s.T(), err)
+	token, err := jwt.Signed(signer).Claims(c).CompactSerialize()
+	require.NoError(s.T(), err)
+	return token
+}
+
+func (s *JWTTestSuite) validClaims() claims {
+	return claims{
+		Claims: jwt.Claims{
+			Subject:  "jane",
+			Issuer:   testIssuer,
+			Audience: jwt.Audience{"albatross"},
+			Expiry:   jwt.NewNumericDate(s.now.Add(time.Hour)),
+		},
+		Groups: []string{"deployers"},
+	}
+}
+
+func (s *JWTTestSuite) authenticate(token string) (Principal, error) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	return s.auth.Authenticate(r)
+}
+
+func (s *JWTTestSuite) TestValidToken() {
+	p, err := s.authenticate(s.token(s.key, "albatross", s.validClaims()))
+
+	require.NoError(s.T(), err)
+	assert.Equal(s.T(), Principal{Name: "jane", Method: JWT, Groups: []string{"deployers"}}, p)
+}
+
+func (s *JWTTestSuite) TestExpiredToken() {
+	c := s.validClaims()
+	c.Expiry = jwt.NewNumericDate(s.now.Add(-time.Hour))
+
+	_, err := s.authenticate(s.token(s.key, "albatross", c))
+
+	assert.True(s.T(), errors.Is(err, ErrInvalidCredentials))
+}
+
+func (s *JWTTestSuite) TestTokenWithoutExpiry() {
+	c := s.validClaims()
+	c.Expiry = nil
+
+	_, err := s.authenticate(s.token(s.key, "albatross", c))
+
+	assert.EqualError(s.T(), err, "invalid credentials: token has no expiry")
+}
+
+func (s *JWTTestSuite) TestWrongIssuer() {
+	c := s.validClaims()
+	c.Issuer = "https://evil.example.com"
+
+	_, err := s.authenticate(s.token(s.key, "albatross", c))
+
+	assert.True(s.T(), errors.Is(err, ErrInvalidCredentials))
+}
+
+func (s *JWTTestSuite) TestWrongAudience() {
+	c := s.validClaims()
+	c.Audience = jwt.Audience{"other"}
+
+	_, err := s.authenticate(s.token(s.key, "albatross", c))
+
+	assert.True(s.T(), errors.Is(err, ErrInvalidCredentials))
+}
+
+func (s *JWTTestSuite) TestUnknownSigningKey() {
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(s.T(), err)
+
+	_, err = s.authenticate(s.token(other, "albatross", s.validClaims()))
+
+	assert.EqualError(s.T(), err, "invalid credentials: invalid signature")
+}
+
+func (s *JWTTestSuite) TestUnknownKeyID() {
+	_, err := s.authenticate(s.token(s.key, "rotated", s.validClaims()))
+
+	assert.EqualError(s.T(), err, `invalid credentials: unknown signing key "rotated"`)
+}
+
+func (s *JWTTestSuite) TestNoBearerToken() {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", "Basic amFuZTpzM2NyZXQ=")
+
+	_, err := s.auth.Authenticate(r)
+
+	assert.Equal(s.T(), ErrNoCredentials, err)
+}
+
+func (s *JWTTestSuite) TestRejectsPrivateKeysInJWKS() {
+	jwks := jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: s.key, KeyID: "albatross"}}}
+	b, err := json.Marshal(jwks)
+	require.NoError(s.T(), err)
+	require.NoError(s.T(), ioutil.WriteFile(s.jwksFile, b, 0600))
+
+	_, err = NewJWTAuthenticator(JWTConfig{JWKSFile: s.jwksFile, Issuer: testIssuer})
+
+	assert.Error(s.T(), err)
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	suite.Run(t, new(JWTTestSuite))
+}
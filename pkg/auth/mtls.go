@@ -0,0 +1,41 @@
+package auth
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+)
+
+type mtlsAuthenticator struct{}
+
+// NewMTLSAuthenticator authenticates requests with the client certificate verified by the TLS server.
+// The certificate common name is the principal name and its organizations are the groups.
+func NewMTLSAuthenticator() Authenticator {
+	return mtlsAuthenticator{}
+}
+
+func (mtlsAuthenticator) Authenticate(r *http.Request) (Principal, error) {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.VerifiedChains[0]) == 0 {
+		return Principal{}, ErrNoCredentials
+	}
+	cert := r.TLS.VerifiedChains[0][0]
+	if cert.Subject.CommonName == "" {
+		return Principal{}, fmt.Errorf("%w: client certificate has no common name", ErrInvalidCredentials)
+	}
+	return Principal{Name: cert.Subject.CommonName, Method: MTLS, Groups: cert.Subject.Organization}, nil
+}
+
+// LoadClientCAs reads the PEM bundle of the CAs client certificates are verified against
+func LoadClientCAs(path string) (*x509.CertPool, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(b) {
+		return nil, errors.New("no certificates found in " + path)
+	}
+	return pool, nil
+}
@@ -0,0 +1,51 @@
+package auth
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMTLSAuthenticator(t *testing.T) {
+	tests := []struct {
+		name    string
+		subject *pkix.Name
+		want    Principal
+		err     error
+	}{
+		{
+			name:    "verified certificate",
+			subject: &pkix.Name{CommonName: "ci", Organization: []string{"deployers"}},
+			want:    Principal{Name: "ci", Method: MTLS, Groups: []string{"deployers"}},
+		},
+		{
+			name:    "certificate without common name",
+			subject: &pkix.Name{Organization: []string{"deployers"}},
+			err:     ErrInvalidCredentials,
+		},
+		{
+			name: "no certificate",
+			err:  ErrNoCredentials,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.TLS = &tls.ConnectionState{}
+			if tt.subject != nil {
+				r.TLS.VerifiedChains = [][]*x509.Certificate{{{Subject: *tt.subject}}}
+			}
+
+			got, err := NewMTLSAuthenticator().Authenticate(r)
+
+			assert.True(t, errors.Is(err, tt.err), "got error %v", err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package authz
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/auth"
+	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/upload"
+)
+
+// Middleware authorizes the principal of the request for verb before calling next and answers 403 Forbidden
+// with the reason when the policy denies it.
+// The cluster, namespace and release come from the route variables, install and template requests
+// name their release in the body, which is also where the verbs deploying or rendering a chart name it.
+// Chart reads name it in the chart query parameter, which is ignored for every other verb.
+// Uploaded chart archives have no chart name, so only rules without charts allow them.
+// Bodies larger than upload.MaxBodySize are answered with 413 Request Entity Too Large.
+func Middleware(p *Policy, verb Verb) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			principal, ok := auth.FromContext(r.Context())
+			if !ok {
+				respondForbidden(w, r, errors.New("request is not authenticated"))
+				return
+			}
+
+			req, err := newRequest(w, r, verb)
+			if errors.Is(err, upload.ErrBodyTooLarge) {
+				apiErrors.Write(w, err)
+				return
+			}
+			if err != nil {
+				respondForbidden(w, r, err)
+				return
+			}
+			if err := p.Authorize(principal, req); err != nil {
+				respondForbidden(w, r, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func newRequest(w http.ResponseWriter, r *http.Request, verb Verb) (Request, error) {
+	vars := mux.Vars(r)
+	req := Request{
+		Verb:      verb,
+		Cluster:   vars["cluster"],
+		Namespace: vars["namespace"],
+		Release:   vars["release_name"],
+	}
+	if verb == ReadCharts {
+		req.Chart = r.URL.Query().Get("chart")
+	}
+	body, err := upload.ReadBody(w, r)
+	if err != nil || body == nil {
+		return req, err
+	}
+	var named struct {
+		Name  string `json:"name"`
+		Chart string `json:"chart"`
+	}
+	// malformed bodies are rejected by the handler
+	if json.Unmarshal(upload.RequestJSON(r, body), &named) == nil {
+		if deploys[verb] {
+			req.Chart = named.Chart
+			req.Upload = upload.HasArchive(r, body)
+		}
+		if req.Release == "" && (verb == Install || verb == Template) {
+			req.Release = named.Name
+		}
+	}
+	return req, nil
+}
+
+func respondForbidden(w http.ResponseWriter, r *http.Request, err error) {
+	logger.Errorf("[Authz] denying %s %s: %v", r.Method, r.URL.Path, err)
+	apiErrors.Write(w, apiErrors.Wrap(apiErrors.Forbidden, err))
+}
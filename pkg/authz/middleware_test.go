@@ -0,0 +1,153 @@
+package authz
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/stretchr/testify/suite"
+
+	"github.com/gojekfarm/albatross/pkg/auth"
+	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/upload"
+)
+
+type MiddlewareTestSuite struct {
+	suite.Suite
+	router    *mux.Router
+	principal *auth.Principal
+	body      string
+}
+
+func (s *MiddlewareTestSuite) SetupSuite() {
+	logger.Setup("default")
+}
+
+func (s *MiddlewareTestSuite) SetupTest() {
+	policy := &Policy{Roles: []Role{{
+		Name:     "payments",
+		Subjects: []Subject{{Group: "payments"}},
+		Rules: []Rule{{
+			Verbs:      []Verb{Install, Upgrade},
+			Namespaces: []string{"payments"},
+			Releases:   []string{"api-*"},
+			Charts:     []string{"stable/*"},
+		}, {
+			Verbs:      []Verb{Install},
+			Namespaces: []string{"sandbox"},
+		}, {
+			Verbs:  []Verb{ReadCharts},
+			Charts: []string{"stable/*"},
+		}},
+	}}}
+	s.principal = &auth.Principal{Name: "ci", Groups: []string{"payments"}}
+	s.body = ""
+	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		s.body = string(b)
+	})
+	withPrincipal := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if s.principal != nil {
+				r = r.WithContext(auth.NewContext(r.Context(), *s.principal))
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+	s.router = mux.NewRouter()
+	s.router.Use(withPrincipal)
+	s.router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases", Middleware(policy, Install)(handler)).Methods(http.MethodPost)
+	s.router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}", Middleware(policy, Upgrade)(handler)).Methods(http.MethodPut)
+	s.router.Handle("/charts/show", Middleware(policy, ReadCharts)(handler)).Methods(http.MethodGet)
+}
+
+func (s *MiddlewareTestSuite) serve(method, path, body string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	s.router.ServeHTTP(rec, httptest.NewRequest(method, path, strings.NewReader(body)))
+	return rec
+}
+
+func (s *MiddlewareTestSuite) TestAllowsInstallAndKeepsTheBody() {
+	body := `{"name":"api-1","chart":"stable/redis"}`
+
+	rec := s.serve(http.MethodPost, "/clusters/staging/namespaces/payments/releases", body)
+
+	assert.Equal(s.T(), http.StatusOK, rec.Code)
+	assert.Equal(s.T(), body, s.body)
+}
+
+func (s *MiddlewareTestSuite) TestUsesReleaseNameFromTheBodyForInstall() {
+	rec := s.serve(http.MethodPost, "/clusters/staging/namespaces/payments/releases", `{"name":"worker","chart":"stable/redis"}`)
+
+	assert.Equal(s.T(), http.StatusForbidden, rec.Code)
+	assert.JSONEq(s.T(), `{"code":"forbidden","message":"forbidden: ci may not install release worker in namespace payments of cluster staging"}`, rec.Body.String())
+}
+
+func (s *MiddlewareTestSuite) TestUsesReleaseNameFromThePathForUpgrade() {
+	rec := s.serve(http.MethodPut, "/clusters/staging/namespaces/payments/releases/api-1", `{"name":"worker","chart":"stable/redis"}`)
+
+	assert.Equal(s.T(), http.StatusOK, rec.Code)
+}
+
+func (s *MiddlewareTestSuite) TestDeniesChartsThatAreNotAllowed() {
+	rec := s.serve(http.MethodPut, "/clusters/staging/namespaces/payments/releases/api-1", `{"chart":"incubator/redis"}`)
+
+	assert.Equal(s.T(), http.StatusForbidden, rec.Code)
+	assert.JSONEq(s.T(), `{"code":"forbidden","message":"forbidden: ci may not upgrade chart incubator/redis"}`, rec.Body.String())
+	assert.Empty(s.T(), s.body)
+}
+
+func (s *MiddlewareTestSuite) TestUsesChartFromTheQuery() {
+	allowed := s.serve(http.MethodGet, "/charts/show?chart=stable/redis", "")
+	denied := s.serve(http.MethodGet, "/charts/show?chart=incubator/redis", "")
+
+	assert.Equal(s.T(), http.StatusOK, allowed.Code)
+	assert.Equal(s.T(), http.StatusForbidden, denied.Code)
+	assert.JSONEq(s.T(), `{"code":"forbidden","message":"forbidden: ci may not read_charts chart incubator/redis"}`, denied.Body.String())
+}
+
+func (s *MiddlewareTestSuite) TestIgnoresTheQueryChartForDeployingVerbs() {
+	rec := s.serve(http.MethodPost, "/clusters/staging/namespaces/payments/releases?chart=stable/redis", `{"name":"api-1","chart":"incubator/redis"}`)
+
+	assert.Equal(s.T(), http.StatusForbidden, rec.Code)
+	assert.JSONEq(s.T(), `{"code":"forbidden","message":"forbidden: ci may not install chart incubator/redis"}`, rec.Body.String())
+}
+
+func (s *MiddlewareTestSuite) TestDeniesUploadsUnlessARuleAllowsEveryChart() {
+	uploaded := `{"name":"api-1","chart_archive":"YXJjaGl2ZQ=="}`
+
+	denied := s.serve(http.MethodPost, "/clusters/staging/namespaces/payments/releases?chart=stable/redis", uploaded)
+	allowed := s.serve(http.MethodPost, "/clusters/staging/namespaces/sandbox/releases", uploaded)
+
+	assert.Equal(s.T(), http.StatusForbidden, denied.Code)
+	assert.JSONEq(s.T(), `{"code":"forbidden","message":"forbidden: ci may not install an uploaded chart"}`, denied.Body.String())
+	assert.Equal(s.T(), http.StatusOK, allowed.Code)
+}
+
+func (s *MiddlewareTestSuite) TestRejectsBodiesLargerThanAnUpload() {
+	body := `{"name":"api-1","chart":"stable/redis","values":"` + strings.Repeat("x", upload.MaxBodySize) + `"}`
+
+	rec := s.serve(http.MethodPost, "/clusters/staging/namespaces/payments/releases", body)
+
+	assert.Equal(s.T(), http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Contains(s.T(), rec.Body.String(), `"code":"too_large"`)
+	assert.Empty(s.T(), s.body)
+}
+
+func (s *MiddlewareTestSuite) TestDeniesUnauthenticatedRequests() {
+	s.principal = nil
+
+	rec := s.serve(http.MethodPut, "/clusters/staging/namespaces/payments/releases/api-1", `{"chart":"stable/redis"}`)
+
+	require.Equal(s.T(), http.StatusForbidden, rec.Code)
+	assert.JSONEq(s.T(), `{"code":"forbidden","message":"request is not authenticated"}`, rec.Body.String())
+}
+
+func TestMiddleware(t *testing.T) {
+	suite.Run(t, new(MiddlewareTestSuite))
+}
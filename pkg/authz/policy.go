@@ -0,0 +1,231 @@
+package authz
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/gojekfarm/albatross/pkg/auth"
+)
+
+// Verb is an action on releases, named after the handler performing it
+type Verb string
+
+// Status also covers the values, manifest, notes and hooks of a release.
+// ManageClusters and ManageRepositories cover the cluster registry and chart repository routes,
+// ReadAudit covers the audit log and ReadCharts the chart search and show routes.
+const (
+	List               Verb = "list"
+	Status             Verb = "status"
+	History            Verb = "history"
+	Install            Verb = "install"
+	Upgrade            Verb = "upgrade"
+	Uninstall          Verb = "uninstall"
+	Rollback           Verb = "rollback"
+	Recover            Verb = "recover"
+	Test               Verb = "test"
+	Diff               Verb = "diff"
+	Template           Verb = "template"
+	ManageClusters     Verb = "manage_clusters"
+	ManageRepositories Verb = "manage_repositories"
+	ReadAudit          Verb = "read_audit"
+	ReadCharts         Verb = "read_charts"
+)
+
+var verbs = map[Verb]bool{
+	List: true, Status: true, History: true, Install: true, Upgrade: true, Uninstall: true, Rollback: true,
+	Test: true, Diff: true, Template: true, ManageClusters: true, ManageRepositories: true, ReadAudit: true,
+	ReadCharts: true, Recover: true,
+}
+
+// deploys are the verbs deploying or rendering the chart named in their body, so they are always restricted
+// by the allowed charts
+var deploys = map[Verb]bool{Install: true, Upgrade: true, Diff: true, Template: true}
+
+// ErrForbidden is wrapped by the errors of denied requests, the rest of the message is the reason
+var ErrForbidden = errors.New("forbidden")
+
+// Policy is the declarative authorization policy, requests no role allows are denied
+type Policy struct {
+	Roles []Role `yaml:"roles"`
+}
+
+// Role grants its rules to its subjects
+type Role struct {
+	Name     string    `yaml:"name"`
+	Subjects []Subject `yaml:"subjects"`
+	Rules    []Rule    `yaml:"rules"`
+}
+
+// Subject is a principal name or one of its groups, exactly one of them is set
+type Subject struct {
+	User  string `yaml:"user,omitempty"`
+	Group string `yaml:"group,omitempty"`
+}
+
+// Rule allows verbs on the releases matching all its glob patterns, an omitted pattern list matches anything.
+// Patterns use path.Match syntax, so * does not match a / in chart names.
+type Rule struct {
+	Verbs      []Verb   `yaml:"verbs"`
+	Clusters   []string `yaml:"clusters,omitempty"`
+	Namespaces []string `yaml:"namespaces,omitempty"`
+	Releases   []string `yaml:"releases,omitempty"`
+	Charts     []string `yaml:"charts,omitempty"`
+}
+
+// Request is the action being authorized, fields that do not apply to the verb are empty
+type Request struct {
+	Verb      Verb
+	Cluster   string
+	Namespace string
+	Release   string
+	// Chart is only set for the verbs deploying, rendering or reading a chart
+	Chart string
+	// Upload is set for requests deploying an uploaded chart archive, which has no name
+	Upload bool
+}
+
+// Load reads and validates a policy file
+func Load(file string) (*Policy, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var p Policy
+	if err := yaml.UnmarshalStrict(b, &p); err != nil {
+		return nil, fmt.Errorf("error parsing policy %s: %w", file, err)
+	}
+	if err := p.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid policy %s: %w", file, err)
+	}
+	return &p, nil
+}
+
+// Validate checks the subjects, verbs and patterns of every role
+func (p *Policy) Validate() error {
+	for _, role := range p.Roles {
+		if role.Name == "" {
+			return errors.New("role name cannot be empty")
+		}
+		for _, s := range role.Subjects {
+			if (s.User == "") == (s.Group == "") {
+				return fmt.Errorf("role %s: a subject must have exactly one of user or group", role.Name)
+			}
+		}
+		for _, rule := range role.Rules {
+			if len(rule.Verbs) == 0 {
+				return fmt.Errorf("role %s: a rule must have verbs", role.Name)
+			}
+			for _, v := range rule.Verbs {
+				if !verbs[v] {
+					return fmt.Errorf("role %s: unknown verb %q", role.Name, v)
+				}
+			}
+			for _, patterns := range [][]string{rule.Clusters, rule.Namespaces, rule.Releases, rule.Charts} {
+				for _, pattern := range patterns {
+					if _, err := path.Match(pattern, ""); err != nil {
+						return fmt.Errorf("role %s: invalid pattern %q: %w", role.Name, pattern, err)
+					}
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Authorize returns nil when a role of the principal allows the request,
+// otherwise an error wrapping ErrForbidden with the reason.
+func (p *Policy) Authorize(principal auth.Principal, req Request) error {
+	chartDenied := false
+	for _, role := range p.Roles {
+		if !role.boundTo(principal) {
+			continue
+		}
+		for _, rule := range role.Rules {
+			if !rule.matches(req) {
+				continue
+			}
+			if rule.allowsChart(req) {
+				return nil
+			}
+			chartDenied = true
+		}
+	}
+
+	switch {
+	case chartDenied && req.Upload:
+		return fmt.Errorf("%w: %s may not %s an uploaded chart", ErrForbidden, principal.Name, req.Verb)
+	case chartDenied && req.Chart == "":
+		return fmt.Errorf("%w: %s may not %s an unnamed chart", ErrForbidden, principal.Name, req.Verb)
+	case chartDenied:
+		return fmt.Errorf("%w: %s may not %s chart %s", ErrForbidden, principal.Name, req.Verb, req.Chart)
+	}
+	return fmt.Errorf("%w: %s may not %s %s", ErrForbidden, principal.Name, req.Verb, req.target())
+}
+
+func (role Role) boundTo(principal auth.Principal) bool {
+	for _, s := range role.Subjects {
+		if s.User != "" && s.User == principal.Name {
+			return true
+		}
+		for _, group := range principal.Groups {
+			if s.Group != "" && s.Group == group {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func (rule Rule) matches(req Request) bool {
+	hasVerb := false
+	for _, v := range rule.Verbs {
+		hasVerb = hasVerb || v == req.Verb
+	}
+	return hasVerb &&
+		matchAny(rule.Clusters, req.Cluster) &&
+		matchAny(rule.Namespaces, req.Namespace) &&
+		matchAny(rule.Releases, req.Release)
+}
+
+// allowsChart tells whether the rule allows the chart of the request. Uploaded charts have no name, so only rules
+// without charts allow them. Chart searches and the verbs acting on existing releases name no chart and are not restricted.
+func (rule Rule) allowsChart(req Request) bool {
+	switch {
+	case req.Upload:
+		return len(rule.Charts) == 0
+	case req.Chart == "" && !deploys[req.Verb]:
+		return true
+	default:
+		return matchAny(rule.Charts, req.Chart)
+	}
+}
+
+func matchAny(patterns []string, value string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, value); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// target describes the release, namespace or cluster of the request for denial reasons
+func (req Request) target() string {
+	switch {
+	case req.Release != "":
+		return fmt.Sprintf("release %s in namespace %s of cluster %s", req.Release, req.Namespace, req.Cluster)
+	case req.Namespace != "":
+		return fmt.Sprintf("namespace %s of cluster %s", req.Namespace, req.Cluster)
+	case req.Cluster != "":
+		return fmt.Sprintf("cluster %s", req.Cluster)
+	default:
+		return "resources"
+	}
+}
@@ -0,0 +1,172 @@
+package authz
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/albatross/pkg/auth"
+)
+
+const testPolicy = `
+roles:
+- name: payments
+  subjects:
+  - group: payments
+  rules:
+  - verbs: [install, upgrade, uninstall, list, status]
+    clusters: ["staging-*"]
+    namespaces: [payments, "payments-*"]
+    charts: ["stable/*"]
+- name: admin
+  subjects:
+  - user: jane
+  rules:
+  - verbs: [list]
+`
+
+func loadTestPolicy(t *testing.T, content string) (*Policy, error) {
+	f, err := ioutil.TempFile("", "policy")
+	require.NoError(t, err)
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(content)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	return Load(f.Name())
+}
+
+func TestPolicyAuthorize(t *testing.T) {
+	policy, err := loadTestPolicy(t, testPolicy)
+	require.NoError(t, err)
+	payments := auth.Principal{Name: "ci", Groups: []string{"payments"}}
+	jane := auth.Principal{Name: "jane"}
+
+	tests := []struct {
+		name      string
+		principal auth.Principal
+		req       Request
+		reason    string
+	}{
+		{
+			name:      "team installs in its namespace",
+			principal: payments,
+			req:       Request{Verb: Install, Cluster: "staging-1", Namespace: "payments", Release: "api", Chart: "stable/redis"},
+		},
+		{
+			name:      "team lists its namespace",
+			principal: payments,
+			req:       Request{Verb: List, Cluster: "staging-1", Namespace: "payments-jobs"},
+		},
+		{
+			name:      "team upgrades in another namespace",
+			principal: payments,
+			req:       Request{Verb: Upgrade, Cluster: "staging-1", Namespace: "orders", Release: "api", Chart: "stable/redis"},
+			reason:    "forbidden: ci may not upgrade release api in namespace orders of cluster staging-1",
+		},
+		{
+			name:      "team lists every namespace",
+			principal: payments,
+			req:       Request{Verb: List, Cluster: "staging-1"},
+			reason:    "forbidden: ci may not list cluster staging-1",
+		},
+		{
+			name:      "team installs a chart that is not allowed",
+			principal: payments,
+			req:       Request{Verb: Install, Cluster: "staging-1", Namespace: "payments", Release: "api", Chart: "incubator/redis"},
+			reason:    "forbidden: ci may not install chart incubator/redis",
+		},
+		{
+			name:      "team installs without naming a chart",
+			principal: payments,
+			req:       Request{Verb: Install, Cluster: "staging-1", Namespace: "payments", Release: "api"},
+			reason:    "forbidden: ci may not install an unnamed chart",
+		},
+		{
+			name:      "team installs an uploaded chart",
+			principal: payments,
+			req:       Request{Verb: Install, Cluster: "staging-1", Namespace: "payments", Release: "api", Upload: true},
+			reason:    "forbidden: ci may not install an uploaded chart",
+		},
+		{
+			name:      "team installs an uploaded chart claiming an allowed name",
+			principal: payments,
+			req:       Request{Verb: Install, Cluster: "staging-1", Namespace: "payments", Release: "api", Chart: "stable/redis", Upload: true},
+			reason:    "forbidden: ci may not install an uploaded chart",
+		},
+		{
+			name:      "team uninstalls without naming a chart",
+			principal: payments,
+			req:       Request{Verb: Uninstall, Cluster: "staging-1", Namespace: "payments", Release: "api"},
+		},
+		{
+			name:      "team rolls back",
+			principal: payments,
+			req:       Request{Verb: Rollback, Cluster: "staging-1", Namespace: "payments", Release: "api"},
+			reason:    "forbidden: ci may not rollback release api in namespace payments of cluster staging-1",
+		},
+		{
+			name:      "rules without patterns match everything",
+			principal: jane,
+			req:       Request{Verb: List, Cluster: "production"},
+		},
+		{
+			name:      "principal without roles",
+			principal: auth.Principal{Name: "john"},
+			req:       Request{Verb: List, Cluster: "production"},
+			reason:    "forbidden: john may not list cluster production",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := policy.Authorize(tt.principal, tt.req)
+
+			if tt.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tt.reason)
+			assert.True(t, errors.Is(err, ErrForbidden))
+		})
+	}
+}
+
+func TestLoadRejectsInvalidPolicies(t *testing.T) {
+	tests := []struct {
+		name    string
+		content string
+		wantErr string
+	}{
+		{
+			name:    "unknown verb",
+			content: "roles:\n- name: dev\n  rules:\n  - verbs: [delete]\n",
+			wantErr: `role dev: unknown verb "delete"`,
+		},
+		{
+			name:    "subject with user and group",
+			content: "roles:\n- name: dev\n  subjects:\n  - user: jane\n    group: dev\n",
+			wantErr: "role dev: a subject must have exactly one of user or group",
+		},
+		{
+			name:    "invalid pattern",
+			content: "roles:\n- name: dev\n  rules:\n  - verbs: [list]\n    namespaces: [\"[\"]\n",
+			wantErr: `role dev: invalid pattern "["`,
+		},
+		{
+			name:    "unknown field",
+			content: "roles:\n- name: dev\n  verbs: [list]\n",
+			wantErr: "error parsing policy",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := loadTestPolicy(t, tt.content)
+
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,76 @@
+package cluster
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+// AuthMethod is the way albatross authenticates against a cluster
+type AuthMethod string
+
+const (
+	// TokenAuth authenticates with a bearer token, inline or read from a file
+	TokenAuth AuthMethod = "token"
+	// ClientCertificateAuth authenticates with a client certificate and key
+	ClientCertificateAuth AuthMethod = "client_certificate"
+	// KubeconfigAuth uses a context of a kubeconfig file available to the server
+	KubeconfigAuth AuthMethod = "kubeconfig"
+)
+
+var (
+	// ErrNotFound is returned when a cluster is not registered
+	ErrNotFound = errors.New("cluster not found")
+
+	validName = regexp.MustCompile(`^[a-zA-Z0-9]([-a-zA-Z0-9_.]*[a-zA-Z0-9])?$`)
+)
+
+// Cluster holds everything needed to connect to a kubernetes cluster
+type Cluster struct {
+	Name                  string `yaml:"name"`
+	APIServer             string `yaml:"api_server,omitempty"`
+	CAData                string `yaml:"ca_data,omitempty"`
+	InsecureSkipTLSVerify bool   `yaml:"insecure_skip_tls_verify,omitempty"`
+	DefaultNamespace      string `yaml:"default_namespace,omitempty"`
+	Auth                  Auth   `yaml:"auth"`
+}
+
+// Auth holds the credentials of a cluster, only the fields of the method are used
+type Auth struct {
+	Method                AuthMethod `yaml:"method"`
+	Token                 string     `yaml:"token,omitempty"`
+	TokenFile             string     `yaml:"token_file,omitempty"`
+	ClientCertificateData string     `yaml:"client_certificate_data,omitempty"`
+	ClientKeyData         string     `yaml:"client_key_data,omitempty"`
+	Kubeconfig            string     `yaml:"kubeconfig,omitempty"`
+	Context               string     `yaml:"context,omitempty"`
+}
+
+// Validate checks that the cluster can be connected to with its auth method
+func (c Cluster) Validate() error {
+	if !validName.MatchString(c.Name) {
+		return fmt.Errorf("cluster name %q must match regex %s", c.Name, validName.String())
+	}
+	switch c.Auth.Method {
+	case TokenAuth:
+		if c.Auth.Token == "" && c.Auth.TokenFile == "" {
+			return errors.New("token auth requires a token or a token_file")
+		}
+	case ClientCertificateAuth:
+		if c.Auth.ClientCertificateData == "" || c.Auth.ClientKeyData == "" {
+			return errors.New("client_certificate auth requires client_certificate_data and client_key_data")
+		}
+	case KubeconfigAuth:
+		// the kubeconfig context already names the api server and the credentials
+		if c.APIServer != "" || c.Auth.Token != "" {
+			return errors.New("kubeconfig auth cannot be combined with api_server or token")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown auth method %q, expected one of %s, %s or %s", c.Auth.Method, TokenAuth, ClientCertificateAuth, KubeconfigAuth)
+	}
+	if c.APIServer == "" {
+		return errors.New("api_server cannot be empty")
+	}
+	return nil
+}
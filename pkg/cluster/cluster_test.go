@@ -0,0 +1,79 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClusterValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		cluster Cluster
+		wantErr string
+	}{
+		{
+			name:    "token",
+			cluster: Cluster{Name: "staging", APIServer: "https://10.0.0.1", Auth: Auth{Method: TokenAuth, Token: "token"}},
+		},
+		{
+			name:    "token file",
+			cluster: Cluster{Name: "staging", APIServer: "https://10.0.0.1", Auth: Auth{Method: TokenAuth, TokenFile: "/tmp/token"}},
+		},
+		{
+			name:    "client certificate",
+			cluster: Cluster{Name: "staging", APIServer: "https://10.0.0.1", Auth: Auth{Method: ClientCertificateAuth, ClientCertificateData: "cert", ClientKeyData: "key"}},
+		},
+		{
+			name:    "kubeconfig without api server",
+			cluster: Cluster{Name: "staging", Auth: Auth{Method: KubeconfigAuth, Context: "gke_staging"}},
+		},
+		{
+			name:    "invalid name",
+			cluster: Cluster{Name: "staging/1", APIServer: "https://10.0.0.1", Auth: Auth{Method: TokenAuth, Token: "token"}},
+			wantErr: `cluster name "staging/1" must match regex`,
+		},
+		{
+			name:    "missing token",
+			cluster: Cluster{Name: "staging", APIServer: "https://10.0.0.1", Auth: Auth{Method: TokenAuth}},
+			wantErr: "token auth requires a token or a token_file",
+		},
+		{
+			name:    "missing client key",
+			cluster: Cluster{Name: "staging", APIServer: "https://10.0.0.1", Auth: Auth{Method: ClientCertificateAuth, ClientCertificateData: "cert"}},
+			wantErr: "client_certificate auth requires client_certificate_data and client_key_data",
+		},
+		{
+			name:    "missing api server",
+			cluster: Cluster{Name: "staging", Auth: Auth{Method: TokenAuth, Token: "token"}},
+			wantErr: "api_server cannot be empty",
+		},
+		{
+			name:    "kubeconfig with api server",
+			cluster: Cluster{Name: "staging", APIServer: "https://10.0.0.1", Auth: Auth{Method: KubeconfigAuth, Context: "gke_staging"}},
+			wantErr: "kubeconfig auth cannot be combined with api_server or token",
+		},
+		{
+			name:    "kubeconfig with token",
+			cluster: Cluster{Name: "staging", Auth: Auth{Method: KubeconfigAuth, Context: "gke_staging", Token: "token"}},
+			wantErr: "kubeconfig auth cannot be combined with api_server or token",
+		},
+		{
+			name:    "unknown method",
+			cluster: Cluster{Name: "staging", APIServer: "https://10.0.0.1", Auth: Auth{Method: "basic"}},
+			wantErr: `unknown auth method "basic"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.cluster.Validate()
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package cluster
+
+import (
+	"sync"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/discovery/cached/memory"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// RESTClientGetter returns a getter for the kube clients of the cluster scoped to namespace.
+// The cluster default namespace is used when namespace is empty.
+func (c Cluster) RESTClientGetter(namespace string) genericclioptions.RESTClientGetter {
+	if namespace == "" {
+		namespace = c.DefaultNamespace
+	}
+
+	if c.Auth.Method == KubeconfigAuth {
+		return kube.GetConfig(c.Auth.Kubeconfig, c.Auth.Context, namespace)
+	}
+
+	config := clientcmdapi.NewConfig()
+	config.Clusters[c.Name] = &clientcmdapi.Cluster{
+		Server:                   c.APIServer,
+		CertificateAuthorityData: []byte(c.CAData),
+		InsecureSkipTLSVerify:    c.InsecureSkipTLSVerify,
+	}
+	config.AuthInfos[c.Name] = &clientcmdapi.AuthInfo{
+		Token:                 c.Auth.Token,
+		TokenFile:             c.Auth.TokenFile,
+		ClientCertificateData: []byte(c.Auth.ClientCertificateData),
+		ClientKeyData:         []byte(c.Auth.ClientKeyData),
+	}
+	config.Contexts[c.Name] = &clientcmdapi.Context{
+		Cluster:   c.Name,
+		AuthInfo:  c.Name,
+		Namespace: namespace,
+	}
+	config.CurrentContext = c.Name
+
+	return &restClientGetter{
+		clientConfig: clientcmd.NewDefaultClientConfig(*config, &clientcmd.ConfigOverrides{}),
+	}
+}
+
+// restClientGetter builds kube clients from an in-memory kubeconfig.
+// Discovery results are cached for the lifetime of the getter, which is a single helm action.
+type restClientGetter struct {
+	clientConfig clientcmd.ClientConfig
+
+	mu        sync.Mutex
+	discovery discovery.CachedDiscoveryInterface
+}
+
+func (g *restClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return g.clientConfig.ClientConfig()
+}
+
+func (g *restClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.discovery != nil {
+		return g.discovery, nil
+	}
+
+	config, err := g.ToRESTConfig()
+	if err != nil {
+		return nil, err
+	}
+	// matches the burst used by kubectl so that discovery on large clusters is not throttled
+	config.Burst = 100
+	client, err := discovery.NewDiscoveryClientForConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	g.discovery = memory.NewMemCacheClient(client)
+	return g.discovery, nil
+}
+
+func (g *restClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	discoveryClient, err := g.ToDiscoveryClient()
+	if err != nil {
+		return nil, err
+	}
+	mapper := restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient)
+	return restmapper.NewShortcutExpander(mapper, discoveryClient), nil
+}
+
+func (g *restClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	return g.clientConfig
+}
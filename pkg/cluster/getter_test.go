@@ -0,0 +1,48 @@
+package cluster
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRESTClientGetterUsesClusterCredentials(t *testing.T) {
+	c := Cluster{
+		Name:                  "staging",
+		APIServer:             "https://10.0.0.1:6443",
+		InsecureSkipTLSVerify: true,
+		DefaultNamespace:      "albatross",
+		Auth:                  Auth{Method: TokenAuth, Token: "secret-token"},
+	}
+
+	getter := c.RESTClientGetter("")
+
+	config, err := getter.ToRESTConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://10.0.0.1:6443", config.Host)
+	assert.Equal(t, "secret-token", config.BearerToken)
+	assert.True(t, config.Insecure)
+	namespace, _, err := getter.ToRawKubeConfigLoader().Namespace()
+	require.NoError(t, err)
+	assert.Equal(t, "albatross", namespace)
+}
+
+func TestRESTClientGetterPrefersRequestNamespace(t *testing.T) {
+	c := Cluster{
+		Name:             "staging",
+		APIServer:        "https://10.0.0.1:6443",
+		DefaultNamespace: "albatross",
+		Auth:             Auth{Method: ClientCertificateAuth, ClientCertificateData: "cert", ClientKeyData: "key"},
+	}
+
+	getter := c.RESTClientGetter("payments")
+
+	config, err := getter.ToRESTConfig()
+	require.NoError(t, err)
+	assert.Equal(t, []byte("cert"), config.CertData)
+	assert.Equal(t, []byte("key"), config.KeyData)
+	namespace, _, err := getter.ToRawKubeConfigLoader().Namespace()
+	require.NoError(t, err)
+	assert.Equal(t, "payments", namespace)
+}
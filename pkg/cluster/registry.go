@@ -0,0 +1,195 @@
+package cluster
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+	"gopkg.in/yaml.v2"
+
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+const lockTimeout = 30 * time.Second
+
+// Lookup resolves a cluster by name
+type Lookup interface {
+	Get(name string) (Cluster, error)
+}
+
+// file is the layout of the cluster registry config file
+type file struct {
+	Clusters []Cluster `yaml:"clusters"`
+}
+
+// Registry keeps the registered clusters in a YAML file shared by every albatross process using it.
+// A registry without a file keeps the clusters in memory.
+type Registry struct {
+	path     string
+	mu       sync.RWMutex
+	clusters map[string]Cluster
+}
+
+// NewRegistry loads the registry from path, a missing file is an empty registry
+func NewRegistry(path string) (*Registry, error) {
+	r := &Registry{path: path, clusters: map[string]Cluster{}}
+	if path == "" {
+		return r, nil
+	}
+	clusters, err := r.read()
+	if err != nil {
+		return nil, fmt.Errorf("error loading cluster registry %s: %w", path, err)
+	}
+	for _, c := range clusters {
+		if err := c.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid cluster %s in %s: %w", c.Name, path, err)
+		}
+	}
+	return r, nil
+}
+
+// List returns the registered clusters sorted by name
+func (r *Registry) List() ([]Cluster, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	clusters, err := r.load()
+	if err != nil {
+		return nil, err
+	}
+	list := make([]Cluster, 0, len(clusters))
+	for _, c := range clusters {
+		list = append(list, c)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list, nil
+}
+
+// Get returns a registered cluster or ErrNotFound
+func (r *Registry) Get(name string) (Cluster, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	clusters, err := r.load()
+	if err != nil {
+		return Cluster{}, err
+	}
+	c, ok := clusters[name]
+	if !ok {
+		return Cluster{}, ErrNotFound
+	}
+	return c, nil
+}
+
+// Put registers a cluster or replaces the cluster with the same name
+func (r *Registry) Put(ctx context.Context, c Cluster) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+	return r.update(ctx, func(clusters map[string]Cluster) error {
+		clusters[c.Name] = c
+		return nil
+	})
+}
+
+// Delete removes a cluster or returns ErrNotFound
+func (r *Registry) Delete(ctx context.Context, name string) error {
+	return r.update(ctx, func(clusters map[string]Cluster) error {
+		if _, ok := clusters[name]; !ok {
+			return ErrNotFound
+		}
+		delete(clusters, name)
+		return nil
+	})
+}
+
+func (r *Registry) update(ctx context.Context, fn func(map[string]Cluster) error) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.path == "" {
+		return fn(r.clusters)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), os.ModePerm); err != nil && !os.IsExist(err) {
+		return err
+	}
+	// Acquire a file lock for process synchronization
+	fileLock := flock.New(strings.Replace(r.path, filepath.Ext(r.path), ".lock", 1))
+	lockCtx, cancel := context.WithTimeout(ctx, lockTimeout)
+	defer cancel()
+	locked, err := fileLock.TryLockContext(lockCtx, time.Second)
+	if err != nil {
+		return err
+	}
+	if locked {
+		defer func() {
+			if err := fileLock.Unlock(); err != nil {
+				logger.Errorf("[Cluster] error unlocking %s: %v", r.path, err)
+			}
+		}()
+	}
+
+	clusters, err := r.load()
+	if err != nil {
+		return err
+	}
+	if err := fn(clusters); err != nil {
+		return err
+	}
+	return r.write(clusters)
+}
+
+// load returns the current clusters, callers must hold the lock
+func (r *Registry) load() (map[string]Cluster, error) {
+	if r.path == "" {
+		clusters := make(map[string]Cluster, len(r.clusters))
+		for name, c := range r.clusters {
+			clusters[name] = c
+		}
+		return clusters, nil
+	}
+	list, err := r.read()
+	if err != nil {
+		return nil, err
+	}
+	clusters := make(map[string]Cluster, len(list))
+	for _, c := range list {
+		clusters[c.Name] = c
+	}
+	return clusters, nil
+}
+
+func (r *Registry) read() ([]Cluster, error) {
+	b, err := ioutil.ReadFile(r.path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	var f file
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+	return f.Clusters, nil
+}
+
+// write replaces the registry file atomically, it contains credentials so it is only readable by the owner
+func (r *Registry) write(clusters map[string]Cluster) error {
+	var f file
+	for _, c := range clusters {
+		f.Clusters = append(f.Clusters, c)
+	}
+	sort.Slice(f.Clusters, func(i, j int) bool { return f.Clusters[i].Name < f.Clusters[j].Name })
+	b, err := yaml.Marshal(&f)
+	if err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, b, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
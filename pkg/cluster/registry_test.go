@@ -0,0 +1,91 @@
+package cluster
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var staging = Cluster{
+	Name:             "staging",
+	APIServer:        "https://10.0.0.1:6443",
+	DefaultNamespace: "albatross",
+	Auth:             Auth{Method: TokenAuth, Token: "secret-token"},
+}
+
+func TestRegistryPersistsClusters(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clusters")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "clusters.yaml")
+
+	registry, err := NewRegistry(path)
+	require.NoError(t, err)
+	require.NoError(t, registry.Put(context.Background(), staging))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	reloaded, err := NewRegistry(path)
+	require.NoError(t, err)
+	got, err := reloaded.Get("staging")
+	require.NoError(t, err)
+	assert.Equal(t, staging, got)
+
+	require.NoError(t, reloaded.Delete(context.Background(), "staging"))
+	_, err = registry.Get("staging")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestRegistryListSortsByName(t *testing.T) {
+	registry, err := NewRegistry("")
+	require.NoError(t, err)
+	production := staging
+	production.Name = "production"
+	require.NoError(t, registry.Put(context.Background(), staging))
+	require.NoError(t, registry.Put(context.Background(), production))
+
+	clusters, err := registry.List()
+
+	require.NoError(t, err)
+	assert.Equal(t, []Cluster{production, staging}, clusters)
+}
+
+func TestRegistryRejectsInvalidClusters(t *testing.T) {
+	registry, err := NewRegistry("")
+	require.NoError(t, err)
+
+	err = registry.Put(context.Background(), Cluster{Name: "staging", Auth: Auth{Method: TokenAuth}})
+
+	assert.EqualError(t, err, "token auth requires a token or a token_file")
+	clusters, err := registry.List()
+	require.NoError(t, err)
+	assert.Empty(t, clusters)
+}
+
+func TestRegistryDeleteUnknownCluster(t *testing.T) {
+	registry, err := NewRegistry("")
+	require.NoError(t, err)
+
+	err = registry.Delete(context.Background(), "staging")
+
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestNewRegistryFailsOnInvalidFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clusters")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "clusters.yaml")
+	require.NoError(t, ioutil.WriteFile(path, []byte("clusters:\n- name: staging\n  auth:\n    method: token\n"), 0600))
+
+	_, err = NewRegistry(path)
+
+	assert.Error(t, err)
+}
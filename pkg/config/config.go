@@ -0,0 +1,247 @@
+// Package config loads the configuration of the albatross server from a YAML file and the environment.
+// Environment variables override the file, which overrides the defaults, and the result is validated
+// before the server starts.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/yaml.v2"
+)
+
+// ErrInvalid is returned for configurations that fail validation
+var ErrInvalid = errors.New("invalid configuration")
+
+// Config is the configuration of the server
+type Config struct {
+	Server Server `yaml:"server"`
+	Log    Log    `yaml:"log"`
+	Helm   Helm   `yaml:"helm"`
+	// ClusterConfig is the file registered clusters are stored in
+	ClusterConfig string      `yaml:"cluster_config"`
+	Auth          Auth        `yaml:"auth"`
+	Authz         Authz       `yaml:"authz"`
+	Audit         Audit       `yaml:"audit"`
+	SecretStore   SecretStore `yaml:"secret_store"`
+	Provenance    Provenance  `yaml:"provenance"`
+	Tracing       Tracing     `yaml:"tracing"`
+	Locks         Locks       `yaml:"locks"`
+	Features      Features    `yaml:"features"`
+}
+
+// Server configures the HTTP server
+type Server struct {
+	// Address is the host and port the server listens on
+	Address  string   `yaml:"address"`
+	TLS      TLS      `yaml:"tls"`
+	Timeouts Timeouts `yaml:"timeouts"`
+	// ShutdownGracePeriod is how long in-flight install, upgrade and uninstall requests and operations
+	// are waited for after SIGTERM or SIGINT
+	ShutdownGracePeriod time.Duration `yaml:"shutdown_grace_period"`
+}
+
+// TLS serves HTTPS when the certificate and key are set
+type TLS struct {
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// ClientCAFile verifies client certificates, which then authenticate their requests
+	ClientCAFile string `yaml:"client_ca_file"`
+}
+
+// Enabled is true when the server serves HTTPS
+func (t TLS) Enabled() bool {
+	return t.CertFile != "" && t.KeyFile != ""
+}
+
+// Timeouts of the HTTP server, zero disables a timeout
+type Timeouts struct {
+	Read       time.Duration `yaml:"read"`
+	ReadHeader time.Duration `yaml:"read_header"`
+	// Write is disabled by default since synchronous helm actions last as long as their own timeout
+	Write time.Duration `yaml:"write"`
+	Idle  time.Duration `yaml:"idle"`
+}
+
+// Log configures the logger
+type Log struct {
+	// Level is debug, info, warn, error or none
+	Level string `yaml:"level"`
+	// Format is json or console
+	Format string `yaml:"format"`
+}
+
+// Helm configures the storage of releases and repositories
+type Helm struct {
+	// Driver is the storage of release information: secret, configmap or memory
+	Driver string `yaml:"driver"`
+	// RepositoryConfig is the repositories file
+	RepositoryConfig string `yaml:"repository_config"`
+	// RepositoryCache is the directory of cached repository indexes and charts
+	RepositoryCache string `yaml:"repository_cache"`
+}
+
+// Auth enables authentication methods, every request is allowed when none is
+type Auth struct {
+	APIKeysFile string `yaml:"api_keys_file"`
+	JWKSFile    string `yaml:"jwks_file"`
+	JWTIssuer   string `yaml:"jwt_issuer"`
+	JWTAudience string `yaml:"jwt_audience"`
+}
+
+// Enabled is true when requests have to be authenticated, client certificates count when tls verifies them
+func (a Auth) Enabled(tls TLS) bool {
+	return a.APIKeysFile != "" || a.JWKSFile != "" || tls.ClientCAFile != ""
+}
+
+// Authz configures authorization
+type Authz struct {
+	PolicyFile string `yaml:"policy_file"`
+}
+
+// Audit configures the sinks of audit events
+type Audit struct {
+	File       string `yaml:"file"`
+	Stdout     bool   `yaml:"stdout"`
+	WebhookURL string `yaml:"webhook_url"`
+}
+
+// SecretStore configures where credentials are stored
+type SecretStore struct {
+	// Kind is file or kubernetes, credentials are rejected when empty
+	Kind        string `yaml:"kind"`
+	File        string `yaml:"file"`
+	KeyFile     string `yaml:"key_file"`
+	Namespace   string `yaml:"namespace"`
+	KubeContext string `yaml:"kube_context"`
+}
+
+// Provenance configures the verification of charts
+type Provenance struct {
+	Keyring  string `yaml:"keyring"`
+	Required bool   `yaml:"required"`
+}
+
+// Tracing configures the export of spans
+type Tracing struct {
+	// Exporter is none, stdout or file
+	Exporter    string  `yaml:"exporter"`
+	File        string  `yaml:"file"`
+	SampleRatio float64 `yaml:"sample_ratio"`
+}
+
+// Locks configures the per-release locks of install, upgrade, uninstall, rollback and recover requests
+type Locks struct {
+	// Backend is memory for a single instance or kubernetes, which keeps leases every replica sees
+	Backend string `yaml:"backend"`
+	// Namespace and KubeContext locate the leases of the kubernetes backend
+	Namespace   string `yaml:"namespace"`
+	KubeContext string `yaml:"kube_context"`
+	// LeaseDuration is how long the lease of a replica that died holding it blocks the release
+	LeaseDuration time.Duration `yaml:"lease_duration"`
+	// QueueTimeout is how long requests wait for a held lock, they are refused with 409 Conflict right away when zero
+	QueueTimeout time.Duration `yaml:"queue_timeout"`
+}
+
+// Features toggles optional routes
+type Features struct {
+	// Documentation serves the swagger documentation under /docs
+	Documentation bool `yaml:"documentation"`
+	// Metrics serves prometheus metrics under /metrics, without authentication
+	Metrics bool `yaml:"metrics"`
+}
+
+// Default returns the configuration of a server without a file or environment
+func Default() Config {
+	return Config{
+		Server: Server{
+			Address: ":8080",
+			Timeouts: Timeouts{
+				Read:       time.Minute,
+				ReadHeader: 10 * time.Second,
+				Idle:       2 * time.Minute,
+			},
+			ShutdownGracePeriod: 5 * time.Minute,
+		},
+		Log:     Log{Level: "info", Format: "json"},
+		Tracing: Tracing{Exporter: "none"},
+		Locks:   Locks{Backend: "memory", LeaseDuration: 30 * time.Second},
+	}
+}
+
+// Load reads the file at path over the defaults, an empty path keeps the defaults,
+// then applies the environment overrides and validates the result
+func Load(path string) (Config, error) {
+	cfg := Default()
+	if path != "" {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return cfg, err
+		}
+		if err := yaml.UnmarshalStrict(b, &cfg); err != nil {
+			return cfg, fmt.Errorf("error parsing %s: %w", path, err)
+		}
+	}
+	if err := cfg.applyEnv(os.LookupEnv); err != nil {
+		return cfg, err
+	}
+	return cfg, cfg.Validate()
+}
+
+// Validate checks the configuration is consistent, files are only checked once they are used
+func (c Config) Validate() error {
+	checks := []struct {
+		failed bool
+		reason string
+	}{
+		{c.Server.Address == "", "server.address is required"},
+		{(c.Server.TLS.CertFile == "") != (c.Server.TLS.KeyFile == ""), "server.tls requires both cert_file and key_file"},
+		{c.Server.TLS.ClientCAFile != "" && !c.Server.TLS.Enabled(), "server.tls.client_ca_file requires cert_file and key_file"},
+		{c.Server.Timeouts.Read < 0 || c.Server.Timeouts.ReadHeader < 0 || c.Server.Timeouts.Write < 0 || c.Server.Timeouts.Idle < 0,
+			"server.timeouts cannot be negative"},
+		{c.Server.ShutdownGracePeriod < 0, "server.shutdown_grace_period cannot be negative"},
+		{!validLevel(c.Log.Level), fmt.Sprintf("unknown log.level %q, must be debug, info, warn, error or none", c.Log.Level)},
+		{c.Log.Format != "json" && c.Log.Format != "console", fmt.Sprintf("unknown log.format %q, must be json or console", c.Log.Format)},
+		{!oneOf(c.Helm.Driver, "", "secret", "secrets", "configmap", "configmaps", "memory"),
+			fmt.Sprintf("unknown helm.driver %q, must be secret, configmap or memory", c.Helm.Driver)},
+		{c.Authz.PolicyFile != "" && !c.Auth.Enabled(c.Server.TLS), "authz.policy_file requires an authentication method"},
+		{!oneOf(c.SecretStore.Kind, "", "file", "kubernetes"), fmt.Sprintf("unknown secret_store.kind %q, must be file or kubernetes", c.SecretStore.Kind)},
+		{c.SecretStore.Kind == "file" && (c.SecretStore.File == "" || c.SecretStore.KeyFile == ""), "secret_store kind file requires file and key_file"},
+		{c.SecretStore.Kind == "kubernetes" && c.SecretStore.Namespace == "", "secret_store kind kubernetes requires namespace"},
+		{c.Provenance.Required && c.Provenance.Keyring == "", "provenance.required requires a keyring"},
+		{!oneOf(c.Tracing.Exporter, "", "none", "stdout", "file"), fmt.Sprintf("unknown tracing.exporter %q, must be none, stdout or file", c.Tracing.Exporter)},
+		{c.Tracing.Exporter == "file" && c.Tracing.File == "", "tracing exporter file requires a file"},
+		{c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1, "tracing.sample_ratio must be between 0 and 1"},
+		{!oneOf(c.Locks.Backend, "memory", "kubernetes"), fmt.Sprintf("unknown locks.backend %q, must be memory or kubernetes", c.Locks.Backend)},
+		{c.Locks.Backend == "kubernetes" && c.Locks.Namespace == "", "locks backend kubernetes requires namespace"},
+		{c.Locks.LeaseDuration < time.Second, "locks.lease_duration must be at least 1s"},
+		{c.Locks.QueueTimeout < 0, "locks.queue_timeout cannot be negative"},
+	}
+	for _, check := range checks {
+		if check.failed {
+			return fmt.Errorf("%w: %s", ErrInvalid, check.reason)
+		}
+	}
+	return nil
+}
+
+func validLevel(level string) bool {
+	if level == "none" {
+		return true
+	}
+	var l zapcore.Level
+	return l.UnmarshalText([]byte(level)) == nil && l <= zapcore.ErrorLevel
+}
+
+func oneOf(value string, allowed ...string) bool {
+	for _, a := range allowed {
+		if value == a {
+			return true
+		}
+	}
+	return false
+}
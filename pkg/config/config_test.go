@@ -0,0 +1,118 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadShouldReturnDefaultsWithoutFile(t *testing.T) {
+	cfg, err := Load("")
+
+	require.NoError(t, err)
+	assert.Equal(t, Default(), cfg)
+	assert.Equal(t, ":8080", cfg.Server.Address)
+	assert.False(t, cfg.Features.Metrics)
+}
+
+func TestLoadShouldReadFileOverDefaults(t *testing.T) {
+	cfg, err := Load("testdata/albatross.yaml")
+
+	require.NoError(t, err)
+	assert.Equal(t, ":8443", cfg.Server.Address)
+	assert.True(t, cfg.Server.TLS.Enabled())
+	assert.Equal(t, Timeouts{Read: 30 * time.Second, ReadHeader: 10 * time.Second, Write: 10 * time.Minute, Idle: 2 * time.Minute}, cfg.Server.Timeouts)
+	assert.Equal(t, 2*time.Minute, cfg.Server.ShutdownGracePeriod)
+	assert.Equal(t, Log{Level: "debug", Format: "console"}, cfg.Log)
+	assert.Equal(t, Helm{Driver: "configmap", RepositoryConfig: "/var/lib/albatross/repositories.yaml", RepositoryCache: "/var/cache/albatross"}, cfg.Helm)
+	assert.Equal(t, Locks{Backend: "kubernetes", Namespace: "albatross", LeaseDuration: 30 * time.Second, QueueTimeout: time.Minute}, cfg.Locks)
+	assert.True(t, cfg.Features.Documentation)
+	assert.True(t, cfg.Features.Metrics)
+}
+
+func TestLoadShouldApplyEnvironmentOverFile(t *testing.T) {
+	os.Setenv("LOG_LEVEL", "warn")
+	os.Setenv("SERVER_WRITE_TIMEOUT", "5m")
+	defer os.Unsetenv("LOG_LEVEL")
+	defer os.Unsetenv("SERVER_WRITE_TIMEOUT")
+
+	cfg, err := Load("testdata/albatross.yaml")
+
+	require.NoError(t, err)
+	assert.Equal(t, "warn", cfg.Log.Level)
+	assert.Equal(t, 5*time.Minute, cfg.Server.Timeouts.Write)
+}
+
+func TestLoadShouldRejectUnknownFields(t *testing.T) {
+	file, err := ioutil.TempFile("", "albatross-config")
+	require.NoError(t, err)
+	defer os.Remove(file.Name())
+	_, err = file.WriteString("server:\n  port: 8080\n")
+	require.NoError(t, err)
+	file.Close()
+
+	_, err = Load(file.Name())
+
+	assert.Error(t, err)
+}
+
+func TestApplyEnvShouldRejectMalformedValues(t *testing.T) {
+	for env, value := range map[string]string{
+		"AUDIT_STDOUT":         "sometimes",
+		"SERVER_READ_TIMEOUT":  "30",
+		"TRACING_SAMPLE_RATIO": "half",
+	} {
+		cfg := Default()
+		err := cfg.applyEnv(func(name string) (string, bool) {
+			return value, name == env
+		})
+
+		assert.True(t, errors.Is(err, ErrInvalid), env)
+		assert.Contains(t, err.Error(), env)
+	}
+}
+
+func TestValidateShouldRejectInconsistentConfigurations(t *testing.T) {
+	cases := map[string]func(*Config){
+		"empty address":               func(c *Config) { c.Server.Address = "" },
+		"certificate without key":     func(c *Config) { c.Server.TLS.CertFile = "tls.crt" },
+		"client CA without TLS":       func(c *Config) { c.Server.TLS.ClientCAFile = "ca.crt" },
+		"negative timeout":            func(c *Config) { c.Server.Timeouts.Idle = -time.Second },
+		"negative grace period":       func(c *Config) { c.Server.ShutdownGracePeriod = -time.Second },
+		"unknown lock backend":        func(c *Config) { c.Locks.Backend = "redis" },
+		"lease lock without ns":       func(c *Config) { c.Locks.Backend = "kubernetes" },
+		"sub-second lease":            func(c *Config) { c.Locks.LeaseDuration = time.Millisecond },
+		"unknown log level":           func(c *Config) { c.Log.Level = "verbose" },
+		"unknown log format":          func(c *Config) { c.Log.Format = "xml" },
+		"unknown helm driver":         func(c *Config) { c.Helm.Driver = "sql" },
+		"policy without auth":         func(c *Config) { c.Authz.PolicyFile = "policy.yaml" },
+		"unknown secret store":        func(c *Config) { c.SecretStore.Kind = "vault" },
+		"file store without key":      func(c *Config) { c.SecretStore = SecretStore{Kind: "file", File: "secrets"} },
+		"kubernetes store without ns": func(c *Config) { c.SecretStore.Kind = "kubernetes" },
+		"required without keyring":    func(c *Config) { c.Provenance.Required = true },
+		"unknown trace exporter":      func(c *Config) { c.Tracing.Exporter = "zipkin" },
+		"file exporter without file":  func(c *Config) { c.Tracing.Exporter = "file" },
+		"sample ratio above one":      func(c *Config) { c.Tracing.SampleRatio = 2 },
+	}
+	for name, change := range cases {
+		cfg := Default()
+		change(&cfg)
+
+		err := cfg.Validate()
+
+		assert.True(t, errors.Is(err, ErrInvalid), name)
+	}
+}
+
+func TestValidateShouldAcceptPolicyWithClientCertificates(t *testing.T) {
+	cfg := Default()
+	cfg.Server.TLS = TLS{CertFile: "tls.crt", KeyFile: "tls.key", ClientCAFile: "ca.crt"}
+	cfg.Authz.PolicyFile = "policy.yaml"
+
+	assert.NoError(t, cfg.Validate())
+}
@@ -0,0 +1,112 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// override sets a field of the configuration from an environment variable
+type override struct {
+	env string
+	set func(c *Config, value string) error
+}
+
+// overrides keeps the environment variables albatross was configured with before the configuration file,
+// and the ones helm reads itself
+var overrides = []override{
+	{"LISTEN_ADDRESS", str(func(c *Config) *string { return &c.Server.Address })},
+	{"TLS_CERT_FILE", str(func(c *Config) *string { return &c.Server.TLS.CertFile })},
+	{"TLS_KEY_FILE", str(func(c *Config) *string { return &c.Server.TLS.KeyFile })},
+	{"AUTH_CLIENT_CA_FILE", str(func(c *Config) *string { return &c.Server.TLS.ClientCAFile })},
+	{"SERVER_READ_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Server.Timeouts.Read })},
+	{"SERVER_READ_HEADER_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Server.Timeouts.ReadHeader })},
+	{"SERVER_WRITE_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Server.Timeouts.Write })},
+	{"SERVER_IDLE_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Server.Timeouts.Idle })},
+	{"SHUTDOWN_GRACE_PERIOD", duration(func(c *Config) *time.Duration { return &c.Server.ShutdownGracePeriod })},
+	{"LOG_LEVEL", str(func(c *Config) *string { return &c.Log.Level })},
+	{"LOG_FORMAT", str(func(c *Config) *string { return &c.Log.Format })},
+	{"HELM_DRIVER", str(func(c *Config) *string { return &c.Helm.Driver })},
+	{"HELM_REPOSITORY_CONFIG", str(func(c *Config) *string { return &c.Helm.RepositoryConfig })},
+	{"HELM_REPOSITORY_CACHE", str(func(c *Config) *string { return &c.Helm.RepositoryCache })},
+	{"CLUSTER_CONFIG", str(func(c *Config) *string { return &c.ClusterConfig })},
+	{"AUTH_API_KEYS_FILE", str(func(c *Config) *string { return &c.Auth.APIKeysFile })},
+	{"AUTH_JWKS_FILE", str(func(c *Config) *string { return &c.Auth.JWKSFile })},
+	{"AUTH_JWT_ISSUER", str(func(c *Config) *string { return &c.Auth.JWTIssuer })},
+	{"AUTH_JWT_AUDIENCE", str(func(c *Config) *string { return &c.Auth.JWTAudience })},
+	{"AUTHZ_POLICY_FILE", str(func(c *Config) *string { return &c.Authz.PolicyFile })},
+	{"AUDIT_FILE", str(func(c *Config) *string { return &c.Audit.File })},
+	{"AUDIT_STDOUT", boolean(func(c *Config) *bool { return &c.Audit.Stdout })},
+	{"AUDIT_WEBHOOK_URL", str(func(c *Config) *string { return &c.Audit.WebhookURL })},
+	{"SECRET_STORE", str(func(c *Config) *string { return &c.SecretStore.Kind })},
+	{"SECRET_STORE_FILE", str(func(c *Config) *string { return &c.SecretStore.File })},
+	{"SECRET_STORE_KEY_FILE", str(func(c *Config) *string { return &c.SecretStore.KeyFile })},
+	{"SECRET_STORE_NAMESPACE", str(func(c *Config) *string { return &c.SecretStore.Namespace })},
+	{"SECRET_STORE_KUBE_CONTEXT", str(func(c *Config) *string { return &c.SecretStore.KubeContext })},
+	{"PROVENANCE_KEYRING", str(func(c *Config) *string { return &c.Provenance.Keyring })},
+	{"PROVENANCE_REQUIRED", boolean(func(c *Config) *bool { return &c.Provenance.Required })},
+	{"TRACING_EXPORTER", str(func(c *Config) *string { return &c.Tracing.Exporter })},
+	{"TRACING_FILE", str(func(c *Config) *string { return &c.Tracing.File })},
+	{"TRACING_SAMPLE_RATIO", float(func(c *Config) *float64 { return &c.Tracing.SampleRatio })},
+	{"LOCK_BACKEND", str(func(c *Config) *string { return &c.Locks.Backend })},
+	{"LOCK_NAMESPACE", str(func(c *Config) *string { return &c.Locks.Namespace })},
+	{"LOCK_KUBE_CONTEXT", str(func(c *Config) *string { return &c.Locks.KubeContext })},
+	{"LOCK_LEASE_DURATION", duration(func(c *Config) *time.Duration { return &c.Locks.LeaseDuration })},
+	{"LOCK_QUEUE_TIMEOUT", duration(func(c *Config) *time.Duration { return &c.Locks.QueueTimeout })},
+	{"DOCUMENTATION", boolean(func(c *Config) *bool { return &c.Features.Documentation })},
+	{"METRICS", boolean(func(c *Config) *bool { return &c.Features.Metrics })},
+}
+
+// applyEnv sets the fields of the environment variables found by lookup
+func (c *Config) applyEnv(lookup func(string) (string, bool)) error {
+	for _, o := range overrides {
+		value, ok := lookup(o.env)
+		if !ok {
+			continue
+		}
+		if err := o.set(c, value); err != nil {
+			return fmt.Errorf("%w: %s: %v", ErrInvalid, o.env, err)
+		}
+	}
+	return nil
+}
+
+func str(field func(*Config) *string) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		*field(c) = value
+		return nil
+	}
+}
+
+func boolean(field func(*Config) *bool) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = b
+		return nil
+	}
+}
+
+func duration(field func(*Config) *time.Duration) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+func float(field func(*Config) *float64) func(*Config, string) error {
+	return func(c *Config, value string) error {
+		f, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return err
+		}
+		*field(c) = f
+		return nil
+	}
+}
@@ -0,0 +1,140 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// ChangeType describes how a resource differs between two manifests
+type ChangeType string
+
+const (
+	// Added is a resource that is only present in the proposed manifest
+	Added ChangeType = "added"
+	// Removed is a resource that is only present in the current manifest
+	Removed ChangeType = "removed"
+	// Changed is a resource present in both manifests with different content
+	Changed ChangeType = "changed"
+)
+
+const contextLines = 3
+
+// Resource identifies a kubernetes object rendered in a manifest
+type Resource struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+}
+
+// String formats the resource the way it is shown in diff headers
+func (r Resource) String() string {
+	return fmt.Sprintf("%s, %s, %s (%s)", r.Namespace, r.Name, r.Kind, r.APIVersion)
+}
+
+// Change is the difference of a single resource between two manifests
+type Change struct {
+	Resource
+	Type ChangeType
+	// Diff is the unified text diff of the resource
+	Diff string
+}
+
+type manifestHead struct {
+	APIVersion string `yaml:"apiVersion"`
+	Kind       string `yaml:"kind"`
+	Metadata   struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
+}
+
+// Manifests compares the current and proposed multi document manifests resource by resource.
+// Resources without an explicit namespace are assumed to live in namespace.
+// Unchanged resources are left out of the result, which is sorted by namespace, kind and name.
+func Manifests(current, proposed, namespace string) ([]Change, error) {
+	currentResources, err := parse(current, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing current manifest: %w", err)
+	}
+	proposedResources, err := parse(proposed, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing proposed manifest: %w", err)
+	}
+
+	var changes []Change
+	for res, before := range currentResources {
+		after, ok := proposedResources[res]
+		switch {
+		case !ok:
+			changes = append(changes, change(res, Removed, before, ""))
+		case before != after:
+			changes = append(changes, change(res, Changed, before, after))
+		}
+	}
+	for res, after := range proposedResources {
+		if _, ok := currentResources[res]; !ok {
+			changes = append(changes, change(res, Added, "", after))
+		}
+	}
+
+	sort.Slice(changes, func(i, j int) bool {
+		a, b := changes[i].Resource, changes[j].Resource
+		if a.Namespace != b.Namespace {
+			return a.Namespace < b.Namespace
+		}
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+		return a.Name < b.Name
+	})
+	return changes, nil
+}
+
+func parse(manifest, namespace string) (map[Resource]string, error) {
+	resources := make(map[Resource]string)
+	for _, content := range releaseutil.SplitManifests(manifest) {
+		var head manifestHead
+		if err := yaml.Unmarshal([]byte(content), &head); err != nil {
+			return nil, err
+		}
+		if head.Kind == "" {
+			continue
+		}
+		res := Resource{
+			APIVersion: head.APIVersion,
+			Kind:       head.Kind,
+			Namespace:  head.Metadata.Namespace,
+			Name:       head.Metadata.Name,
+		}
+		if res.Namespace == "" {
+			res.Namespace = namespace
+		}
+		resources[res] = strings.TrimSpace(content)
+	}
+	return resources, nil
+}
+
+func change(res Resource, changeType ChangeType, before, after string) Change {
+	ud := difflib.UnifiedDiff{
+		A:        difflib.SplitLines(before),
+		B:        difflib.SplitLines(after),
+		FromFile: res.String(),
+		ToFile:   res.String(),
+		Context:  contextLines,
+	}
+	if before == "" {
+		ud.A = nil
+	}
+	if after == "" {
+		ud.B = nil
+	}
+	// writing to an in-memory buffer cannot fail
+	text, _ := difflib.GetUnifiedDiffString(ud)
+	return Change{Resource: res, Type: changeType, Diff: text}
+}
@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const currentManifest = `---
+# Source: albatross/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: albatross-config
+data:
+  author: "gojek"
+---
+# Source: albatross/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: albatross-secret
+  namespace: kube-system
+---
+# Source: albatross/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: albatross
+`
+
+const proposedManifest = `---
+# Source: albatross/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: albatross-config
+data:
+  author: "gojekfarm"
+---
+# Source: albatross/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: albatross
+---
+# Source: albatross/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: albatross
+`
+
+func TestManifestsReturnsChangesPerResource(t *testing.T) {
+	changes, err := Manifests(currentManifest, proposedManifest, "default")
+
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+
+	assert.Equal(t, Resource{APIVersion: "v1", Kind: "ConfigMap", Namespace: "default", Name: "albatross-config"}, changes[0].Resource)
+	assert.Equal(t, Changed, changes[0].Type)
+	assert.Contains(t, changes[0].Diff, "-  author: \"gojek\"\n")
+	assert.Contains(t, changes[0].Diff, "+  author: \"gojekfarm\"\n")
+	assert.Contains(t, changes[0].Diff, "--- default, albatross-config, ConfigMap (v1)\n")
+
+	assert.Equal(t, Resource{APIVersion: "apps/v1", Kind: "Deployment", Namespace: "default", Name: "albatross"}, changes[1].Resource)
+	assert.Equal(t, Added, changes[1].Type)
+	assert.Contains(t, changes[1].Diff, "+kind: Deployment\n")
+	assert.NotContains(t, changes[1].Diff, "\n-")
+
+	assert.Equal(t, Resource{APIVersion: "v1", Kind: "Secret", Namespace: "kube-system", Name: "albatross-secret"}, changes[2].Resource)
+	assert.Equal(t, Removed, changes[2].Type)
+	assert.Contains(t, changes[2].Diff, "-kind: Secret\n")
+}
+
+func TestManifestsReturnsNoChangesForIdenticalManifests(t *testing.T) {
+	changes, err := Manifests(currentManifest, currentManifest, "default")
+
+	require.NoError(t, err)
+	assert.Empty(t, changes)
+}
+
+func TestManifestsTreatsEveryResourceAsAddedWithoutCurrentManifest(t *testing.T) {
+	changes, err := Manifests("", proposedManifest, "default")
+
+	require.NoError(t, err)
+	require.Len(t, changes, 3)
+	for _, c := range changes {
+		assert.Equal(t, Added, c.Type)
+	}
+}
+
+func TestManifestsFailsOnInvalidYaml(t *testing.T) {
+	_, err := Manifests("kind: [", proposedManifest, "default")
+
+	assert.EqualError(t, err, "error parsing current manifest: yaml: line 1: did not find expected node content")
+}
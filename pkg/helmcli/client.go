@@ -2,12 +2,18 @@ package helmcli
 
 import (
 	"context"
+	"time"
 
 	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/release"
 
+	"github.com/gojekfarm/albatross/pkg/cluster"
 	"github.com/gojekfarm/albatross/pkg/helmcli/config"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/logger"
 )
 
 type Client interface {
@@ -16,14 +22,23 @@ type Client interface {
 	NewLister(flags.ListFlags) (Lister, error)
 	NewUninstaller(flags.UninstallFlags) (Uninstaller, error)
 	NewStatusGiver(flags.StatusFlags) (StatusGiver, error)
+	NewRollbacker(flags.RollbackFlags) (Rollbacker, error)
+	NewHistoryGiver(flags.HistoryFlags) (HistoryGiver, error)
+	NewValuesGiver(flags.GetValuesFlags) (ValuesGiver, error)
+	NewTemplater(flags.TemplateFlags) (Templater, error)
+	NewTester(flags.TestFlags) (Tester, error)
+	NewShower(flags.ShowFlags) (Shower, error)
+	NewRecoverer(flags.RecoverFlags) (Recoverer, error)
 }
 
 type Upgrader interface {
 	Upgrade(ctx context.Context, relName, chartName string, values map[string]interface{}) (*release.Release, error)
+	UpgradeChart(ctx context.Context, relName string, ch *chart.Chart, values map[string]interface{}) (*release.Release, error)
 }
 
 type Installer interface {
 	Install(ctx context.Context, relName, chartName string, values map[string]interface{}) (*release.Release, error)
+	InstallChart(ctx context.Context, relName string, ch *chart.Chart, values map[string]interface{}) (*release.Release, error)
 }
 
 type Lister interface {
@@ -38,26 +53,94 @@ type StatusGiver interface {
 	Status(ctx context.Context, releaseName string) (*release.Release, error)
 }
 
+type Rollbacker interface {
+	Rollback(ctx context.Context, releaseName string) (*release.Release, error)
+}
+
+type HistoryGiver interface {
+	History(ctx context.Context, releaseName string) ([]*release.Release, error)
+}
+
+type ValuesGiver interface {
+	Values(ctx context.Context, releaseName string) (map[string]interface{}, error)
+}
+
+type Templater interface {
+	Template(ctx context.Context, relName, chartName string, values map[string]interface{}) (*release.Release, error)
+}
+
+// Recoverer recovers a release whose last revision was left pending by an interrupted action.
+type Recoverer interface {
+	Recover(ctx context.Context, releaseName string) (*release.Release, error)
+}
+
+// Tester runs the test hooks of a release.
+type Tester interface {
+	Test(ctx context.Context, releaseName string) (*release.Release, error)
+	Logs(ctx context.Context, hook *release.Hook) (string, error)
+}
+
+// Options configure a client, every option is optional
+type Options struct {
+	// Clusters are the registered clusters, other cluster names are looked up in the kubeconfig contexts
+	Clusters cluster.Lookup
+	// Credentials resolves the credentials of private chart repositories
+	Credentials CredentialsResolver
+	// Registry pulls the charts of oci:// references, they are pulled anonymously without it
+	Registry RegistryClient
+	// Provenance verifies installed and upgraded charts against their provenance files
+	Provenance Provenance
+}
+
+// Shower loads a chart of a repository to show its metadata, values, readme and schema.
+type Shower interface {
+	Show(ctx context.Context, chartName string) (*chart.Chart, error)
+}
+
 func New() Client {
 	return helmClient{}
 }
 
-type helmClient struct{}
+// NewWithOptions returns a client configured with opts
+func NewWithOptions(opts Options) Client {
+	return helmClient{
+		clusters:    opts.Clusters,
+		credentials: opts.Credentials,
+		registry:    opts.Registry,
+		provenance:  opts.Provenance,
+	}
+}
+
+type helmClient struct {
+	clusters    cluster.Lookup
+	credentials CredentialsResolver
+	registry    RegistryClient
+	provenance  Provenance
+}
 
 func (c helmClient) NewUpgrader(flg flags.UpgradeFlags) (Upgrader, error) {
 	//TODO: ifpossible envconfig could be moved to actionconfig new, remove pointer usage of globalflags
 	envconfig := config.NewEnvConfig(&flg.GlobalFlags)
-	actionconfig, err := config.NewActionConfig(envconfig, &flg.GlobalFlags)
+	actionconfig, err := config.NewActionConfig(envconfig, &flg.GlobalFlags, c.clusters)
 	if err != nil {
 		return nil, err
 	}
 
 	upgrade := action.NewUpgrade(actionconfig.Configuration)
 	history := action.NewHistory(actionconfig.Configuration)
-	installer, err := c.NewInstaller(flags.InstallFlags{
-		DryRun:      flg.DryRun,
-		Version:     flg.Version,
-		GlobalFlags: flg.GlobalFlags,
+	installer, err := c.newInstaller(flags.InstallFlags{
+		DryRun:          flg.DryRun,
+		Version:         flg.Version,
+		Wait:            flg.Wait,
+		WaitForJobs:     flg.WaitForJobs,
+		Timeout:         flg.Timeout,
+		Atomic:          flg.Atomic,
+		DisableHooks:    flg.DisableHooks,
+		SkipCRDs:        flg.SkipCRDs,
+		CreateNamespace: flg.CreateNamespace,
+		Description:     flg.Description,
+		Verify:          flg.Verify,
+		GlobalFlags:     flg.GlobalFlags,
 	})
 	if err != nil {
 		return nil, err
@@ -67,19 +150,50 @@ func (c helmClient) NewUpgrader(flg flags.UpgradeFlags) (Upgrader, error) {
 	upgrade.Install = flg.Install
 	upgrade.DryRun = flg.DryRun
 	upgrade.Version = flg.Version
+	upgrade.Wait = flg.Wait
+	upgrade.Timeout = flg.Timeout
+	upgrade.Atomic = flg.Atomic
+	upgrade.CleanupOnFail = flg.CleanupOnFail
+	upgrade.Force = flg.Force
+	upgrade.ResetValues = flg.ResetValues
+	upgrade.ReuseValues = flg.ReuseValues
+	upgrade.DisableHooks = flg.DisableHooks
+	upgrade.SkipCRDs = flg.SkipCRDs
+	upgrade.Description = flg.Description
+
+	var rollback *action.Rollback
+	if flg.Atomic {
+		rollback = action.NewRollback(actionconfig.Configuration)
+		rollback.Wait = true
+		rollback.Timeout = flg.Timeout
+		rollback.CleanupOnFail = flg.CleanupOnFail
+		rollback.DisableHooks = flg.DisableHooks
+		rollback.Force = flg.Force
+	}
 
 	return &upgrader{
 		action:      upgrade,
 		envSettings: envconfig.EnvSettings,
+		kubeClient:  actionconfig.Setup,
 		history:     history,
 		installer:   installer,
+		credentials: c.credentials,
+		registry:    c.registry,
+		verify:      c.provenance.verification(flg.Verify),
+		jobs:        newJobWaiter(actionconfig.KubeClient, flg.Wait || flg.Atomic, flg.WaitForJobs, flg.Timeout),
+		releases:    actionconfig.Releases,
+		rollback:    rollback,
 	}, nil
 }
 
 // NewInstaller returns a new instance of Installer struct.
 func (c helmClient) NewInstaller(flg flags.InstallFlags) (Installer, error) {
+	return c.newInstaller(flg)
+}
+
+func (c helmClient) newInstaller(flg flags.InstallFlags) (*installer, error) {
 	envconfig := config.NewEnvConfig(&flg.GlobalFlags)
-	actionconfig, err := config.NewActionConfig(envconfig, &flg.GlobalFlags)
+	actionconfig, err := config.NewActionConfig(envconfig, &flg.GlobalFlags, c.clusters)
 	if err != nil {
 		return nil, err
 	}
@@ -88,17 +202,38 @@ func (c helmClient) NewInstaller(flg flags.InstallFlags) (Installer, error) {
 	install.Namespace = flg.Namespace
 	install.DryRun = flg.DryRun
 	install.Version = flg.Version
+	install.Wait = flg.Wait
+	install.Timeout = flg.Timeout
+	install.Atomic = flg.Atomic
+	install.DisableHooks = flg.DisableHooks
+	install.SkipCRDs = flg.SkipCRDs
+	install.CreateNamespace = flg.CreateNamespace
+	install.Description = flg.Description
+
+	var uninstall *action.Uninstall
+	if flg.Atomic {
+		uninstall = action.NewUninstall(actionconfig.Configuration)
+		uninstall.DisableHooks = flg.DisableHooks
+		uninstall.Timeout = flg.Timeout
+	}
 
 	return &installer{
 		action:      install,
 		envSettings: envconfig.EnvSettings,
+		kubeClient:  actionconfig.Setup,
+		credentials: c.credentials,
+		registry:    c.registry,
+		verify:      c.provenance.verification(flg.Verify),
+		jobs:        newJobWaiter(actionconfig.KubeClient, flg.Wait || flg.Atomic, flg.WaitForJobs, flg.Timeout),
+		releases:    actionconfig.Releases,
+		uninstall:   uninstall,
 	}, nil
 }
 
 // NewLister returns a new Lister instance.
 func (c helmClient) NewLister(flg flags.ListFlags) (Lister, error) {
 	envconfig := config.NewEnvConfig(&flg.GlobalFlags)
-	actionconfig, err := config.NewActionConfig(envconfig, &flg.GlobalFlags)
+	actionconfig, err := config.NewActionConfig(envconfig, &flg.GlobalFlags, c.clusters)
 	if err != nil {
 		return nil, err
 	}
@@ -110,16 +245,24 @@ func (c helmClient) NewLister(flg flags.ListFlags) (Lister, error) {
 	list.Pending = flg.Pending
 	list.Uninstalling = flg.Uninstalling
 	list.Uninstalled = flg.Uninstalled
+	if flg.Stuck {
+		// every state is listed so that only the last revision of each release is checked for being pending
+		list.StateMask = action.ListAll
+	}
 
 	return &lister{
 		action:      list,
 		envSettings: envconfig.EnvSettings,
+		kubeClient:  actionconfig.Setup,
+		stuck:       flg.Stuck,
+		stuckFor:    flg.StuckFor,
+		now:         time.Now,
 	}, nil
 }
 
 func (c helmClient) NewUninstaller(flg flags.UninstallFlags) (Uninstaller, error) {
 	envconfig := config.NewEnvConfig(&flg.GlobalFlags)
-	actionconfig, err := config.NewActionConfig(envconfig, &flg.GlobalFlags)
+	actionconfig, err := config.NewActionConfig(envconfig, &flg.GlobalFlags, c.clusters)
 	if err != nil {
 		return nil, err
 	}
@@ -133,12 +276,13 @@ func (c helmClient) NewUninstaller(flg flags.UninstallFlags) (Uninstaller, error
 	return &uninstaller{
 		action:      uninstall,
 		envSettings: envconfig.EnvSettings,
+		kubeClient:  actionconfig.Setup,
 	}, nil
 }
 
 func (c helmClient) NewStatusGiver(flg flags.StatusFlags) (StatusGiver, error) {
 	envconfig := config.NewEnvConfig(&flg.GlobalFlags)
-	actionconfig, err := config.NewActionConfig(envconfig, &flg.GlobalFlags)
+	actionconfig, err := config.NewActionConfig(envconfig, &flg.GlobalFlags, c.clusters)
 	if err != nil {
 		return nil, err
 	}
@@ -153,5 +297,151 @@ func (c helmClient) NewStatusGiver(flg flags.StatusFlags) (StatusGiver, error) {
 	return &statusGiver{
 		action:      status,
 		envSettings: envconfig.EnvSettings,
+		kubeClient:  actionconfig.Setup,
 	}, err
 }
+
+// NewRollbacker returns a new Rollbacker instance.
+func (c helmClient) NewRollbacker(flg flags.RollbackFlags) (Rollbacker, error) {
+	envconfig := config.NewEnvConfig(&flg.GlobalFlags)
+	actionconfig, err := config.NewActionConfig(envconfig, &flg.GlobalFlags, c.clusters)
+	if err != nil {
+		return nil, err
+	}
+
+	rollback := action.NewRollback(actionconfig.Configuration)
+	rollback.Version = flg.Version
+	rollback.Timeout = flg.Timeout
+	rollback.Wait = flg.Wait
+	rollback.Force = flg.Force
+	rollback.Recreate = flg.Recreate
+	rollback.CleanupOnFail = flg.CleanupOnFail
+
+	return &rollbacker{
+		action:      rollback,
+		status:      action.NewStatus(actionconfig.Configuration),
+		envSettings: envconfig.EnvSettings,
+		kubeClient:  actionconfig.Setup,
+	}, nil
+}
+
+// NewHistoryGiver returns a new HistoryGiver instance.
+func (c helmClient) NewHistoryGiver(flg flags.HistoryFlags) (HistoryGiver, error) {
+	envconfig := config.NewEnvConfig(&flg.GlobalFlags)
+	actionconfig, err := config.NewActionConfig(envconfig, &flg.GlobalFlags, c.clusters)
+	if err != nil {
+		return nil, err
+	}
+
+	history := action.NewHistory(actionconfig.Configuration)
+	history.Max = flg.Max
+
+	return &historyGiver{
+		action:      history,
+		envSettings: envconfig.EnvSettings,
+		kubeClient:  actionconfig.Setup,
+	}, nil
+}
+
+// NewValuesGiver returns a new ValuesGiver instance.
+func (c helmClient) NewValuesGiver(flg flags.GetValuesFlags) (ValuesGiver, error) {
+	envconfig := config.NewEnvConfig(&flg.GlobalFlags)
+	actionconfig, err := config.NewActionConfig(envconfig, &flg.GlobalFlags, c.clusters)
+	if err != nil {
+		return nil, err
+	}
+
+	getValues := action.NewGetValues(actionconfig.Configuration)
+	getValues.Version = flg.Version
+	getValues.AllValues = flg.AllValues
+
+	return &valuesGiver{
+		action:      getValues,
+		envSettings: envconfig.EnvSettings,
+		kubeClient:  actionconfig.Setup,
+	}, nil
+}
+
+// NewTemplater returns a new Templater instance.
+// The templater runs the install action in client only mode and never talks to a cluster.
+func (c helmClient) NewTemplater(flg flags.TemplateFlags) (Templater, error) {
+	actionconfig := &action.Configuration{Log: logger.Debugf}
+
+	install := action.NewInstall(actionconfig)
+	install.ClientOnly = true
+	install.DryRun = true
+	install.Replace = true
+	install.Namespace = flg.Namespace
+	install.Version = flg.Version
+	install.IncludeCRDs = flg.IncludeCRDs
+	install.IsUpgrade = flg.IsUpgrade
+	install.APIVersions = chartutil.VersionSet(flg.APIVersions)
+
+	return &templater{
+		action:      install,
+		envSettings: cli.New(),
+		credentials: c.credentials,
+		registry:    c.registry,
+	}, nil
+}
+
+// NewTester returns a new Tester instance.
+func (c helmClient) NewTester(flg flags.TestFlags) (Tester, error) {
+	filter, err := newTestHookFilter(flg.Filters)
+	if err != nil {
+		return nil, err
+	}
+
+	envconfig := config.NewEnvConfig(&flg.GlobalFlags)
+	actionconfig, err := config.NewActionConfig(envconfig, &flg.GlobalFlags, c.clusters)
+	if err != nil {
+		return nil, err
+	}
+	filter.apply(actionconfig.Configuration)
+
+	releaseTesting := action.NewReleaseTesting(actionconfig.Configuration)
+	releaseTesting.Timeout = flg.Timeout
+	releaseTesting.Namespace = flg.Namespace
+
+	return &tester{
+		action:      releaseTesting,
+		envSettings: envconfig.EnvSettings,
+		kubeClient:  actionconfig.Setup,
+	}, nil
+}
+
+// NewShower returns a new Shower instance.
+// Charts are shown without a cluster, like templates.
+func (c helmClient) NewShower(flg flags.ShowFlags) (Shower, error) {
+	return &shower{
+		options:     action.ChartPathOptions{Version: flg.Version},
+		envSettings: cli.New(),
+		credentials: c.credentials,
+		registry:    c.registry,
+	}, nil
+}
+
+// NewRecoverer returns a new Recoverer instance.
+func (c helmClient) NewRecoverer(flg flags.RecoverFlags) (Recoverer, error) {
+	envconfig := config.NewEnvConfig(&flg.GlobalFlags)
+	actionconfig, err := config.NewActionConfig(envconfig, &flg.GlobalFlags, c.clusters)
+	if err != nil {
+		return nil, err
+	}
+
+	var rollback *action.Rollback
+	if flg.Rollback {
+		rollback = action.NewRollback(actionconfig.Configuration)
+		rollback.Timeout = flg.Timeout
+		rollback.Wait = flg.Wait
+	}
+
+	return &recoverer{
+		releases:    actionconfig.Releases,
+		rollback:    rollback,
+		stuckFor:    flg.StuckFor,
+		envSettings: envconfig.EnvSettings,
+		kubeClient:  actionconfig.Setup,
+		now:         time.Now,
+	}, nil
+}
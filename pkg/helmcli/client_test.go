@@ -2,6 +2,7 @@ package helmcli
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -64,6 +65,75 @@ func (s *TestSuite) TestNewInstallerSetsChartOptionsUsingFlagValues() {
 	assert.Equal(t, globalFlags.Namespace, newInstaller.action.Namespace)
 }
 
+func (s *TestSuite) TestNewUpgraderSetsDeployOptionsUsingFlagValues() {
+	t := s.T()
+	flg := flags.UpgradeFlags{
+		Install:         true,
+		Wait:            true,
+		WaitForJobs:     true,
+		Timeout:         time.Minute,
+		Atomic:          true,
+		CleanupOnFail:   true,
+		Force:           true,
+		ResetValues:     true,
+		DisableHooks:    true,
+		SkipCRDs:        true,
+		CreateNamespace: true,
+		Description:     "deployed by albatross",
+	}
+
+	u, err := s.c.NewUpgrader(flg)
+
+	newUpgrader, ok := u.(*upgrader)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, newUpgrader.action.Wait)
+	assert.Equal(t, time.Minute, newUpgrader.action.Timeout)
+	assert.True(t, newUpgrader.action.Atomic)
+	assert.True(t, newUpgrader.action.CleanupOnFail)
+	assert.True(t, newUpgrader.action.Force)
+	assert.True(t, newUpgrader.action.ResetValues)
+	assert.False(t, newUpgrader.action.ReuseValues)
+	assert.True(t, newUpgrader.action.DisableHooks)
+	assert.True(t, newUpgrader.action.SkipCRDs)
+	assert.Equal(t, "deployed by albatross", newUpgrader.action.Description)
+	require.NotNil(t, newUpgrader.jobs)
+	assert.Equal(t, time.Minute, newUpgrader.jobs.timeout)
+
+	newInstaller := newUpgrader.installer
+	require.NotNil(t, newInstaller)
+	assert.True(t, newInstaller.action.CreateNamespace)
+	assert.True(t, newInstaller.action.Atomic)
+	assert.True(t, newInstaller.action.SkipCRDs)
+}
+
+func (s *TestSuite) TestNewInstallerSetsDeployOptionsUsingFlagValues() {
+	t := s.T()
+	flg := flags.InstallFlags{
+		Wait:            true,
+		Timeout:         time.Minute,
+		Atomic:          true,
+		DisableHooks:    true,
+		SkipCRDs:        true,
+		CreateNamespace: true,
+		Description:     "deployed by albatross",
+	}
+
+	i, err := s.c.NewInstaller(flg)
+
+	newInstaller, ok := i.(*installer)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, newInstaller.action.Wait)
+	assert.Equal(t, time.Minute, newInstaller.action.Timeout)
+	assert.True(t, newInstaller.action.Atomic)
+	assert.True(t, newInstaller.action.DisableHooks)
+	assert.True(t, newInstaller.action.SkipCRDs)
+	assert.True(t, newInstaller.action.CreateNamespace)
+	assert.Equal(t, "deployed by albatross", newInstaller.action.Description)
+	assert.Nil(t, newInstaller.jobs)
+}
+
 func (s *TestSuite) TestNewUninstallerUsingFlagValues() {
 	t := s.T()
 	dryRun := true
@@ -110,6 +180,128 @@ func (s *TestSuite) TestNewStatusGiverUsingFlagValues() {
 	assert.Equal(t, globalFlags.KubeContext, newStatusGiver.envSettings.KubeContext)
 }
 
+func (s *TestSuite) TestNewRollbackerUsingFlagValues() {
+	t := s.T()
+	globalFlags := flags.GlobalFlags{
+		Namespace:   "minikube",
+		KubeContext: "staging",
+	}
+	rbFlags := flags.RollbackFlags{
+		GlobalFlags:   globalFlags,
+		Version:       2,
+		Wait:          true,
+		Force:         true,
+		Recreate:      true,
+		CleanupOnFail: true,
+	}
+
+	r, err := s.c.NewRollbacker(rbFlags)
+
+	newRollbacker, ok := r.(*rollbacker)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, newRollbacker.action.Version)
+	assert.True(t, newRollbacker.action.Wait)
+	assert.True(t, newRollbacker.action.Force)
+	assert.True(t, newRollbacker.action.Recreate)
+	assert.True(t, newRollbacker.action.CleanupOnFail)
+	assert.NotNil(t, newRollbacker.status)
+	assert.Equal(t, globalFlags.KubeContext, newRollbacker.envSettings.KubeContext)
+}
+
+func (s *TestSuite) TestNewHistoryGiverUsingFlagValues() {
+	t := s.T()
+	globalFlags := flags.GlobalFlags{
+		Namespace:   "minikube",
+		KubeContext: "staging",
+	}
+	historyFlags := flags.HistoryFlags{
+		GlobalFlags: globalFlags,
+		Max:         5,
+	}
+
+	h, err := s.c.NewHistoryGiver(historyFlags)
+
+	newHistoryGiver, ok := h.(*historyGiver)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 5, newHistoryGiver.action.Max)
+	assert.Equal(t, globalFlags.KubeContext, newHistoryGiver.envSettings.KubeContext)
+}
+
+func (s *TestSuite) TestNewValuesGiverUsingFlagValues() {
+	t := s.T()
+	globalFlags := flags.GlobalFlags{
+		Namespace:   "minikube",
+		KubeContext: "staging",
+	}
+	valuesFlags := flags.GetValuesFlags{
+		GlobalFlags: globalFlags,
+		Version:     2,
+		AllValues:   true,
+	}
+
+	v, err := s.c.NewValuesGiver(valuesFlags)
+
+	newValuesGiver, ok := v.(*valuesGiver)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 2, newValuesGiver.action.Version)
+	assert.True(t, newValuesGiver.action.AllValues)
+	assert.Equal(t, globalFlags.KubeContext, newValuesGiver.envSettings.KubeContext)
+}
+
+func (s *TestSuite) TestNewTemplaterSetsClientOnlyOptions() {
+	t := s.T()
+	flg := flags.TemplateFlags{
+		Version:     "0.1.0",
+		Namespace:   "namespace",
+		IncludeCRDs: true,
+		APIVersions: []string{"monitoring.coreos.com/v1"},
+	}
+
+	tc, err := s.c.NewTemplater(flg)
+
+	newTemplater, ok := tc.(*templater)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.True(t, newTemplater.action.ClientOnly)
+	assert.True(t, newTemplater.action.DryRun)
+	assert.True(t, newTemplater.action.Replace)
+	assert.True(t, newTemplater.action.IncludeCRDs)
+	assert.Equal(t, flg.Version, newTemplater.action.Version)
+	assert.Equal(t, flg.Namespace, newTemplater.action.Namespace)
+	assert.True(t, newTemplater.action.APIVersions.Has("monitoring.coreos.com/v1"))
+}
+
+func (s *TestSuite) TestNewTesterUsingFlagValues() {
+	t := s.T()
+	globalFlags := flags.GlobalFlags{
+		Namespace:   "minikube",
+		KubeContext: "staging",
+	}
+	testFlags := flags.TestFlags{
+		GlobalFlags: globalFlags,
+		Timeout:     time.Minute,
+		Filters:     []string{"name=mysql-test"},
+	}
+
+	tc, err := s.c.NewTester(testFlags)
+
+	newTester, ok := tc.(*tester)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, newTester.action.Timeout)
+	assert.Equal(t, globalFlags.Namespace, newTester.action.Namespace)
+	assert.Equal(t, globalFlags.KubeContext, newTester.envSettings.KubeContext)
+}
+
+func (s *TestSuite) TestNewTesterFailsOnInvalidFilter() {
+	_, err := s.c.NewTester(flags.TestFlags{Filters: []string{"mysql-test"}})
+
+	assert.EqualError(s.T(), err, `invalid test filter "mysql-test", expected name=<hook> or !name=<hook>`)
+}
+
 func TestHandler(t *testing.T) {
 	suite.Run(t, new(TestSuite))
 }
@@ -1,31 +1,46 @@
 package config
 
 import (
+	"errors"
 	"os"
+	"time"
 
+	"github.com/gojekfarm/albatross/pkg/cluster"
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
 	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/tracing"
 
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/kube"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 )
 
+// ErrClusterOverride is returned when a request sets kube_apiserver or kube_token for a registered cluster.
+// The credentials of the cluster would otherwise be sent to the API server of the request.
+var ErrClusterOverride = errors.New("kube_apiserver and kube_token cannot be set for registered clusters")
+
 // ActionConfig acts as a proxy to helm package's action configuration.
 // It defines methods to set the default/common action config members.
 type ActionConfig struct {
 	*action.Configuration
+	// Setup is the time spent creating the kube client. Helm actions are created without a context,
+	// so it is recorded as a span when they run.
+	Setup tracing.Interval
 }
 
 // NewActionConfig returns a new instance of actionconfig.
-func NewActionConfig(envconfig *EnvConfig, flg *flags.GlobalFlags) (*ActionConfig, error) {
+// The kube context is looked up in clusters first, unregistered names are used as kubeconfig contexts.
+// clusters can be nil.
+func NewActionConfig(envconfig *EnvConfig, flg *flags.GlobalFlags, clusters cluster.Lookup) (*ActionConfig, error) {
+	start := time.Now()
 	config := &ActionConfig{
-		new(action.Configuration),
+		Configuration: new(action.Configuration),
 	}
 
-	if err := config.setFlags(envconfig, flg); err != nil {
+	if err := config.setFlags(envconfig, flg, clusters); err != nil {
 		return nil, err
 	}
+	config.Setup = tracing.Since(start)
 	return config, nil
 }
 
@@ -48,15 +63,45 @@ func kubeClientConfig(envconfig *EnvConfig, namespace string) genericclioptions.
 	return clientConfig
 }
 
+// registeredClientConfig returns a kube config for a registered cluster.
+// The API server and credentials of registered clusters are not overridden, neither by the request nor by the environment.
+// The second return value is false when the cluster is not registered.
+func registeredClientConfig(envconfig *EnvConfig, flg *flags.GlobalFlags, clusters cluster.Lookup) (genericclioptions.RESTClientGetter, bool, error) {
+	if clusters == nil || envconfig.KubeContext == "" {
+		return nil, false, nil
+	}
+	c, err := clusters.Get(envconfig.KubeContext)
+	if errors.Is(err, cluster.ErrNotFound) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	if flg.KubeAPIServer != "" || flg.KubeToken != "" {
+		return nil, true, ErrClusterOverride
+	}
+
+	return c.RESTClientGetter(flg.Namespace), true, nil
+}
+
 // setFlags initializes the action configuration with proper config flags.
-func (ac *ActionConfig) setFlags(envconfig *EnvConfig, flg *flags.GlobalFlags) error {
-	actionNamespace := envconfig.Namespace()
-	if flg.Namespace != "" {
-		actionNamespace = flg.Namespace
+func (ac *ActionConfig) setFlags(envconfig *EnvConfig, flg *flags.GlobalFlags, clusters cluster.Lookup) error {
+	getter, registered, err := registeredClientConfig(envconfig, flg, clusters)
+	if err != nil {
+		return err
+	}
+
+	if !registered {
+		actionNamespace := envconfig.Namespace()
+		if flg.Namespace != "" {
+			actionNamespace = flg.Namespace
+		}
+		getter = kubeClientConfig(envconfig, actionNamespace)
 	}
 
 	return ac.Configuration.Init(
-		kubeClientConfig(envconfig, actionNamespace),
+		getter,
 		flg.Namespace,
 		os.Getenv("HELM_DRIVER"),
 		logger.Debugf,
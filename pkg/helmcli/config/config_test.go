@@ -0,0 +1,69 @@
+package config
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/albatross/pkg/cluster"
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+)
+
+type fakeClusters map[string]cluster.Cluster
+
+func (f fakeClusters) Get(name string) (cluster.Cluster, error) {
+	c, ok := f[name]
+	if !ok {
+		return cluster.Cluster{}, cluster.ErrNotFound
+	}
+	return c, nil
+}
+
+var registered = fakeClusters{
+	"staging": {
+		Name:      "staging",
+		APIServer: "https://staging.example.com",
+		Auth:      cluster.Auth{Method: cluster.TokenAuth, Token: "secret"},
+	},
+}
+
+func TestNewActionConfigRejectsOverridesForRegisteredClusters(t *testing.T) {
+	for name, flg := range map[string]flags.GlobalFlags{
+		"api server": {KubeContext: "staging", KubeAPIServer: "https://attacker.example.com"},
+		"token":      {KubeContext: "staging", KubeToken: "stolen"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			flg := flg
+			_, err := NewActionConfig(NewEnvConfig(&flg), &flg, registered)
+
+			assert.True(t, errors.Is(err, ErrClusterOverride))
+		})
+	}
+}
+
+func TestRegisteredClientConfigKeepsTheClusterEndpointAndCredentials(t *testing.T) {
+	flg := &flags.GlobalFlags{KubeContext: "staging"}
+	envconfig := NewEnvConfig(flg)
+	envconfig.KubeAPIServer = "https://attacker.example.com"
+	envconfig.KubeToken = "stolen"
+
+	getter, ok, err := registeredClientConfig(envconfig, flg, registered)
+	require.NoError(t, err)
+	require.True(t, ok)
+
+	restConfig, err := getter.ToRESTConfig()
+	require.NoError(t, err)
+	assert.Equal(t, "https://staging.example.com", restConfig.Host)
+	assert.Equal(t, "secret", restConfig.BearerToken)
+}
+
+func TestRegisteredClientConfigIgnoresUnregisteredClusters(t *testing.T) {
+	flg := &flags.GlobalFlags{KubeContext: "minikube", KubeAPIServer: "https://127.0.0.1:6443"}
+
+	_, ok, err := registeredClientConfig(NewEnvConfig(flg), flg, registered)
+
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
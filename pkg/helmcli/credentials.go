@@ -0,0 +1,30 @@
+package helmcli
+
+import (
+	"context"
+
+	"helm.sh/helm/v3/pkg/action"
+
+	"github.com/gojekfarm/albatross/pkg/secret"
+)
+
+// CredentialsResolver returns the credentials of the repository a chart is downloaded from,
+// ok is false when the repository has none.
+type CredentialsResolver interface {
+	Resolve(ctx context.Context, chartName string) (c secret.Credentials, ok bool, err error)
+}
+
+// setCredentials passes the stored repository credentials to the chart download,
+// they are not part of the repositories file.
+func setCredentials(ctx context.Context, resolver CredentialsResolver, opts *action.ChartPathOptions, chartName string) error {
+	if resolver == nil {
+		return nil
+	}
+	c, ok, err := resolver.Resolve(ctx, chartName)
+	if err != nil || !ok {
+		return err
+	}
+	opts.Username = c.Username
+	opts.Password = c.Password
+	return nil
+}
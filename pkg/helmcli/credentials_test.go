@@ -0,0 +1,47 @@
+package helmcli
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"helm.sh/helm/v3/pkg/action"
+
+	"github.com/gojekfarm/albatross/pkg/secret"
+)
+
+type resolverFunc func(ctx context.Context, chartName string) (secret.Credentials, bool, error)
+
+func (f resolverFunc) Resolve(ctx context.Context, chartName string) (secret.Credentials, bool, error) {
+	return f(ctx, chartName)
+}
+
+func TestSetCredentialsUsesResolvedCredentials(t *testing.T) {
+	resolver := resolverFunc(func(_ context.Context, chartName string) (secret.Credentials, bool, error) {
+		assert.Equal(t, "private/mysql", chartName)
+		return secret.Credentials{Username: "admin", Password: "1234"}, true, nil
+	})
+	var opts action.ChartPathOptions
+
+	err := setCredentials(context.Background(), resolver, &opts, "private/mysql")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "admin", opts.Username)
+	assert.Equal(t, "1234", opts.Password)
+}
+
+func TestSetCredentialsWithoutCredentials(t *testing.T) {
+	var opts action.ChartPathOptions
+	none := resolverFunc(func(context.Context, string) (secret.Credentials, bool, error) {
+		return secret.Credentials{}, false, nil
+	})
+	failing := resolverFunc(func(context.Context, string) (secret.Credentials, bool, error) {
+		return secret.Credentials{}, false, errors.New("credentials not found")
+	})
+
+	assert.NoError(t, setCredentials(context.Background(), nil, &opts, "stable/mysql"))
+	assert.NoError(t, setCredentials(context.Background(), none, &opts, "stable/mysql"))
+	assert.EqualError(t, setCredentials(context.Background(), failing, &opts, "private/mysql"), "credentials not found")
+	assert.Empty(t, opts.Username)
+}
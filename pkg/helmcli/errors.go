@@ -0,0 +1,52 @@
+package helmcli
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrChartNotFound is returned when a chart cannot be found on disk or in its repository
+	ErrChartNotFound = errors.New("chart not found")
+	// ErrReleaseExists is returned when installing a release whose name is still in use
+	ErrReleaseExists = errors.New("release already exists")
+)
+
+// helm returns these errors as bare strings, they are matched here only, once, to give them a kind
+const (
+	reusedName     = "cannot re-use a name that is still in use"
+	failedDownload = "failed to download"
+	notFound       = "not found"
+)
+
+// kindError gives err the kind of a sentinel error while keeping the message helm gave it
+type kindError struct {
+	err  error
+	kind error
+}
+
+func (e kindError) Error() string { return e.err.Error() }
+
+func (e kindError) Unwrap() error { return e.err }
+
+func (e kindError) Is(target error) bool { return target == e.kind }
+
+// chartError tells missing charts apart in the errors of locating a chart. Helm reports a chart
+// missing from a repository index as a failed download.
+func chartError(err error) error {
+	if err == nil || errors.Is(err, ErrVerification) {
+		return err
+	}
+	if msg := err.Error(); strings.Contains(msg, notFound) || strings.HasPrefix(msg, failedDownload) {
+		return kindError{err: err, kind: ErrChartNotFound}
+	}
+	return err
+}
+
+// installError tells apart installs of a release whose name is in use
+func installError(err error) error {
+	if err != nil && err.Error() == reusedName {
+		return kindError{err: err, kind: ErrReleaseExists}
+	}
+	return err
+}
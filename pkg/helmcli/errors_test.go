@@ -0,0 +1,35 @@
+package helmcli
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChartErrorTellsMissingChartsApart(t *testing.T) {
+	tests := []struct {
+		err     error
+		missing bool
+	}{
+		{err: errors.New(`path "./mysql" not found`), missing: true},
+		{err: errors.New(`failed to download "stable/mysql" (hint: running 'helm repo update' may help)`), missing: true},
+		{err: errors.New("Get https://charts.example.com/index.yaml: connection refused"), missing: false},
+		{err: ErrVerification, missing: false},
+	}
+	for _, tt := range tests {
+		err := chartError(tt.err)
+
+		assert.Equal(t, tt.missing, errors.Is(err, ErrChartNotFound), tt.err.Error())
+		assert.EqualError(t, err, tt.err.Error())
+	}
+	assert.NoError(t, chartError(nil))
+}
+
+func TestInstallErrorTellsReusedNamesApart(t *testing.T) {
+	err := installError(errors.New("cannot re-use a name that is still in use"))
+
+	assert.True(t, errors.Is(err, ErrReleaseExists))
+	assert.EqualError(t, err, "cannot re-use a name that is still in use")
+	assert.False(t, errors.Is(installError(errors.New("timed out")), ErrReleaseExists))
+}
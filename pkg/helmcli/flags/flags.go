@@ -19,12 +19,41 @@ type UpgradeFlags struct {
 	DryRun  bool
 	Install bool
 	Version string
+	// Wait waits for the release resources to be ready before marking the release deployed
+	Wait bool
+	// WaitForJobs also waits for the jobs of the release to complete, it only applies with Wait
+	WaitForJobs   bool
+	Timeout       time.Duration
+	Atomic        bool
+	CleanupOnFail bool
+	Force         bool
+	ResetValues   bool
+	ReuseValues   bool
+	DisableHooks  bool
+	// SkipCRDs and CreateNamespace are used when the release does not exist and is installed
+	SkipCRDs        bool
+	CreateNamespace bool
+	Description     string
+	// Verify checks the chart against its provenance file
+	Verify bool
 	GlobalFlags
 }
 
 type InstallFlags struct {
 	DryRun  bool
 	Version string
+	// Wait waits for the release resources to be ready before marking the release deployed
+	Wait bool
+	// WaitForJobs also waits for the jobs of the release to complete, it only applies with Wait
+	WaitForJobs     bool
+	Timeout         time.Duration
+	Atomic          bool
+	DisableHooks    bool
+	SkipCRDs        bool
+	CreateNamespace bool
+	Description     string
+	// Verify checks the chart against its provenance file
+	Verify bool
 	GlobalFlags
 }
 
@@ -35,6 +64,10 @@ type ListFlags struct {
 	Pending       bool
 	Uninstalled   bool
 	Uninstalling  bool
+	// Stuck only lists releases whose last revision is pending, ignoring the other states
+	Stuck bool
+	// StuckFor only lists stuck releases whose last revision was updated longer ago
+	StuckFor time.Duration
 	GlobalFlags
 }
 
@@ -53,12 +86,69 @@ type StatusFlags struct {
 	GlobalFlags
 }
 
+// RollbackFlags maps the list of options that can be passed to the rollback action.
+type RollbackFlags struct {
+	Version       int
+	Timeout       time.Duration
+	Wait          bool
+	Force         bool
+	Recreate      bool
+	CleanupOnFail bool
+	GlobalFlags
+}
+
+// RecoverFlags maps the options of recovering a release stuck in a pending state.
+type RecoverFlags struct {
+	// Rollback rolls back to the last deployed revision once the stuck revision is marked failed
+	Rollback bool
+	// StuckFor only recovers releases whose stuck revision was last updated longer ago
+	StuckFor time.Duration
+	// Timeout and Wait apply to the rollback
+	Timeout time.Duration
+	Wait    bool
+	GlobalFlags
+}
+
+// TestFlags maps the list of options that can be passed to the release testing action.
+type TestFlags struct {
+	Timeout time.Duration
+	// Filters selects test hooks by name, "name=<hook>" runs only the given hooks
+	// and "!name=<hook>" skips them
+	Filters []string
+	GlobalFlags
+}
+
+// HistoryFlags maps the list of options that can be passed to the history action.
+type HistoryFlags struct {
+	Max int
+	GlobalFlags
+}
+
+// GetValuesFlags maps the list of options that can be passed to the get values action.
+type GetValuesFlags struct {
+	Version   int
+	AllValues bool
+	GlobalFlags
+}
+
+// TemplateFlags maps the list of options that can be passed to render a chart locally.
+// Templates are rendered without a cluster, so there are no cluster specific flags.
+type TemplateFlags struct {
+	Version     string
+	Namespace   string
+	IncludeCRDs bool
+	IsUpgrade   bool
+	APIVersions []string
+}
+
 type AddFlags struct {
-	Name        string
-	URL         string
-	Username    string
-	Password    string
-	ForceUpdate bool
+	Name     string
+	URL      string
+	Username string
+	Password string
+	// CredentialsRef names credentials in the secret store, it cannot be used with Username and Password
+	CredentialsRef string
+	ForceUpdate    bool
 
 	CertFile              string
 	KeyFile               string
@@ -68,3 +158,37 @@ type AddFlags struct {
 	RepoFile  string
 	RepoCache string
 }
+
+// RepoRemoveFlags maps the options to remove a repository and its cached index.
+type RepoRemoveFlags struct {
+	Name string
+
+	RepoFile  string
+	RepoCache string
+}
+
+// SearchFlags maps the options to search the charts of the configured repositories.
+type SearchFlags struct {
+	// Keyword matches the chart name, description and keywords, every chart matches an empty keyword
+	Keyword string
+	// Version is a semantic version constraint, the latest stable version is used when empty
+	Version string
+	// Versions returns every matching version instead of the latest one
+	Versions bool
+
+	RepoFile  string
+	RepoCache string
+}
+
+// RepoUpdateFlags maps the options to refresh the index of repositories, every repository is updated when Names is empty.
+type RepoUpdateFlags struct {
+	Names []string
+
+	RepoFile  string
+	RepoCache string
+}
+
+// ShowFlags maps the options to show a chart.
+type ShowFlags struct {
+	Version string
+}
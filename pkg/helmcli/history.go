@@ -0,0 +1,39 @@
+package helmcli
+
+import (
+	"context"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+type historyGiver struct {
+	action      *action.History
+	envSettings *cli.EnvSettings
+	kubeClient  tracing.Interval
+}
+
+// History returns the stored revisions of a release, oldest first.
+// The history action does not apply Max by itself, it is left to the caller as in the helm cli.
+func (h *historyGiver) History(ctx context.Context, releaseName string) ([]*release.Release, error) {
+	_, span := startAction(ctx, "history", h.kubeClient)
+	releases, err := h.action.Run(releaseName)
+	tracing.End(span, err)
+	if err != nil {
+		return nil, err
+	}
+	if len(releases) == 0 {
+		return nil, driver.ErrReleaseNotFound
+	}
+
+	releaseutil.SortByRevision(releases)
+	if h.action.Max > 0 && len(releases) > h.action.Max {
+		releases = releases[len(releases)-h.action.Max:]
+	}
+	return releases, nil
+}
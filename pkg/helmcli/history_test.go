@@ -0,0 +1,89 @@
+package helmcli
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func fakeHistoryConfiguration(t *testing.T) *action.Configuration {
+	newStorage := storage.Init(driver.NewMemory())
+	statuses := []release.Status{release.StatusSuperseded, release.StatusSuperseded, release.StatusDeployed}
+	// Stored out of order to ensure the history is sorted by revision
+	for _, version := range []int{3, 1, 2} {
+		err := newStorage.Create(
+			release.Mock(
+				&release.MockReleaseOptions{
+					Name:      testReleaseName,
+					Version:   version,
+					Namespace: "default",
+					Status:    statuses[version-1],
+				}))
+		require.NoError(t, err)
+	}
+
+	return &action.Configuration{
+		Releases: newStorage,
+		KubeClient: &kubefake.FailingKubeClient{
+			PrintingKubeClient: kubefake.PrintingKubeClient{
+				Out: ioutil.Discard,
+			},
+		},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log: func(format string, v ...interface{}) {
+			t.Helper()
+			t.Logf(format, v...)
+		},
+	}
+}
+
+func TestHistoryShouldFailForInvalidRelease(t *testing.T) {
+	h := &historyGiver{
+		action:      action.NewHistory(fakeHistoryConfiguration(t)),
+		envSettings: cli.New(),
+	}
+
+	_, err := h.History(context.Background(), testReleaseName+"-incorrect")
+
+	assert.Equal(t, driver.ErrReleaseNotFound, err)
+}
+
+func TestHistoryShouldReturnAllRevisionsSorted(t *testing.T) {
+	h := &historyGiver{
+		action:      action.NewHistory(fakeHistoryConfiguration(t)),
+		envSettings: cli.New(),
+	}
+
+	releases, err := h.History(context.Background(), testReleaseName)
+
+	require.NoError(t, err)
+	require.Len(t, releases, 3)
+	for i, rel := range releases {
+		assert.Equal(t, i+1, rel.Version)
+	}
+}
+
+func TestHistoryShouldLimitToLatestMaxRevisions(t *testing.T) {
+	h := &historyGiver{
+		action:      action.NewHistory(fakeHistoryConfiguration(t)),
+		envSettings: cli.New(),
+	}
+	h.action.Max = 2
+
+	releases, err := h.History(context.Background(), testReleaseName)
+
+	require.NoError(t, err)
+	require.Len(t, releases, 2)
+	assert.Equal(t, 2, releases[0].Version)
+	assert.Equal(t, 3, releases[1].Version)
+}
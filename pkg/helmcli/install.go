@@ -2,35 +2,99 @@ package helmcli
 
 import (
 	"context"
+	"fmt"
 
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
-	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/registry"
+	"github.com/gojekfarm/albatross/pkg/tracing"
 )
 
 type installer struct {
 	action      *action.Install
 	envSettings *cli.EnvSettings
+	credentials CredentialsResolver
+	registry    RegistryClient
+	verify      verification
+	jobs        *jobWaiter
+	releases    *storage.Storage
+	// uninstall is set with atomic, to uninstall releases whose jobs failed
+	uninstall  *action.Uninstall
+	kubeClient tracing.Interval
 }
 
 func (i *installer) Install(ctx context.Context, relName, chartName string, values map[string]interface{}) (*release.Release, error) {
+	ch, err := i.loadChart(ctx, chartName)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.install(ctx, relName, ch, values)
+}
+
+// InstallChart installs a chart that is already loaded, such as an uploaded archive.
+// Such charts have no provenance, so they are refused when the chart must be verified.
+func (i *installer) InstallChart(ctx context.Context, relName string, ch *chart.Chart, values map[string]interface{}) (*release.Release, error) {
+	if err := i.verify.refuse("uploaded"); err != nil {
+		return nil, err
+	}
+	return i.install(ctx, relName, ch, values)
+}
+
+func (i *installer) install(ctx context.Context, relName string, ch *chart.Chart, values map[string]interface{}) (*release.Release, error) {
 	i.action.ReleaseName = relName
 
-	ch, err := i.loadChart(chartName)
-	if err != nil {
+	// helm actions cannot be interrupted, so a cancelled request stops before touching the cluster
+	if err := ctx.Err(); err != nil {
 		return nil, err
 	}
+	ctx, span := startAction(ctx, "install", i.kubeClient)
+	rel, err := i.action.Run(ch, values)
+	err = installError(err)
+	if err == nil && !i.action.DryRun {
+		if err = i.jobs.Wait(ctx, rel); err != nil {
+			err = i.failJobs(rel, err)
+		}
+	}
+	tracing.End(span, err)
+	return rel, err
+}
 
-	return i.action.Run(ch, values)
+// failJobs marks a release whose jobs failed as failed and, with atomic, uninstalls it like helm does
+// when the resources of the release are not ready in time
+func (i *installer) failJobs(rel *release.Release, jobErr error) error {
+	if err := markFailed(i.releases, rel, jobErr); err != nil {
+		return err
+	}
+	if i.uninstall == nil {
+		return jobErr
+	}
+	if _, err := i.uninstall.Run(rel.Name); err != nil {
+		return fmt.Errorf("error uninstalling release %s after its jobs failed: %v: %w", rel.Name, err, jobErr)
+	}
+	return fmt.Errorf("release %s failed, and has been uninstalled due to atomic being set: %w", rel.Name, jobErr)
 }
 
-func (i *installer) loadChart(chartName string) (*chart.Chart, error) {
-	cp, err := i.action.LocateChart(chartName, i.envSettings)
+func (i *installer) loadChart(ctx context.Context, chartName string) (*chart.Chart, error) {
+	if registry.IsOCI(chartName) {
+		if err := i.verify.refuse("oci"); err != nil {
+			return nil, err
+		}
+		return loadOCIChart(ctx, i.registry, chartName, i.action.Version)
+	}
+	if err := setCredentials(ctx, i.credentials, &i.action.ChartPathOptions, chartName); err != nil {
+		return nil, err
+	}
+	cp, err := traceLocate(ctx, chartName, func() (string, error) {
+		return i.verify.locateChart(&i.action.ChartPathOptions, i.envSettings, chartName)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return loader.Load(cp)
+	return loadLocated(ctx, cp)
 }
@@ -65,3 +65,19 @@ func TestInstallShouldReturnInstalledReleaseOnSuccess(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, release.Name, "test-release")
 }
+
+func TestInstallShouldNotRunWhenContextIsCancelled(t *testing.T) {
+	config := fakeInstallConfiguration(t)
+	u := &installer{
+		action:      action.NewInstall(config),
+		envSettings: cli.New(),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := u.Install(ctx, "test-release", "../../api/testdata/albatross", map[string]interface{}{})
+
+	assert.Equal(t, context.Canceled, err)
+	_, err = config.Releases.Last("test-release")
+	assert.Error(t, err)
+}
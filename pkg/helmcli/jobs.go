@@ -0,0 +1,65 @@
+package helmcli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"time"
+
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"k8s.io/cli-runtime/pkg/resource"
+
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+const jobKind = "Job"
+
+// jobWaiter waits for the jobs of a release to complete.
+// The wait option of helm 3.2 only covers pods, services and volumes, so jobs are watched separately.
+type jobWaiter struct {
+	kubeClient kube.Interface
+	timeout    time.Duration
+}
+
+func newJobWaiter(kubeClient kube.Interface, wait, waitForJobs bool, timeout time.Duration) *jobWaiter {
+	if !wait || !waitForJobs {
+		return nil
+	}
+	return &jobWaiter{kubeClient: kubeClient, timeout: timeout}
+}
+
+// Wait blocks until every job of the release completed, a nil waiter returns right away
+func (w *jobWaiter) Wait(ctx context.Context, rel *release.Release) (err error) {
+	if w == nil || rel == nil {
+		return nil
+	}
+	_, span := tracing.Start(ctx, "helm.jobs.wait")
+	defer func() { tracing.End(span, err) }()
+
+	resources, err := w.kubeClient.Build(bytes.NewBufferString(rel.Manifest), false)
+	if err != nil {
+		return fmt.Errorf("error building release resources: %w", err)
+	}
+	jobs := resources.Filter(func(info *resource.Info) bool {
+		return info.Mapping != nil && info.Mapping.GroupVersionKind.Kind == jobKind
+	})
+	if len(jobs) == 0 {
+		return nil
+	}
+	if err := w.kubeClient.WatchUntilReady(jobs, w.timeout); err != nil {
+		return fmt.Errorf("error waiting for jobs: %w", err)
+	}
+	return nil
+}
+
+// markFailed marks a release whose jobs failed as failed. Helm stored it as deployed before its jobs were
+// awaited, while it marks releases whose resources are not ready in time failed.
+func markFailed(releases *storage.Storage, rel *release.Release, jobErr error) error {
+	rel.SetStatus(release.StatusFailed, fmt.Sprintf("Release %q failed: %s", rel.Name, jobErr))
+	if err := releases.Update(rel); err != nil {
+		return fmt.Errorf("error marking release %s failed: %v: %w", rel.Name, err, jobErr)
+	}
+	return nil
+}
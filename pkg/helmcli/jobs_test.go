@@ -0,0 +1,163 @@
+package helmcli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/kube"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+type jobKubeClient struct {
+	kubefake.PrintingKubeClient
+	resources kube.ResourceList
+	watched   kube.ResourceList
+	timeout   time.Duration
+	watchErr  error
+}
+
+func (c *jobKubeClient) Build(_ io.Reader, _ bool) (kube.ResourceList, error) {
+	return c.resources, nil
+}
+
+func (c *jobKubeClient) WatchUntilReady(resources kube.ResourceList, timeout time.Duration) error {
+	c.watched = resources
+	c.timeout = timeout
+	return c.watchErr
+}
+
+func resourceInfo(name, kind string) *resource.Info {
+	return &resource.Info{
+		Name:    name,
+		Mapping: &meta.RESTMapping{GroupVersionKind: schema.GroupVersionKind{Kind: kind}},
+	}
+}
+
+func newJobKubeClient() *jobKubeClient {
+	return &jobKubeClient{
+		PrintingKubeClient: kubefake.PrintingKubeClient{Out: ioutil.Discard},
+		resources: kube.ResourceList{
+			resourceInfo("migrate", "Job"),
+			resourceInfo("api", "Deployment"),
+		},
+	}
+}
+
+func TestJobWaiterIsDisabledWithoutWait(t *testing.T) {
+	assert.Nil(t, newJobWaiter(newJobKubeClient(), false, true, time.Minute))
+	assert.Nil(t, newJobWaiter(newJobKubeClient(), true, false, time.Minute))
+
+	var w *jobWaiter
+	assert.NoError(t, w.Wait(context.Background(), &release.Release{}))
+}
+
+func TestJobWaiterWatchesOnlyJobs(t *testing.T) {
+	kc := newJobKubeClient()
+	w := newJobWaiter(kc, true, true, time.Minute)
+
+	err := w.Wait(context.Background(), &release.Release{Manifest: "kind: Job"})
+
+	assert.NoError(t, err)
+	assert.Len(t, kc.watched, 1)
+	assert.Equal(t, "migrate", kc.watched[0].Name)
+	assert.Equal(t, time.Minute, kc.timeout)
+}
+
+func TestJobWaiterSkipsReleasesWithoutJobs(t *testing.T) {
+	kc := newJobKubeClient()
+	kc.resources = kube.ResourceList{resourceInfo("api", "Deployment")}
+	kc.watchErr = errors.New("should not watch")
+	w := newJobWaiter(kc, true, true, time.Minute)
+
+	assert.NoError(t, w.Wait(context.Background(), &release.Release{}))
+	assert.Nil(t, kc.watched)
+}
+
+func TestJobWaiterReturnsWatchErrors(t *testing.T) {
+	kc := newJobKubeClient()
+	kc.watchErr = errors.New("job failed: BackoffLimitExceeded")
+	w := newJobWaiter(kc, true, true, time.Minute)
+
+	err := w.Wait(context.Background(), &release.Release{})
+
+	assert.EqualError(t, err, "error waiting for jobs: job failed: BackoffLimitExceeded")
+}
+
+func failingJobWaiter() *jobWaiter {
+	kc := newJobKubeClient()
+	kc.watchErr = errors.New("job failed: BackoffLimitExceeded")
+	return newJobWaiter(kc, true, true, time.Minute)
+}
+
+func TestInstallShouldMarkReleaseFailedWhenJobsFail(t *testing.T) {
+	config := fakeInstallConfiguration(t)
+	i := &installer{
+		action:      action.NewInstall(config),
+		envSettings: cli.New(),
+		jobs:        failingJobWaiter(),
+		releases:    config.Releases,
+	}
+
+	rel, err := i.Install(context.Background(), "test-release", "../../api/testdata/albatross", nil)
+
+	assert.EqualError(t, err, "error waiting for jobs: job failed: BackoffLimitExceeded")
+	stored, err := config.Releases.Last("test-release")
+	require.NoError(t, err)
+	assert.Equal(t, release.StatusFailed, stored.Info.Status)
+	assert.Equal(t, release.StatusFailed, rel.Info.Status)
+}
+
+func TestAtomicInstallShouldUninstallReleaseWhenJobsFail(t *testing.T) {
+	config := fakeInstallConfiguration(t)
+	i := &installer{
+		action:      action.NewInstall(config),
+		envSettings: cli.New(),
+		jobs:        failingJobWaiter(),
+		releases:    config.Releases,
+		uninstall:   action.NewUninstall(config),
+	}
+	i.action.Atomic = true
+
+	_, err := i.Install(context.Background(), "test-release", "../../api/testdata/albatross", nil)
+
+	assert.EqualError(t, err, "release test-release failed, and has been uninstalled due to atomic being set: error waiting for jobs: job failed: BackoffLimitExceeded")
+	_, err = config.Releases.Last("test-release")
+	assert.True(t, errors.Is(err, driver.ErrReleaseNotFound), err)
+}
+
+func TestAtomicUpgradeShouldRollBackReleaseWhenJobsFail(t *testing.T) {
+	config := fakeRecoverConfiguration(t, release.StatusDeployed)
+	u := &upgrader{
+		action:      action.NewUpgrade(config),
+		history:     action.NewHistory(config),
+		envSettings: cli.New(),
+		jobs:        failingJobWaiter(),
+		releases:    config.Releases,
+		rollback:    action.NewRollback(config),
+	}
+	u.action.Atomic = true
+
+	rel, err := u.Upgrade(context.Background(), testReleaseName, "../../api/testdata/albatross", nil)
+
+	assert.EqualError(t, err, "release "+testReleaseName+" failed, and has been rolled back due to atomic being set: error waiting for jobs: job failed: BackoffLimitExceeded")
+	assert.Equal(t, 2, rel.Version)
+	assert.Equal(t, release.StatusFailed, rel.Info.Status)
+	last, err := config.Releases.Last(testReleaseName)
+	require.NoError(t, err)
+	assert.Equal(t, 3, last.Version)
+	assert.Equal(t, release.StatusDeployed, last.Info.Status)
+	assert.Equal(t, "Rollback to 1", last.Info.Description)
+}
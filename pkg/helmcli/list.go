@@ -2,18 +2,49 @@ package helmcli
 
 import (
 	"context"
+	"time"
 
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/gojekfarm/albatross/pkg/tracing"
 )
 
 type lister struct {
 	action      *action.List
 	envSettings *cli.EnvSettings
+	kubeClient  tracing.Interval
+	// stuck only keeps the releases whose last revision has been pending for at least stuckFor
+	stuck    bool
+	stuckFor time.Duration
+	now      func() time.Time
 }
 
 // List runs the list operation.
 func (l *lister) List(ctx context.Context) ([]*release.Release, error) {
-	return l.action.Run()
+	_, span := startAction(ctx, "list", l.kubeClient)
+	releases, err := l.action.Run()
+	tracing.End(span, err)
+	if err != nil || !l.stuck {
+		return releases, err
+	}
+
+	var stuck []*release.Release
+	for _, rel := range releases {
+		if isStuck(rel, l.stuckFor, l.now()) {
+			stuck = append(stuck, rel)
+		}
+	}
+	return stuck, nil
+}
+
+// isStuck tells whether rel is pending and was last updated at least stuckFor before now
+func isStuck(rel *release.Release, stuckFor time.Duration, now time.Time) bool {
+	return isPending(rel.Info.Status) && now.Sub(rel.Info.LastDeployed.Time) >= stuckFor
+}
+
+// isPending tells whether status is left by an action that has not finished
+func isPending(status release.Status) bool {
+	return status == release.StatusPendingInstall || status == release.StatusPendingUpgrade || status == release.StatusPendingRollback
 }
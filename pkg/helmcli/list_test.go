@@ -3,6 +3,7 @@ package helmcli
 import (
 	"context"
 	"testing"
+	gotime "time"
 
 	"github.com/stretchr/testify/assert"
 	"helm.sh/helm/v3/pkg/action"
@@ -37,3 +38,31 @@ func TestListShouldReturnListOfReleasesOnSuccess(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, releases[0].Name, "test-release")
 }
+
+func TestListShouldOnlyReturnReleasesStuckInPendingStates(t *testing.T) {
+	config := fakeInstallConfiguration(t)
+	for _, rel := range []*release.Release{
+		{Name: "recovered", Namespace: "default", Version: 1, Info: &release.Info{Status: release.StatusPendingUpgrade}},
+		{Name: "recovered", Namespace: "default", Version: 2, Info: &release.Info{Status: release.StatusDeployed}},
+		{Name: "stuck", Namespace: "default", Version: 1, Info: &release.Info{Status: release.StatusPendingInstall}},
+	} {
+		if err := config.Releases.Create(rel); err != nil {
+			t.Error(err)
+		}
+	}
+	list := action.NewList(config)
+	list.StateMask = action.ListAll
+	l := &lister{
+		action:      list,
+		envSettings: cli.New(),
+		stuck:       true,
+		now:         gotime.Now,
+	}
+
+	releases, err := l.List(context.Background())
+
+	assert.NoError(t, err)
+	if assert.Len(t, releases, 1) {
+		assert.Equal(t, "stuck", releases[0].Name)
+	}
+}
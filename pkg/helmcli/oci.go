@@ -0,0 +1,27 @@
+package helmcli
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"helm.sh/helm/v3/pkg/chart"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/registry"
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+// RegistryClient loads charts of oci:// references
+type RegistryClient interface {
+	Load(ctx context.Context, chartName, version string) (*chart.Chart, error)
+}
+
+// loadOCIChart pulls the chart from its registry, anonymously when no client is configured
+func loadOCIChart(ctx context.Context, client RegistryClient, chartName, version string) (*chart.Chart, error) {
+	if client == nil {
+		client = registry.NewClient(nil)
+	}
+	ctx, span := tracing.Start(ctx, "helm.chart.pull", attribute.String("helm.chart", chartName))
+	ch, err := client.Load(ctx, chartName, version)
+	tracing.End(span, err)
+	return ch, err
+}
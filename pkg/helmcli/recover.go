@@ -0,0 +1,99 @@
+package helmcli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+var (
+	// ErrNotStuck is returned when recovering a release whose last revision is not pending,
+	// or was updated more recently than the threshold
+	ErrNotStuck = errors.New("release is not stuck in a pending state")
+	// ErrNoDeployedRevision is returned when rolling back a stuck release that has no deployed revision
+	ErrNoDeployedRevision = errors.New("release has no deployed revision to roll back to")
+)
+
+type recoverer struct {
+	releases *storage.Storage
+	// rollback is nil when the stuck revision is only marked failed
+	rollback    *action.Rollback
+	stuckFor    time.Duration
+	envSettings *cli.EnvSettings
+	kubeClient  tracing.Interval
+	now         func() time.Time
+}
+
+// Recover marks the pending last revision of a release failed, so that it can be upgraded again,
+// then rolls back to the last deployed revision when configured to.
+// It returns the last revision of the release once recovered.
+func (r *recoverer) Recover(ctx context.Context, releaseName string) (*release.Release, error) {
+	_, span := startAction(ctx, "recover", r.kubeClient)
+	rel, err := r.recover(releaseName)
+	tracing.End(span, err)
+	return rel, err
+}
+
+func (r *recoverer) recover(releaseName string) (*release.Release, error) {
+	last, err := r.releases.Last(releaseName)
+	if err != nil {
+		return nil, err
+	}
+	if !isPending(last.Info.Status) {
+		return nil, fmt.Errorf("%w: revision %d is %s", ErrNotStuck, last.Version, last.Info.Status)
+	}
+	if !isStuck(last, r.stuckFor, r.now()) {
+		return nil, fmt.Errorf("%w: revision %d was updated less than %s ago", ErrNotStuck, last.Version, r.stuckFor)
+	}
+
+	var deployed *release.Release
+	if r.rollback != nil {
+		// checked before anything changes so a release without a deployed revision is left as it is
+		if deployed, err = r.lastDeployed(last); err != nil {
+			return nil, err
+		}
+	}
+
+	last.SetStatus(release.StatusFailed, fmt.Sprintf("Marked failed after being stuck in %s", last.Info.Status))
+	if err := r.releases.Update(last); err != nil {
+		return nil, err
+	}
+	if deployed == nil {
+		return last, nil
+	}
+
+	r.rollback.Version = deployed.Version
+	if err := r.rollback.Run(releaseName); err != nil {
+		return nil, err
+	}
+	return r.releases.Last(releaseName)
+}
+
+// lastDeployed returns the most recent deployed revision older than the stuck one
+func (r *recoverer) lastDeployed(stuck *release.Release) (*release.Release, error) {
+	history, err := r.releases.History(stuck.Name)
+	if err != nil {
+		return nil, err
+	}
+	var deployed *release.Release
+	for _, rel := range history {
+		if rel.Version >= stuck.Version || rel.Info.Status != release.StatusDeployed {
+			continue
+		}
+		if deployed == nil || rel.Version > deployed.Version {
+			deployed = rel
+		}
+	}
+	if deployed == nil {
+		return nil, ErrNoDeployedRevision
+	}
+	return deployed, nil
+}
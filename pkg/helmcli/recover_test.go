@@ -0,0 +1,127 @@
+package helmcli
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func fakeRecoverConfiguration(t *testing.T, statuses ...release.Status) *action.Configuration {
+	newStorage := storage.Init(driver.NewMemory())
+	for version, status := range statuses {
+		err := newStorage.Create(
+			release.Mock(
+				&release.MockReleaseOptions{
+					Name:      testReleaseName,
+					Version:   version + 1,
+					Namespace: "default",
+					Status:    status,
+				}))
+		require.NoError(t, err)
+	}
+
+	return &action.Configuration{
+		Releases: newStorage,
+		KubeClient: &kubefake.FailingKubeClient{
+			PrintingKubeClient: kubefake.PrintingKubeClient{
+				Out: ioutil.Discard,
+			},
+		},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log: func(format string, v ...interface{}) {
+			t.Helper()
+			t.Logf(format, v...)
+		},
+	}
+}
+
+func newTestRecoverer(actionConfig *action.Configuration, rollback bool) *recoverer {
+	r := &recoverer{
+		releases:    actionConfig.Releases,
+		envSettings: cli.New(),
+		now:         time.Now,
+	}
+	if rollback {
+		r.rollback = action.NewRollback(actionConfig)
+	}
+	return r
+}
+
+func TestRecoverShouldMarkStuckRevisionFailed(t *testing.T) {
+	config := fakeRecoverConfiguration(t, release.StatusDeployed, release.StatusPendingUpgrade)
+	r := newTestRecoverer(config, false)
+
+	rel, err := r.Recover(context.Background(), testReleaseName)
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, rel.Version)
+	assert.Equal(t, release.StatusFailed, rel.Info.Status)
+	stored, err := config.Releases.Get(testReleaseName, 2)
+	require.NoError(t, err)
+	assert.Equal(t, release.StatusFailed, stored.Info.Status)
+}
+
+func TestRecoverShouldRollBackToLastDeployedRevision(t *testing.T) {
+	config := fakeRecoverConfiguration(t, release.StatusSuperseded, release.StatusDeployed, release.StatusPendingUpgrade)
+	r := newTestRecoverer(config, true)
+
+	rel, err := r.Recover(context.Background(), testReleaseName)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, rel.Version)
+	assert.Equal(t, release.StatusDeployed, rel.Info.Status)
+	assert.Equal(t, "Rollback to 2", rel.Info.Description)
+}
+
+func TestRecoverShouldNotRollBackWithoutDeployedRevision(t *testing.T) {
+	config := fakeRecoverConfiguration(t, release.StatusPendingInstall)
+	r := newTestRecoverer(config, true)
+
+	_, err := r.Recover(context.Background(), testReleaseName)
+
+	assert.True(t, errors.Is(err, ErrNoDeployedRevision))
+	stored, err := config.Releases.Last(testReleaseName)
+	require.NoError(t, err)
+	assert.Equal(t, release.StatusPendingInstall, stored.Info.Status)
+}
+
+func TestRecoverShouldRejectReleasesThatAreNotPending(t *testing.T) {
+	r := newTestRecoverer(fakeRecoverConfiguration(t, release.StatusPendingUpgrade, release.StatusDeployed), false)
+
+	_, err := r.Recover(context.Background(), testReleaseName)
+
+	assert.True(t, errors.Is(err, ErrNotStuck))
+}
+
+func TestRecoverShouldRejectReleasesUpdatedWithinThreshold(t *testing.T) {
+	config := fakeRecoverConfiguration(t, release.StatusDeployed, release.StatusPendingRollback)
+	last, err := config.Releases.Last(testReleaseName)
+	require.NoError(t, err)
+	r := newTestRecoverer(config, false)
+	r.stuckFor = 10 * time.Minute
+	r.now = func() time.Time { return last.Info.LastDeployed.Time.Add(time.Minute) }
+
+	_, err = r.Recover(context.Background(), testReleaseName)
+
+	assert.True(t, errors.Is(err, ErrNotStuck))
+}
+
+func TestRecoverShouldFailForUnknownRelease(t *testing.T) {
+	r := newTestRecoverer(fakeRecoverConfiguration(t, release.StatusPendingUpgrade), false)
+
+	_, err := r.Recover(context.Background(), testReleaseName+"-incorrect")
+
+	assert.True(t, errors.Is(err, driver.ErrReleaseNotFound))
+}
@@ -0,0 +1,117 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gojekfarm/albatross/pkg/secret"
+)
+
+// challenge is a parsed WWW-Authenticate header
+type challenge struct {
+	scheme string
+	params map[string]string
+}
+
+// parseChallenge parses challenges such as Bearer realm="https://auth.example.com/token",service="registry"
+func parseChallenge(header string) challenge {
+	parts := strings.SplitN(strings.TrimSpace(header), " ", 2)
+	c := challenge{scheme: strings.ToLower(parts[0]), params: map[string]string{}}
+	if len(parts) < 2 {
+		return c
+	}
+	for _, param := range splitParams(parts[1]) {
+		kv := strings.SplitN(param, "=", 2)
+		if len(kv) == 2 {
+			c.params[strings.ToLower(strings.TrimSpace(kv[0]))] = strings.Trim(strings.TrimSpace(kv[1]), `"`)
+		}
+	}
+	return c
+}
+
+// splitParams splits on the commas outside of quotes, scopes can contain commas
+func splitParams(s string) []string {
+	var params []string
+	quoted, start := false, 0
+	for i, r := range s {
+		switch {
+		case r == '"':
+			quoted = !quoted
+		case r == ',' && !quoted:
+			params = append(params, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(params, s[start:])
+}
+
+// authorization answers the challenge of a registry with the Authorization header of the retried request
+func (c *Client) authorization(ctx context.Context, ch challenge, creds *secret.Credentials) (string, error) {
+	switch ch.scheme {
+	case "basic":
+		if creds == nil {
+			return "", fmt.Errorf("registry requires credentials, log in first")
+		}
+		req, _ := http.NewRequest(http.MethodGet, "/", nil)
+		req.SetBasicAuth(creds.Username, creds.Password)
+		return req.Header.Get("Authorization"), nil
+	case "bearer":
+		token, err := c.token(ctx, ch, creds)
+		if err != nil {
+			return "", err
+		}
+		return "Bearer " + token, nil
+	default:
+		return "", fmt.Errorf("unsupported registry authentication scheme %q", ch.scheme)
+	}
+}
+
+// token requests a bearer token from the realm of the challenge, anonymously when there are no credentials
+func (c *Client) token(ctx context.Context, ch challenge, creds *secret.Credentials) (string, error) {
+	realm, err := url.Parse(ch.params["realm"])
+	if err != nil || realm.Host == "" {
+		return "", fmt.Errorf("invalid registry token realm %q", ch.params["realm"])
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if v := ch.params[key]; v != "" {
+			query.Set(key, v)
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	req = req.WithContext(ctx)
+	if creds != nil {
+		req.SetBasicAuth(creds.Username, creds.Password)
+	}
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := ioutil.ReadAll(resp.Body)
+		return "", fmt.Errorf("%w: token request failed with %s: %s", ErrUnauthorized, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var body struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", err
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
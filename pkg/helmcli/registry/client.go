@@ -0,0 +1,288 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Masterminds/semver/v3"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+
+	"github.com/gojekfarm/albatross/pkg/secret"
+)
+
+const (
+	// ChartLayerMediaType is the media type of the chart archive layer
+	ChartLayerMediaType = "application/vnd.cncf.helm.chart.content.v1.tar+gzip"
+	// legacyChartLayerMediaType is pushed by helm 3.0 to 3.6
+	legacyChartLayerMediaType = "application/tar+gzip"
+	manifestMediaType         = "application/vnd.oci.image.manifest.v1+json"
+
+	credentialsPrefix = "registry-"
+	// charts are small, a larger blob is not a chart
+	maxChartSize = 20 << 20
+	timeout      = 2 * time.Minute
+)
+
+var (
+	// ErrNoSecretStore is returned on login when no secret store is configured
+	ErrNoSecretStore = errors.New("registry credentials require a secret store")
+	// ErrUnauthorized is returned when the registry rejects the credentials
+	ErrUnauthorized = errors.New("registry authentication failed")
+	// ErrNotFound is returned for charts or versions missing in the registry
+	ErrNotFound = errors.New("chart not found in registry")
+)
+
+// Client pulls charts from OCI registries with the credentials stored by Login
+type Client struct {
+	http    *http.Client
+	secrets secret.Store
+}
+
+// NewClient returns a client keeping registry credentials in secrets,
+// without a store charts are pulled anonymously.
+func NewClient(secrets secret.Store) *Client {
+	return newClient(secrets, &http.Client{Timeout: timeout})
+}
+
+func newClient(secrets secret.Store, httpClient *http.Client) *Client {
+	return &Client{http: httpClient, secrets: secrets}
+}
+
+// CredentialsName is the name the credentials of host are stored under
+func CredentialsName(host string) string {
+	return credentialsPrefix + strings.Replace(strings.ToLower(host), ":", "-", -1)
+}
+
+// Login checks the credentials against host and stores them
+func (c *Client) Login(ctx context.Context, host string, creds secret.Credentials) error {
+	if c.secrets == nil {
+		return ErrNoSecretStore
+	}
+	name := CredentialsName(host)
+	if err := secret.ValidateName(name); err != nil {
+		return err
+	}
+	resp, err := c.do(ctx, http.MethodGet, "https://"+host+"/v2/", "", &creds)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: %s answered %s", ErrUnauthorized, host, resp.Status)
+	}
+	return c.secrets.Put(ctx, name, creds)
+}
+
+// Logout removes the stored credentials of host
+func (c *Client) Logout(ctx context.Context, host string) error {
+	if c.secrets == nil {
+		return ErrNoSecretStore
+	}
+	return c.secrets.Delete(ctx, CredentialsName(host))
+}
+
+// Load pulls and loads the chart of an oci:// reference.
+// A reference without a tag is pulled at the highest version matching the version constraint,
+// the latest stable one when the constraint is empty.
+func (c *Client) Load(ctx context.Context, chartName, version string) (*chart.Chart, error) {
+	ref, err := ParseReference(chartName)
+	if err != nil {
+		return nil, err
+	}
+	creds, err := c.credentials(ctx, ref.Host)
+	if err != nil {
+		return nil, err
+	}
+	if ref.Tag == "" {
+		if ref.Tag, err = c.resolveTag(ctx, ref, version, creds); err != nil {
+			return nil, err
+		}
+	} else if version != "" && version != ref.Tag {
+		return nil, fmt.Errorf("chart reference %s conflicts with version %s", ref, version)
+	}
+
+	layer, err := c.chartLayer(ctx, ref, creds)
+	if err != nil {
+		return nil, err
+	}
+	archive, err := c.blob(ctx, ref, layer, creds)
+	if err != nil {
+		return nil, err
+	}
+	return loader.LoadArchive(bytes.NewReader(archive))
+}
+
+func (c *Client) credentials(ctx context.Context, host string) (*secret.Credentials, error) {
+	if c.secrets == nil {
+		return nil, nil
+	}
+	creds, err := c.secrets.Get(ctx, CredentialsName(host))
+	if errors.Is(err, secret.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &creds, nil
+}
+
+// resolveTag picks the highest tag matching the version constraint, tags are chart versions
+// with + replaced by _ as + is not allowed in tags.
+func (c *Client) resolveTag(ctx context.Context, ref Reference, version string, creds *secret.Credentials) (string, error) {
+	if version == "" {
+		// like helm, an empty constraint excludes prereleases
+		version = ">0.0.0"
+	}
+	if v, err := semver.StrictNewVersion(version); err == nil {
+		return tag(v), nil
+	}
+	constraint, err := semver.NewConstraint(version)
+	if err != nil {
+		return "", fmt.Errorf("invalid version constraint %q: %w", version, err)
+	}
+
+	var tags struct {
+		Tags []string `json:"tags"`
+	}
+	if err := c.getJSON(ctx, ref, "/tags/list", "application/json", creds, &tags); err != nil {
+		return "", err
+	}
+	var versions []*semver.Version
+	for _, t := range tags.Tags {
+		v, err := semver.NewVersion(strings.Replace(t, "_", "+", -1))
+		if err == nil && constraint.Check(v) {
+			versions = append(versions, v)
+		}
+	}
+	if len(versions) == 0 {
+		return "", fmt.Errorf("%w: no version of %s matches %s", ErrNotFound, ref, version)
+	}
+	sort.Sort(semver.Collection(versions))
+	return tag(versions[len(versions)-1]), nil
+}
+
+func tag(v *semver.Version) string {
+	return strings.Replace(v.Original(), "+", "_", -1)
+}
+
+type descriptor struct {
+	MediaType string `json:"mediaType"`
+	Digest    string `json:"digest"`
+	Size      int64  `json:"size"`
+}
+
+func (c *Client) chartLayer(ctx context.Context, ref Reference, creds *secret.Credentials) (descriptor, error) {
+	var manifest struct {
+		Layers []descriptor `json:"layers"`
+	}
+	if err := c.getJSON(ctx, ref, "/manifests/"+ref.Tag, manifestMediaType, creds, &manifest); err != nil {
+		return descriptor{}, err
+	}
+	for _, layer := range manifest.Layers {
+		if layer.MediaType == ChartLayerMediaType || layer.MediaType == legacyChartLayerMediaType {
+			return layer, nil
+		}
+	}
+	return descriptor{}, fmt.Errorf("%s is not a helm chart, its manifest has no chart layer", ref)
+}
+
+// blob downloads the layer and checks it against its digest
+func (c *Client) blob(ctx context.Context, ref Reference, layer descriptor, creds *secret.Credentials) ([]byte, error) {
+	if !strings.HasPrefix(layer.Digest, "sha256:") {
+		return nil, fmt.Errorf("unsupported digest %q of %s", layer.Digest, ref)
+	}
+	if layer.Size > maxChartSize {
+		return nil, fmt.Errorf("chart layer of %s is larger than %d bytes", ref, maxChartSize)
+	}
+	resp, err := c.get(ctx, ref, "/blobs/"+layer.Digest, "", creds)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxChartSize+1))
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256.Sum256(b)
+	if "sha256:"+hex.EncodeToString(sum[:]) != layer.Digest {
+		return nil, fmt.Errorf("chart layer of %s does not match its digest %s", ref, layer.Digest)
+	}
+	return b, nil
+}
+
+func (c *Client) getJSON(ctx context.Context, ref Reference, path, accept string, creds *secret.Credentials, v interface{}) error {
+	resp, err := c.get(ctx, ref, path, accept, creds)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// get requests path of the repository of ref, the response has status 200
+func (c *Client) get(ctx context.Context, ref Reference, path, accept string, creds *secret.Credentials) (*http.Response, error) {
+	url := "https://" + ref.Host + "/v2/" + ref.Repository + path
+	resp, err := c.do(ctx, http.MethodGet, url, accept, creds)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, ref)
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, fmt.Errorf("%w: %s answered %s", ErrUnauthorized, ref.Host, resp.Status)
+	default:
+		return nil, fmt.Errorf("error pulling %s: %s: %s", ref, resp.Status, strings.TrimSpace(string(body)))
+	}
+}
+
+// do sends the request and answers an authentication challenge of the registry once
+func (c *Client) do(ctx context.Context, method, url, accept string, creds *secret.Credentials) (*http.Response, error) {
+	newRequest := func() (*http.Request, error) {
+		req, err := http.NewRequest(method, url, nil)
+		if err != nil {
+			return nil, err
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		return req.WithContext(ctx), nil
+	}
+
+	req, err := newRequest()
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.http.Do(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+	resp.Body.Close()
+
+	authorization, err := c.authorization(ctx, parseChallenge(resp.Header.Get("WWW-Authenticate")), creds)
+	if err != nil {
+		return nil, err
+	}
+	if req, err = newRequest(); err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", authorization)
+	return c.http.Do(req)
+}
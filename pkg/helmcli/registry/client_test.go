@@ -0,0 +1,221 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+
+	"github.com/gojekfarm/albatross/pkg/secret"
+)
+
+type memorySecrets map[string]secret.Credentials
+
+func (m memorySecrets) Get(_ context.Context, name string) (secret.Credentials, error) {
+	c, ok := m[name]
+	if !ok {
+		return secret.Credentials{}, secret.ErrNotFound
+	}
+	return c, nil
+}
+
+func (m memorySecrets) Put(_ context.Context, name string, c secret.Credentials) error {
+	m[name] = c
+	return nil
+}
+
+func (m memorySecrets) Delete(_ context.Context, name string) error {
+	if _, ok := m[name]; !ok {
+		return secret.ErrNotFound
+	}
+	delete(m, name)
+	return nil
+}
+
+var registryCredentials = secret.Credentials{Username: "robot", Password: "s3cr3t"}
+
+// fakeRegistry serves the charts/mysql repository behind a token service accepting registryCredentials
+type fakeRegistry struct {
+	*httptest.Server
+	blobs map[string][]byte
+	tags  map[string]string
+}
+
+func newFakeRegistry(t *testing.T, versions ...string) *fakeRegistry {
+	r := &fakeRegistry{blobs: map[string][]byte{}, tags: map[string]string{}}
+	dir, err := ioutil.TempDir("", "charts")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	for _, v := range versions {
+		ch := &chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "mysql", Version: v}}
+		path, err := chartutil.Save(ch, dir)
+		require.NoError(t, err)
+		b, err := ioutil.ReadFile(path)
+		require.NoError(t, err)
+		sum := sha256.Sum256(b)
+		digest := "sha256:" + hex.EncodeToString(sum[:])
+		r.blobs[digest] = b
+		r.tags[strings.Replace(v, "+", "_", -1)] = digest
+	}
+	r.Server = httptest.NewTLSServer(http.HandlerFunc(r.serve))
+	return r
+}
+
+func (r *fakeRegistry) host() string {
+	return strings.TrimPrefix(r.URL, "https://")
+}
+
+func (r *fakeRegistry) client(secrets secret.Store) *Client {
+	return newClient(secrets, r.Client())
+}
+
+func (r *fakeRegistry) serve(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path == "/token" {
+		username, password, _ := req.BasicAuth()
+		if username != registryCredentials.Username || password != registryCredentials.Password {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(map[string]string{"token": "t0k3n"})
+		return
+	}
+	if req.Header.Get("Authorization") != "Bearer t0k3n" {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake",scope="repository:charts/mysql:pull"`, r.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	path := req.URL.Path
+	switch {
+	case path == "/v2/":
+	case path == "/v2/charts/mysql/tags/list":
+		var tags []string
+		for t := range r.tags {
+			tags = append(tags, t)
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"name": "charts/mysql", "tags": tags})
+	case strings.HasPrefix(path, "/v2/charts/mysql/manifests/"):
+		digest, ok := r.tags[strings.TrimPrefix(path, "/v2/charts/mysql/manifests/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", manifestMediaType)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"schemaVersion": 2,
+			"layers":        []descriptor{{MediaType: ChartLayerMediaType, Digest: digest, Size: int64(len(r.blobs[digest]))}},
+		})
+	case strings.HasPrefix(path, "/v2/charts/mysql/blobs/"):
+		b, ok := r.blobs[strings.TrimPrefix(path, "/v2/charts/mysql/blobs/")]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write(b)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestParseReference(t *testing.T) {
+	ref, err := ParseReference("oci://registry.example.com:5000/charts/mysql:1.6.9")
+	require.NoError(t, err)
+	assert.Equal(t, Reference{Host: "registry.example.com:5000", Repository: "charts/mysql", Tag: "1.6.9"}, ref)
+
+	ref, err = ParseReference("oci://registry.example.com/charts/mysql")
+	require.NoError(t, err)
+	assert.Equal(t, "", ref.Tag)
+
+	for _, name := range []string{"stable/mysql", "oci://registry.example.com", "oci:///mysql", "oci://registry.example.com/Charts/mysql"} {
+		_, err := ParseReference(name)
+		assert.Error(t, err, name)
+	}
+}
+
+func TestCredentialsName(t *testing.T) {
+	assert.Equal(t, "registry-registry.example.com-5000", CredentialsName("Registry.example.com:5000"))
+}
+
+func TestLoginStoresVerifiedCredentials(t *testing.T) {
+	registry := newFakeRegistry(t)
+	defer registry.Close()
+	secrets := memorySecrets{}
+	client := registry.client(secrets)
+
+	err := client.Login(context.Background(), registry.host(), secret.Credentials{Username: "robot", Password: "wrong"})
+	assert.True(t, errors.Is(err, ErrUnauthorized), err)
+	assert.Empty(t, secrets)
+
+	require.NoError(t, client.Login(context.Background(), registry.host(), registryCredentials))
+	assert.Equal(t, registryCredentials, secrets[CredentialsName(registry.host())])
+
+	require.NoError(t, client.Logout(context.Background(), registry.host()))
+	assert.Empty(t, secrets)
+	assert.Equal(t, secret.ErrNotFound, client.Logout(context.Background(), registry.host()))
+}
+
+func TestLoginRequiresSecretStore(t *testing.T) {
+	client := NewClient(nil)
+
+	assert.Equal(t, ErrNoSecretStore, client.Login(context.Background(), "registry.example.com", registryCredentials))
+	assert.Equal(t, ErrNoSecretStore, client.Logout(context.Background(), "registry.example.com"))
+}
+
+func TestLoadPullsChartWithStoredCredentials(t *testing.T) {
+	registry := newFakeRegistry(t, "1.5.0", "1.6.9", "1.7.0-rc.1")
+	defer registry.Close()
+	client := registry.client(memorySecrets{CredentialsName(registry.host()): registryCredentials})
+	name := "oci://" + registry.host() + "/charts/mysql"
+
+	for _, tc := range []struct{ chartName, version, expected string }{
+		{name + ":1.5.0", "", "1.5.0"},
+		{name, "1.5.0", "1.5.0"},
+		{name, "", "1.6.9"},
+		{name, "~1.5", "1.5.0"},
+		{name, ">1.6.9-0", "1.7.0-rc.1"},
+	} {
+		ch, err := client.Load(context.Background(), tc.chartName, tc.version)
+		require.NoError(t, err, tc)
+		assert.Equal(t, "mysql", ch.Name())
+		assert.Equal(t, tc.expected, ch.Metadata.Version, tc)
+	}
+}
+
+func TestLoadFailures(t *testing.T) {
+	registry := newFakeRegistry(t, "1.6.9")
+	defer registry.Close()
+	name := "oci://" + registry.host() + "/charts/mysql"
+
+	_, err := registry.client(nil).Load(context.Background(), name+":1.6.9", "")
+	assert.True(t, errors.Is(err, ErrUnauthorized), err)
+
+	client := registry.client(memorySecrets{CredentialsName(registry.host()): registryCredentials})
+	_, err = client.Load(context.Background(), name+":1.0.0", "")
+	assert.True(t, errors.Is(err, ErrNotFound), err)
+
+	_, err = client.Load(context.Background(), name, "~2.0")
+	assert.True(t, errors.Is(err, ErrNotFound), err)
+
+	_, err = client.Load(context.Background(), name+":1.6.9", "1.5.0")
+	assert.EqualError(t, err, "chart reference "+name+":1.6.9 conflicts with version 1.5.0")
+
+	for digest, b := range registry.blobs {
+		registry.blobs[digest] = bytes.ToUpper(b)
+	}
+	_, err = client.Load(context.Background(), name+":1.6.9", "")
+	assert.Contains(t, err.Error(), "does not match its digest")
+}
@@ -0,0 +1,52 @@
+package registry
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Scheme prefixes chart references of OCI registries
+const Scheme = "oci://"
+
+var repositoryPattern = regexp.MustCompile(`^[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*(?:/[a-z0-9]+(?:(?:[._]|__|[-]*)[a-z0-9]+)*)*$`)
+
+// Reference is a chart in an OCI registry, oci://host/repository:tag
+type Reference struct {
+	Host       string
+	Repository string
+	// Tag is empty when the reference does not name a version
+	Tag string
+}
+
+// IsOCI reports whether chartName is a reference to a chart in an OCI registry
+func IsOCI(chartName string) bool {
+	return strings.HasPrefix(chartName, Scheme)
+}
+
+// ParseReference parses an oci:// chart reference
+func ParseReference(chartName string) (Reference, error) {
+	if !IsOCI(chartName) {
+		return Reference{}, fmt.Errorf("chart reference %s must start with %s", chartName, Scheme)
+	}
+	parts := strings.SplitN(strings.TrimPrefix(chartName, Scheme), "/", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return Reference{}, fmt.Errorf("chart reference %s must be %shost/repository[:tag]", chartName, Scheme)
+	}
+	ref := Reference{Host: parts[0], Repository: parts[1]}
+	if i := strings.LastIndex(ref.Repository, ":"); i >= 0 {
+		ref.Repository, ref.Tag = ref.Repository[:i], ref.Repository[i+1:]
+	}
+	if !repositoryPattern.MatchString(ref.Repository) {
+		return Reference{}, fmt.Errorf("invalid repository %q in chart reference %s", ref.Repository, chartName)
+	}
+	return ref, nil
+}
+
+func (r Reference) String() string {
+	s := Scheme + r.Host + "/" + r.Repository
+	if r.Tag != "" {
+		s += ":" + r.Tag
+	}
+	return s
+}
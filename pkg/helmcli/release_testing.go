@@ -0,0 +1,156 @@
+package helmcli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+const podLogsHeader = "POD LOGS: %s\n"
+
+type tester struct {
+	action      *action.ReleaseTesting
+	envSettings *cli.EnvSettings
+	kubeClient  tracing.Interval
+}
+
+// Test runs the test hooks of the release and returns the release with the results of the executed hooks.
+func (t *tester) Test(ctx context.Context, releaseName string) (*release.Release, error) {
+	_, span := startAction(ctx, "test", t.kubeClient)
+	rel, err := t.action.Run(releaseName)
+	tracing.End(span, err)
+	return rel, err
+}
+
+// Logs returns the logs of the pod created by a test hook.
+func (t *tester) Logs(ctx context.Context, hook *release.Hook) (string, error) {
+	var out bytes.Buffer
+	if err := t.action.GetPodLogs(&out, &release.Release{Hooks: []*release.Hook{hook}}); err != nil {
+		return "", err
+	}
+	logs := strings.TrimPrefix(out.String(), fmt.Sprintf(podLogsHeader, hook.Name))
+	return strings.TrimSuffix(logs, "\n"), nil
+}
+
+// testHookFilter selects the test hooks to run by name.
+// The release testing action of helm 3.2 runs every test hook, so the filter is applied
+// by hiding the skipped hooks from the action while it reads the release from storage.
+type testHookFilter struct {
+	include map[string]bool
+	exclude map[string]bool
+}
+
+func newTestHookFilter(filters []string) (testHookFilter, error) {
+	filter := testHookFilter{include: map[string]bool{}, exclude: map[string]bool{}}
+	for _, f := range filters {
+		switch {
+		case strings.HasPrefix(f, "!name="):
+			filter.exclude[strings.TrimPrefix(f, "!name=")] = true
+		case strings.HasPrefix(f, "name="):
+			filter.include[strings.TrimPrefix(f, "name=")] = true
+		default:
+			return testHookFilter{}, fmt.Errorf("invalid test filter %q, expected name=<hook> or !name=<hook>", f)
+		}
+	}
+	return filter, nil
+}
+
+func (f testHookFilter) empty() bool {
+	return len(f.include) == 0 && len(f.exclude) == 0
+}
+
+func (f testHookFilter) skip(hook *release.Hook) bool {
+	isTest := false
+	for _, e := range hook.Events {
+		if e == release.HookTest {
+			isTest = true
+		}
+	}
+	if !isTest {
+		return false
+	}
+	if f.exclude[hook.Name] {
+		return true
+	}
+	return len(f.include) > 0 && !f.include[hook.Name]
+}
+
+func (f testHookFilter) apply(cfg *action.Configuration) {
+	if f.empty() || cfg.Releases == nil {
+		return
+	}
+	cfg.Releases.Driver = &testHookFilterDriver{
+		Driver:  cfg.Releases.Driver,
+		filter:  f,
+		skipped: map[string][]*release.Hook{},
+	}
+}
+
+// testHookFilterDriver hands out releases without the skipped test hooks
+// and puts them back when the release is written to storage again.
+type testHookFilterDriver struct {
+	driver.Driver
+	filter  testHookFilter
+	skipped map[string][]*release.Hook
+}
+
+func (d *testHookFilterDriver) Get(key string) (*release.Release, error) {
+	rel, err := d.Driver.Get(key)
+	if err != nil {
+		return rel, err
+	}
+	return d.hide(key, rel), nil
+}
+
+func (d *testHookFilterDriver) Query(labels map[string]string) ([]*release.Release, error) {
+	releases, err := d.Driver.Query(labels)
+	if err != nil {
+		return releases, err
+	}
+	filtered := make([]*release.Release, 0, len(releases))
+	for _, rel := range releases {
+		filtered = append(filtered, d.hide(releaseKey(rel), rel))
+	}
+	return filtered, nil
+}
+
+func (d *testHookFilterDriver) Update(key string, rel *release.Release) error {
+	skipped, ok := d.skipped[key]
+	if !ok {
+		return d.Driver.Update(key, rel)
+	}
+	restored := *rel
+	restored.Hooks = append(append([]*release.Hook{}, rel.Hooks...), skipped...)
+	return d.Driver.Update(key, &restored)
+}
+
+func (d *testHookFilterDriver) hide(key string, rel *release.Release) *release.Release {
+	var kept, skipped []*release.Hook
+	for _, h := range rel.Hooks {
+		if d.filter.skip(h) {
+			skipped = append(skipped, h)
+			continue
+		}
+		kept = append(kept, h)
+	}
+	if len(skipped) == 0 {
+		return rel
+	}
+	d.skipped[key] = skipped
+	filtered := *rel
+	filtered.Hooks = kept
+	return &filtered
+}
+
+// releaseKey builds the storage key the same way helm's storage does.
+func releaseKey(rel *release.Release) string {
+	return fmt.Sprintf("sh.helm.release.v1.%s.v%d", rel.Name, rel.Version)
+}
@@ -0,0 +1,116 @@
+package helmcli
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func fakeTestConfiguration(t *testing.T) *action.Configuration {
+	rel := release.Mock(&release.MockReleaseOptions{
+		Name:      testReleaseName,
+		Version:   1,
+		Namespace: "default",
+		Status:    release.StatusDeployed,
+	})
+	rel.Hooks = []*release.Hook{
+		{Name: "first-test", Kind: "Pod", Path: "tests/first.yaml", Events: []release.HookEvent{release.HookTest}},
+		{Name: "second-test", Kind: "Pod", Path: "tests/second.yaml", Events: []release.HookEvent{release.HookTest}},
+		{Name: "pre-install-hook", Kind: "Job", Path: "hooks/pre-install.yaml", Events: []release.HookEvent{release.HookPreInstall}},
+	}
+	newStorage := storage.Init(driver.NewMemory())
+	require.NoError(t, newStorage.Create(rel))
+
+	return &action.Configuration{
+		Releases: newStorage,
+		KubeClient: &kubefake.FailingKubeClient{
+			PrintingKubeClient: kubefake.PrintingKubeClient{
+				Out: ioutil.Discard,
+			},
+		},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log: func(format string, v ...interface{}) {
+			t.Helper()
+			t.Logf(format, v...)
+		},
+	}
+}
+
+func newTestTester(t *testing.T, actionConfig *action.Configuration, filters ...string) *tester {
+	filter, err := newTestHookFilter(filters)
+	require.NoError(t, err)
+	filter.apply(actionConfig)
+	return &tester{
+		action:      action.NewReleaseTesting(actionConfig),
+		envSettings: cli.New(),
+	}
+}
+
+func hookPhases(hooks []*release.Hook) map[string]release.HookPhase {
+	phases := map[string]release.HookPhase{}
+	for _, h := range hooks {
+		phases[h.Name] = h.LastRun.Phase
+	}
+	return phases
+}
+
+func TestTestShouldFailForInvalidRelease(t *testing.T) {
+	tc := newTestTester(t, fakeTestConfiguration(t))
+
+	_, err := tc.Test(context.Background(), "invalid-release")
+
+	assert.EqualError(t, err, "release: not found")
+}
+
+func TestTestShouldRunAllTestHooks(t *testing.T) {
+	tc := newTestTester(t, fakeTestConfiguration(t))
+
+	rel, err := tc.Test(context.Background(), testReleaseName)
+
+	require.NoError(t, err)
+	phases := hookPhases(rel.Hooks)
+	assert.Equal(t, release.HookPhaseSucceeded, phases["first-test"])
+	assert.Equal(t, release.HookPhaseSucceeded, phases["second-test"])
+	assert.Empty(t, phases["pre-install-hook"])
+}
+
+func TestTestShouldOnlyRunIncludedHooks(t *testing.T) {
+	cfg := fakeTestConfiguration(t)
+	tc := newTestTester(t, cfg, "name=second-test")
+
+	rel, err := tc.Test(context.Background(), testReleaseName)
+
+	require.NoError(t, err)
+	phases := hookPhases(rel.Hooks)
+	assert.NotContains(t, phases, "first-test")
+	assert.Equal(t, release.HookPhaseSucceeded, phases["second-test"])
+
+	stored, err := cfg.Releases.Driver.(*testHookFilterDriver).Driver.Get(releaseKey(rel))
+	require.NoError(t, err)
+	assert.Len(t, stored.Hooks, 3)
+	storedPhases := hookPhases(stored.Hooks)
+	assert.Empty(t, storedPhases["first-test"])
+	assert.Equal(t, release.HookPhaseSucceeded, storedPhases["second-test"])
+}
+
+func TestTestShouldSkipExcludedHooks(t *testing.T) {
+	tc := newTestTester(t, fakeTestConfiguration(t), "!name=second-test")
+
+	rel, err := tc.Test(context.Background(), testReleaseName)
+
+	require.NoError(t, err)
+	phases := hookPhases(rel.Hooks)
+	assert.Equal(t, release.HookPhaseSucceeded, phases["first-test"])
+	assert.NotContains(t, phases, "second-test")
+	assert.Contains(t, phases, "pre-install-hook")
+}
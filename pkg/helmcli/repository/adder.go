@@ -2,18 +2,13 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"os"
-	"path/filepath"
-	"strings"
 	"time"
 
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
-	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/secret"
 
-	"github.com/gofrs/flock"
-	"gopkg.in/yaml.v2"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/getter"
 	"helm.sh/helm/v3/pkg/repo"
@@ -24,6 +19,7 @@ const timeout time.Duration = 30 * time.Second
 type adder struct {
 	flags.AddFlags
 	settings *cli.EnvSettings
+	secrets  secret.Store
 }
 
 func (o *adder) Add(ctx context.Context) (*repo.Entry, error) {
@@ -31,63 +27,112 @@ func (o *adder) Add(ctx context.Context) (*repo.Entry, error) {
 	if err != nil {
 		return nil, err
 	}
-
-	// Acquire a file lock for process synchronization
-	fileLock := flock.New(strings.Replace(o.RepoFile, filepath.Ext(o.RepoFile), ".lock", 1))
-	lockCtx, cancel := context.WithTimeout(ctx, timeout)
-	defer cancel()
-	locked, err := fileLock.TryLockContext(lockCtx, time.Second)
-	if err == nil && locked {
-		defer checkFileUnlock(fileLock.Unlock)
+	ref, credentials, err := o.credentials(ctx)
+	if err != nil {
+		return nil, err
 	}
+
+	unlock, err := lockRepoFile(ctx, o.RepoFile)
 	if err != nil {
 		return nil, err
 	}
+	defer unlock()
 
+	// the credentials are kept in the secret store, the repositories file only has the entry
 	c := repo.Entry{
 		Name:                  o.Name,
 		URL:                   o.URL,
-		Username:              o.Username,
-		Password:              o.Password,
 		CertFile:              o.CertFile,
 		KeyFile:               o.KeyFile,
 		CAFile:                o.CaFile,
 		InsecureSkipTLSverify: o.InsecureSkipTLSverify,
 	}
 
-	f, err := o.updateRepoEntryInFile(c)
+	refs, err := readRefs(o.RepoFile)
+	if err != nil {
+		return nil, err
+	}
+	credentialsChanged := refs.Repositories[o.Name] != ref
+	if !credentialsChanged && o.hasInlineCredentials() {
+		existing, err := o.secrets.Get(ctx, ref)
+		credentialsChanged = err != nil || existing != credentials
+	}
+	f, err := o.updateRepoEntryInFile(c, credentials, credentialsChanged)
 	if err != nil {
 		return nil, err
 	}
 
+	if o.hasInlineCredentials() {
+		if err := o.secrets.Put(ctx, ref, credentials); err != nil {
+			return nil, err
+		}
+	}
 	if err := f.WriteFile(o.RepoFile, 0644); err != nil {
 		return nil, err
 	}
+	if ref == "" {
+		delete(refs.Repositories, o.Name)
+	} else {
+		refs.Repositories[o.Name] = ref
+	}
+	if err := writeRefs(o.RepoFile, refs); err != nil {
+		return nil, err
+	}
 
 	return &c, nil
 }
 
-func (o *adder) checkPrerequisite() error {
-	// Ensure the file directory exists as it is required for file locking
-	err := os.MkdirAll(filepath.Dir(o.RepoFile), os.ModePerm)
-	if err != nil && !os.IsExist(err) {
-		return err
+// credentials returns the name and value of the credentials of the repository,
+// inline credentials are stored under the repository name and referenced credentials must exist.
+func (o *adder) credentials(ctx context.Context) (string, secret.Credentials, error) {
+	inline := o.hasInlineCredentials()
+	if !inline && o.CredentialsRef == "" {
+		return "", secret.Credentials{}, nil
 	}
-	return nil
+	if inline && o.CredentialsRef != "" {
+		return "", secret.Credentials{}, errors.New("credentials_ref cannot be used with username and password")
+	}
+	if o.secrets == nil {
+		return "", secret.Credentials{}, ErrNoSecretStore
+	}
+
+	if inline {
+		if err := secret.ValidateName(o.Name); err != nil {
+			return "", secret.Credentials{}, fmt.Errorf("repository name cannot be used for its credentials, use credentials_ref: %w", err)
+		}
+		return o.Name, secret.Credentials{Username: o.Username, Password: o.Password}, nil
+	}
+	c, err := o.secrets.Get(ctx, o.CredentialsRef)
+	if err != nil {
+		return "", secret.Credentials{}, fmt.Errorf("error reading credentials %s: %w", o.CredentialsRef, err)
+	}
+	return o.CredentialsRef, c, nil
+}
+
+func (o *adder) hasInlineCredentials() bool {
+	return o.Username != "" || o.Password != ""
+}
+
+func (o *adder) checkPrerequisite() error {
+	return ensureRepoDir(o.RepoFile)
 }
 
-func (o *adder) updateRepoEntryInFile(c repo.Entry) (*repo.File, error) {
+func (o *adder) updateRepoEntryInFile(c repo.Entry, credentials secret.Credentials, credentialsChanged bool) (*repo.File, error) {
 	f, err := o.initialiseRepoFile()
 	if err != nil {
 		return nil, err
 	}
 
-	err = o.validateRepoFile(f, c)
+	err = o.validateRepoFile(f, c, credentialsChanged)
 	if err != nil {
 		return nil, err
 	}
 
-	err = o.initialiseChartsFromRepository(c)
+	// the index is downloaded with the credentials, which are not part of the saved entry
+	withCredentials := c
+	withCredentials.Username = credentials.Username
+	withCredentials.Password = credentials.Password
+	err = o.initialiseChartsFromRepository(withCredentials)
 	if err != nil {
 		return nil, err
 	}
@@ -97,17 +142,7 @@ func (o *adder) updateRepoEntryInFile(c repo.Entry) (*repo.File, error) {
 }
 
 func (o *adder) initialiseRepoFile() (*repo.File, error) {
-	b, err := ioutil.ReadFile(o.RepoFile)
-	if err != nil && !os.IsNotExist(err) {
-		return nil, err
-	}
-
-	var f repo.File
-	if err := yaml.Unmarshal(b, &f); err != nil {
-		return nil, err
-	}
-
-	return &f, nil
+	return readRepoFile(o.RepoFile)
 }
 
 func (o *adder) initialiseChartsFromRepository(c repo.Entry) error {
@@ -125,13 +160,13 @@ func (o *adder) initialiseChartsFromRepository(c repo.Entry) error {
 	return nil
 }
 
-func (o *adder) validateRepoFile(f *repo.File, c repo.Entry) error {
+func (o *adder) validateRepoFile(f *repo.File, c repo.Entry, credentialsChanged bool) error {
 	// If the repo exists do one of two things:
 	// 1. If the configuration for the name is the same continue without error
 	// 2. When the config is different require --force-update
 	if !o.ForceUpdate && f.Has(o.Name) {
 		existing := f.Get(o.Name)
-		if c != *existing {
+		if c != *existing || credentialsChanged {
 			// The input coming in for the name is different from what is already
 			// configured. Return an error.
 			return fmt.Errorf("repository name (%s) already exists, please use force_update to update or a different name to make a new entry", o.Name)
@@ -140,9 +175,3 @@ func (o *adder) validateRepoFile(f *repo.File, c repo.Entry) error {
 
 	return nil
 }
-
-func checkFileUnlock(f func() error) {
-	if err := f(); err != nil {
-		logger.Errorf("Error while %v", err)
-	}
-}
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/secret"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -35,6 +36,26 @@ func (s *AdderTestSuite) SetupTest() {
 	os.Setenv("HELM_REPOSITORY_CACHE", testCachePath)
 }
 
+type memorySecrets map[string]secret.Credentials
+
+func (m memorySecrets) Get(_ context.Context, name string) (secret.Credentials, error) {
+	c, ok := m[name]
+	if !ok {
+		return secret.Credentials{}, secret.ErrNotFound
+	}
+	return c, nil
+}
+
+func (m memorySecrets) Put(_ context.Context, name string, c secret.Credentials) error {
+	m[name] = c
+	return nil
+}
+
+func (m memorySecrets) Delete(_ context.Context, name string) error {
+	delete(m, name)
+	return nil
+}
+
 func initialiseAdder() *adder {
 	settings := cli.New()
 	adder := &adder{
@@ -45,6 +66,7 @@ func initialiseAdder() *adder {
 			RepoCache: settings.RepositoryCache,
 		},
 		settings: settings,
+		secrets:  memorySecrets{},
 	}
 	return adder
 }
@@ -107,16 +129,20 @@ func (s *AdderTestSuite) TestAddDuplicateRepoForceUpdate() {
 	suiteAssertion.NoError(err)
 	var f repo.File
 	expectedRepo := &repo.Entry{
-		Name:     "influxdata",
-		URL:      "https://helm.influxdata.com/",
-		Username: "abcd",
-		Password: "1234",
+		Name: "influxdata",
+		URL:  "https://helm.influxdata.com/",
 	}
 	suiteAssertion.NoError(yaml.Unmarshal(b, &f))
 	suiteAssertion.Equal(expectedRepo.Name, entry.Name)
 	suiteAssertion.Equal(expectedRepo.URL, entry.URL)
-	suiteAssertion.Equal(expectedRepo.Username, entry.Username)
-	suiteAssertion.Equal(expectedRepo.Password, entry.Password)
+	suiteAssertion.Empty(entry.Password)
+	suiteAssertion.NotContains(string(b), "1234")
+	stored, err := newAdder.secrets.Get(context.Background(), "influxdata")
+	suiteAssertion.NoError(err)
+	suiteAssertion.Equal(secret.Credentials{Username: "abcd", Password: "1234"}, stored)
+	refs, err := readRefs(newAdder.RepoFile)
+	suiteAssertion.NoError(err)
+	suiteAssertion.Equal("influxdata", refs.Repositories["influxdata"])
 	repoFoundCount := 0
 	for _, repo := range f.Repositories {
 		if repo.Name == expectedRepo.Name {
@@ -174,6 +200,7 @@ func (s *AdderTestSuite) TearDownTest() {
 	if err != nil {
 		s.FailNow("Failed to delete config file", err)
 	}
+	os.Remove(refsFile(testConfigPath))
 	err = os.RemoveAll(testCachePath)
 	if err != nil {
 		s.FailNow("Failed to delete cache folder", err)
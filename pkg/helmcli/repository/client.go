@@ -4,6 +4,7 @@ import (
 	"context"
 
 	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/secret"
 
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/repo"
@@ -11,22 +12,80 @@ import (
 
 type Client interface {
 	NewAdder(flags.AddFlags) (Adder, error)
+	NewLister() (Lister, error)
+	NewRemover(flags.RepoRemoveFlags) (Remover, error)
+	NewUpdater(flags.RepoUpdateFlags) (Updater, error)
+	NewSearcher(flags.SearchFlags) (Searcher, error)
 }
 
 type Adder interface {
 	Add(ctx context.Context) (*repo.Entry, error)
 }
 
-type repoClient struct{}
+// Lister reads the configured repositories
+type Lister interface {
+	List(ctx context.Context) ([]Entry, error)
+	Get(ctx context.Context, name string) (Entry, error)
+}
+
+// Remover removes a repository
+type Remover interface {
+	Remove(ctx context.Context) error
+}
+
+// Searcher searches the charts of the cached repository indexes
+type Searcher interface {
+	Search(ctx context.Context) ([]ChartVersion, error)
+}
+
+// Updater refreshes the index of repositories
+type Updater interface {
+	Update(ctx context.Context) ([]UpdateResult, error)
+}
+
+type repoClient struct {
+	secrets secret.Store
+}
 
 func (c repoClient) NewAdder(addFlags flags.AddFlags) (Adder, error) {
 	settings := cli.New()
 	addFlags.RepoCache = settings.RepositoryCache
 	addFlags.RepoFile = settings.RepositoryConfig
-	newAdder := adder{AddFlags: addFlags, settings: settings}
+	newAdder := adder{AddFlags: addFlags, settings: settings, secrets: c.secrets}
 	return &newAdder, nil
 }
 
-func NewClient() Client {
-	return repoClient{}
+// NewLister returns a lister of the repositories file
+func (c repoClient) NewLister() (Lister, error) {
+	return &lister{repoFile: cli.New().RepositoryConfig}, nil
+}
+
+// NewRemover returns a new Remover instance.
+func (c repoClient) NewRemover(removeFlags flags.RepoRemoveFlags) (Remover, error) {
+	settings := cli.New()
+	removeFlags.RepoCache = settings.RepositoryCache
+	removeFlags.RepoFile = settings.RepositoryConfig
+	return &remover{RepoRemoveFlags: removeFlags}, nil
+}
+
+// NewUpdater returns a new Updater instance.
+func (c repoClient) NewUpdater(updateFlags flags.RepoUpdateFlags) (Updater, error) {
+	settings := cli.New()
+	updateFlags.RepoCache = settings.RepositoryCache
+	updateFlags.RepoFile = settings.RepositoryConfig
+	return &updater{RepoUpdateFlags: updateFlags, settings: settings, secrets: c.secrets}, nil
+}
+
+// NewSearcher returns a new Searcher instance.
+func (c repoClient) NewSearcher(searchFlags flags.SearchFlags) (Searcher, error) {
+	settings := cli.New()
+	searchFlags.RepoCache = settings.RepositoryCache
+	searchFlags.RepoFile = settings.RepositoryConfig
+	return &searcher{SearchFlags: searchFlags}, nil
+}
+
+// NewClient returns a client keeping repository credentials in secrets,
+// credentials are rejected when secrets is nil.
+func NewClient(secrets secret.Store) Client {
+	return repoClient{secrets: secrets}
 }
@@ -20,7 +20,7 @@ const (
 )
 
 func (s *RepositoryClientTestSuite) SetupTest() {
-	s.c = NewClient()
+	s.c = NewClient(nil)
 	os.Setenv("HELM_REPOSITORY_CONFIG", configPath)
 	os.Setenv("HELM_REPOSITORY_CACHE", cachePath)
 }
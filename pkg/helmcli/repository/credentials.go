@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/cli"
+
+	"github.com/gojekfarm/albatross/pkg/secret"
+)
+
+// ErrNoSecretStore is returned when credentials are given but no secret store is configured
+var ErrNoSecretStore = errors.New("repository credentials require a secret store")
+
+// credentialRefs maps repository names to the name of their credentials in the secret store.
+// The passwords are never written to the repositories file, only the references are kept beside it.
+type credentialRefs struct {
+	Repositories map[string]string `yaml:"repositories"`
+}
+
+func refsFile(repoFile string) string {
+	ext := filepath.Ext(repoFile)
+	return strings.TrimSuffix(repoFile, ext) + "-credentials" + ext
+}
+
+func readRefs(repoFile string) (*credentialRefs, error) {
+	refs := &credentialRefs{}
+	b, err := ioutil.ReadFile(refsFile(repoFile))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := yaml.Unmarshal(b, refs); err != nil {
+		return nil, err
+	}
+	if refs.Repositories == nil {
+		refs.Repositories = map[string]string{}
+	}
+	return refs, nil
+}
+
+func writeRefs(repoFile string, refs *credentialRefs) error {
+	b, err := yaml.Marshal(refs)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(refsFile(repoFile), b, 0600)
+}
+
+// CredentialsResolver finds the stored credentials of the repository a chart is downloaded from
+type CredentialsResolver struct {
+	secrets secret.Store
+}
+
+// NewCredentialsResolver returns a resolver reading credentials from secrets
+func NewCredentialsResolver(secrets secret.Store) *CredentialsResolver {
+	return &CredentialsResolver{secrets: secrets}
+}
+
+// Resolve returns the credentials for a chart reference of the form repository/chart,
+// ok is false when the repository has no stored credentials.
+func (r *CredentialsResolver) Resolve(ctx context.Context, chartName string) (secret.Credentials, bool, error) {
+	parts := strings.SplitN(chartName, "/", 2)
+	// absolute urls and local paths are not repository references
+	if len(parts) != 2 || strings.Contains(chartName, "://") || strings.HasPrefix(chartName, ".") {
+		return secret.Credentials{}, false, nil
+	}
+
+	return repoCredentials(ctx, r.secrets, cli.New().RepositoryConfig, parts[0])
+}
+
+// repoCredentials returns the stored credentials of the repository name of repoFile
+func repoCredentials(ctx context.Context, secrets secret.Store, repoFile, name string) (secret.Credentials, bool, error) {
+	refs, err := readRefs(repoFile)
+	if err != nil {
+		return secret.Credentials{}, false, err
+	}
+	ref, ok := refs.Repositories[name]
+	if !ok {
+		return secret.Credentials{}, false, nil
+	}
+	if secrets == nil {
+		return secret.Credentials{}, false, ErrNoSecretStore
+	}
+	c, err := secrets.Get(ctx, ref)
+	if err != nil {
+		return secret.Credentials{}, false, fmt.Errorf("error reading credentials %s of repository %s: %w", ref, name, err)
+	}
+	return c, true, nil
+}
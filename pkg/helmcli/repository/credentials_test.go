@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/secret"
+)
+
+func TestAdderCredentials(t *testing.T) {
+	secrets := memorySecrets{"shared": {Username: "ci", Password: "token"}}
+	cases := []struct {
+		name  string
+		flags flags.AddFlags
+		ref   string
+		creds secret.Credentials
+		err   string
+	}{
+		{name: "none", flags: flags.AddFlags{Name: "stable"}},
+		{name: "inline", flags: flags.AddFlags{Name: "private", Username: "admin", Password: "1234"}, ref: "private", creds: secret.Credentials{Username: "admin", Password: "1234"}},
+		{name: "reference", flags: flags.AddFlags{Name: "private", CredentialsRef: "shared"}, ref: "shared", creds: secret.Credentials{Username: "ci", Password: "token"}},
+		{name: "both", flags: flags.AddFlags{Name: "private", Username: "admin", CredentialsRef: "shared"}, err: "credentials_ref cannot be used with username and password"},
+		{name: "missing reference", flags: flags.AddFlags{Name: "private", CredentialsRef: "unknown"}, err: "error reading credentials unknown: credentials not found"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			a := &adder{AddFlags: c.flags, secrets: secrets}
+
+			ref, creds, err := a.credentials(context.Background())
+
+			if c.err != "" {
+				assert.EqualError(t, err, c.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, c.ref, ref)
+			assert.Equal(t, c.creds, creds)
+		})
+	}
+}
+
+func TestAdderRejectsCredentialsWithoutSecretStore(t *testing.T) {
+	a := &adder{AddFlags: flags.AddFlags{Name: "private", Username: "admin", Password: "1234"}}
+
+	_, _, err := a.credentials(context.Background())
+
+	assert.Equal(t, ErrNoSecretStore, err)
+}
+
+func TestCredentialsResolverReadsReferencedCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "repositories")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	repoFile := filepath.Join(dir, "repositories.yaml")
+	os.Setenv("HELM_REPOSITORY_CONFIG", repoFile)
+	defer os.Unsetenv("HELM_REPOSITORY_CONFIG")
+	require.NoError(t, writeRefs(repoFile, &credentialRefs{Repositories: map[string]string{"private": "shared"}}))
+	resolver := NewCredentialsResolver(memorySecrets{"shared": {Username: "ci", Password: "token"}})
+
+	creds, ok, err := resolver.Resolve(context.Background(), "private/mysql")
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, secret.Credentials{Username: "ci", Password: "token"}, creds)
+
+	for _, chart := range []string{"stable/mysql", "https://charts.example.com/mysql-1.0.0.tgz", "./mysql", "mysql"} {
+		_, ok, err := resolver.Resolve(context.Background(), chart)
+		require.NoError(t, err)
+		assert.False(t, ok, chart)
+	}
+}
@@ -0,0 +1,65 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+// ErrNotFound is returned for repositories missing from the repositories file
+var ErrNotFound = errors.New("repository not found")
+
+// ensureRepoDir creates the directory of the repositories file as it is required for file locking
+func ensureRepoDir(repoFile string) error {
+	err := os.MkdirAll(filepath.Dir(repoFile), os.ModePerm)
+	if err != nil && !os.IsExist(err) {
+		return err
+	}
+	return nil
+}
+
+// lockRepoFile acquires the file lock synchronizing every process changing repoFile,
+// the returned function releases it.
+func lockRepoFile(ctx context.Context, repoFile string) (func(), error) {
+	fileLock := flock.New(strings.Replace(repoFile, filepath.Ext(repoFile), ".lock", 1))
+	lockCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	locked, err := fileLock.TryLockContext(lockCtx, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return func() {}, nil
+	}
+	return func() { checkFileUnlock(fileLock.Unlock) }, nil
+}
+
+func readRepoFile(repoFile string) (*repo.File, error) {
+	b, err := ioutil.ReadFile(repoFile)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	var f repo.File
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, err
+	}
+
+	return &f, nil
+}
+
+func checkFileUnlock(f func() error) {
+	if err := f(); err != nil {
+		logger.Errorf("Error while %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// Entry is a configured repository without its credentials
+type Entry struct {
+	repo.Entry
+	// CredentialsRef is the name of the credentials of the repository in the secret store
+	CredentialsRef string
+}
+
+type lister struct {
+	repoFile string
+}
+
+// List returns the configured repositories in the order of the repositories file
+func (l *lister) List(ctx context.Context) ([]Entry, error) {
+	if err := ensureRepoDir(l.repoFile); err != nil {
+		return nil, err
+	}
+	unlock, err := lockRepoFile(ctx, l.repoFile)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	f, err := readRepoFile(l.repoFile)
+	if err != nil {
+		return nil, err
+	}
+	refs, err := readRefs(l.repoFile)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Entry, 0, len(f.Repositories))
+	for _, r := range f.Repositories {
+		e := Entry{Entry: *r, CredentialsRef: refs.Repositories[r.Name]}
+		// entries added before the secret store may still have credentials in the file
+		e.Username, e.Password = "", ""
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// Get returns the repository name
+func (l *lister) Get(ctx context.Context, name string) (Entry, error) {
+	entries, err := l.List(ctx)
+	if err != nil {
+		return Entry{}, err
+	}
+	for _, e := range entries {
+		if e.Name == name {
+			return e, nil
+		}
+	}
+	return Entry{}, ErrNotFound
+}
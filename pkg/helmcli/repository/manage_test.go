@@ -0,0 +1,143 @@
+package repository
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/secret"
+)
+
+const emptyIndex = "apiVersion: v1\nentries: {}\n"
+
+// repoDir returns a repositories file with a repository served by a local index server
+func repoDir(t *testing.T, handler http.HandlerFunc) (string, string, *httptest.Server) {
+	dir, err := ioutil.TempDir("", "repositories")
+	require.NoError(t, err)
+	server := httptest.NewServer(handler)
+	repoFile := filepath.Join(dir, "repositories.yaml")
+	f := repo.NewFile()
+	f.Add(&repo.Entry{Name: "private", URL: server.URL, Username: "legacy", Password: "legacy"}, &repo.Entry{Name: "stable", URL: server.URL})
+	require.NoError(t, f.WriteFile(repoFile, 0644))
+	require.NoError(t, writeRefs(repoFile, &credentialRefs{Repositories: map[string]string{"private": "shared"}}))
+	return dir, repoFile, server
+}
+
+func serveIndex(w http.ResponseWriter, _ *http.Request) {
+	_, _ = w.Write([]byte(emptyIndex))
+}
+
+func TestListerReturnsEntriesWithoutCredentials(t *testing.T) {
+	dir, repoFile, server := repoDir(t, serveIndex)
+	defer os.RemoveAll(dir)
+	defer server.Close()
+	l := &lister{repoFile: repoFile}
+
+	entries, err := l.List(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+	assert.Equal(t, Entry{Entry: repo.Entry{Name: "private", URL: server.URL}, CredentialsRef: "shared"}, entries[0])
+	assert.Equal(t, Entry{Entry: repo.Entry{Name: "stable", URL: server.URL}}, entries[1])
+	_, err = l.Get(context.Background(), "unknown")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestRemoverDeletesEntryAndCachedIndex(t *testing.T) {
+	dir, repoFile, server := repoDir(t, serveIndex)
+	defer os.RemoveAll(dir)
+	defer server.Close()
+	cache := filepath.Join(dir, "cache")
+	require.NoError(t, os.MkdirAll(cache, 0755))
+	index := filepath.Join(cache, "private-index.yaml")
+	require.NoError(t, ioutil.WriteFile(index, []byte(emptyIndex), 0644))
+	r := &remover{RepoRemoveFlags: flags.RepoRemoveFlags{Name: "private", RepoFile: repoFile, RepoCache: cache}}
+
+	require.NoError(t, r.Remove(context.Background()))
+
+	f, err := readRepoFile(repoFile)
+	require.NoError(t, err)
+	assert.False(t, f.Has("private"))
+	assert.True(t, f.Has("stable"))
+	refs, err := readRefs(repoFile)
+	require.NoError(t, err)
+	assert.Empty(t, refs.Repositories)
+	_, err = os.Stat(index)
+	assert.True(t, os.IsNotExist(err))
+	assert.Equal(t, ErrNotFound, r.Remove(context.Background()))
+}
+
+func TestUpdaterDownloadsIndexWithStoredCredentials(t *testing.T) {
+	dir, repoFile, server := repoDir(t, func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "ci" || password != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		serveIndex(w, r)
+	})
+	defer os.RemoveAll(dir)
+	defer server.Close()
+	cache := filepath.Join(dir, "cache")
+	u := &updater{
+		RepoUpdateFlags: flags.RepoUpdateFlags{RepoFile: repoFile, RepoCache: cache},
+		settings:        cli.New(),
+		secrets:         memorySecrets{"shared": secret.Credentials{Username: "ci", Password: "token"}},
+	}
+
+	results, err := u.Update(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, "private", results[0].Name)
+	assert.NoError(t, results[0].Err)
+	assert.FileExists(t, filepath.Join(cache, "private-index.yaml"))
+	assert.Equal(t, "stable", results[1].Name)
+	assert.Error(t, results[1].Err)
+}
+
+func TestUpdaterRejectsUnknownRepositories(t *testing.T) {
+	dir, repoFile, server := repoDir(t, serveIndex)
+	defer os.RemoveAll(dir)
+	defer server.Close()
+	u := &updater{RepoUpdateFlags: flags.RepoUpdateFlags{Names: []string{"unknown"}, RepoFile: repoFile}, settings: cli.New()}
+
+	_, err := u.Update(context.Background())
+
+	assert.EqualError(t, err, "repository not found: unknown")
+}
+
+func TestUpdaterDoesNotLockRepositoriesWhileDownloading(t *testing.T) {
+	var repoFile string
+	var removeErr error
+	dir, repoFile, server := repoDir(t, func(w http.ResponseWriter, r *http.Request) {
+		rm := &remover{RepoRemoveFlags: flags.RepoRemoveFlags{Name: "stable", RepoFile: repoFile, RepoCache: filepath.Join(filepath.Dir(repoFile), "cache")}}
+		ctx, cancel := context.WithTimeout(r.Context(), time.Second)
+		defer cancel()
+		removeErr = rm.Remove(ctx)
+		serveIndex(w, r)
+	})
+	defer os.RemoveAll(dir)
+	defer server.Close()
+	cache := filepath.Join(dir, "cache")
+	u := &updater{RepoUpdateFlags: flags.RepoUpdateFlags{Names: []string{"stable"}, RepoFile: repoFile, RepoCache: cache}, settings: cli.New()}
+
+	results, err := u.Update(context.Background())
+
+	require.NoError(t, err)
+	require.NoError(t, removeErr)
+	require.Len(t, results, 1)
+	assert.EqualError(t, results[0].Err, "repository not found: stable")
+	_, err = os.Stat(filepath.Join(cache, "stable-index.yaml"))
+	assert.True(t, os.IsNotExist(err))
+}
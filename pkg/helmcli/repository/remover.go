@@ -0,0 +1,57 @@
+package repository
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"helm.sh/helm/v3/pkg/helmpath"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+)
+
+type remover struct {
+	flags.RepoRemoveFlags
+}
+
+// Remove removes the repository from the repositories file and deletes its cached index.
+// Stored credentials are kept as other repositories can reference them.
+func (o *remover) Remove(ctx context.Context) error {
+	if err := ensureRepoDir(o.RepoFile); err != nil {
+		return err
+	}
+	unlock, err := lockRepoFile(ctx, o.RepoFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := readRepoFile(o.RepoFile)
+	if err != nil {
+		return err
+	}
+	if !f.Remove(o.Name) {
+		return ErrNotFound
+	}
+	if err := f.WriteFile(o.RepoFile, 0644); err != nil {
+		return err
+	}
+
+	refs, err := readRefs(o.RepoFile)
+	if err != nil {
+		return err
+	}
+	if _, ok := refs.Repositories[o.Name]; ok {
+		delete(refs.Repositories, o.Name)
+		if err := writeRefs(o.RepoFile, refs); err != nil {
+			return err
+		}
+	}
+
+	for _, cached := range []string{helmpath.CacheIndexFile(o.Name), helmpath.CacheChartsFile(o.Name)} {
+		if err := os.Remove(filepath.Join(o.RepoCache, cached)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+// ErrInvalidConstraint is returned for search versions that are not semantic version constraints
+var ErrInvalidConstraint = errors.New("invalid version constraint")
+
+// stableVersions excludes pre-releases, like helm search does without --devel
+const stableVersions = ">0.0.0"
+
+// ChartVersion is a chart version of a repository index
+type ChartVersion struct {
+	// Name is the chart reference, repository/chart
+	Name string
+	*repo.ChartVersion
+}
+
+type searcher struct {
+	flags.SearchFlags
+}
+
+// Search returns the matching chart versions of the cached repository indexes, sorted by name.
+// Repositories whose index was not downloaded yet are skipped.
+func (o *searcher) Search(ctx context.Context) ([]ChartVersion, error) {
+	constraint, err := semver.NewConstraint(stableVersions)
+	if o.Version != "" {
+		constraint, err = semver.NewConstraint(o.Version)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w %q: %v", ErrInvalidConstraint, o.Version, err)
+	}
+
+	if err := ensureRepoDir(o.RepoFile); err != nil {
+		return nil, err
+	}
+	unlock, err := lockRepoFile(ctx, o.RepoFile)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+	f, err := readRepoFile(o.RepoFile)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []ChartVersion{}
+	for _, r := range f.Repositories {
+		index, err := repo.LoadIndexFile(filepath.Join(o.RepoCache, helmpath.CacheIndexFile(r.Name)))
+		if os.IsNotExist(err) {
+			logger.Infof("[RepoSearch] skipping repository %s without a cached index", r.Name)
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading index of repository %s: %w", r.Name, err)
+		}
+		for name, versions := range index.Entries {
+			results = append(results, o.matches(r.Name+"/"+name, versions, constraint)...)
+		}
+	}
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+	return results, nil
+}
+
+// matches returns the versions of a chart matching the search, the index has them newest first
+func (o *searcher) matches(name string, versions repo.ChartVersions, constraint *semver.Constraints) []ChartVersion {
+	if len(versions) == 0 || !o.matchesKeyword(name, versions[0]) {
+		return nil
+	}
+	var matched []ChartVersion
+	for _, v := range versions {
+		version, err := semver.NewVersion(v.Version)
+		if err != nil || !constraint.Check(version) {
+			continue
+		}
+		matched = append(matched, ChartVersion{Name: name, ChartVersion: v})
+		if !o.Versions {
+			break
+		}
+	}
+	return matched
+}
+
+func (o *searcher) matchesKeyword(name string, latest *repo.ChartVersion) bool {
+	keyword := strings.ToLower(o.Keyword)
+	if keyword == "" || strings.Contains(strings.ToLower(name), keyword) || strings.Contains(strings.ToLower(latest.Description), keyword) {
+		return true
+	}
+	for _, k := range latest.Keywords {
+		if strings.Contains(strings.ToLower(k), keyword) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+const stableIndex = `apiVersion: v1
+entries:
+  mysql:
+  - name: mysql
+    version: 1.7.0-rc.1
+    description: Fast, reliable, scalable, and easy to use open-source relational database system.
+    keywords: [mysql, database, sql]
+  - name: mysql
+    version: 1.6.9
+    description: Fast, reliable, scalable, and easy to use open-source relational database system.
+    keywords: [mysql, database, sql]
+  - name: mysql
+    version: 1.5.0
+    description: Fast, reliable, scalable, and easy to use open-source relational database system.
+    keywords: [mysql, database, sql]
+  redis:
+  - name: redis
+    version: 10.5.7
+    description: Open source, advanced key-value store.
+    keywords: [redis, keyvalue, database]
+`
+
+func searchDir(t *testing.T) (string, flags.SearchFlags) {
+	dir, err := ioutil.TempDir("", "search")
+	require.NoError(t, err)
+	repoFile := filepath.Join(dir, "repositories.yaml")
+	cache := filepath.Join(dir, "cache")
+	f := repo.NewFile()
+	f.Add(&repo.Entry{Name: "stable", URL: "https://charts.helm.sh/stable"}, &repo.Entry{Name: "uncached", URL: "https://charts.example.com"})
+	require.NoError(t, f.WriteFile(repoFile, 0644))
+	require.NoError(t, os.MkdirAll(cache, 0755))
+	require.NoError(t, ioutil.WriteFile(filepath.Join(cache, "stable-index.yaml"), []byte(stableIndex), 0644))
+	return dir, flags.SearchFlags{RepoFile: repoFile, RepoCache: cache}
+}
+
+func versions(results []ChartVersion) []string {
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Name+"@"+r.Version)
+	}
+	return got
+}
+
+func TestSearcher(t *testing.T) {
+	logger.Setup("default")
+	cases := []struct {
+		name     string
+		keyword  string
+		version  string
+		versions bool
+		want     []string
+	}{
+		{name: "latest stable versions", want: []string{"stable/mysql@1.6.9", "stable/redis@10.5.7"}},
+		{name: "keyword in keywords", keyword: "SQL", want: []string{"stable/mysql@1.6.9"}},
+		{name: "keyword in description", keyword: "key-value", want: []string{"stable/redis@10.5.7"}},
+		{name: "version constraint", keyword: "mysql", version: "~1.5", want: []string{"stable/mysql@1.5.0"}},
+		{name: "all versions", keyword: "mysql", versions: true, want: []string{"stable/mysql@1.6.9", "stable/mysql@1.5.0"}},
+		{name: "all versions with pre-releases", keyword: "mysql", version: ">=1.0.0-0", versions: true, want: []string{"stable/mysql@1.7.0-rc.1", "stable/mysql@1.6.9", "stable/mysql@1.5.0"}},
+		{name: "no match", keyword: "postgres"},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			dir, searchFlags := searchDir(t)
+			defer os.RemoveAll(dir)
+			searchFlags.Keyword, searchFlags.Version, searchFlags.Versions = c.keyword, c.version, c.versions
+			s := &searcher{SearchFlags: searchFlags}
+
+			results, err := s.Search(context.Background())
+
+			require.NoError(t, err)
+			assert.Equal(t, c.want, versions(results))
+		})
+	}
+}
+
+func TestSearcherRejectsInvalidConstraint(t *testing.T) {
+	s := &searcher{SearchFlags: flags.SearchFlags{Version: "not a version"}}
+
+	_, err := s.Search(context.Background())
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/repo"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/secret"
+)
+
+// UpdateResult is the outcome of refreshing the index of a repository
+type UpdateResult struct {
+	Name string
+	// Err is set when the index could not be downloaded
+	Err error
+	// Duration is the time spent refreshing the index
+	Duration time.Duration
+}
+
+type updater struct {
+	flags.RepoUpdateFlags
+	settings *cli.EnvSettings
+	secrets  secret.Store
+}
+
+// Update downloads the index of the repositories, a repository failing does not stop the others.
+// ErrNotFound is returned when a requested repository is not configured.
+// The repositories file is only locked to read the repositories and to store each index, not while downloading,
+// so a slow repository does not hold up changes to the others.
+func (o *updater) Update(ctx context.Context) ([]UpdateResult, error) {
+	entries, err := o.entries(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]UpdateResult, 0, len(entries))
+	for _, e := range entries {
+		// helm actions cannot be interrupted, so a cancelled request stops between repositories
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		start := time.Now()
+		err := o.update(ctx, e)
+		results = append(results, UpdateResult{Name: e.Name, Err: err, Duration: time.Since(start)})
+	}
+	return results, nil
+}
+
+// update is a repository to update, with its stored credentials or the error reading them
+type update struct {
+	repo.Entry
+	err error
+}
+
+// entries snapshots the repositories to update under the lock of the repositories file
+func (o *updater) entries(ctx context.Context) ([]update, error) {
+	if err := ensureRepoDir(o.RepoFile); err != nil {
+		return nil, err
+	}
+	unlock, err := lockRepoFile(ctx, o.RepoFile)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	f, err := readRepoFile(o.RepoFile)
+	if err != nil {
+		return nil, err
+	}
+	entries := f.Repositories
+	if len(o.Names) > 0 {
+		entries = make([]*repo.Entry, 0, len(o.Names))
+		for _, name := range o.Names {
+			e := f.Get(name)
+			if e == nil {
+				return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+			}
+			entries = append(entries, e)
+		}
+	}
+
+	updates := make([]update, 0, len(entries))
+	for _, e := range entries {
+		u := update{Entry: *e}
+		c, ok, err := repoCredentials(ctx, o.secrets, o.RepoFile, e.Name)
+		if ok {
+			u.Username, u.Password = c.Username, c.Password
+		}
+		u.err = err
+		updates = append(updates, u)
+	}
+	return updates, nil
+}
+
+// update downloads the index of a repository to a temporary directory of the cache,
+// then moves it into the cache under the lock of the repositories file.
+func (o *updater) update(ctx context.Context, u update) error {
+	if u.err != nil {
+		return u.err
+	}
+
+	r, err := repo.NewChartRepository(&u.Entry, getter.All(o.settings))
+	if err != nil {
+		return err
+	}
+	cache := r.CachePath
+	if o.RepoCache != "" {
+		cache = o.RepoCache
+	}
+	if err := os.MkdirAll(cache, 0755); err != nil {
+		return err
+	}
+	download, err := ioutil.TempDir(cache, ".update-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(download)
+
+	r.CachePath = download
+	if _, err := r.DownloadIndexFile(); err != nil {
+		return fmt.Errorf("%w looks like %v is not a valid chart repository or cannot be reached", err, u.URL)
+	}
+	return o.store(ctx, u.Entry, download, cache)
+}
+
+// store moves the downloaded index of a repository into the cache, unless the repository was removed
+// or changed while it was downloaded.
+func (o *updater) store(ctx context.Context, e repo.Entry, download, cache string) error {
+	unlock, err := lockRepoFile(ctx, o.RepoFile)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	f, err := readRepoFile(o.RepoFile)
+	if err != nil {
+		return err
+	}
+	current := f.Get(e.Name)
+	if current == nil {
+		return fmt.Errorf("%w: %s", ErrNotFound, e.Name)
+	}
+	if current.URL != e.URL {
+		return fmt.Errorf("repository %s changed while its index was downloaded", e.Name)
+	}
+
+	for _, cached := range []string{helmpath.CacheIndexFile(e.Name), helmpath.CacheChartsFile(e.Name)} {
+		if err := os.Rename(filepath.Join(download, cached), filepath.Join(cache, cached)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package helmcli
+
+import (
+	"context"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+type rollbacker struct {
+	action      *action.Rollback
+	status      *action.Status
+	envSettings *cli.EnvSettings
+	kubeClient  tracing.Interval
+}
+
+// Rollback rolls the release back to the configured revision.
+// The rollback action does not return the release it creates, so the latest revision is fetched afterwards.
+func (r *rollbacker) Rollback(ctx context.Context, releaseName string) (*release.Release, error) {
+	_, span := startAction(ctx, "rollback", r.kubeClient)
+	err := r.action.Run(releaseName)
+	tracing.End(span, err)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.status.Run(releaseName)
+}
@@ -0,0 +1,83 @@
+package helmcli
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func fakeRollbackConfiguration(t *testing.T) *action.Configuration {
+	newStorage := storage.Init(driver.NewMemory())
+	for version, status := range []release.Status{release.StatusSuperseded, release.StatusDeployed} {
+		err := newStorage.Create(
+			release.Mock(
+				&release.MockReleaseOptions{
+					Name:      testReleaseName,
+					Version:   version + 1,
+					Namespace: "default",
+					Status:    status,
+				}))
+		require.NoError(t, err)
+	}
+
+	return &action.Configuration{
+		Releases: newStorage,
+		KubeClient: &kubefake.FailingKubeClient{
+			PrintingKubeClient: kubefake.PrintingKubeClient{
+				Out: ioutil.Discard,
+			},
+		},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log: func(format string, v ...interface{}) {
+			t.Helper()
+			t.Logf(format, v...)
+		},
+	}
+}
+
+func newTestRollbacker(actionConfig *action.Configuration, version int) *rollbacker {
+	r := &rollbacker{
+		action:      action.NewRollback(actionConfig),
+		status:      action.NewStatus(actionConfig),
+		envSettings: cli.New(),
+	}
+	r.action.Version = version
+	return r
+}
+
+func TestRollbackShouldFailForInvalidRelease(t *testing.T) {
+	r := newTestRollbacker(fakeRollbackConfiguration(t), 1)
+
+	_, err := r.Rollback(context.Background(), testReleaseName+"-incorrect")
+
+	assert.Error(t, err)
+}
+
+func TestRollbackShouldFailForUnknownRevision(t *testing.T) {
+	r := newTestRollbacker(fakeRollbackConfiguration(t), 5)
+
+	_, err := r.Rollback(context.Background(), testReleaseName)
+
+	assert.Error(t, err)
+}
+
+func TestRollbackShouldCreateNewRevisionForValidRelease(t *testing.T) {
+	r := newTestRollbacker(fakeRollbackConfiguration(t), 1)
+
+	rel, err := r.Rollback(context.Background(), testReleaseName)
+
+	require.NoError(t, err)
+	require.NotNil(t, rel)
+	assert.Equal(t, 3, rel.Version)
+	assert.Equal(t, release.StatusDeployed, rel.Info.Status)
+}
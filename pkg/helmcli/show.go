@@ -0,0 +1,36 @@
+package helmcli
+
+import (
+	"context"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/cli"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/registry"
+)
+
+type shower struct {
+	options     action.ChartPathOptions
+	envSettings *cli.EnvSettings
+	credentials CredentialsResolver
+	registry    RegistryClient
+}
+
+// Show downloads the chart into the repository cache, or pulls it from its registry, and loads it
+func (s *shower) Show(ctx context.Context, chartName string) (*chart.Chart, error) {
+	if registry.IsOCI(chartName) {
+		return loadOCIChart(ctx, s.registry, chartName, s.options.Version)
+	}
+	if err := setCredentials(ctx, s.credentials, &s.options, chartName); err != nil {
+		return nil, err
+	}
+	cp, err := traceLocate(ctx, chartName, func() (string, error) {
+		return s.options.LocateChart(chartName, s.envSettings)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loadLocated(ctx, cp)
+}
@@ -0,0 +1,54 @@
+package helmcli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+)
+
+func TestShowShouldLoadChart(t *testing.T) {
+	sc, err := New().NewShower(flags.ShowFlags{})
+	require.NoError(t, err)
+
+	ch, err := sc.Show(context.Background(), "../../api/testdata/albatross")
+
+	require.NoError(t, err)
+	assert.Equal(t, "albatross", ch.Metadata.Name)
+	assert.NotEmpty(t, ch.Values)
+}
+
+func TestShowShouldFailForInvalidChart(t *testing.T) {
+	sc, err := New().NewShower(flags.ShowFlags{})
+	require.NoError(t, err)
+
+	_, err = sc.Show(context.Background(), "../../api/testdata/albatrossdne")
+
+	assert.Error(t, err)
+}
+
+type registryStub struct {
+	chartName, version string
+}
+
+func (r *registryStub) Load(_ context.Context, chartName, version string) (*chart.Chart, error) {
+	r.chartName, r.version = chartName, version
+	return &chart.Chart{Metadata: &chart.Metadata{Name: "mysql", Version: "1.6.9"}}, nil
+}
+
+func TestShowShouldPullOCIChartsFromRegistry(t *testing.T) {
+	registry := &registryStub{}
+	sc, err := NewWithOptions(Options{Registry: registry}).NewShower(flags.ShowFlags{Version: "~1.6"})
+	require.NoError(t, err)
+
+	ch, err := sc.Show(context.Background(), "oci://registry.example.com/charts/mysql")
+
+	require.NoError(t, err)
+	assert.Equal(t, "mysql", ch.Metadata.Name)
+	assert.Equal(t, "oci://registry.example.com/charts/mysql", registry.chartName)
+	assert.Equal(t, "~1.6", registry.version)
+}
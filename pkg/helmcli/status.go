@@ -6,13 +6,19 @@ import (
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/gojekfarm/albatross/pkg/tracing"
 )
 
 type statusGiver struct {
 	action      *action.Status
 	envSettings *cli.EnvSettings
+	kubeClient  tracing.Interval
 }
 
 func (s *statusGiver) Status(ctx context.Context, releaseName string) (*release.Release, error) {
-	return s.action.Run(releaseName)
+	_, span := startAction(ctx, "status", s.kubeClient)
+	rel, err := s.action.Run(releaseName)
+	tracing.End(span, err)
+	return rel, err
 }
@@ -0,0 +1,58 @@
+package helmcli
+
+import (
+	"context"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/registry"
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+// defaultTemplateReleaseName is the release name used by helm template when none is given.
+const defaultTemplateReleaseName = "release-name"
+
+type templater struct {
+	action      *action.Install
+	envSettings *cli.EnvSettings
+	credentials CredentialsResolver
+	registry    RegistryClient
+}
+
+// Template renders the chart locally and returns the release that would have been installed.
+func (t *templater) Template(ctx context.Context, relName, chartName string, values map[string]interface{}) (*release.Release, error) {
+	t.action.ReleaseName = relName
+	if relName == "" {
+		t.action.ReleaseName = defaultTemplateReleaseName
+	}
+
+	ch, err := t.loadChart(ctx, chartName)
+	if err != nil {
+		return nil, err
+	}
+
+	_, span := startAction(ctx, "template", tracing.Interval{})
+	rel, err := t.action.Run(ch, values)
+	tracing.End(span, err)
+	return rel, err
+}
+
+func (t *templater) loadChart(ctx context.Context, chartName string) (*chart.Chart, error) {
+	if registry.IsOCI(chartName) {
+		return loadOCIChart(ctx, t.registry, chartName, t.action.Version)
+	}
+	if err := setCredentials(ctx, t.credentials, &t.action.ChartPathOptions, chartName); err != nil {
+		return nil, err
+	}
+	cp, err := traceLocate(ctx, chartName, func() (string, error) {
+		return t.action.LocateChart(chartName, t.envSettings)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return loadLocated(ctx, cp)
+}
@@ -0,0 +1,52 @@
+package helmcli
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+func newTestTemplater(t *testing.T, flg flags.TemplateFlags) *templater {
+	logger.Setup("none")
+	tc, err := New().NewTemplater(flg)
+	require.NoError(t, err)
+	return tc.(*templater)
+}
+
+func TestTemplateShouldRenderChartWithoutCluster(t *testing.T) {
+	tc := newTestTemplater(t, flags.TemplateFlags{Namespace: "templating"})
+	values := map[string]interface{}{
+		"global": map[string]interface{}{"author": "Wordsworth"},
+	}
+
+	rel, err := tc.Template(context.Background(), "test-release", "../../api/testdata/albatross", values)
+
+	require.NoError(t, err)
+	assert.Contains(t, rel.Manifest, "name: test-release-config")
+	assert.Contains(t, rel.Manifest, "namespace: templating")
+	assert.Contains(t, rel.Manifest, `author: "Wordsworth"`)
+	assert.Contains(t, rel.Info.Notes, "test-release has been deployed to templating.")
+}
+
+func TestTemplateShouldUseDefaultReleaseName(t *testing.T) {
+	tc := newTestTemplater(t, flags.TemplateFlags{})
+
+	rel, err := tc.Template(context.Background(), "", "../../api/testdata/albatross", nil)
+
+	require.NoError(t, err)
+	assert.Equal(t, defaultTemplateReleaseName, rel.Name)
+	assert.Contains(t, rel.Manifest, "name: release-name-config")
+}
+
+func TestTemplateShouldFailForInvalidChart(t *testing.T) {
+	tc := newTestTemplater(t, flags.TemplateFlags{})
+
+	_, err := tc.Template(context.Background(), "test-release", "../../api/testdata/albatrossdne", nil)
+
+	assert.EqualError(t, err, "path \"../../api/testdata/albatrossdne\" not found")
+}
@@ -0,0 +1,36 @@
+package helmcli
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+// startAction records the creation of the kube client of an action, which happened in its constructor,
+// and starts the span of the action run
+func startAction(ctx context.Context, name string, kubeClient tracing.Interval) (context.Context, trace.Span) {
+	kubeClient.Record(ctx, "helm.kube_client")
+	return tracing.Start(ctx, "helm.action.run", attribute.String("helm.action", name))
+}
+
+// traceLocate traces locate, which downloads a chart of a repository into the cache or finds it on disk
+func traceLocate(ctx context.Context, chartName string, locate func() (string, error)) (string, error) {
+	_, span := tracing.Start(ctx, "helm.chart.locate", attribute.String("helm.chart", chartName))
+	path, err := locate()
+	err = chartError(err)
+	tracing.End(span, err)
+	return path, err
+}
+
+// loadLocated loads a located chart from disk
+func loadLocated(ctx context.Context, path string) (*chart.Chart, error) {
+	_, span := tracing.Start(ctx, "helm.chart.load")
+	ch, err := loader.Load(path)
+	tracing.End(span, err)
+	return ch, err
+}
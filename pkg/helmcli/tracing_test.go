@@ -0,0 +1,57 @@
+package helmcli
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+func recordSpans() (*tracetest.SpanRecorder, func()) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder, func() { otel.SetTracerProvider(previous) }
+}
+
+func spanNames(recorder *tracetest.SpanRecorder) []string {
+	var names []string
+	for _, s := range recorder.Ended() {
+		names = append(names, s.Name())
+	}
+	return names
+}
+
+func TestTemplateShouldTraceChartLocateLoadAndRun(t *testing.T) {
+	recorder, restore := recordSpans()
+	defer restore()
+	tc := newTestTemplater(t, flags.TemplateFlags{})
+	ctx, parent := tracing.Start(context.Background(), "template.Template")
+
+	_, err := tc.Template(ctx, "", "../../api/testdata/albatross", nil)
+	parent.End()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"helm.chart.locate", "helm.chart.load", "helm.action.run", "template.Template"}, spanNames(recorder))
+	for _, s := range recorder.Ended()[:3] {
+		assert.Equal(t, parent.SpanContext().SpanID(), s.Parent().SpanID())
+	}
+}
+
+func TestStartActionShouldRecordKubeClientCreation(t *testing.T) {
+	recorder, restore := recordSpans()
+	defer restore()
+
+	_, span := startAction(context.Background(), "status", tracing.Since(time.Now()))
+	span.End()
+
+	assert.Equal(t, []string{"helm.kube_client", "helm.action.run"}, spanNames(recorder))
+}
@@ -6,14 +6,23 @@ import (
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/release"
+
+	"github.com/gojekfarm/albatross/pkg/tracing"
 )
 
 type uninstaller struct {
 	action      *action.Uninstall
 	envSettings *cli.EnvSettings
+	kubeClient  tracing.Interval
 }
 
 // Uninstall runs the uninstall operation for a given releaseName if it exists.
 func (u *uninstaller) Uninstall(ctx context.Context, releaseName string) (*release.UninstallReleaseResponse, error) {
-	return u.action.Run(releaseName)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	_, span := startAction(ctx, "uninstall", u.kubeClient)
+	resp, err := u.action.Run(releaseName)
+	tracing.End(span, err)
+	return resp, err
 }
@@ -92,3 +92,19 @@ func fakeUninstallConfiguration(t *testing.T) *action.Configuration {
 		},
 	}
 }
+
+func TestUninstallShouldNotRunWhenContextIsCancelled(t *testing.T) {
+	actionConfig := fakeUninstallConfiguration(t)
+	u := &uninstaller{
+		action:      action.NewUninstall(actionConfig),
+		envSettings: cli.New(),
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	_, err := u.Uninstall(ctx, testReleaseName)
+
+	assert.Equal(t, context.Canceled, err)
+	_, err = actionConfig.Releases.Last(testReleaseName)
+	assert.NoError(t, err)
+}
@@ -6,49 +6,132 @@ import (
 
 	"helm.sh/helm/v3/pkg/action"
 	"helm.sh/helm/v3/pkg/chart"
-	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/cli"
 	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
 	"helm.sh/helm/v3/pkg/storage/driver"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/registry"
+	"github.com/gojekfarm/albatross/pkg/tracing"
 )
 
 type upgrader struct {
 	action      *action.Upgrade
 	history     *action.History
 	envSettings *cli.EnvSettings
-	installer   Installer
+	credentials CredentialsResolver
+	registry    RegistryClient
+	verify      verification
+	installer   *installer
+	jobs        *jobWaiter
+	releases    *storage.Storage
+	// rollback is set with atomic, to roll back releases whose jobs failed
+	rollback   *action.Rollback
+	kubeClient tracing.Interval
 }
 
 // Upgrade executes the upgrade action.
 func (u *upgrader) Upgrade(ctx context.Context, relName, chartName string, values map[string]interface{}) (*release.Release, error) {
+	ch, err := u.loadChart(ctx, chartName)
+	if err != nil {
+		return nil, fmt.Errorf("error loading chart: %w", err)
+	}
+
+	return u.upgrade(ctx, relName, ch, values)
+}
+
+// UpgradeChart upgrades the release to a chart that is already loaded, such as an uploaded archive.
+// Such charts have no provenance, so they are refused when the chart must be verified.
+func (u *upgrader) UpgradeChart(ctx context.Context, relName string, ch *chart.Chart, values map[string]interface{}) (*release.Release, error) {
+	if err := u.verify.refuse("uploaded"); err != nil {
+		return nil, err
+	}
+	return u.upgrade(ctx, relName, ch, values)
+}
+
+func (u *upgrader) upgrade(ctx context.Context, relName string, ch *chart.Chart, values map[string]interface{}) (*release.Release, error) {
 	// Install the release first if install is set to true
 	if u.action.Install {
 		u.history.Max = 1
 		if _, runErr := u.history.Run(relName); runErr == driver.ErrReleaseNotFound {
-			rel, err := u.installer.Install(ctx, relName, chartName, values)
-			if err != nil {
-				return nil, err
-			}
-
-			return rel, nil
+			return u.installer.install(ctx, relName, ch, values)
 		} else if runErr != nil {
 			return nil, runErr
 		}
 	}
 
-	ch, err := u.loadChart(chartName)
+	// helm actions cannot be interrupted, so a cancelled request stops before touching the cluster
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	ctx, span := startAction(ctx, "upgrade", u.kubeClient)
+	rel, err := u.action.Run(relName, ch, values)
+	if err == nil && !u.action.DryRun {
+		if err = u.jobs.Wait(ctx, rel); err != nil {
+			err = u.failJobs(rel, err)
+		}
+	}
+	tracing.End(span, err)
+	return rel, err
+}
+
+// failJobs marks a release whose jobs failed as failed and, with atomic, rolls it back to the last
+// successful revision like helm does when the resources of the release are not ready in time
+func (u *upgrader) failJobs(rel *release.Release, jobErr error) error {
+	if err := markFailed(u.releases, rel, jobErr); err != nil {
+		return err
+	}
+	if u.rollback == nil {
+		return jobErr
+	}
+	previous, err := u.lastSuccessful(rel)
 	if err != nil {
-		return nil, fmt.Errorf("error loading chart: %w", err)
+		return fmt.Errorf("error rolling back release %s after its jobs failed: %v: %w", rel.Name, err, jobErr)
+	}
+	u.rollback.Version = previous.Version
+	if err := u.rollback.Run(rel.Name); err != nil {
+		return fmt.Errorf("error rolling back release %s after its jobs failed: %v: %w", rel.Name, err, jobErr)
 	}
+	return fmt.Errorf("release %s failed, and has been rolled back due to atomic being set: %w", rel.Name, jobErr)
+}
 
-	return u.action.Run(relName, ch, values)
+// lastSuccessful returns the most recent deployed or superseded revision older than rel
+func (u *upgrader) lastSuccessful(rel *release.Release) (*release.Release, error) {
+	history, err := u.releases.History(rel.Name)
+	if err != nil {
+		return nil, err
+	}
+	var previous *release.Release
+	for _, r := range history {
+		if r.Version >= rel.Version || (r.Info.Status != release.StatusDeployed && r.Info.Status != release.StatusSuperseded) {
+			continue
+		}
+		if previous == nil || r.Version > previous.Version {
+			previous = r
+		}
+	}
+	if previous == nil {
+		return nil, fmt.Errorf("release %s has no successful revision", rel.Name)
+	}
+	return previous, nil
 }
 
-func (u *upgrader) loadChart(chartName string) (*chart.Chart, error) {
-	cp, err := u.action.LocateChart(chartName, u.envSettings)
+func (u *upgrader) loadChart(ctx context.Context, chartName string) (*chart.Chart, error) {
+	if registry.IsOCI(chartName) {
+		if err := u.verify.refuse("oci"); err != nil {
+			return nil, err
+		}
+		return loadOCIChart(ctx, u.registry, chartName, u.action.Version)
+	}
+	if err := setCredentials(ctx, u.credentials, &u.action.ChartPathOptions, chartName); err != nil {
+		return nil, err
+	}
+	cp, err := traceLocate(ctx, chartName, func() (string, error) {
+		return u.verify.locateChart(&u.action.ChartPathOptions, u.envSettings, chartName)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return loader.Load(cp)
+	return loadLocated(ctx, cp)
 }
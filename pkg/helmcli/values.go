@@ -0,0 +1,24 @@
+package helmcli
+
+import (
+	"context"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/cli"
+
+	"github.com/gojekfarm/albatross/pkg/tracing"
+)
+
+type valuesGiver struct {
+	action      *action.GetValues
+	envSettings *cli.EnvSettings
+	kubeClient  tracing.Interval
+}
+
+// Values returns the user supplied values of a release, or the computed values when AllValues is set.
+func (v *valuesGiver) Values(ctx context.Context, releaseName string) (map[string]interface{}, error) {
+	_, span := startAction(ctx, "get_values", v.kubeClient)
+	values, err := v.action.Run(releaseName)
+	tracing.End(span, err)
+	return values, err
+}
@@ -0,0 +1,86 @@
+package helmcli
+
+import (
+	"context"
+	"io/ioutil"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	kubefake "helm.sh/helm/v3/pkg/kube/fake"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func fakeValuesConfiguration(t *testing.T) *action.Configuration {
+	newStorage := storage.Init(driver.NewMemory())
+	rel := release.Mock(
+		&release.MockReleaseOptions{
+			Name:      testReleaseName,
+			Version:   1,
+			Namespace: "default",
+			Chart: &chart.Chart{
+				Metadata: &chart.Metadata{Name: "albatross", Version: "0.1.0"},
+				Values:   map[string]interface{}{"replicaCount": 1, "image": "nginx"},
+			},
+			Status: release.StatusDeployed,
+		})
+	rel.Config = map[string]interface{}{"replicaCount": 3}
+	require.NoError(t, newStorage.Create(rel))
+
+	return &action.Configuration{
+		Releases: newStorage,
+		KubeClient: &kubefake.FailingKubeClient{
+			PrintingKubeClient: kubefake.PrintingKubeClient{
+				Out: ioutil.Discard,
+			},
+		},
+		Capabilities: chartutil.DefaultCapabilities,
+		Log: func(format string, v ...interface{}) {
+			t.Helper()
+			t.Logf(format, v...)
+		},
+	}
+}
+
+func TestValuesShouldFailForInvalidRelease(t *testing.T) {
+	v := &valuesGiver{
+		action:      action.NewGetValues(fakeValuesConfiguration(t)),
+		envSettings: cli.New(),
+	}
+
+	_, err := v.Values(context.Background(), testReleaseName+"-incorrect")
+
+	assert.Error(t, err)
+}
+
+func TestValuesShouldReturnUserSuppliedValues(t *testing.T) {
+	v := &valuesGiver{
+		action:      action.NewGetValues(fakeValuesConfiguration(t)),
+		envSettings: cli.New(),
+	}
+
+	values, err := v.Values(context.Background(), testReleaseName)
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"replicaCount": 3}, values)
+}
+
+func TestValuesShouldReturnComputedValuesWhenAllValuesIsSet(t *testing.T) {
+	v := &valuesGiver{
+		action:      action.NewGetValues(fakeValuesConfiguration(t)),
+		envSettings: cli.New(),
+	}
+	v.action.AllValues = true
+
+	values, err := v.Values(context.Background(), testReleaseName)
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, values["replicaCount"])
+	assert.Equal(t, "nginx", values["image"])
+}
@@ -0,0 +1,135 @@
+package helmcli
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+const (
+	signedChart = "testdata/signtest-0.1.0.tgz"
+	testKeyring = "testdata/helm-test-key.pub"
+)
+
+func verificationSettings(t *testing.T) (*cli.EnvSettings, func()) {
+	dir, err := ioutil.TempDir("", "verify")
+	require.NoError(t, err)
+	settings := cli.New()
+	settings.RepositoryConfig = filepath.Join(dir, "repositories.yaml")
+	settings.RepositoryCache = filepath.Join(dir, "cache")
+	return settings, func() { os.RemoveAll(dir) }
+}
+
+func copyChart(t *testing.T, dir string, withProvenance, tamper bool) string {
+	b, err := ioutil.ReadFile(signedChart)
+	require.NoError(t, err)
+	if tamper {
+		b = append(b, 0)
+	}
+	path := filepath.Join(dir, filepath.Base(signedChart))
+	require.NoError(t, ioutil.WriteFile(path, b, 0644))
+	if withProvenance {
+		prov, err := ioutil.ReadFile(signedChart + ".prov")
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(path+".prov", prov, 0644))
+	}
+	return path
+}
+
+func TestLocateChartVerifiesLocalCharts(t *testing.T) {
+	settings, cleanup := verificationSettings(t)
+	defer cleanup()
+	v := Provenance{Keyring: testKeyring}.verification(true)
+
+	path, err := v.locateChart(&action.ChartPathOptions{}, settings, signedChart)
+	require.NoError(t, err)
+	assert.True(t, filepath.IsAbs(path))
+
+	dir, err := ioutil.TempDir("", "charts")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	_, err = v.locateChart(&action.ChartPathOptions{}, settings, copyChart(t, dir, false, false))
+	assert.True(t, errors.Is(err, ErrVerification), err)
+	assert.Contains(t, err.Error(), "could not load provenance file")
+
+	_, err = v.locateChart(&action.ChartPathOptions{}, settings, "../../api/testdata/albatross")
+	assert.True(t, errors.Is(err, ErrVerification), err)
+	assert.EqualError(t, err, "chart verification failed: unpacked charts cannot be verified")
+}
+
+func TestLocateChartReportsMismatch(t *testing.T) {
+	settings, cleanup := verificationSettings(t)
+	defer cleanup()
+	dir, err := ioutil.TempDir("", "charts")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	v := Provenance{Keyring: testKeyring}.verification(true)
+
+	_, err = v.locateChart(&action.ChartPathOptions{}, settings, copyChart(t, dir, true, true))
+
+	assert.True(t, errors.Is(err, ErrVerification), err)
+	assert.Contains(t, err.Error(), "sha256 sum does not match")
+}
+
+func TestLocateChartDownloadsProvenance(t *testing.T) {
+	settings, cleanup := verificationSettings(t)
+	defer cleanup()
+	withProvenance := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if filepath.Ext(r.URL.Path) == ".prov" && !withProvenance {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		http.ServeFile(w, r, filepath.Join("testdata", filepath.Base(r.URL.Path)))
+	}))
+	defer server.Close()
+	v := Provenance{Keyring: testKeyring}.verification(true)
+
+	path, err := v.locateChart(&action.ChartPathOptions{}, settings, server.URL+"/signtest-0.1.0.tgz")
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(settings.RepositoryCache, "signtest-0.1.0.tgz"), path)
+
+	withProvenance = false
+	os.Remove(path + ".prov")
+	_, err = v.locateChart(&action.ChartPathOptions{}, settings, server.URL+"/signtest-0.1.0.tgz")
+	assert.True(t, errors.Is(err, ErrVerification), err)
+	assert.Contains(t, err.Error(), "could not load provenance file")
+
+	_, err = v.locateChart(&action.ChartPathOptions{}, settings, server.URL+"/missing-0.1.0.tgz")
+	assert.False(t, errors.Is(err, ErrVerification), err)
+}
+
+func TestVerificationRequiresKeyring(t *testing.T) {
+	settings, cleanup := verificationSettings(t)
+	defer cleanup()
+
+	_, err := Provenance{Required: true}.verification(false).locateChart(&action.ChartPathOptions{}, settings, signedChart)
+
+	assert.EqualError(t, err, "chart verification failed: no keyring is configured")
+}
+
+func TestVerificationRefusesChartsWithoutProvenance(t *testing.T) {
+	ch := &chart.Chart{Metadata: &chart.Metadata{Name: "mysql", Version: "1.6.9"}}
+	i := &installer{verify: Provenance{Keyring: testKeyring, Required: true}.verification(false)}
+
+	_, err := i.InstallChart(context.Background(), "mysql", ch, nil)
+	assert.EqualError(t, err, "chart verification failed: uploaded charts cannot be verified")
+
+	_, err = i.loadChart(context.Background(), "oci://registry.example.com/charts/mysql")
+	assert.EqualError(t, err, "chart verification failed: oci charts cannot be verified")
+
+	u := &upgrader{verify: Provenance{Keyring: testKeyring}.verification(true)}
+	_, err = u.UpgradeChart(context.Background(), "mysql", ch, nil)
+	assert.True(t, errors.Is(err, ErrVerification), err)
+}
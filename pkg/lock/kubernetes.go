@@ -0,0 +1,198 @@
+package lock
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+const (
+	leasePrefix       = "albatross-release-"
+	managedByKey      = "app.kubernetes.io/managed-by"
+	managedBy         = "albatross"
+	releaseAnnotation = "albatross.gojek.com/release"
+	holderAnnotation  = "albatross.gojek.com/holder"
+	// releaseTimeout bounds the deletion of a lease, which happens after the request context may be done
+	releaseTimeout = 10 * time.Second
+)
+
+// LeaseLocker keeps locks in kubernetes leases of a namespace, so that every albatross replica sees them.
+// A lease is renewed while its lock is held, and expires after its duration when the replica holding it dies.
+type LeaseLocker struct {
+	client    kubernetes.Interface
+	namespace string
+	duration  time.Duration
+	now       func() time.Time
+}
+
+// NewLeaseLocker returns a locker keeping leases lasting duration in namespace
+func NewLeaseLocker(client kubernetes.Interface, namespace string, duration time.Duration) *LeaseLocker {
+	return &LeaseLocker{client: client, namespace: namespace, duration: duration, now: time.Now}
+}
+
+// TryLock takes the lease of key unless another holder renewed it within its duration
+func (l *LeaseLocker) TryLock(ctx context.Context, key Key, holder Holder) (func(), error) {
+	identity, err := newIdentity()
+	if err != nil {
+		return nil, err
+	}
+	annotation, err := json.Marshal(holder)
+	if err != nil {
+		return nil, err
+	}
+
+	leases := l.client.CoordinationV1().Leases(l.namespace)
+	name := leaseName(key)
+	lease, err := leases.Get(ctx, name, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		lease = &coordinationv1.Lease{ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: l.namespace,
+			Labels:    map[string]string{managedByKey: managedBy},
+		}}
+		l.hold(lease, key, identity, annotation)
+		lease, err = leases.Create(ctx, lease, metav1.CreateOptions{})
+	case err != nil:
+		return nil, err
+	case l.held(lease):
+		return nil, lockedBy(key, lease)
+	default:
+		l.hold(lease, key, identity, annotation)
+		lease, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+	}
+	// another replica created or took the lease since it was read
+	if apierrors.IsAlreadyExists(err) || apierrors.IsConflict(err) {
+		if current, err := leases.Get(ctx, name, metav1.GetOptions{}); err == nil {
+			return nil, lockedBy(key, current)
+		}
+		return nil, &LockedError{Key: key}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	stop := make(chan struct{})
+	renewed := make(chan struct{})
+	go l.renew(key, lease.Name, identity, stop, renewed)
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			close(stop)
+			<-renewed
+			l.release(key, lease.Name, identity)
+		})
+	}, nil
+}
+
+// hold makes identity the holder of lease
+func (l *LeaseLocker) hold(lease *coordinationv1.Lease, key Key, identity string, holder []byte) {
+	now := metav1.NewMicroTime(l.now())
+	seconds := int32(l.duration / time.Second)
+	if lease.Annotations == nil {
+		lease.Annotations = map[string]string{}
+	}
+	lease.Annotations[releaseAnnotation] = key.String()
+	lease.Annotations[holderAnnotation] = string(holder)
+	lease.Spec = coordinationv1.LeaseSpec{
+		HolderIdentity:       &identity,
+		LeaseDurationSeconds: &seconds,
+		AcquireTime:          &now,
+		RenewTime:            &now,
+	}
+}
+
+// held tells whether lease has a holder that renewed it within its duration
+func (l *LeaseLocker) held(lease *coordinationv1.Lease) bool {
+	spec := lease.Spec
+	if spec.HolderIdentity == nil || *spec.HolderIdentity == "" || spec.RenewTime == nil || spec.LeaseDurationSeconds == nil {
+		return false
+	}
+	expiry := spec.RenewTime.Add(time.Duration(*spec.LeaseDurationSeconds) * time.Second)
+	return l.now().Before(expiry)
+}
+
+// renew keeps the lease held by identity until stop is closed or the lease is lost
+func (l *LeaseLocker) renew(key Key, name, identity string, stop <-chan struct{}, done chan<- struct{}) {
+	defer close(done)
+	ticker := time.NewTicker(l.duration / 3)
+	defer ticker.Stop()
+	leases := l.client.CoordinationV1().Leases(l.namespace)
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), l.duration/3)
+		lease, err := leases.Get(ctx, name, metav1.GetOptions{})
+		if err == nil && !heldBy(lease, identity) {
+			cancel()
+			logger.Errorf("[Lock] lost the lease of %s to another holder", key)
+			return
+		}
+		if err == nil {
+			now := metav1.NewMicroTime(l.now())
+			lease.Spec.RenewTime = &now
+			_, err = leases.Update(ctx, lease, metav1.UpdateOptions{})
+		}
+		cancel()
+		if err != nil {
+			logger.Errorf("[Lock] error renewing the lease of %s: %v", key, err)
+		}
+	}
+}
+
+// release deletes the lease unless another holder took it after it expired
+func (l *LeaseLocker) release(key Key, name, identity string) {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+	leases := l.client.CoordinationV1().Leases(l.namespace)
+	lease, err := leases.Get(ctx, name, metav1.GetOptions{})
+	if err == nil && !heldBy(lease, identity) {
+		return
+	}
+	if err == nil {
+		precondition := metav1.Preconditions{ResourceVersion: &lease.ResourceVersion}
+		err = leases.Delete(ctx, name, metav1.DeleteOptions{Preconditions: &precondition})
+	}
+	if err != nil && !apierrors.IsNotFound(err) {
+		logger.Errorf("[Lock] error releasing the lease of %s, it expires on its own: %v", key, err)
+	}
+}
+
+func heldBy(lease *coordinationv1.Lease, identity string) bool {
+	return lease.Spec.HolderIdentity != nil && *lease.Spec.HolderIdentity == identity
+}
+
+func lockedBy(key Key, lease *coordinationv1.Lease) *LockedError {
+	err := &LockedError{Key: key}
+	// leases taken by something else than albatross have no holder, the error still reports the lock
+	_ = json.Unmarshal([]byte(lease.Annotations[holderAnnotation]), &err.Holder)
+	return err
+}
+
+// leaseName derives a valid object name from key, whose parts can be too long or contain any character
+func leaseName(key Key) string {
+	sum := sha256.Sum256([]byte(key.String()))
+	return leasePrefix + hex.EncodeToString(sum[:])[:24]
+}
+
+func newIdentity() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,88 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	coordinationv1 "k8s.io/api/coordination/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestLeaseLockerKeepsLocksInLeases(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	l := NewLeaseLocker(client, "albatross", 30*time.Second)
+	ctx := context.Background()
+
+	unlock, err := l.TryLock(ctx, apiKey, upgradeJob)
+	require.NoError(t, err)
+
+	lease, err := client.CoordinationV1().Leases("albatross").Get(ctx, leaseName(apiKey), metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "staging/payments/api", lease.Annotations[releaseAnnotation])
+	assert.Equal(t, int32(30), *lease.Spec.LeaseDurationSeconds)
+	_, err = l.TryLock(ctx, apiKey, Holder{Action: "uninstall"})
+	var locked *LockedError
+	require.True(t, errors.As(err, &locked))
+	assert.Equal(t, upgradeJob, locked.Holder)
+
+	unlock()
+	_, err = client.CoordinationV1().Leases("albatross").Get(ctx, leaseName(apiKey), metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	unlock, err = l.TryLock(ctx, apiKey, Holder{Action: "uninstall"})
+	require.NoError(t, err)
+	unlock()
+}
+
+func TestLeaseLockerTakesOverExpiredLeases(t *testing.T) {
+	identity := "crashed-replica"
+	seconds := int32(30)
+	renewed := metav1.NewMicroTime(time.Now().Add(-time.Minute))
+	client := fake.NewSimpleClientset(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: leaseName(apiKey), Namespace: "albatross"},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &identity,
+			LeaseDurationSeconds: &seconds,
+			AcquireTime:          &renewed,
+			RenewTime:            &renewed,
+		},
+	})
+	l := NewLeaseLocker(client, "albatross", 30*time.Second)
+
+	unlock, err := l.TryLock(context.Background(), apiKey, upgradeJob)
+
+	require.NoError(t, err)
+	lease, err := client.CoordinationV1().Leases("albatross").Get(context.Background(), leaseName(apiKey), metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.NotEqual(t, identity, *lease.Spec.HolderIdentity)
+	unlock()
+}
+
+func TestLeaseLockerRenewsHeldLeases(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	l := NewLeaseLocker(client, "albatross", 30*time.Millisecond)
+	ctx := context.Background()
+	unlock, err := l.TryLock(ctx, apiKey, upgradeJob)
+	require.NoError(t, err)
+	defer unlock()
+	lease, err := client.CoordinationV1().Leases("albatross").Get(ctx, leaseName(apiKey), metav1.GetOptions{})
+	require.NoError(t, err)
+	acquired := lease.Spec.RenewTime.Time
+
+	assert.Eventually(t, func() bool {
+		lease, err := client.CoordinationV1().Leases("albatross").Get(ctx, leaseName(apiKey), metav1.GetOptions{})
+		return err == nil && lease.Spec.RenewTime.After(acquired)
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestLeaseNamesAreValidObjectNames(t *testing.T) {
+	name := leaseName(Key{Cluster: "gke_project_region_cluster", Namespace: "payments", Release: "api"})
+
+	assert.Regexp(t, "^albatross-release-[0-9a-f]{24}$", name)
+	assert.NotEqual(t, name, leaseName(workerKey))
+}
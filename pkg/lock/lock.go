@@ -0,0 +1,84 @@
+// Package lock serializes the mutating actions on a release, so that concurrent requests,
+// possibly served by different albatross replicas, do not race inside helm.
+package lock
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrLocked is matched by the errors of locks held by someone else
+var ErrLocked = errors.New("release is locked")
+
+// retryInterval is how often a queued request tries to take a lock again
+var retryInterval = time.Second
+
+// Key identifies the release a lock is taken on
+type Key struct {
+	Cluster   string
+	Namespace string
+	Release   string
+}
+
+func (k Key) String() string {
+	return fmt.Sprintf("%s/%s/%s", k.Cluster, k.Namespace, k.Release)
+}
+
+// Holder describes the request holding a lock
+type Holder struct {
+	// Action is the action of the request, install, upgrade or uninstall
+	Action string `json:"action"`
+	// Principal is the authenticated principal of the request, if any
+	Principal string `json:"principal,omitempty"`
+	// OperationID is the id of the operation of asynchronous requests
+	OperationID string `json:"operation_id,omitempty"`
+	// Replica is the host name of the albatross replica serving the request
+	Replica    string    `json:"replica,omitempty"`
+	AcquiredAt time.Time `json:"acquired_at"`
+}
+
+// LockedError is returned when the lock of a release is held by another request
+type LockedError struct {
+	Key    Key
+	Holder Holder
+}
+
+func (e *LockedError) Error() string {
+	msg := fmt.Sprintf("release %s is locked by %s", e.Key, e.Holder.Action)
+	if e.Holder.OperationID != "" {
+		msg += fmt.Sprintf(" operation %s", e.Holder.OperationID)
+	}
+	return msg + fmt.Sprintf(" since %s", e.Holder.AcquiredAt.Format(time.RFC3339))
+}
+
+// Is matches ErrLocked
+func (e *LockedError) Is(target error) bool {
+	return target == ErrLocked
+}
+
+// Locker takes locks on releases
+type Locker interface {
+	// TryLock takes the lock of key for holder, or fails with a *LockedError naming the current holder.
+	// The returned function releases the lock.
+	TryLock(ctx context.Context, key Key, holder Holder) (func(), error)
+}
+
+// Lock takes the lock of key like TryLock, but waits for a held lock to be released until ctx is done,
+// in which case the last *LockedError is returned.
+func Lock(ctx context.Context, l Locker, key Key, holder Holder) (func(), error) {
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+	for {
+		unlock, err := l.TryLock(ctx, key, holder)
+		if !errors.Is(err, ErrLocked) {
+			return unlock, err
+		}
+		select {
+		case <-ctx.Done():
+			return nil, err
+		case <-ticker.C:
+		}
+	}
+}
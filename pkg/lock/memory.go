@@ -0,0 +1,36 @@
+package lock
+
+import (
+	"context"
+	"sync"
+)
+
+// MemoryLocker keeps locks in memory, it serializes the requests of a single albatross instance
+type MemoryLocker struct {
+	mu   sync.Mutex
+	held map[Key]Holder
+}
+
+// NewMemoryLocker returns a locker without locks held
+func NewMemoryLocker() *MemoryLocker {
+	return &MemoryLocker{held: map[Key]Holder{}}
+}
+
+// TryLock takes the lock of key unless it is held
+func (m *MemoryLocker) TryLock(_ context.Context, key Key, holder Holder) (func(), error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if current, ok := m.held[key]; ok {
+		return nil, &LockedError{Key: key, Holder: current}
+	}
+	m.held[key] = holder
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			delete(m.held, key)
+		})
+	}, nil
+}
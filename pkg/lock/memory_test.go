@@ -0,0 +1,70 @@
+package lock
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	apiKey     = Key{Cluster: "staging", Namespace: "payments", Release: "api"}
+	workerKey  = Key{Cluster: "staging", Namespace: "payments", Release: "worker"}
+	upgradeJob = Holder{Action: "upgrade", Principal: "ci", OperationID: "42", AcquiredAt: time.Date(2021, 3, 24, 12, 0, 0, 0, time.UTC)}
+)
+
+func TestMemoryLockerRefusesHeldLocks(t *testing.T) {
+	l := NewMemoryLocker()
+	ctx := context.Background()
+	unlock, err := l.TryLock(ctx, apiKey, upgradeJob)
+	require.NoError(t, err)
+
+	_, err = l.TryLock(ctx, apiKey, Holder{Action: "uninstall"})
+
+	var locked *LockedError
+	require.True(t, errors.As(err, &locked))
+	assert.True(t, errors.Is(err, ErrLocked))
+	assert.Equal(t, upgradeJob, locked.Holder)
+	assert.Equal(t, "release staging/payments/api is locked by upgrade operation 42 since 2021-03-24T12:00:00Z", err.Error())
+	unlockWorker, err := l.TryLock(ctx, workerKey, Holder{Action: "install"})
+	require.NoError(t, err)
+	unlockWorker()
+
+	unlock()
+	unlock()
+	unlock, err = l.TryLock(ctx, apiKey, Holder{Action: "uninstall"})
+	require.NoError(t, err)
+	unlock()
+}
+
+func TestLockWaitsForHeldLocks(t *testing.T) {
+	defer func(interval time.Duration) { retryInterval = interval }(retryInterval)
+	retryInterval = time.Millisecond
+	l := NewMemoryLocker()
+	unlock, err := l.TryLock(context.Background(), apiKey, upgradeJob)
+	require.NoError(t, err)
+	time.AfterFunc(10*time.Millisecond, unlock)
+
+	queued, err := Lock(context.Background(), l, apiKey, Holder{Action: "uninstall"})
+
+	require.NoError(t, err)
+	queued()
+}
+
+func TestLockGivesUpWhenContextIsDone(t *testing.T) {
+	defer func(interval time.Duration) { retryInterval = interval }(retryInterval)
+	retryInterval = time.Millisecond
+	l := NewMemoryLocker()
+	unlock, err := l.TryLock(context.Background(), apiKey, upgradeJob)
+	require.NoError(t, err)
+	defer unlock()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err = Lock(ctx, l, apiKey, Holder{Action: "uninstall"})
+
+	assert.True(t, errors.Is(err, ErrLocked))
+}
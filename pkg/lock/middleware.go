@@ -0,0 +1,134 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/auth"
+	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/operation"
+	"github.com/gojekfarm/albatross/pkg/upload"
+)
+
+// replica names the albatross instance in the holders of its locks
+var replica, _ = os.Hostname()
+
+// Middleware holds the lock of the release of the request while next serves it, and answers 409 Conflict
+// with the holder when another request holds it. With a queue timeout, requests wait up to that long
+// for the lock instead.
+// The cluster, namespace and release come from the route variables, install requests name their release in the body,
+// which is answered with 413 Request Entity Too Large when it is larger than upload.MaxBodySize.
+func Middleware(l Locker, action string, queueTimeout time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			key, err := newKey(w, r)
+			if errors.Is(err, upload.ErrBodyTooLarge) {
+				apiErrors.Write(w, err)
+				return
+			}
+			if err != nil || key.Release == "" {
+				// the handler rejects requests without a release
+				next.ServeHTTP(w, r)
+				return
+			}
+			if h, ok := r.Context().Value(heldKey{}).(*held); ok && h.key == key {
+				// the lock was taken before the request was queued as an operation
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			unlock, err := lock(r.Context(), l, key, newHolder(r.Context(), action), queueTimeout)
+			if err != nil {
+				respondLockError(w, r, err)
+				return
+			}
+			h := &held{key: key, unlock: unlock}
+			next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), heldKey{}, h)))
+			if !h.kept {
+				unlock()
+			}
+		})
+	}
+}
+
+type heldKey struct{}
+
+// held is the lock the middleware took for a request
+type held struct {
+	key    Key
+	unlock func()
+	kept   bool
+}
+
+// Keep keeps the lock the middleware took for the request of ctx once the request is served,
+// for work that goes on in the background, such as an operation. The lock middleware of that work
+// then lets it through. The returned function releases the lock, it does nothing when no lock was taken.
+func Keep(ctx context.Context) func() {
+	h, ok := ctx.Value(heldKey{}).(*held)
+	if !ok || h.kept {
+		return func() {}
+	}
+	h.kept = true
+	var once sync.Once
+	return func() { once.Do(h.unlock) }
+}
+
+func lock(ctx context.Context, l Locker, key Key, holder Holder, queueTimeout time.Duration) (func(), error) {
+	if queueTimeout <= 0 {
+		return l.TryLock(ctx, key, holder)
+	}
+	// the timeout only bounds the wait, the lock is held until the request is served once taken
+	ctx, cancel := context.WithTimeout(ctx, queueTimeout)
+	defer cancel()
+	return Lock(ctx, l, key, holder)
+}
+
+func newKey(w http.ResponseWriter, r *http.Request) (Key, error) {
+	vars := mux.Vars(r)
+	key := Key{Cluster: vars["cluster"], Namespace: vars["namespace"], Release: vars["release_name"]}
+	if key.Release != "" {
+		return key, nil
+	}
+
+	body, err := upload.ReadBody(w, r)
+	if err != nil || body == nil {
+		return key, err
+	}
+	var named struct {
+		Name string `json:"name"`
+	}
+	// malformed bodies are rejected by the handler
+	if json.Unmarshal(upload.RequestJSON(r, body), &named) == nil {
+		key.Release = named.Name
+	}
+	return key, nil
+}
+
+func newHolder(ctx context.Context, action string) Holder {
+	h := Holder{Action: action, Replica: replica, AcquiredAt: time.Now()}
+	if p, ok := auth.FromContext(ctx); ok {
+		h.Principal = p.Name
+	}
+	if id, ok := operation.IDFromContext(ctx); ok {
+		h.OperationID = id
+	}
+	return h
+}
+
+// respondLockError answers a held lock with a conflict detailing its holder
+func respondLockError(w http.ResponseWriter, r *http.Request, err error) {
+	logger.Errorf("[Lock] refusing %s %s: %v", r.Method, r.URL.Path, err)
+	var locked *LockedError
+	if errors.As(err, &locked) {
+		err = apiErrors.Wrap(apiErrors.Conflict, err).WithDetails(locked.Holder)
+	}
+	apiErrors.Write(w, err)
+}
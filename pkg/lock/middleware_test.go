@@ -0,0 +1,118 @@
+package lock
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/upload"
+)
+
+func newRouter(l Locker, queueTimeout time.Duration, handler http.HandlerFunc) *mux.Router {
+	router := mux.NewRouter()
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases", Middleware(l, "install", queueTimeout)(handler)).Methods(http.MethodPost)
+	router.Handle("/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}", Middleware(l, "upgrade", queueTimeout)(handler)).Methods(http.MethodPut)
+	return router
+}
+
+func TestMiddlewareHoldsTheLockOfTheReleaseWhileServing(t *testing.T) {
+	logger.Setup("default")
+	l := NewMemoryLocker()
+	var body string
+	router := newRouter(l, 0, func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		body = string(b)
+		_, err := l.TryLock(r.Context(), apiKey, Holder{})
+		assert.Error(t, err)
+	})
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/clusters/staging/namespaces/payments/releases", strings.NewReader(`{"name":"api"}`)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, `{"name":"api"}`, body)
+	unlock, err := l.TryLock(context.Background(), apiKey, Holder{})
+	require.NoError(t, err)
+	unlock()
+}
+
+func TestMiddlewareAnswersConflictWithTheHolder(t *testing.T) {
+	logger.Setup("default")
+	l := NewMemoryLocker()
+	unlock, err := l.TryLock(context.Background(), apiKey, upgradeJob)
+	require.NoError(t, err)
+	defer unlock()
+	router := newRouter(l, 0, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called")
+	})
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/clusters/staging/namespaces/payments/releases/api", strings.NewReader(`{}`)))
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var resp struct {
+		Code    apiErrors.Code `json:"code"`
+		Message string         `json:"message"`
+		Details Holder         `json:"details"`
+	}
+	require.NoError(t, json.NewDecoder(w.Body).Decode(&resp))
+	assert.Equal(t, apiErrors.Conflict, resp.Code)
+	assert.Equal(t, upgradeJob, resp.Details)
+	assert.Contains(t, resp.Message, "release staging/payments/api is locked by upgrade operation 42")
+}
+
+func TestMiddlewareQueuesRequestsWithAQueueTimeout(t *testing.T) {
+	defer func(interval time.Duration) { retryInterval = interval }(retryInterval)
+	retryInterval = time.Millisecond
+	logger.Setup("default")
+	l := NewMemoryLocker()
+	unlock, err := l.TryLock(context.Background(), apiKey, upgradeJob)
+	require.NoError(t, err)
+	time.AfterFunc(10*time.Millisecond, unlock)
+	served := false
+	router := newRouter(l, time.Second, func(w http.ResponseWriter, r *http.Request) {
+		served = true
+	})
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/clusters/staging/namespaces/payments/releases/api", strings.NewReader(`{}`)))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.True(t, served)
+}
+
+func TestMiddlewareRejectsBodiesLargerThanAnUpload(t *testing.T) {
+	logger.Setup("default")
+	router := newRouter(NewMemoryLocker(), 0, func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called")
+	})
+	w := httptest.NewRecorder()
+	body := `{"name":"api","values":"` + strings.Repeat("x", upload.MaxBodySize) + `"}`
+
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/clusters/staging/namespaces/payments/releases", strings.NewReader(body)))
+
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestMiddlewareLeavesRequestsWithoutReleaseToTheHandler(t *testing.T) {
+	logger.Setup("default")
+	router := newRouter(NewMemoryLocker(), 0, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	})
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/clusters/staging/namespaces/payments/releases", strings.NewReader(`{"chart":"stable/mysql"}`)))
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
@@ -22,8 +22,31 @@ func Infof(fmt string, args ...interface{}) {
 	log.Infof(fmt, args...)
 }
 
+// Configure replaces the logger with one logging at level, debug, info, warn, error or none,
+// in format, json or console
+func Configure(level, format string) error {
+	if level == "none" {
+		log = zap.NewNop().Sugar()
+		return nil
+	}
+	cfg := zap.NewProductionConfig()
+	if err := cfg.Level.UnmarshalText([]byte(level)); err != nil {
+		return err
+	}
+	if format == "console" {
+		cfg.Encoding = "console"
+		cfg.EncoderConfig = zap.NewDevelopmentEncoderConfig()
+	}
+	zlog, err := cfg.Build()
+	if err != nil {
+		return err
+	}
+	log = zlog.Sugar()
+	return nil
+}
+
+// Setup sets up the logger once, tests call it to log at level
 func Setup(level string) {
-	//TODO: map from env config
 	var zlog *zap.Logger
 	switch level {
 	case "debug":
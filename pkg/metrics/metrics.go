@@ -0,0 +1,173 @@
+// Package metrics exposes the prometheus metrics of albatross: the HTTP requests by route,
+// the helm actions by cluster and outcome and the refreshes of repository indexes.
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/gojekfarm/albatross/internal/response"
+)
+
+const (
+	namespace = "albatross"
+
+	// Success is the outcome of actions answered with a status below 400
+	Success = "success"
+	// Failure is the outcome of the other actions
+	Failure = "failure"
+)
+
+// helm actions wait for resources, so their buckets reach further than the request defaults
+var actionBuckets = []float64{0.1, 0.5, 1, 2.5, 5, 10, 30, 60, 120, 300, 600}
+
+// Metrics holds the collectors of albatross in their own registry
+type Metrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+
+	actions        *prometheus.CounterVec
+	actionDuration *prometheus.HistogramVec
+	actionsRunning *prometheus.GaugeVec
+
+	refreshes       *prometheus.CounterVec
+	refreshDuration *prometheus.HistogramVec
+	lastRefresh     *prometheus.GaugeVec
+}
+
+// New returns the metrics of albatross registered with the go and process collectors
+func New() *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by route template, method and status code.",
+		}, []string{"route", "method", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "Latency of HTTP requests by route template, method and status code.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"route", "method", "code"}),
+		actions: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "helm",
+			Name:      "actions_total",
+			Help:      "Helm actions by action, cluster and outcome.",
+		}, []string{"action", "cluster", "outcome"}),
+		actionDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "helm",
+			Name:      "action_duration_seconds",
+			Help:      "Duration of helm actions by action, cluster and outcome.",
+			Buckets:   actionBuckets,
+		}, []string{"action", "cluster", "outcome"}),
+		actionsRunning: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "helm",
+			Name:      "actions_in_flight",
+			Help:      "Helm actions running, including the ones of background operations.",
+		}, []string{"action", "cluster"}),
+		refreshes: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "index_refreshes_total",
+			Help:      "Refreshes of repository index files by repository and outcome.",
+		}, []string{"repository", "outcome"}),
+		refreshDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "index_refresh_duration_seconds",
+			Help:      "Duration of repository index downloads by repository.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"repository"}),
+		lastRefresh: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Namespace: namespace,
+			Subsystem: "repository",
+			Name:      "index_last_success_timestamp_seconds",
+			Help:      "Unix time of the last successful refresh of a repository index.",
+		}, []string{"repository"}),
+	}
+	m.registry.MustRegister(
+		prometheus.NewGoCollector(),
+		prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}),
+		m.requests, m.requestDuration,
+		m.actions, m.actionDuration, m.actionsRunning,
+		m.refreshes, m.refreshDuration, m.lastRefresh,
+	)
+	return m
+}
+
+// Handler serves the metrics in the prometheus exposition format
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware counts and times the requests of a router by route template, so release names do not
+// become labels. It is installed with Router.Use, which only runs it for matched routes.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rw := response.Wrap(w, 0)
+		next.ServeHTTP(rw, r)
+
+		labels := prometheus.Labels{"route": routeTemplate(r), "method": r.Method, "code": strconv.Itoa(rw.Status())}
+		m.requests.With(labels).Inc()
+		m.requestDuration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
+
+// Action records the helm action served by next, labelled with the cluster of the route.
+// Like the audit middleware it is installed inside asynchronous operations, so it times the action itself.
+func (m *Metrics) Action(action string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cluster := mux.Vars(r)["cluster"]
+			running := m.actionsRunning.WithLabelValues(action, cluster)
+			running.Inc()
+			defer running.Dec()
+
+			start := time.Now()
+			rw := response.Wrap(w, 0)
+			next.ServeHTTP(rw, r)
+
+			outcome := Success
+			if rw.Status() >= http.StatusBadRequest {
+				outcome = Failure
+			}
+			m.actions.WithLabelValues(action, cluster, outcome).Inc()
+			m.actionDuration.WithLabelValues(action, cluster, outcome).Observe(time.Since(start).Seconds())
+		})
+	}
+}
+
+// ObserveRefresh records the download of the index of a repository
+func (m *Metrics) ObserveRefresh(repository string, duration time.Duration, err error) {
+	outcome := Success
+	if err != nil {
+		outcome = Failure
+	} else {
+		m.lastRefresh.WithLabelValues(repository).SetToCurrentTime()
+	}
+	m.refreshes.WithLabelValues(repository, outcome).Inc()
+	m.refreshDuration.WithLabelValues(repository).Observe(duration.Seconds())
+}
+
+func routeTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return "unmatched"
+}
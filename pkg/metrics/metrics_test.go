@@ -0,0 +1,115 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/helmcli/repository"
+)
+
+const releasePath = "/clusters/{cluster}/namespaces/{namespace}/releases/{release_name}"
+
+func serve(router *mux.Router, method, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(method, target, nil))
+	return w
+}
+
+func TestMiddlewareCountsRequestsByRouteTemplate(t *testing.T) {
+	m := New()
+	router := mux.NewRouter()
+	router.Use(m.Middleware)
+	router.HandleFunc(releasePath, func(w http.ResponseWriter, r *http.Request) {
+		if mux.Vars(r)["release_name"] == "missing" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(`{}`))
+	}).Methods(http.MethodGet)
+
+	serve(router, http.MethodGet, "/clusters/staging/namespaces/default/releases/mysql")
+	serve(router, http.MethodGet, "/clusters/staging/namespaces/default/releases/redis")
+	serve(router, http.MethodGet, "/clusters/staging/namespaces/default/releases/missing")
+
+	assert.Equal(t, float64(2), testutil.ToFloat64(m.requests.WithLabelValues(releasePath, http.MethodGet, "200")))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.requests.WithLabelValues(releasePath, http.MethodGet, "404")))
+}
+
+func TestActionRecordsOutcomeByCluster(t *testing.T) {
+	m := New()
+	router := mux.NewRouter()
+	var running float64
+	router.Handle(releasePath, m.Action("upgrade")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		running = testutil.ToFloat64(m.actionsRunning.WithLabelValues("upgrade", mux.Vars(r)["cluster"]))
+		if mux.Vars(r)["cluster"] == "production" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))).Methods(http.MethodPut)
+
+	serve(router, http.MethodPut, "/clusters/staging/namespaces/default/releases/mysql")
+	assert.Equal(t, float64(1), running)
+	serve(router, http.MethodPut, "/clusters/production/namespaces/default/releases/mysql")
+
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.actions.WithLabelValues("upgrade", "staging", Success)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.actions.WithLabelValues("upgrade", "staging", Failure)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.actions.WithLabelValues("upgrade", "production", Failure)))
+	assert.Equal(t, float64(0), testutil.ToFloat64(m.actionsRunning.WithLabelValues("upgrade", "staging")))
+}
+
+type stubClient struct {
+	repository.Client
+	results []repository.UpdateResult
+}
+
+func (c stubClient) NewUpdater(flags.RepoUpdateFlags) (repository.Updater, error) {
+	return stubUpdater(c.results), nil
+}
+
+type stubUpdater []repository.UpdateResult
+
+func (u stubUpdater) Update(context.Context) ([]repository.UpdateResult, error) {
+	return u, nil
+}
+
+func TestRepositoriesRecordsIndexRefreshes(t *testing.T) {
+	m := New()
+	client := m.Repositories(stubClient{results: []repository.UpdateResult{
+		{Name: "stable", Duration: time.Second},
+		{Name: "private", Err: errors.New("401 Unauthorized"), Duration: time.Second},
+	}})
+	u, err := client.NewUpdater(flags.RepoUpdateFlags{})
+	require.NoError(t, err)
+
+	results, err := u.Update(context.Background())
+
+	require.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.refreshes.WithLabelValues("stable", Success)))
+	assert.Equal(t, float64(1), testutil.ToFloat64(m.refreshes.WithLabelValues("private", Failure)))
+	assert.NotZero(t, testutil.ToFloat64(m.lastRefresh.WithLabelValues("stable")))
+	assert.Zero(t, testutil.ToFloat64(m.lastRefresh.WithLabelValues("private")))
+}
+
+func TestHandlerServesMetrics(t *testing.T) {
+	m := New()
+	m.ObserveRefresh("stable", time.Second, nil)
+	w := httptest.NewRecorder()
+
+	m.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	body, _ := ioutil.ReadAll(w.Body)
+	assert.Contains(t, string(body), `albatross_repository_index_refreshes_total{outcome="success",repository="stable"} 1`)
+	assert.Contains(t, string(body), "go_goroutines")
+}
@@ -0,0 +1,39 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/gojekfarm/albatross/pkg/helmcli/flags"
+	"github.com/gojekfarm/albatross/pkg/helmcli/repository"
+)
+
+// Repositories records the index refreshes of the updaters of client
+func (m *Metrics) Repositories(client repository.Client) repository.Client {
+	return repoClient{Client: client, metrics: m}
+}
+
+type repoClient struct {
+	repository.Client
+	metrics *Metrics
+}
+
+func (c repoClient) NewUpdater(updateFlags flags.RepoUpdateFlags) (repository.Updater, error) {
+	u, err := c.Client.NewUpdater(updateFlags)
+	if err != nil {
+		return nil, err
+	}
+	return updater{Updater: u, metrics: c.metrics}, nil
+}
+
+type updater struct {
+	repository.Updater
+	metrics *Metrics
+}
+
+func (u updater) Update(ctx context.Context) ([]repository.UpdateResult, error) {
+	results, err := u.Updater.Update(ctx)
+	for _, r := range results {
+		u.metrics.ObserveRefresh(r.Name, r.Duration, r.Err)
+	}
+	return results, err
+}
@@ -0,0 +1,282 @@
+package operation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// State is the lifecycle state of an operation
+type State string
+
+const (
+	// Pending operations are queued and waiting for a free worker
+	Pending State = "pending"
+	// Running operations are being executed by a worker
+	Running State = "running"
+	// Succeeded operations finished without an error
+	Succeeded State = "succeeded"
+	// Failed operations finished with an error
+	Failed State = "failed"
+	// Cancelled operations were cancelled before they started
+	Cancelled State = "cancelled"
+)
+
+var (
+	// ErrNotFound is returned for unknown operation ids
+	ErrNotFound = errors.New("operation not found")
+	// ErrQueueFull is returned when no more operations can be queued
+	ErrQueueFull = errors.New("operation queue is full")
+	// ErrFinished is returned when cancelling an operation that already finished
+	ErrFinished = errors.New("operation already finished")
+	// ErrRunning is returned when cancelling an operation that already started.
+	// helm actions do not stop when their context is cancelled, so a running operation always runs to its end.
+	ErrRunning = errors.New("operation already started, only pending operations can be cancelled")
+)
+
+// Func is the work done by an operation, ctx is cancelled once it returns
+type Func func(ctx context.Context) (interface{}, error)
+
+// Operation is a snapshot of a unit of work submitted to the manager
+type Operation struct {
+	ID   string
+	Kind string
+	// Owner identifies who submitted the operation, empty when requests are not authenticated
+	Owner      string
+	State      State
+	CreatedAt  time.Time
+	StartedAt  time.Time
+	FinishedAt time.Time
+	Result     interface{}
+	Error      string
+}
+
+// Finished tells whether the operation reached a final state
+func (o Operation) Finished() bool {
+	return o.State == Succeeded || o.State == Failed || o.State == Cancelled
+}
+
+type idKey struct{}
+
+// IDFromContext returns the id of the operation whose function runs with ctx, or that is about to be submitted with it
+func IDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(idKey{}).(string)
+	return id, ok
+}
+
+// NewContext returns ctx with the id of an operation about to be submitted with it, which Submit then gives to the operation.
+// Work done before the operation is queued, such as taking locks, can refer to the operation by that id.
+func NewContext(ctx context.Context) (context.Context, error) {
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+	return context.WithValue(ctx, idKey{}, id), nil
+}
+
+type entry struct {
+	op     Operation
+	ctx    context.Context
+	cancel context.CancelFunc
+	fn     Func
+	// done is closed once the operation finished
+	done chan struct{}
+}
+
+// Manager runs operations on a fixed pool of workers and keeps their state in memory
+type Manager struct {
+	mu         sync.RWMutex
+	operations map[string]*entry
+	queue      chan *entry
+	retention  time.Duration
+	now        func() time.Time
+}
+
+// NewManager starts a manager with the given number of workers.
+// At most queueSize operations wait for a worker, finished operations are forgotten after retention.
+func NewManager(workers, queueSize int, retention time.Duration) *Manager {
+	m := &Manager{
+		operations: map[string]*entry{},
+		queue:      make(chan *entry, queueSize),
+		retention:  retention,
+		now:        time.Now,
+	}
+	for i := 0; i < workers; i++ {
+		go m.work()
+	}
+	return m
+}
+
+// Submit queues fn for execution on behalf of owner, with the id of ctx when it comes from NewContext.
+// The context passed to fn keeps the values of ctx and carries the operation id,
+// but is only cancelled through Cancel, so the operation outlives the request that submitted it.
+func (m *Manager) Submit(ctx context.Context, kind, owner string, fn Func) (Operation, error) {
+	id, ok := IDFromContext(ctx)
+	if !ok {
+		var err error
+		if id, err = newID(); err != nil {
+			return Operation{}, err
+		}
+	}
+	opCtx, cancel := context.WithCancel(context.WithValue(detach(ctx), idKey{}, id))
+	e := &entry{
+		op:     Operation{ID: id, Kind: kind, Owner: owner, State: Pending, CreatedAt: m.now()},
+		ctx:    opCtx,
+		cancel: cancel,
+		fn:     fn,
+		done:   make(chan struct{}),
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.purge()
+	if _, ok := m.operations[id]; ok {
+		cancel()
+		return Operation{}, fmt.Errorf("operation %s was already submitted", id)
+	}
+	select {
+	case m.queue <- e:
+	default:
+		cancel()
+		return Operation{}, ErrQueueFull
+	}
+	m.operations[id] = e
+	return e.op, nil
+}
+
+// Get returns the current state of an operation
+func (m *Manager) Get(id string) (Operation, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	e, ok := m.operations[id]
+	if !ok {
+		return Operation{}, ErrNotFound
+	}
+	return e.op, nil
+}
+
+// Running returns the operations that have not finished yet, pending or running, oldest first
+func (m *Manager) Running() []Operation {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var running []Operation
+	for _, e := range m.operations {
+		if !e.op.Finished() {
+			running = append(running, e.op)
+		}
+	}
+	sort.Slice(running, func(i, j int) bool {
+		return running[i].CreatedAt.Before(running[j].CreatedAt)
+	})
+	return running
+}
+
+// Cancel cancels a pending operation so that it never starts, operations that already started cannot be cancelled
+func (m *Manager) Cancel(id string) (Operation, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e, ok := m.operations[id]
+	if !ok {
+		return Operation{}, ErrNotFound
+	}
+	if e.op.Finished() {
+		return e.op, ErrFinished
+	}
+	if e.op.State == Running {
+		return e.op, ErrRunning
+	}
+	e.cancel()
+	e.op.State = Cancelled
+	e.op.Error = context.Canceled.Error()
+	e.op.FinishedAt = m.now()
+	close(e.done)
+	return e.op, nil
+}
+
+// Done returns a channel closed once the operation finished, including when it was cancelled before it started.
+// The channel of an unknown id is nil.
+func (m *Manager) Done(id string) <-chan struct{} {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	e, ok := m.operations[id]
+	if !ok {
+		return nil
+	}
+	return e.done
+}
+
+func (m *Manager) work() {
+	for e := range m.queue {
+		if !m.start(e) {
+			continue
+		}
+		result, err := e.fn(e.ctx)
+		m.finish(e, result, err)
+	}
+}
+
+func (m *Manager) start(e *entry) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if e.op.State != Pending {
+		return false
+	}
+	e.op.State = Running
+	e.op.StartedAt = m.now()
+	return true
+}
+
+func (m *Manager) finish(e *entry, result interface{}, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	e.op.Result = result
+	e.op.FinishedAt = m.now()
+	if err != nil {
+		e.op.State = Failed
+		e.op.Error = err.Error()
+	} else {
+		e.op.State = Succeeded
+	}
+	e.cancel()
+	close(e.done)
+}
+
+// purge drops finished operations older than the retention, callers must hold the lock
+func (m *Manager) purge() {
+	if m.retention <= 0 {
+		return
+	}
+	cutoff := m.now().Add(-m.retention)
+	for id, e := range m.operations {
+		if e.op.Finished() && e.op.FinishedAt.Before(cutoff) {
+			delete(m.operations, id)
+		}
+	}
+}
+
+func newID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// detachedContext carries the values of its parent without its deadline or cancellation
+type detachedContext struct {
+	context.Context
+	parent context.Context
+}
+
+func detach(ctx context.Context) context.Context {
+	return detachedContext{Context: context.Background(), parent: ctx}
+}
+
+func (d detachedContext) Value(key interface{}) interface{} {
+	return d.parent.Value(key)
+}
@@ -0,0 +1,222 @@
+package operation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type ctxKey struct{}
+
+func waitFor(t *testing.T, m *Manager, id string, state State) Operation {
+	t.Helper()
+	var op Operation
+	require.Eventually(t, func() bool {
+		var err error
+		op, err = m.Get(id)
+		require.NoError(t, err)
+		return op.State == state
+	}, time.Second, time.Millisecond)
+	return op
+}
+
+func TestShouldRunOperationToSuccess(t *testing.T) {
+	m := NewManager(1, 1, time.Hour)
+	ctx := context.WithValue(context.Background(), ctxKey{}, "value")
+
+	var id string
+	op, err := m.Submit(ctx, "install", "", func(ctx context.Context) (interface{}, error) {
+		id, _ = IDFromContext(ctx)
+		return ctx.Value(ctxKey{}), nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, Pending, op.State)
+	assert.Equal(t, "install", op.Kind)
+	assert.NotEmpty(t, op.ID)
+	done := waitFor(t, m, op.ID, Succeeded)
+	assert.Equal(t, "value", done.Result)
+	assert.Equal(t, op.ID, id)
+	assert.Empty(t, done.Error)
+	assert.False(t, done.StartedAt.IsZero())
+	assert.False(t, done.FinishedAt.IsZero())
+}
+
+func TestShouldSubmitWithTheIDOfTheContext(t *testing.T) {
+	m := NewManager(1, 1, time.Hour)
+	ctx, err := NewContext(context.Background())
+	require.NoError(t, err)
+	id, _ := IDFromContext(ctx)
+
+	op, err := m.Submit(ctx, "install", "", func(ctx context.Context) (interface{}, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, id, op.ID)
+	waitFor(t, m, op.ID, Succeeded)
+
+	_, err = m.Submit(ctx, "install", "", func(ctx context.Context) (interface{}, error) {
+		return nil, nil
+	})
+	assert.Error(t, err)
+}
+
+func TestShouldRecordFailedOperation(t *testing.T) {
+	m := NewManager(1, 1, time.Hour)
+
+	op, err := m.Submit(context.Background(), "upgrade", "", func(ctx context.Context) (interface{}, error) {
+		return "partial", errors.New("upgrade failed")
+	})
+
+	require.NoError(t, err)
+	done := waitFor(t, m, op.ID, Failed)
+	assert.Equal(t, "upgrade failed", done.Error)
+	assert.Equal(t, "partial", done.Result)
+}
+
+func TestShouldNotCancelOperationWhenSubmittingContextIsDone(t *testing.T) {
+	m := NewManager(1, 1, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	op, err := m.Submit(ctx, "install", "", func(ctx context.Context) (interface{}, error) {
+		return nil, ctx.Err()
+	})
+
+	require.NoError(t, err)
+	waitFor(t, m, op.ID, Succeeded)
+}
+
+func TestShouldNotCancelRunningOperation(t *testing.T) {
+	m := NewManager(1, 1, time.Hour)
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	op, err := m.Submit(context.Background(), "install", "", func(ctx context.Context) (interface{}, error) {
+		close(started)
+		<-release
+		return nil, ctx.Err()
+	})
+	require.NoError(t, err)
+	<-started
+
+	running, err := m.Cancel(op.ID)
+	close(release)
+
+	assert.Equal(t, ErrRunning, err)
+	assert.Equal(t, Running, running.State)
+	done := waitFor(t, m, op.ID, Succeeded)
+	assert.Empty(t, done.Error)
+}
+
+func TestShouldCancelPendingOperationWithoutRunningIt(t *testing.T) {
+	m := NewManager(1, 2, time.Hour)
+	release := make(chan struct{})
+	blocking, err := m.Submit(context.Background(), "install", "", func(ctx context.Context) (interface{}, error) {
+		<-release
+		return nil, nil
+	})
+	require.NoError(t, err)
+	ran := false
+	pending, err := m.Submit(context.Background(), "install", "", func(ctx context.Context) (interface{}, error) {
+		ran = true
+		return nil, nil
+	})
+	require.NoError(t, err)
+
+	cancelled, err := m.Cancel(pending.ID)
+	close(release)
+
+	require.NoError(t, err)
+	assert.Equal(t, Cancelled, cancelled.State)
+	waitFor(t, m, blocking.ID, Succeeded)
+	assert.False(t, ran)
+	for _, id := range []string{pending.ID, blocking.ID} {
+		select {
+		case <-m.Done(id):
+		default:
+			t.Errorf("operation %s is not done", id)
+		}
+	}
+}
+
+func TestShouldNotCancelFinishedOperation(t *testing.T) {
+	m := NewManager(1, 1, time.Hour)
+	op, err := m.Submit(context.Background(), "install", "", func(ctx context.Context) (interface{}, error) {
+		return nil, nil
+	})
+	require.NoError(t, err)
+	waitFor(t, m, op.ID, Succeeded)
+
+	_, err = m.Cancel(op.ID)
+
+	assert.Equal(t, ErrFinished, err)
+}
+
+func TestShouldRejectOperationsWhenQueueIsFull(t *testing.T) {
+	m := NewManager(0, 1, time.Hour)
+	noop := func(ctx context.Context) (interface{}, error) { return nil, nil }
+	_, err := m.Submit(context.Background(), "install", "", noop)
+	require.NoError(t, err)
+
+	_, err = m.Submit(context.Background(), "install", "", noop)
+
+	assert.Equal(t, ErrQueueFull, err)
+}
+
+func TestShouldForgetFinishedOperationsAfterRetention(t *testing.T) {
+	m := NewManager(1, 2, time.Minute)
+	now := time.Now()
+	m.now = func() time.Time { return now }
+	noop := func(ctx context.Context) (interface{}, error) { return nil, nil }
+	op, err := m.Submit(context.Background(), "install", "", noop)
+	require.NoError(t, err)
+	waitFor(t, m, op.ID, Succeeded)
+
+	now = now.Add(2 * time.Minute)
+	_, err = m.Submit(context.Background(), "install", "", noop)
+	require.NoError(t, err)
+
+	_, err = m.Get(op.ID)
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestShouldReturnNotFoundForUnknownOperation(t *testing.T) {
+	m := NewManager(1, 1, time.Hour)
+
+	_, err := m.Get("unknown")
+	assert.Equal(t, ErrNotFound, err)
+
+	_, err = m.Cancel("unknown")
+	assert.Equal(t, ErrNotFound, err)
+}
+
+func TestShouldListUnfinishedOperations(t *testing.T) {
+	m := NewManager(1, 2, time.Hour)
+	noop := func(ctx context.Context) (interface{}, error) { return nil, nil }
+	finished, err := m.Submit(context.Background(), "install", "", noop)
+	require.NoError(t, err)
+	waitFor(t, m, finished.ID, Succeeded)
+	release := make(chan struct{})
+	defer close(release)
+	running, err := m.Submit(context.Background(), "upgrade", "", func(ctx context.Context) (interface{}, error) {
+		<-release
+		return nil, nil
+	})
+	require.NoError(t, err)
+	waitFor(t, m, running.ID, Running)
+	pending, err := m.Submit(context.Background(), "uninstall", "", noop)
+	require.NoError(t, err)
+
+	unfinished := m.Running()
+
+	require.Len(t, unfinished, 2)
+	assert.Equal(t, running.ID, unfinished[0].ID)
+	assert.Equal(t, Running, unfinished[0].State)
+	assert.Equal(t, pending.ID, unfinished[1].ID)
+	assert.Equal(t, Pending, unfinished[1].State)
+}
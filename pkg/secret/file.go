@@ -0,0 +1,197 @@
+package secret
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofrs/flock"
+
+	"github.com/gojekfarm/albatross/pkg/logger"
+)
+
+const (
+	keySize     = 32
+	lockTimeout = 30 * time.Second
+)
+
+// FileStore keeps credentials in a file encrypted with AES-256-GCM
+type FileStore struct {
+	path string
+	aead cipher.AEAD
+	mu   sync.Mutex
+}
+
+type fileContent struct {
+	Credentials map[string]Credentials `json:"credentials"`
+}
+
+// NewFileStore returns a store encrypting path with the key in keyFile.
+// The key file holds 32 bytes, raw or base64 encoded.
+func NewFileStore(path, keyFile string) (*FileStore, error) {
+	b, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := parseKey(b)
+	if err != nil {
+		return nil, fmt.Errorf("invalid key in %s: %w", keyFile, err)
+	}
+	return newFileStore(path, key)
+}
+
+func newFileStore(path string, key []byte) (*FileStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	s := &FileStore{path: path, aead: aead}
+	// fail at startup rather than on the first request when the key does not match the file
+	if _, err := s.read(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func parseKey(b []byte) ([]byte, error) {
+	if len(b) == keySize {
+		return b, nil
+	}
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(b)))
+	if err != nil || len(key) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, raw or base64 encoded", keySize)
+	}
+	return key, nil
+}
+
+// Get returns the credentials stored under name
+func (s *FileStore) Get(ctx context.Context, name string) (Credentials, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	content, err := s.read()
+	if err != nil {
+		return Credentials{}, err
+	}
+	c, ok := content.Credentials[name]
+	if !ok {
+		return Credentials{}, ErrNotFound
+	}
+	return c, nil
+}
+
+// Put stores the credentials under name, replacing existing ones
+func (s *FileStore) Put(ctx context.Context, name string, c Credentials) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	return s.update(ctx, func(content *fileContent) error {
+		content.Credentials[name] = c
+		return nil
+	})
+}
+
+// Delete removes the credentials stored under name
+func (s *FileStore) Delete(ctx context.Context, name string) error {
+	return s.update(ctx, func(content *fileContent) error {
+		if _, ok := content.Credentials[name]; !ok {
+			return ErrNotFound
+		}
+		delete(content.Credentials, name)
+		return nil
+	})
+}
+
+func (s *FileStore) update(ctx context.Context, fn func(*fileContent) error) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := os.MkdirAll(filepath.Dir(s.path), os.ModePerm); err != nil && !os.IsExist(err) {
+		return err
+	}
+	// Acquire a file lock for process synchronization
+	fileLock := flock.New(strings.Replace(s.path, filepath.Ext(s.path), ".lock", 1))
+	lockCtx, cancel := context.WithTimeout(ctx, lockTimeout)
+	defer cancel()
+	locked, err := fileLock.TryLockContext(lockCtx, time.Second)
+	if err != nil {
+		return err
+	}
+	if locked {
+		defer func() {
+			if err := fileLock.Unlock(); err != nil {
+				logger.Errorf("[Secret] error unlocking %s: %v", s.path, err)
+			}
+		}()
+	}
+
+	content, err := s.read()
+	if err != nil {
+		return err
+	}
+	if err := fn(content); err != nil {
+		return err
+	}
+	return s.write(content)
+}
+
+func (s *FileStore) read() (*fileContent, error) {
+	content := &fileContent{Credentials: map[string]Credentials{}}
+	b, err := ioutil.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return content, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := s.aead.NonceSize()
+	if len(b) < nonceSize {
+		return nil, fmt.Errorf("%s is not a credentials file", s.path)
+	}
+	plain, err := s.aead.Open(nil, b[:nonceSize], b[nonceSize:], nil)
+	if err != nil {
+		return nil, errors.New("error decrypting " + s.path + ", the key does not match")
+	}
+	if err := json.Unmarshal(plain, content); err != nil {
+		return nil, err
+	}
+	if content.Credentials == nil {
+		content.Credentials = map[string]Credentials{}
+	}
+	return content, nil
+}
+
+// write encrypts the content with a fresh nonce and replaces the file atomically
+func (s *FileStore) write(content *fileContent) error {
+	plain, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	sealed := s.aead.Seal(nonce, nonce, plain, nil)
+
+	tmp := s.path + ".tmp"
+	if err := ioutil.WriteFile(tmp, sealed, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
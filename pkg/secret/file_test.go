@@ -0,0 +1,77 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var credentials = Credentials{Username: "admin", Password: "s3cr3t"}
+
+func TestFileStoreEncryptsCredentials(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	keyFile := filepath.Join(dir, "key")
+	key := bytes.Repeat([]byte{7}, keySize)
+	require.NoError(t, ioutil.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600))
+	path := filepath.Join(dir, "credentials.enc")
+
+	store, err := NewFileStore(path, keyFile)
+	require.NoError(t, err)
+	require.NoError(t, store.Put(context.Background(), "private", credentials))
+
+	content, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	assert.NotContains(t, string(content), "s3cr3t")
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	assert.Equal(t, os.FileMode(0600), info.Mode().Perm())
+
+	reloaded, err := NewFileStore(path, keyFile)
+	require.NoError(t, err)
+	got, err := reloaded.Get(context.Background(), "private")
+	require.NoError(t, err)
+	assert.Equal(t, credentials, got)
+
+	require.NoError(t, reloaded.Delete(context.Background(), "private"))
+	_, err = store.Get(context.Background(), "private")
+	assert.Equal(t, ErrNotFound, err)
+	assert.Equal(t, ErrNotFound, store.Delete(context.Background(), "private"))
+}
+
+func TestFileStoreRejectsWrongKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "credentials.enc")
+	store, err := newFileStore(path, bytes.Repeat([]byte{1}, keySize))
+	require.NoError(t, err)
+	require.NoError(t, store.Put(context.Background(), "private", credentials))
+
+	_, err = newFileStore(path, bytes.Repeat([]byte{2}, keySize))
+
+	assert.EqualError(t, err, "error decrypting "+path+", the key does not match")
+}
+
+func TestFileStoreRejectsInvalidKeysAndNames(t *testing.T) {
+	_, err := parseKey([]byte("short"))
+	assert.EqualError(t, err, "key must be 32 bytes, raw or base64 encoded")
+
+	dir, err := ioutil.TempDir("", "secrets")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	store, err := newFileStore(filepath.Join(dir, "credentials.enc"), bytes.Repeat([]byte{1}, keySize))
+	require.NoError(t, err)
+
+	err = store.Put(context.Background(), "Private Repo", credentials)
+
+	assert.Error(t, err)
+}
@@ -0,0 +1,82 @@
+package secret
+
+import (
+	"context"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	secretPrefix  = "albatross-credentials-"
+	usernameKey   = "username"
+	passwordKey   = "password"
+	managedByKey  = "app.kubernetes.io/managed-by"
+	managedBy     = "albatross"
+	secretTypeKey = "albatross.gojek.com/type"
+	secretType    = "repository-credentials"
+)
+
+// KubernetesStore keeps credentials in kubernetes secrets of a namespace,
+// credentials named foo are kept in the secret albatross-credentials-foo.
+type KubernetesStore struct {
+	client    kubernetes.Interface
+	namespace string
+}
+
+// NewKubernetesStore returns a store keeping secrets in namespace
+func NewKubernetesStore(client kubernetes.Interface, namespace string) *KubernetesStore {
+	return &KubernetesStore{client: client, namespace: namespace}
+}
+
+// Get returns the credentials stored under name
+func (s *KubernetesStore) Get(ctx context.Context, name string) (Credentials, error) {
+	secret, err := s.client.CoreV1().Secrets(s.namespace).Get(ctx, secretPrefix+name, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return Credentials{}, ErrNotFound
+	}
+	if err != nil {
+		return Credentials{}, err
+	}
+	return Credentials{Username: string(secret.Data[usernameKey]), Password: string(secret.Data[passwordKey])}, nil
+}
+
+// Put creates or updates the secret of name
+func (s *KubernetesStore) Put(ctx context.Context, name string, c Credentials) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      secretPrefix + name,
+			Namespace: s.namespace,
+			Labels:    map[string]string{managedByKey: managedBy, secretTypeKey: secretType},
+		},
+		Type: corev1.SecretTypeOpaque,
+		Data: map[string][]byte{usernameKey: []byte(c.Username), passwordKey: []byte(c.Password)},
+	}
+
+	secrets := s.client.CoreV1().Secrets(s.namespace)
+	_, err := secrets.Create(ctx, secret, metav1.CreateOptions{})
+	if !apierrors.IsAlreadyExists(err) {
+		return err
+	}
+	existing, err := secrets.Get(ctx, secret.Name, metav1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	existing.Data = secret.Data
+	_, err = secrets.Update(ctx, existing, metav1.UpdateOptions{})
+	return err
+}
+
+// Delete removes the secret of name
+func (s *KubernetesStore) Delete(ctx context.Context, name string) error {
+	err := s.client.CoreV1().Secrets(s.namespace).Delete(ctx, secretPrefix+name, metav1.DeleteOptions{})
+	if apierrors.IsNotFound(err) {
+		return ErrNotFound
+	}
+	return err
+}
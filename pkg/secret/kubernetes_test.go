@@ -0,0 +1,32 @@
+package secret
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestKubernetesStoreKeepsCredentialsInSecrets(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	store := NewKubernetesStore(client, "albatross")
+	ctx := context.Background()
+
+	require.NoError(t, store.Put(ctx, "private", Credentials{Username: "admin", Password: "old"}))
+	require.NoError(t, store.Put(ctx, "private", credentials))
+
+	secret, err := client.CoreV1().Secrets("albatross").Get(ctx, "albatross-credentials-private", metav1.GetOptions{})
+	require.NoError(t, err)
+	assert.Equal(t, "s3cr3t", string(secret.Data["password"]))
+	got, err := store.Get(ctx, "private")
+	require.NoError(t, err)
+	assert.Equal(t, credentials, got)
+
+	require.NoError(t, store.Delete(ctx, "private"))
+	_, err = store.Get(ctx, "private")
+	assert.Equal(t, ErrNotFound, err)
+	assert.Equal(t, ErrNotFound, store.Delete(ctx, "private"))
+}
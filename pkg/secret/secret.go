@@ -0,0 +1,39 @@
+package secret
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+)
+
+var (
+	// ErrNotFound is returned for credentials that are not stored
+	ErrNotFound = errors.New("credentials not found")
+	// ErrInvalidName is returned for names that cannot be stored
+	ErrInvalidName = errors.New("invalid credentials name")
+
+	// names are used as part of kubernetes secret names, so they follow the DNS subdomain rules
+	validName = regexp.MustCompile(`^[a-z0-9]([-a-z0-9.]{0,198}[a-z0-9])?$`)
+)
+
+// Credentials are the basic auth credentials of a chart repository
+type Credentials struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+// Store keeps credentials by name
+type Store interface {
+	Get(ctx context.Context, name string) (Credentials, error)
+	Put(ctx context.Context, name string, c Credentials) error
+	Delete(ctx context.Context, name string) error
+}
+
+// ValidateName checks that name can be used with every store
+func ValidateName(name string) error {
+	if !validName.MatchString(name) {
+		return fmt.Errorf("%w: %q must match regex %s", ErrInvalidName, name, validName.String())
+	}
+	return nil
+}
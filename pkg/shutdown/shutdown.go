@@ -0,0 +1,127 @@
+// Package shutdown drains the server before it stops, so helm actions are not killed halfway through
+// and releases are not left pending.
+package shutdown
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	apiErrors "github.com/gojekfarm/albatross/api/errors"
+	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/operation"
+)
+
+// ErrShuttingDown is returned for mutating requests received after the gate closed
+var ErrShuttingDown = errors.New("server is shutting down")
+
+// pollInterval is how often Drain checks whether requests and operations finished
+var pollInterval = 200 * time.Millisecond
+
+// Request is a mutating request being served
+type Request struct {
+	Action  string
+	Method  string
+	Path    string
+	Started time.Time
+}
+
+// Gate keeps track of the mutating requests being served and refuses new ones once closed
+type Gate struct {
+	mu       sync.Mutex
+	closed   bool
+	next     int
+	inFlight map[int]Request
+}
+
+// NewGate returns an open gate
+func NewGate() *Gate {
+	return &Gate{inFlight: map[int]Request{}}
+}
+
+// Middleware serves requests of action while the gate is open and answers 503 Service Unavailable once it is closed.
+// Asynchronous requests are only tracked until their operation is queued, operations are drained separately.
+func (g *Gate) Middleware(action string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id, ok := g.enter(Request{Action: action, Method: r.Method, Path: r.URL.Path, Started: time.Now()})
+			if !ok {
+				respondShuttingDown(w, r)
+				return
+			}
+			defer g.leave(id)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Close refuses every request received afterwards, requests already admitted keep running
+func (g *Gate) Close() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.closed = true
+}
+
+// InFlight returns the requests being served, oldest first
+func (g *Gate) InFlight() []Request {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	requests := make([]Request, 0, len(g.inFlight))
+	for _, r := range g.inFlight {
+		requests = append(requests, r)
+	}
+	sort.Slice(requests, func(i, j int) bool {
+		return requests[i].Started.Before(requests[j].Started)
+	})
+	return requests
+}
+
+func (g *Gate) enter(r Request) (int, bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return 0, false
+	}
+	g.next++
+	g.inFlight[g.next] = r
+	return g.next, true
+}
+
+func (g *Gate) leave(id int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.inFlight, id)
+}
+
+// Operations lists the operations that have not finished yet
+type Operations interface {
+	Running() []operation.Operation
+}
+
+// Drain closes the gate and waits until neither its requests nor operations are running, or ctx is done.
+// It returns what was still running when it stopped waiting, which is nothing when everything finished.
+func Drain(ctx context.Context, gate *Gate, operations Operations) ([]Request, []operation.Operation) {
+	gate.Close()
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		requests, ops := gate.InFlight(), operations.Running()
+		if len(requests) == 0 && len(ops) == 0 {
+			return nil, nil
+		}
+		select {
+		case <-ctx.Done():
+			return requests, ops
+		case <-ticker.C:
+		}
+	}
+}
+
+func respondShuttingDown(w http.ResponseWriter, r *http.Request) {
+	logger.Infof("[Shutdown] refusing %s %s: %v", r.Method, r.URL.Path, ErrShuttingDown)
+	w.Header().Set("Connection", "close")
+	apiErrors.Write(w, apiErrors.Wrap(apiErrors.Unavailable, ErrShuttingDown))
+}
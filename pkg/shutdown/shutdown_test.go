@@ -0,0 +1,95 @@
+package shutdown
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/gojekfarm/albatross/pkg/logger"
+	"github.com/gojekfarm/albatross/pkg/operation"
+)
+
+type fakeOperations struct {
+	running func() []operation.Operation
+}
+
+func (f fakeOperations) Running() []operation.Operation {
+	return f.running()
+}
+
+func noOperations() fakeOperations {
+	return fakeOperations{running: func() []operation.Operation { return nil }}
+}
+
+func TestGateServesRequestsWhileOpen(t *testing.T) {
+	logger.Setup("default")
+	gate := NewGate()
+	called := false
+	handler := gate.Middleware("install")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		w.WriteHeader(http.StatusOK)
+	}))
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/clusters/minikube/namespaces/default/releases", nil))
+
+	assert.True(t, called)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, gate.InFlight())
+}
+
+func TestGateRefusesRequestsOnceClosed(t *testing.T) {
+	logger.Setup("default")
+	gate := NewGate()
+	handler := gate.Middleware("upgrade")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Fatal("handler should not be called")
+	}))
+	gate.Close()
+	w := httptest.NewRecorder()
+
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/clusters/minikube/namespaces/default/releases/api", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"code":"unavailable","message":"server is shutting down"}`, w.Body.String())
+}
+
+func TestDrainWaitsForInFlightRequests(t *testing.T) {
+	logger.Setup("default")
+	gate := NewGate()
+	entered := make(chan struct{})
+	release := make(chan struct{})
+	handler := gate.Middleware("uninstall")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-release
+	}))
+	go handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodDelete, "/clusters/minikube/namespaces/default/releases/api", nil))
+	<-entered
+	require.Len(t, gate.InFlight(), 1)
+	assert.Equal(t, "uninstall", gate.InFlight()[0].Action)
+
+	time.AfterFunc(10*time.Millisecond, func() { close(release) })
+	requests, ops := Drain(context.Background(), gate, noOperations())
+
+	assert.Empty(t, requests)
+	assert.Empty(t, ops)
+}
+
+func TestDrainReportsWhatIsStillRunningAfterTheGracePeriod(t *testing.T) {
+	gate := NewGate()
+	upgrade := operation.Operation{ID: "1", Kind: "upgrade", State: operation.Running}
+	operations := fakeOperations{running: func() []operation.Operation { return []operation.Operation{upgrade} }}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	requests, ops := Drain(ctx, gate, operations)
+
+	assert.Empty(t, requests)
+	assert.Equal(t, []operation.Operation{upgrade}, ops)
+	_, open := gate.enter(Request{})
+	assert.False(t, open)
+}
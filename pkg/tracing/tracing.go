@@ -0,0 +1,166 @@
+// Package tracing traces requests through the router, the api services and the helm actions with OpenTelemetry.
+// Trace context is propagated from and to W3C traceparent headers.
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.21.0"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/gojekfarm/albatross/internal/response"
+)
+
+const (
+	instrumentation = "github.com/gojekfarm/albatross"
+	serviceName     = "albatross"
+
+	// None does not export spans, trace context is still propagated
+	None = "none"
+	// Stdout writes spans as JSON to stdout
+	Stdout = "stdout"
+	// File appends spans as JSON to a file
+	File = "file"
+)
+
+// ErrUnknownExporter is returned for exporters other than None, Stdout and File
+var ErrUnknownExporter = errors.New("unknown trace exporter")
+
+// Config selects where spans are exported
+type Config struct {
+	// Exporter is None, Stdout or File, None when empty
+	Exporter string
+	// File is the path spans are appended to with the File exporter
+	File string
+	// SampleRatio is the ratio of new traces that are sampled, every trace when zero.
+	// Traces started by a caller follow the caller's sampling decision.
+	SampleRatio float64
+}
+
+// Setup installs the tracer provider of cfg and the W3C trace context propagator.
+// The returned function flushes the spans left and closes the exporter.
+func Setup(cfg Config) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var w io.Writer
+	closer := func() error { return nil }
+	switch cfg.Exporter {
+	case "", None:
+		return func(context.Context) error { return nil }, nil
+	case Stdout:
+		w = os.Stdout
+	case File:
+		if cfg.File == "" {
+			return nil, errors.New("the file trace exporter requires a file")
+		}
+		f, err := os.OpenFile(cfg.File, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+		if err != nil {
+			return nil, err
+		}
+		w, closer = f, f.Close
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrUnknownExporter, cfg.Exporter)
+	}
+
+	exporter, err := stdouttrace.New(stdouttrace.WithWriter(w))
+	if err != nil {
+		closer()
+		return nil, err
+	}
+	sampler := sdktrace.AlwaysSample()
+	if cfg.SampleRatio > 0 && cfg.SampleRatio < 1 {
+		sampler = sdktrace.TraceIDRatioBased(cfg.SampleRatio)
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sampler)),
+		sdktrace.WithResource(resource.NewWithAttributes(semconv.SchemaURL, semconv.ServiceName(serviceName))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if cerr := closer(); err == nil {
+			err = cerr
+		}
+		return err
+	}, nil
+}
+
+// Start starts a span of ctx, a noop span until Setup installs an exporter
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentation).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End ends span, marking it failed with err when err is set
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Interval is the time spent in work that has no context yet, such as creating the kube client
+// of a helm action in its constructor, to be recorded as a span once a context is known.
+type Interval struct {
+	Start, End time.Time
+}
+
+// Since returns the interval from start until now
+func Since(start time.Time) Interval {
+	return Interval{Start: start, End: time.Now()}
+}
+
+// Record records the interval as a span of ctx, the zero interval is not recorded
+func (i Interval) Record(ctx context.Context, name string, attrs ...attribute.KeyValue) {
+	if i.Start.IsZero() {
+		return
+	}
+	_, span := otel.Tracer(instrumentation).Start(ctx, name, trace.WithTimestamp(i.Start), trace.WithAttributes(attrs...))
+	span.End(trace.WithTimestamp(i.End))
+}
+
+// Middleware starts a server span for every request of a router, continuing the trace of its traceparent header.
+// Spans are named after the route template, so release names do not end up in span names.
+// The trace id is returned in the traceparent response header to find the trace of a response.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route := "unmatched"
+		if current := mux.CurrentRoute(r); current != nil {
+			if template, err := current.GetPathTemplate(); err == nil {
+				route = template
+			}
+		}
+
+		ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := otel.Tracer(instrumentation).Start(ctx, r.Method+" "+route,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(semconv.HTTPMethod(r.Method), semconv.HTTPRoute(route)),
+		)
+		defer span.End()
+		otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(w.Header()))
+
+		rw := response.Wrap(w, 0)
+		next.ServeHTTP(rw, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPStatusCode(rw.Status()))
+		if rw.Status() >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(rw.Status()))
+		}
+	})
+}
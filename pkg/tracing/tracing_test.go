@@ -0,0 +1,115 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// record installs a provider recording spans, until the returned function restores the previous one
+func record() (*tracetest.SpanRecorder, func()) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	return recorder, func() { otel.SetTracerProvider(previous) }
+}
+
+func TestMiddlewareContinuesTraceOfTraceparent(t *testing.T) {
+	_, err := Setup(Config{})
+	require.NoError(t, err)
+	recorder, restore := record()
+	defer restore()
+	var handled trace.SpanContext
+	router := mux.NewRouter()
+	router.Use(Middleware)
+	router.HandleFunc("/clusters/{cluster}/releases", func(w http.ResponseWriter, r *http.Request) {
+		handled = trace.SpanContextFromContext(r.Context())
+		w.WriteHeader(http.StatusBadGateway)
+	})
+	req := httptest.NewRequest(http.MethodGet, "/clusters/staging/releases", nil)
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, "GET /clusters/{cluster}/releases", spans[0].Name())
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext().TraceID().String())
+	assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent().SpanID().String())
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, spans[0].SpanContext().SpanID(), handled.SpanID())
+	assert.Contains(t, w.Header().Get("traceparent"), "4bf92f3577b34da6a3ce929d0e0e4736")
+}
+
+func TestEndRecordsErrors(t *testing.T) {
+	recorder, restore := record()
+	defer restore()
+
+	_, span := Start(context.Background(), "install.Install")
+	End(span, errors.New("release exists"))
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	assert.Equal(t, codes.Error, spans[0].Status().Code)
+	assert.Equal(t, "release exists", spans[0].Status().Description)
+}
+
+func TestIntervalIsRecordedWithItsTimestamps(t *testing.T) {
+	recorder, restore := record()
+	defer restore()
+	ctx, parent := Start(context.Background(), "install.Install")
+	start := time.Now().Add(-time.Second)
+
+	Since(start).Record(ctx, "helm.kube_client")
+	Interval{}.Record(ctx, "helm.kube_client")
+	parent.End()
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 2)
+	assert.Equal(t, "helm.kube_client", spans[0].Name())
+	assert.Equal(t, start, spans[0].StartTime())
+	assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent().SpanID())
+}
+
+func TestSetupExportsToFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracing")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "spans.json")
+	previous := otel.GetTracerProvider()
+	defer otel.SetTracerProvider(previous)
+
+	shutdown, err := Setup(Config{Exporter: File, File: file})
+	require.NoError(t, err)
+	_, span := Start(context.Background(), "upgrade.Upgrade")
+	span.End()
+	require.NoError(t, shutdown(context.Background()))
+
+	spans, err := ioutil.ReadFile(file)
+	require.NoError(t, err)
+	assert.Contains(t, string(spans), `"Name":"upgrade.Upgrade"`)
+}
+
+func TestSetupRejectsUnknownExporters(t *testing.T) {
+	_, err := Setup(Config{Exporter: "zipkin"})
+	assert.True(t, errors.Is(err, ErrUnknownExporter))
+
+	_, err = Setup(Config{Exporter: File})
+	assert.Error(t, err)
+}
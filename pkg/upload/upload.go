@@ -0,0 +1,175 @@
+// Package upload reads packaged charts uploaded with install and upgrade requests.
+// The chart comes either base64 encoded in the chart_archive field of the JSON body,
+// or as the chart_archive file of a multipart/form-data body whose request field holds the JSON body.
+package upload
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+const (
+	// RequestField is the multipart field holding the JSON request
+	RequestField = "request"
+	// ChartField is the multipart file holding the packaged chart
+	ChartField = "chart_archive"
+	// MaxChartSize bounds uploaded chart archives
+	MaxChartSize   = 10 << 20
+	maxRequestSize = 5 << 20
+	// MaxBodySize bounds the bodies read by middlewares before the handler, an uploaded chart with its request
+	MaxBodySize = MaxChartSize + maxRequestSize
+)
+
+var (
+	// ErrInvalidChart is returned for uploads that are not an installable chart archive
+	ErrInvalidChart = errors.New("invalid chart archive")
+	// ErrBodyTooLarge is returned by ReadBody for bodies larger than MaxBodySize
+	ErrBodyTooLarge = fmt.Errorf("request body is larger than %d bytes", MaxBodySize)
+)
+
+// ReadBody reads the body of r, at most MaxBodySize bytes, and puts it back for the handler to decode.
+// Middlewares looking into the body share it, the bytes read by the first one are returned to the others.
+func ReadBody(w http.ResponseWriter, r *http.Request) ([]byte, error) {
+	if r.Body == nil || r.Body == http.NoBody {
+		return nil, nil
+	}
+	if read, ok := r.Body.(*readBody); ok {
+		read.Reset(read.bytes)
+		return read.bytes, nil
+	}
+
+	body, err := ioutil.ReadAll(http.MaxBytesReader(w, r.Body, MaxBodySize))
+	r.Body.Close()
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return nil, ErrBodyTooLarge
+	}
+	if err != nil {
+		return nil, err
+	}
+	r.Body = &readBody{Reader: bytes.NewReader(body), bytes: body}
+	return body, nil
+}
+
+// readBody is a body put back by ReadBody
+type readBody struct {
+	*bytes.Reader
+	bytes []byte
+}
+
+func (b *readBody) Close() error {
+	return nil
+}
+
+// Decode decodes the JSON request of r into v and returns the chart archive of a multipart body
+func Decode(r *http.Request, v interface{}) ([]byte, error) {
+	boundary, ok := multipartBoundary(r)
+	if !ok {
+		return nil, json.NewDecoder(r.Body).Decode(v)
+	}
+	request, archive, err := readParts(r.Body, boundary)
+	if err != nil {
+		return nil, err
+	}
+	if request == nil {
+		return nil, fmt.Errorf("multipart body has no %s field", RequestField)
+	}
+	return archive, json.Unmarshal(request, v)
+}
+
+// RequestJSON returns the JSON request of the body read from r, the body itself unless it is multipart.
+// Malformed multipart bodies have none, the handler rejects them.
+func RequestJSON(r *http.Request, body []byte) []byte {
+	boundary, ok := multipartBoundary(r)
+	if !ok {
+		return body
+	}
+	request, _, err := readParts(bytes.NewReader(body), boundary)
+	if err != nil {
+		return nil
+	}
+	return request
+}
+
+// HasArchive tells whether the body read from r uploads a chart archive, as the chart_archive file of a multipart body
+// or in the chart_archive field of the JSON body
+func HasArchive(r *http.Request, body []byte) bool {
+	boundary, ok := multipartBoundary(r)
+	if ok {
+		_, archive, err := readParts(bytes.NewReader(body), boundary)
+		return err == nil && len(archive) > 0
+	}
+	var request struct {
+		ChartArchive []byte `json:"chart_archive"`
+	}
+	return json.Unmarshal(body, &request) == nil && len(request.ChartArchive) > 0
+}
+
+// Load loads and validates an uploaded chart archive
+func Load(archive []byte) (*chart.Chart, error) {
+	ch, err := loader.LoadArchive(bytes.NewReader(archive))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidChart, err)
+	}
+	if err := ch.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidChart, err)
+	}
+	if ch.Metadata.Type != "" && ch.Metadata.Type != "application" {
+		return nil, fmt.Errorf("%w: %s charts are not installable", ErrInvalidChart, ch.Metadata.Type)
+	}
+	return ch, nil
+}
+
+func multipartBoundary(r *http.Request) (string, bool) {
+	mediaType, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/form-data" || params["boundary"] == "" {
+		return "", false
+	}
+	return params["boundary"], true
+}
+
+func readParts(body io.Reader, boundary string) (request, archive []byte, err error) {
+	mr := multipart.NewReader(body, boundary)
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			return request, archive, nil
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		switch part.FormName() {
+		case RequestField:
+			request, err = readLimited(part, maxRequestSize)
+		case ChartField:
+			archive, err = readLimited(part, MaxChartSize)
+		default:
+			err = fmt.Errorf("unknown multipart field %q", part.FormName())
+		}
+		part.Close()
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+}
+
+func readLimited(part *multipart.Part, limit int64) ([]byte, error) {
+	b, err := ioutil.ReadAll(io.LimitReader(part, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > limit {
+		return nil, fmt.Errorf("multipart field %s is larger than %d bytes", part.FormName(), limit)
+	}
+	return b, nil
+}
@@ -0,0 +1,159 @@
+package upload
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+type request struct {
+	Name         string `json:"name"`
+	ChartArchive []byte `json:"chart_archive"`
+}
+
+func packageChart(t *testing.T, ch *chart.Chart) []byte {
+	dir, err := ioutil.TempDir("", "upload")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir)
+	path, err := chartutil.Save(ch, dir)
+	require.NoError(t, err)
+	b, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	return b
+}
+
+func testArchive(t *testing.T) []byte {
+	ch, err := loader.Load("../../api/testdata/albatross")
+	require.NoError(t, err)
+	return packageChart(t, ch)
+}
+
+func multipartRequest(t *testing.T, fields map[string][]byte) *http.Request {
+	body := &bytes.Buffer{}
+	w := multipart.NewWriter(body)
+	for name, content := range fields {
+		part, err := w.CreateFormFile(name, name)
+		require.NoError(t, err)
+		_, err = part.Write(content)
+		require.NoError(t, err)
+	}
+	require.NoError(t, w.Close())
+	r, err := http.NewRequest(http.MethodPost, "/releases", body)
+	require.NoError(t, err)
+	r.Header.Set("Content-Type", w.FormDataContentType())
+	return r
+}
+
+func TestDecodeJSONWithBase64Archive(t *testing.T) {
+	body := `{"name":"albatross","chart_archive":"` + base64.StdEncoding.EncodeToString([]byte("archive")) + `"}`
+	r, err := http.NewRequest(http.MethodPost, "/releases", bytes.NewBufferString(body))
+	require.NoError(t, err)
+	r.Header.Set("Content-Type", "application/json")
+
+	var req request
+	archive, err := Decode(r, &req)
+
+	require.NoError(t, err)
+	assert.Nil(t, archive)
+	assert.Equal(t, request{Name: "albatross", ChartArchive: []byte("archive")}, req)
+}
+
+func TestDecodeMultipart(t *testing.T) {
+	r := multipartRequest(t, map[string][]byte{RequestField: []byte(`{"name":"albatross"}`), ChartField: []byte("archive")})
+
+	var req request
+	archive, err := Decode(r, &req)
+
+	require.NoError(t, err)
+	assert.Equal(t, []byte("archive"), archive)
+	assert.Equal(t, "albatross", req.Name)
+}
+
+func TestDecodeRejectsMalformedMultipart(t *testing.T) {
+	var req request
+
+	_, err := Decode(multipartRequest(t, map[string][]byte{ChartField: []byte("archive")}), &req)
+	assert.EqualError(t, err, "multipart body has no request field")
+
+	_, err = Decode(multipartRequest(t, map[string][]byte{RequestField: []byte(`{}`), "values": []byte("archive")}), &req)
+	assert.EqualError(t, err, `unknown multipart field "values"`)
+
+	_, err = Decode(multipartRequest(t, map[string][]byte{RequestField: []byte(`{}`), ChartField: make([]byte, MaxChartSize+1)}), &req)
+	assert.EqualError(t, err, "multipart field chart_archive is larger than 10485760 bytes")
+}
+
+func TestRequestJSON(t *testing.T) {
+	r := multipartRequest(t, map[string][]byte{RequestField: []byte(`{"name":"albatross"}`), ChartField: []byte("archive")})
+	body, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"name":"albatross"}`, string(RequestJSON(r, body)))
+
+	r.Header.Set("Content-Type", "application/json")
+	assert.Equal(t, body, RequestJSON(r, body))
+}
+
+func TestHasArchive(t *testing.T) {
+	r := multipartRequest(t, map[string][]byte{RequestField: []byte(`{"name":"albatross"}`), ChartField: []byte("archive")})
+	body, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.True(t, HasArchive(r, body))
+
+	r = multipartRequest(t, map[string][]byte{RequestField: []byte(`{"name":"albatross"}`)})
+	body, err = ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+	assert.False(t, HasArchive(r, body))
+
+	r.Header.Set("Content-Type", "application/json")
+	assert.True(t, HasArchive(r, []byte(`{"chart_archive":"YXJjaGl2ZQ=="}`)))
+	assert.False(t, HasArchive(r, []byte(`{"chart":"stable/redis"}`)))
+}
+
+func TestReadBodyPutsTheBodyBackForEveryReader(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/releases", strings.NewReader(`{"name":"albatross"}`))
+
+	first, err := ReadBody(httptest.NewRecorder(), r)
+	require.NoError(t, err)
+	second, err := ReadBody(httptest.NewRecorder(), r)
+	require.NoError(t, err)
+	handler, err := ioutil.ReadAll(r.Body)
+	require.NoError(t, err)
+
+	assert.Equal(t, `{"name":"albatross"}`, string(first))
+	assert.Equal(t, first, second)
+	assert.Equal(t, first, handler)
+}
+
+func TestReadBodyRejectsLargeBodies(t *testing.T) {
+	r := httptest.NewRequest(http.MethodPost, "/releases", bytes.NewReader(make([]byte, MaxBodySize+1)))
+
+	_, err := ReadBody(httptest.NewRecorder(), r)
+
+	assert.True(t, errors.Is(err, ErrBodyTooLarge))
+}
+
+func TestLoadValidatesArchive(t *testing.T) {
+	ch, err := Load(testArchive(t))
+	require.NoError(t, err)
+	assert.Equal(t, "albatross", ch.Name())
+
+	_, err = Load([]byte("not a chart"))
+	assert.True(t, errors.Is(err, ErrInvalidChart), err)
+
+	library := &chart.Chart{Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "common", Version: "1.0.0", Type: "library"}}
+	_, err = Load(packageChart(t, library))
+	assert.EqualError(t, err, "invalid chart archive: library charts are not installable")
+}
@@ -3,6 +3,8 @@ package swagger
 import (
 	"github.com/gojekfarm/albatross/api/install"
 	"github.com/gojekfarm/albatross/api/list"
+	"github.com/gojekfarm/albatross/api/recovery"
+	"github.com/gojekfarm/albatross/api/rollback"
 	"github.com/gojekfarm/albatross/api/uninstall"
 	"github.com/gojekfarm/albatross/api/upgrade"
 )
@@ -14,12 +16,6 @@ type UninstallResponse struct {
 	Body uninstall.Response
 }
 
-// UninstallErrorResponse error body for uninstall action
-// swagger:model uninstallErrorResponse
-type UninstallErrorResponse struct {
-	Error string `json:"error"`
-}
-
 // UninstallRequest stub for swagger route for uninstall
 // swagger:parameters uninstallRelease
 type UninstallRequest struct {
@@ -41,13 +37,6 @@ type ListResponse struct {
 	Body list.Response
 }
 
-// ListErrorResponse stub for swagger route for List
-// swagger:model listErrorResponse
-type ListErrorResponse struct {
-	//in: body
-	Body list.Response
-}
-
 // InstallRequest installing a release
 // swagger:parameters installRelease
 type InstallRequest struct {
@@ -76,8 +65,16 @@ type UpgradeResponse struct {
 	Body upgrade.Response
 }
 
-// InstallErrorResponse body of install response
-// swagger:model installResponseErrorBody
-type InstallErrorResponse struct {
-	Error string `json:"error"`
+// RollbackResponse response from a rollback request
+// swagger:response rollbackResponse
+type RollbackResponse struct {
+	//in: body
+	Body rollback.Response
+}
+
+// RecoverResponse response from a recover request
+// swagger:response recoverResponse
+type RecoverResponse struct {
+	//in: body
+	Body recovery.Response
 }